@@ -2,16 +2,18 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	docs "github.com/falasefemi2/hms/docs"
 	"github.com/falasefemi2/hms/internal/config"
 	"github.com/falasefemi2/hms/internal/database"
+	"github.com/falasefemi2/hms/internal/logging"
 	"github.com/falasefemi2/hms/internal/server"
+	"github.com/falasefemi2/hms/internal/tracing"
 )
 
 // @title Hospital Management System API
@@ -30,50 +32,79 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token. Example: "Bearer eyJhbGciOiJIUzI1NiIs..."
 func main() {
+	if err := run(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// run starts the API and returns once it has stopped, so deferred cleanup
+// such as flushing traces runs on every exit path. Errors are logged before
+// they are returned.
+func run() error {
 	// Load config
 	cfg := config.LoadConfig()
+	logger := logging.New(cfg.LogLevel, os.Stdout)
+	slog.SetDefault(logger)
+
 	if cfg.Environment == "development" {
 		docs.SwaggerInfo.Host = "localhost:8080"
 	} else {
 		docs.SwaggerInfo.Host = "hms-1-fjlc.onrender.com"
 	}
-	log.Println("Configuration loaded")
+	logger.Info("configuration loaded", "environment", cfg.Environment, "log_level", cfg.LogLevel)
 
 	if err := cfg.Validate(); err != nil {
-		log.Fatalf("Configuration error: %v", err)
+		return logError(logger, "configuration error", err)
 	}
 
-	db, err := database.NewDB(cfg.GetDSN())
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Enabled:     cfg.TracingEnabled,
+		Endpoint:    cfg.TracingEndpoint,
+		ServiceName: cfg.TracingServiceName,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
 	if err != nil {
-		log.Fatalf("Database connection error: %v", err)
+		return logError(logger, "tracing setup error", err)
 	}
-	defer db.Close()
-	log.Println("Database connected!")
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("error flushing traces", "error", err)
+		}
+	}()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	db, err := database.NewDB(cfg.GetDSN(), cfg.SlowQueryThreshold)
+	if err != nil {
+		return logError(logger, "database connection error", err)
+	}
+	defer db.Close()
 
-	if err := db.InitializeSchema(ctx); err != nil {
-		log.Fatalf("Schema initialization error: %v", err)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := database.RunMigrateCommand(context.Background(), db, os.Args[2:], os.Stdout); err != nil {
+			return logError(logger, "migration error", err)
+		}
+		return nil
 	}
-	log.Println("Database schema initialized!")
 
-	srv := server.NewServer(db)
-	port := fmt.Sprintf("%d", cfg.ServerPort)
+	migrateCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-	shutdown := make(chan os.Signal, 1)
-	signal.Notify(shutdown, os.Interrupt, os.Kill)
+	if err := db.Migrate(migrateCtx); err != nil {
+		return logError(logger, "schema migration error", err)
+	}
 
-	go func() {
-		<-shutdown
-		log.Println("Received shutdown signal, shutting down gracefully...")
-		if err := srv.Shutdown(15 * time.Second); err != nil {
-			log.Printf("Error during server shutdown: %v", err)
-		}
-	}()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
 
-	log.Printf("Starting server on port %s...", port)
-	if err := srv.Start(port); err != nil {
-		log.Fatalf("Server error: %v", err)
+	srv := server.NewServer(cfg, db, logger)
+	if err := srv.Run(ctx); err != nil {
+		return logError(logger, "server error", err)
 	}
+	return nil
+}
+
+func logError(logger *slog.Logger, msg string, err error) error {
+	logger.Error(msg, "error", err)
+	return err
 }
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+)
+
+func (a *app) seedDepartments(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("seed-departments", flag.ContinueOnError)
+	file := fs.String("file", "", `JSON file containing [{"name": "...", "description": "..."}] ("-" for stdin)`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var reqs []dto.CreateDepartmentRequest
+	if err := readJSONFile(*file, &reqs); err != nil {
+		return err
+	}
+
+	created, skipped, err := a.deptService.SeedDepartments(ctx, reqs)
+	if err != nil {
+		return err
+	}
+	for _, dept := range created {
+		fmt.Printf("Created department %s (%s)\n", dept.Name, dept.ID)
+	}
+	for _, name := range skipped {
+		fmt.Printf("Skipped existing department %s\n", name)
+	}
+	return nil
+}
+
+func (a *app) exportConfig(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export-config", flag.ContinueOnError)
+	out := fs.String("out", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Oldest first, so import-config replays the revisions in order.
+	var configs []*models.HospitalConfig
+	values := url.Values{"sort": {"revision"}, "limit": {strconv.Itoa(listquery.MaxLimit)}}
+	for {
+		q, err := listquery.Parse(values, repository.HospitalConfigHistory)
+		if err != nil {
+			return err
+		}
+		page, err := a.hospitalConfigService.ListHospitalConfigHistory(ctx, q)
+		if err != nil {
+			return err
+		}
+		configs = append(configs, page.Items...)
+		if !page.HasMore {
+			break
+		}
+		values.Set("cursor", page.NextCursor)
+	}
+
+	responses := make([]dto.HospitalConfigResponse, 0, len(configs))
+	for _, config := range configs {
+		responses = append(responses, dto.HospitalConfigResponse{
+			ConfigID:                      config.ConfigID.String(),
+			Revision:                      config.Revision,
+			WorkingHoursStart:             config.WorkingHoursStart,
+			WorkingHoursEnd:               config.WorkingHoursEnd,
+			AppointmentDurationMinutes:    config.AppointmentDurationMinutes,
+			MaxSameDayCancellationHours:   config.MaxSameDayCancellationHours,
+			EnablePatientSelfRegistration: config.EnablePatientSelfRegistration,
+			EffectiveFrom:                 config.EffectiveFrom,
+			IsActive:                      config.IsActive,
+			RolledBackFrom:                config.RolledBackFrom,
+			CreatedBy:                     config.CreatedBy,
+			CreatedAt:                     config.CreatedAt,
+		})
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(responses)
+}
+
+func (a *app) importConfig(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import-config", flag.ContinueOnError)
+	file := fs.String("file", "", `JSON file containing an array of hospital configurations ("-" for stdin)`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var reqs []dto.UpdateHospitalConfigRequest
+	if err := readJSONFile(*file, &reqs); err != nil {
+		return err
+	}
+
+	for _, req := range reqs {
+		config := &models.HospitalConfig{
+			WorkingHoursStart:             strings.TrimSpace(req.WorkingHoursStart),
+			WorkingHoursEnd:               strings.TrimSpace(req.WorkingHoursEnd),
+			AppointmentDurationMinutes:    req.AppointmentDurationMinutes,
+			MaxSameDayCancellationHours:   req.MaxSameDayCancellationHours,
+			EnablePatientSelfRegistration: true,
+		}
+		if req.EnablePatientSelfRegistration != nil {
+			config.EnablePatientSelfRegistration = *req.EnablePatientSelfRegistration
+		}
+		// Revisions from an export took effect in the past; they are
+		// replayed to take effect now. Only future ones keep their time.
+		if req.EffectiveFrom != nil && req.EffectiveFrom.After(time.Now()) {
+			config.EffectiveFrom = *req.EffectiveFrom
+		}
+
+		latest, err := a.hospitalConfigService.LatestRevision(ctx)
+		if err != nil {
+			return err
+		}
+
+		created, err := a.hospitalConfigService.UpdateHospitalConfig(ctx, config, latest)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported hospital config as revision %d\n", created.Revision)
+	}
+
+	return nil
+}
+
+func readJSONFile(path string, v any) error {
+	if path == "" {
+		return errors.New("-file is required")
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
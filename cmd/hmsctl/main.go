@@ -0,0 +1,134 @@
+// Command hmsctl is the operator CLI for bootstrapping and maintaining an HMS
+// deployment. It reads the same configuration as the API server and talks to
+// the database through the service layer.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/falasefemi2/hms/internal/config"
+	"github.com/falasefemi2/hms/internal/database"
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/service"
+)
+
+const usage = `usage: hmsctl <command> [flags]
+
+Commands:
+  create-admin       create an ADMIN user
+  reset-password     set a new password for a user
+  deactivate-user    deactivate a user account; doctors' appointments are
+                     cancelled or reassigned
+  seed-departments   create departments from a JSON file
+  export-config      write the hospital configuration history as JSON
+  import-config      add hospital configuration revisions from a JSON file
+  ` + database.MigrateUsage + `
+
+Run "hmsctl <command> -h" for command flags.`
+
+type app struct {
+	db                    *database.DB
+	userService           *service.UserService
+	deptService           *service.DepartmentService
+	doctorService         *service.DoctorService
+	nurseService          *service.NurseSerivce
+	hospitalConfigService *service.HospitalConfigService
+}
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "help" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Configuration error: %v", err)
+	}
+
+	db, err := database.NewDB(cfg.GetDSN(), cfg.SlowQueryThreshold)
+	if err != nil {
+		log.Fatalf("Database connection error: %v", err)
+	}
+	defer db.Close()
+
+	userRepo := repository.NewUserRepository(db.Pool())
+	deptRepo := repository.NewDepartmentRepository(db.Pool())
+	doctorRepo := repository.NewDoctorRepository(db.Pool())
+	nurseRepo := repository.NewNurseRepository(db.Pool())
+	appointmentRepo := repository.NewAppointmentRepository(db.Pool())
+	auditRepo := repository.NewAuditRepository(db.Pool())
+	hospitalConfigRepo := repository.NewHospitalConfigRepository(db.Pool())
+	txManager := repository.NewTxManager(db.Pool())
+
+	a := &app{
+		db:                    db,
+		userService:           service.NewUserService(userRepo, txManager),
+		deptService:           service.NewDepartmentService(deptRepo, txManager),
+		doctorService:         service.NewDoctorService(doctorRepo, userRepo, deptRepo, appointmentRepo, auditRepo, txManager),
+		nurseService:          service.NewNurseService(nurseRepo, userRepo, deptRepo, auditRepo, txManager),
+		hospitalConfigService: service.NewHospitalConfigService(hospitalConfigRepo, txManager),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if err := a.run(ctx, os.Args[1], os.Args[2:]); err != nil {
+		db.Close()
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		log.Fatalf("%s: %v", os.Args[1], err)
+	}
+}
+
+func (a *app) run(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "create-admin":
+		return a.createAdmin(ctx, args)
+	case "reset-password":
+		return a.resetPassword(ctx, args)
+	case "deactivate-user":
+		return a.deactivateUser(ctx, args)
+	case "seed-departments":
+		return a.seedDepartments(ctx, args)
+	case "export-config":
+		return a.exportConfig(ctx, args)
+	case "import-config":
+		return a.importConfig(ctx, args)
+	case "migrate":
+		return database.RunMigrateCommand(ctx, a.db, args, os.Stdout)
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", command, usage)
+	}
+}
+
+// readPassword returns the flag value if set, otherwise reads a single line
+// from stdin so passwords can be piped in instead of landing in shell history.
+func readPassword(value string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("password is required")
+	}
+
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("password is required")
+	}
+	return password, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+func (a *app) createAdmin(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	username := fs.String("username", "", "username (required)")
+	email := fs.String("email", "", "email address (required)")
+	password := fs.String("password", "", "password; read from stdin when omitted")
+	firstName := fs.String("first-name", "", "first name (required)")
+	lastName := fs.String("last-name", "", "last name (required)")
+	phone := fs.String("phone", "", "phone number")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	pw, err := readPassword(*password)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.userService.CreateAdminUser(
+		ctx,
+		strings.TrimSpace(*username),
+		strings.TrimSpace(*email),
+		pw,
+		strings.TrimSpace(*firstName),
+		strings.TrimSpace(*lastName),
+		strings.TrimSpace(*phone),
+		"ADMIN",
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created admin %s (%s)\n", user.Username, user.ID)
+	return nil
+}
+
+func (a *app) resetPassword(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("reset-password", flag.ContinueOnError)
+	id := fs.String("id", "", "user id")
+	email := fs.String("email", "", "user email (alternative to -id)")
+	password := fs.String("password", "", "new password; read from stdin when omitted")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	userID, err := a.resolveUserID(ctx, *id, *email)
+	if err != nil {
+		return err
+	}
+
+	pw, err := readPassword(*password)
+	if err != nil {
+		return err
+	}
+
+	if err := a.userService.ResetPassword(ctx, userID, pw); err != nil {
+		return err
+	}
+
+	fmt.Printf("Password reset for user %s\n", userID)
+	return nil
+}
+
+func (a *app) deactivateUser(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("deactivate-user", flag.ContinueOnError)
+	id := fs.String("id", "", "user id")
+	email := fs.String("email", "", "user email (alternative to -id)")
+	reassignTo := fs.String("reassign-to", "", "doctor id to take over a doctor's upcoming appointments; they are cancelled when omitted")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	userID, err := a.resolveUserID(ctx, *id, *email)
+	if err != nil {
+		return err
+	}
+	user, err := a.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if *reassignTo != "" && user.Role != "DOCTOR" {
+		return errors.New("-reassign-to only applies to doctors")
+	}
+
+	// Doctors and nurses go through their services, which also take the
+	// doctor off the roster and write the audit log.
+	switch user.Role {
+	case "DOCTOR":
+		var to *uuid.UUID
+		if *reassignTo != "" {
+			parsed, err := uuid.Parse(*reassignTo)
+			if err != nil {
+				return fmt.Errorf("invalid -reassign-to: %w", err)
+			}
+			to = &parsed
+		}
+		doctor, err := a.doctorService.GetDoctorByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		deactivation, err := a.doctorService.DeactivateDoctor(ctx, doctor.DoctorID, to)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Deactivated doctor %s: %d appointments reassigned, %d cancelled\n",
+			userID, deactivation.AppointmentsReassigned, deactivation.AppointmentsCancelled)
+		return nil
+	case "NURSE":
+		nurse, err := a.nurseService.GetNurseByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := a.nurseService.DeactivateNurse(ctx, nurse.NurseID); err != nil {
+			return err
+		}
+	default:
+		if err := a.userService.DeactivateUser(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Deactivated user %s\n", userID)
+	return nil
+}
+
+func (a *app) resolveUserID(ctx context.Context, id, email string) (string, error) {
+	id = strings.TrimSpace(id)
+	email = strings.TrimSpace(email)
+
+	switch {
+	case id != "" && email != "":
+		return "", errors.New("use either -id or -email, not both")
+	case id != "":
+		return id, nil
+	case email != "":
+		user, err := a.userService.GetUserByEmail(ctx, email)
+		if err != nil {
+			return "", err
+		}
+		return user.ID.String(), nil
+	default:
+		return "", errors.New("-id or -email is required")
+	}
+}
@@ -26,35 +26,40 @@ const docTemplate = `{
     "paths": {
         "/admin/departments": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    },
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "Retrieves all active departments with pagination support",
+                "description": "Retrieves a page of departments, active ones unless is_active says otherwise. Walk the pages by passing next_cursor back as cursor.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "departments"
                 ],
-                "summary": "List all departments",
+                "summary": "List departments",
                 "parameters": [
                     {
                         "type": "integer",
-                        "default": 1,
-                        "description": "Page number (default 1)",
-                        "name": "page",
+                        "default": 20,
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Number of items per page (default 10, max 100)",
-                        "name": "page_size",
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "created_at or name; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Filter by active status",
+                        "name": "is_active",
                         "in": "query"
                     }
                 ],
@@ -62,30 +67,22 @@ const docTemplate = `{
                     "200": {
                         "description": "Departments retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/dto.DepartmentListResponse"
+                            "$ref": "#/definitions/dto.ListResponse-dto_DepartmentResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid pagination parameters",
+                        "description": "Invalid filter, sort, limit or cursor",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
@@ -93,7 +90,9 @@ const docTemplate = `{
                     {
                         "Bearer": []
                     }
-                ],
+                ]
+            },
+            "post": {
                 "description": "Creates a new department with the provided name and description",
                 "consumes": [
                     "application/json"
@@ -114,6 +113,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.CreateDepartmentRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -126,26 +131,22 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid request or validation error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/admin/departments/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
@@ -153,7 +154,11 @@ const docTemplate = `{
                     {
                         "Bearer": []
                     }
-                ],
+                ]
+            }
+        },
+        "/admin/departments/{id}": {
+            "get": {
                 "description": "Retrieves a single department by its ID",
                 "produces": [
                     "application/json"
@@ -176,38 +181,33 @@ const docTemplate = `{
                         "description": "Department retrieved successfully",
                         "schema": {
                             "$ref": "#/definitions/dto.DepartmentResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version, to send back as If-Match"
+                            }
                         }
                     },
                     "400": {
                         "description": "Invalid department ID",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "404": {
                         "description": "Department not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            },
-            "put": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
@@ -215,7 +215,9 @@ const docTemplate = `{
                     {
                         "Bearer": []
                     }
-                ],
+                ]
+            },
+            "put": {
                 "description": "Updates one or more fields of an existing department. Only provided fields will be updated.",
                 "consumes": [
                     "application/json"
@@ -243,6 +245,13 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.UpdateDepartmentRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from the last read",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -250,38 +259,51 @@ const docTemplate = `{
                         "description": "Department updated successfully",
                         "schema": {
                             "$ref": "#/definitions/dto.DepartmentResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version, to send back as If-Match"
+                            }
                         }
                     },
                     "400": {
                         "description": "Invalid request or validation error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "404": {
                         "description": "Department not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Department is inactive",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Department changed since it was read",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
@@ -289,7 +311,9 @@ const docTemplate = `{
                     {
                         "Bearer": []
                     }
-                ],
+                ]
+            },
+            "delete": {
                 "description": "Soft deletes a department by setting is_active to false",
                 "produces": [
                     "application/json"
@@ -318,42 +342,135 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request or department already deleted",
+                        "description": "Invalid request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "404": {
                         "description": "Department not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Department already deleted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ]
             }
         },
         "/admin/doctors": {
-            "post": {
+            "get": {
+                "description": "Retrieve a page of doctors. Walk the pages by passing next_cursor back as cursor. Requires valid JWT token with ADMIN role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Doctor Management"
+                ],
+                "summary": "List doctors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "created_at; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by department",
+                        "name": "department_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by specialization",
+                        "name": "specialization",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by availability",
+                        "name": "is_available",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum consultation fee",
+                        "name": "fee_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum consultation fee",
+                        "name": "fee_max",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of doctors",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListResponse-dto_DoctorResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
+                ]
+            },
+            "post": {
                 "description": "Create a new doctor. Requires valid JWT token with ADMIN role",
                 "consumes": [
                     "application/json"
@@ -374,6 +491,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.DoctorSignUpRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -406,17 +529,23 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
-                }
-            }
-        },
-        "/admin/doctors/availability": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/admin/doctors/availability": {
+            "post": {
                 "description": "Create doctor availability slot. Requires valid JWT token with ADMIN role",
                 "consumes": [
                     "application/json"
@@ -437,6 +566,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.AvailabilityRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -463,33 +598,51 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
-                }
-            }
-        },
-        "/admin/hospital-configs": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Get all hospital configurations. Requires valid JWT token with ADMIN role",
+                ]
+            }
+        },
+        "/admin/doctors/{id}": {
+            "get": {
+                "description": "Retrieve a doctor by ID. Requires valid JWT token with ADMIN role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Hospital Configuration"
+                    "Doctor Management"
+                ],
+                "summary": "Get a doctor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Doctor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all hospital configurations",
                 "responses": {
                     "200": {
-                        "description": "Hospital configurations retrieved successfully",
+                        "description": "Doctor",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.HospitalConfigResponse"
-                            }
+                            "$ref": "#/definitions/dto.DoctorResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid doctor ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "401": {
@@ -503,46 +656,59 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Doctor not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Create a new hospital configuration. Requires valid JWT token with ADMIN role",
+                ]
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) to a doctor. Only the fields sent are changed. Requires valid JWT token with ADMIN role",
                 "consumes": [
-                    "application/json"
+                    "application/merge-patch+json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Hospital Configuration"
+                    "Doctor Management"
                 ],
-                "summary": "Create a new hospital configuration",
+                "summary": "Partially update a doctor",
                 "parameters": [
                     {
-                        "description": "Hospital configuration details",
+                        "type": "string",
+                        "description": "Doctor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CreateHospitalConfigRequest"
+                            "$ref": "#/definitions/dto.UpdateDoctorRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Hospital configuration created successfully",
+                    "200": {
+                        "description": "Doctor updated successfully",
                         "schema": {
-                            "$ref": "#/definitions/dto.HospitalConfigResponse"
+                            "$ref": "#/definitions/dto.DoctorResponse"
                         }
                     },
                     "400": {
-                        "description": "Validation error - invalid input",
+                        "description": "Validation error",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -558,43 +724,67 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Doctor not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "License number already registered",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Body is not a JSON Merge Patch",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
-                }
-            }
-        },
-        "/admin/hospital-configs/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Get a specific hospital configuration. Requires valid JWT token with ADMIN role",
+                ]
+            }
+        },
+        "/admin/doctors/{id}/activate": {
+            "post": {
+                "description": "Re-enable a deactivated doctor's account and mark them available. Appointments moved or cancelled on deactivation are not restored. Requires valid JWT token with ADMIN role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Hospital Configuration"
+                    "Doctor Management"
                 ],
-                "summary": "Get a hospital configuration by ID",
+                "summary": "Reactivate a doctor",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Hospital configuration ID",
+                        "description": "Doctor ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Hospital configuration retrieved successfully",
+                        "description": "Doctor reactivated",
                         "schema": {
-                            "$ref": "#/definitions/dto.HospitalConfigResponse"
+                            "$ref": "#/definitions/dto.DoctorResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID format",
+                        "description": "Invalid doctor ID",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -612,20 +802,34 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Hospital configuration not found",
+                        "description": "Doctor not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Doctor is already active",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            },
-            "put": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Update an existing hospital configuration. Requires valid JWT token with ADMIN role",
+                ]
+            }
+        },
+        "/admin/doctors/{id}/deactivate": {
+            "post": {
+                "description": "Disable the doctor's account and mark them unavailable. Their upcoming pending and confirmed appointments move to reassign_to_doctor_id, or are cancelled when it is left out. Reassignment fails with 409 if any appointment would clash with the other doctor's bookings. Requires valid JWT token with ADMIN role",
                 "consumes": [
                     "application/json"
                 ],
@@ -633,36 +837,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Hospital Configuration"
+                    "Doctor Management"
                 ],
-                "summary": "Update a hospital configuration",
+                "summary": "Deactivate a doctor",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Hospital configuration ID",
+                        "description": "Doctor ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated hospital configuration details",
+                        "description": "Who takes over the appointments",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdateHospitalConfigRequest"
+                            "$ref": "#/definitions/dto.DeactivateDoctorRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Hospital configuration updated successfully",
+                        "description": "Doctor deactivated",
                         "schema": {
-                            "$ref": "#/definitions/dto.HospitalConfigResponse"
+                            "$ref": "#/definitions/dto.DoctorDeactivationResponse"
                         }
                     },
                     "400": {
-                        "description": "Validation error - invalid input or ID",
+                        "description": "Invalid ID or unusable replacement doctor",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -680,44 +890,52 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Hospital configuration not found",
+                        "description": "Doctor not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Doctor already deactivated, or the replacement is already booked",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Delete an existing hospital configuration. Requires valid JWT token with ADMIN role",
+                ]
+            }
+        },
+        "/admin/hospital-config": {
+            "get": {
+                "description": "Get the revision of the hospital configuration in force. Self-registration, booking within working hours and the other policies follow it. Requires valid JWT token with ADMIN role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Hospital Configuration"
                 ],
-                "summary": "Delete a hospital configuration",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Hospital configuration ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "Get the hospital configuration",
                 "responses": {
-                    "204": {
-                        "description": "Hospital configuration deleted successfully"
-                    },
-                    "400": {
-                        "description": "Invalid ID format",
+                    "200": {
+                        "description": "Hospital configuration in force",
                         "schema": {
-                            "$ref": "#/definitions/dto.ErrorResponse"
+                            "$ref": "#/definitions/dto.HospitalConfigResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Revision in force; a change already scheduled must be read from the history"
+                            }
                         }
                     },
                     "401": {
@@ -733,22 +951,20 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Hospital configuration not found",
+                        "description": "No revision in force yet; defaults apply",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/admin/nurses": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Create a new nurse. Requires valid JWT token with ADMIN role",
+                ]
+            },
+            "put": {
+                "description": "Add a new revision of the hospital configuration, in force from effective_from (default now). It may not be in the past or before an already scheduled revision. If-Match must name the newest revision; it may be left out only for the first. Requires valid JWT token with ADMIN role",
                 "consumes": [
                     "application/json"
                 ],
@@ -756,29 +972,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Nurse Management"
+                    "Hospital Configuration"
                 ],
-                "summary": "Create a new nurse",
+                "summary": "Change the hospital configuration",
                 "parameters": [
                     {
-                        "description": "Nurse creation details",
+                        "description": "Hospital configuration",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.NurseSignupRequest"
+                            "$ref": "#/definitions/dto.UpdateHospitalConfigRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the newest revision",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Nurse created successfully",
+                        "description": "Revision added",
                         "schema": {
-                            "$ref": "#/definitions/dto.NurseResponse"
+                            "$ref": "#/definitions/dto.HospitalConfigResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "The new revision, to send back as If-Match"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Validation error - invalid input or invalid role",
+                        "description": "Validation error - invalid input",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -795,67 +1030,91 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Conflict - nurse already exists for this user",
+                    "412": {
+                        "description": "Another revision was added since - fetch the history and retry",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/admin/users": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Retrieve a paginated list of all users in the system",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/admin/hospital-config/history": {
+            "get": {
+                "description": "Retrieve a page of hospital configuration revisions, newest first by default, including any scheduled to take effect later. is_active marks the one in force. Walk the pages by passing next_cursor back as cursor. Requires valid JWT token with ADMIN role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Management"
+                    "Hospital Configuration"
                 ],
-                "summary": "List all users with pagination (Admin only)",
+                "summary": "List hospital configuration history",
                 "parameters": [
                     {
                         "type": "integer",
-                        "default": 10,
-                        "description": "Number of users per page (default: 10, max: 100)",
+                        "default": 20,
+                        "description": "Page size (default: 20, max: 100)",
                         "name": "limit",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Number of users to skip for pagination (default: 0)",
-                        "name": "offset",
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-revision",
+                        "description": "revision; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Revisions taking effect at or after (date or RFC 3339)",
+                        "name": "effective_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Revisions taking effect at or before (date or RFC 3339)",
+                        "name": "effective_to",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of users retrieved successfully",
+                        "description": "Page of revisions",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.UserResponse"
+                            "$ref": "#/definitions/dto.ListResponse-dto_HospitalConfigResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Newest revision, to send back as If-Match"
                             }
                         }
                     },
                     "400": {
-                        "description": "Invalid pagination parameters",
+                        "description": "Invalid filter, sort, limit or cursor",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - invalid JWT token",
+                        "description": "Unauthorized - missing or invalid JWT token",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -865,52 +1124,43 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "$ref": "#/definitions/dto.ErrorResponse"
-                        }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Create a new doctor, nurse, or admin user. Requires valid JWT token with ADMIN role",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/admin/hospital-config/history/{revision}": {
+            "get": {
+                "description": "Get one revision from the hospital configuration history. Requires valid JWT token with ADMIN role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Management"
+                    "Hospital Configuration"
                 ],
-                "summary": "Create a new user (Admin only)",
+                "summary": "Get a hospital configuration revision",
                 "parameters": [
                     {
-                        "description": "User creation details with role",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.AdminCreateUserRequest"
-                        }
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "User created successfully",
+                    "200": {
+                        "description": "Revision",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponse"
+                            "$ref": "#/definitions/dto.HospitalConfigResponse"
                         }
                     },
                     "400": {
-                        "description": "Validation error - invalid input or invalid role",
+                        "description": "Invalid revision",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -927,23 +1177,23 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Conflict - email or username already registered",
+                    "404": {
+                        "description": "Revision not found",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/admin/users/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Retrieve a specific user's details by their ID",
+                ]
+            }
+        },
+        "/admin/hospital-config/rollback": {
+            "post": {
+                "description": "Restore the settings of an earlier revision by adding them as a new revision, in force from effective_from (default now). The history keeps both the change and its rollback. If-Match must name the newest revision. Requires valid JWT token with ADMIN role",
                 "consumes": [
                     "application/json"
                 ],
@@ -951,27 +1201,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User Management"
+                    "Hospital Configuration"
                 ],
-                "summary": "Get user by ID (Admin only)",
+                "summary": "Roll back the hospital configuration",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID (UUID format)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Revision to restore",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RollbackHospitalConfigRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the newest revision",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "User details retrieved successfully",
+                    "201": {
+                        "description": "Revision added",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponse"
+                            "$ref": "#/definitions/dto.HospitalConfigResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "The new revision, to send back as If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error or unknown revision",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - invalid JWT token",
+                        "description": "Unauthorized - missing or invalid JWT token",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -982,23 +1259,104 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not found - user does not exist",
+                    "412": {
+                        "description": "Another revision was added since - fetch the history and retry",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/appointments": {
-            "post": {
+        "/admin/nurses": {
+            "get": {
+                "description": "Retrieve a page of nurses. Walk the pages by passing next_cursor back as cursor. Requires valid JWT token with ADMIN role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Nurse Management"
+                ],
+                "summary": "List nurses",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "created_at; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by department",
+                        "name": "department_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by shift",
+                        "name": "shift",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of nurses",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListResponse-dto_NurseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Create a new appointment. Requires valid JWT token",
+                ]
+            },
+            "post": {
+                "description": "Create a new nurse. Requires valid JWT token with ADMIN role",
                 "consumes": [
                     "application/json"
                 ],
@@ -1006,64 +1364,85 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Appointment Management"
+                    "Nurse Management"
                 ],
-                "summary": "Create a new appointment",
+                "summary": "Create a new nurse",
                 "parameters": [
                     {
-                        "description": "Appointment creation details",
+                        "description": "Nurse creation details",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CreateAppointmentRequest"
+                            "$ref": "#/definitions/dto.NurseSignupRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Appointment created successfully",
+                        "description": "Nurse created successfully",
                         "schema": {
-                            "$ref": "#/definitions/dto.AppointmentResponse"
+                            "$ref": "#/definitions/dto.NurseResponse"
                         }
                     },
                     "400": {
-                        "description": "Validation error",
+                        "description": "Validation error - invalid input or invalid role",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - nurse already exists for this user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/appointments/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Get appointment details by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/admin/nurses/{id}": {
+            "get": {
+                "description": "Retrieve a nurse by ID. Requires valid JWT token with ADMIN role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Appointment Management"
+                    "Nurse Management"
                 ],
-                "summary": "Get appointment by ID",
+                "summary": "Get a nurse",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Appointment ID",
+                        "description": "Nurse ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1071,65 +1450,77 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Appointment details",
+                        "description": "Nurse",
                         "schema": {
-                            "$ref": "#/definitions/dto.AppointmentResponse"
+                            "$ref": "#/definitions/dto.NurseResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid nurse ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - admin role required",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Appointment not found",
+                        "description": "Nurse not found",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            },
-            "put": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Update appointment details",
+                ]
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) to a nurse. Only the fields sent are changed. Requires valid JWT token with ADMIN role",
                 "consumes": [
-                    "application/json"
+                    "application/merge-patch+json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Appointment Management"
+                    "Nurse Management"
                 ],
-                "summary": "Update an appointment",
+                "summary": "Partially update a nurse",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Appointment ID",
+                        "description": "Nurse ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Appointment update details",
+                        "description": "Fields to change",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdateAppointmentRequest"
+                            "$ref": "#/definitions/dto.UpdateNurseRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Appointment updated successfully",
+                        "description": "Nurse updated successfully",
                         "schema": {
-                            "$ref": "#/definitions/dto.AppointmentResponse"
+                            "$ref": "#/definitions/dto.NurseResponse"
                         }
                     },
                     "400": {
@@ -1138,213 +1529,244 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
                     "404": {
-                        "description": "Appointment not found",
+                        "description": "Nurse not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "License number already registered",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Body is not a JSON Merge Patch",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/auth/login": {
+        "/admin/nurses/{id}/activate": {
             "post": {
-                "description": "Authenticate a user and receive a JWT token.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Re-enable a deactivated nurse's account. Requires valid JWT token with ADMIN role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Nurse Management"
                 ],
-                "summary": "User login",
+                "summary": "Reactivate a nurse",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Nurse ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login successful, token returned",
+                        "description": "Nurse reactivated",
                         "schema": {
-                            "$ref": "#/definitions/dto.LoginResponse"
+                            "$ref": "#/definitions/dto.NurseResponse"
                         }
                     },
                     "400": {
-                        "description": "Validation error - invalid input format",
+                        "description": "Invalid nurse ID",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - invalid credentials",
+                        "description": "Unauthorized - missing or invalid JWT token",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/auth/signup": {
-            "post": {
-                "description": "Allow patients to register without authentication. Role is automatically set to PATIENT",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Authentication"
-                ],
-                "summary": "Patient self-registration",
-                "parameters": [
-                    {
-                        "description": "Patient signup details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                    },
+                    "403": {
+                        "description": "Forbidden - admin role required",
                         "schema": {
-                            "$ref": "#/definitions/dto.PatientSignUpRequest"
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Patient registered successfully",
+                    },
+                    "404": {
+                        "description": "Nurse not found",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserResponse"
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Validation error - invalid input format",
+                    "409": {
+                        "description": "Nurse is already active",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Conflict - email or username already registered",
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/consultations": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Create a new consultation for a completed appointment",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/admin/nurses/{id}/deactivate": {
+            "post": {
+                "description": "Disable the nurse's account. The nurse record and the care they recorded are kept. Requires valid JWT token with ADMIN role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Consultation Management"
+                    "Nurse Management"
                 ],
-                "summary": "Create a new consultation",
+                "summary": "Deactivate a nurse",
                 "parameters": [
                     {
-                        "description": "Consultation creation details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.CreateConsultationRequest"
-                        }
+                        "type": "string",
+                        "description": "Nurse ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Consultation created successfully",
+                    "200": {
+                        "description": "Nurse deactivated",
                         "schema": {
-                            "$ref": "#/definitions/dto.ConsultationResponse"
+                            "$ref": "#/definitions/dto.NurseResponse"
                         }
                     },
                     "400": {
-                        "description": "Validation error",
+                        "description": "Invalid nurse ID",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Nurse not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Nurse is already deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/consultations/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Get consultation details by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/admin/patient-invites": {
+            "post": {
+                "description": "Issue a one-time code a patient can sign up with while self-registration is disabled. The code is returned only in this response. Requires valid JWT token with ADMIN role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Consultation Management"
+                    "User Management"
                 ],
-                "summary": "Get consultation by ID",
+                "summary": "Issue a patient invite code (Admin only)",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Consultation ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Consultation details",
+                    "201": {
+                        "description": "Invite code issued",
                         "schema": {
-                            "$ref": "#/definitions/dto.ConsultationResponse"
+                            "$ref": "#/definitions/dto.PatientInviteResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid ID",
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Consultation not found",
+                    "403": {
+                        "description": "Forbidden - admin role required",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            },
-            "put": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Update consultation details",
+                ]
+            }
+        },
+        "/admin/patients/{id}/merge": {
+            "post": {
+                "description": "Move the duplicate's appointments, consultations, prescriptions, vitals, lab tests and care notes to this patient, fill this patient's blank details from the duplicate, then delete the duplicate and deactivate its user account. The merge is recorded in the audit log with a snapshot of the duplicate. Requires ADMIN role",
                 "consumes": [
                     "application/json"
                 ],
@@ -1352,81 +1774,81 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Consultation Management"
+                    "Patient Management"
                 ],
-                "summary": "Update a consultation",
+                "summary": "Merge a duplicate patient into this one",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Consultation ID",
+                        "description": "ID of the patient to keep",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Consultation update details",
+                        "description": "Patient to merge away",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdateConsultationRequest"
+                            "$ref": "#/definitions/dto.MergePatientRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Consultation updated successfully",
+                        "description": "Patients merged",
                         "schema": {
-                            "$ref": "#/definitions/dto.ConsultationResponse"
+                            "$ref": "#/definitions/dto.PatientMergeResponse"
                         }
                     },
                     "400": {
-                        "description": "Validation error",
+                        "description": "Invalid ID, same patient twice, or duplicate not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - admin role required",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Consultation not found",
+                        "description": "Patient not found",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/health": {
-            "get": {
-                "description": "get the status of server.",
-                "consumes": [
-                    "*/*"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "root"
-                ],
-                "summary": "Show the status of server.",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/patients/patientprofile": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Create patient profile. Requires valid JWT token with PATIENT role",
+                ]
+            }
+        },
+        "/admin/staff": {
+            "post": {
+                "description": "Create the user account, the doctor or nurse profile in their department and, for doctors, their weekly availability in one transaction: either all of it is created or none. The account has no usable password; an invitation to set one is sent afterwards, and invitation_sent reports whether that worked. If it was not sent, invitation_link holds the set-password link for the admin to pass on; it is not shown again. Requires valid JWT token with ADMIN role",
                 "consumes": [
                     "application/json"
                 ],
@@ -1434,29 +1856,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Patient Management"
+                    "Staff Management"
                 ],
-                "summary": "Create patient profile",
+                "summary": "Onboard a doctor or nurse",
                 "parameters": [
                     {
-                        "description": "Patient Profile details",
+                        "description": "New staff member",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.PatientSignUp"
+                            "$ref": "#/definitions/dto.OnboardStaffRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Patient Profile created successfully",
+                        "description": "Staff member onboarded",
                         "schema": {
-                            "$ref": "#/definitions/dto.PatientResponse"
+                            "$ref": "#/definitions/dto.OnboardStaffResponse"
                         }
                     },
                     "400": {
-                        "description": "Validation error - invalid input or invalid role",
+                        "description": "Validation error, unknown or inactive department",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
@@ -1468,344 +1896,3482 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden - patient role required",
+                        "description": "Forbidden - admin role required",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     },
                     "409": {
-                        "description": "Conflict - patient already exists for this user",
+                        "description": "Username, email or license number already registered",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
                     }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "dto.AdminCreateUserRequest": {
-            "type": "object",
-            "required": [
-                "email",
-                "first_name",
-                "last_name",
-                "password",
-                "role",
-                "username"
-            ],
-            "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "first_name": {
-                    "type": "string",
-                    "maxLength": 255
-                },
-                "last_name": {
-                    "type": "string",
-                    "maxLength": 255
-                },
-                "password": {
-                    "type": "string",
-                    "minLength": 8
-                },
-                "phone": {
-                    "type": "string",
-                    "maxLength": 20
-                },
-                "role": {
-                    "type": "string",
-                    "enum": [
-                        "DOCTOR",
-                        "NURSE",
-                        "ADMIN"
-                    ]
                 },
-                "username": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 3
-                }
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "dto.AppointmentResponse": {
-            "type": "object",
-            "properties": {
-                "appointment_date": {
-                    "type": "string"
-                },
-                "appointment_id": {
-                    "type": "string"
+        "/admin/staff/{user_id}/invitation": {
+            "post": {
+                "description": "Revoke the user's unused set-password invitations and send a new one. If it cannot be sent, sent is false and link holds the set-password link for the admin to pass on. Requires valid JWT token with ADMIN role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff Management"
+                ],
+                "summary": "Resend a staff invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New invitation issued",
+                        "schema": {
+                            "$ref": "#/definitions/dto.InvitationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID, or the user is a patient",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "User is deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "description": "Retrieve a page of users. Walk the pages by passing next_cursor back as cursor.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "List users (Admin only)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "created_at, username or email; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by active status",
+                        "name": "is_active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (date or RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or before (date or RFC 3339)",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of users",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListResponse-dto_UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Create a new doctor, nurse, or admin user. Requires valid JWT token with ADMIN role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Create a new user (Admin only)",
+                "parameters": [
+                    {
+                        "description": "User creation details with role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AdminCreateUserRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error - invalid input or invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - email or username already registered",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/users/{id}": {
+            "get": {
+                "description": "Retrieve a specific user's details by their ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Get user by ID (Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User details retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found - user does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) to a user. Only the fields sent are changed. The role and password cannot be changed here, nor is_active of a doctor or nurse: use their deactivate and activate endpoints, which also handle appointments and the audit log.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Partially update a user (Admin only)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found - user does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Username or email already taken",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Body is not a JSON Merge Patch",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/appointments": {
+            "get": {
+                "description": "Retrieve a page of appointments. Walk the pages by passing next_cursor back as cursor. Requires ADMIN, DOCTOR or NURSE role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Appointment Management"
+                ],
+                "summary": "List appointments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-appointment_date",
+                        "description": "appointment_date or created_at; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status, e.g. CONFIRMED",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by patient",
+                        "name": "patient_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by doctor",
+                        "name": "doctor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Appointments at or after (date or RFC 3339)",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Appointments at or before (date or RFC 3339)",
+                        "name": "date_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of appointments",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListResponse-dto_AppointmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - staff role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Create a new appointment. A patient books for themselves and patient_id is taken from their token; staff must give patient_id. Requires valid JWT token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Appointment Management"
+                ],
+                "summary": "Create a new appointment",
+                "parameters": [
+                    {
+                        "description": "Appointment creation details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateAppointmentRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Appointment created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AppointmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - a patient booking for another patient",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/appointments/{id}": {
+            "get": {
+                "description": "Get appointment details by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Appointment Management"
+                ],
+                "summary": "Get appointment by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Appointment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Appointment details",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AppointmentResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version, to send back as If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Another patient's appointment",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Appointment not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "put": {
+                "description": "Update appointment details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Appointment Management"
+                ],
+                "summary": "Update an appointment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Appointment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Appointment update details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateAppointmentRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from the last read",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Appointment updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AppointmentResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version, to send back as If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Another patient's appointment, or a field the caller may not change",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Appointment not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Appointment is completed or cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Modified since it was read - fetch again and retry",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) to an appointment. Only the fields sent are changed. Patients may only change notes; staff may also change the date, duration and status.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Appointment Management"
+                ],
+                "summary": "Partially update an appointment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Appointment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateAppointmentRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from the last read",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Appointment updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AppointmentResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version, to send back as If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Another patient's appointment, or a field the caller may not change",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Appointment not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Appointment is completed or cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Modified since it was read - fetch again and retry",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Body is not a JSON Merge Patch",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/auth/accept-invitation": {
+            "post": {
+                "description": "Redeem the token from a staff invitation by choosing a password. Each invitation works once and only until it expires",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Set a password from an invitation",
+                "parameters": [
+                    {
+                        "description": "Invitation token and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AcceptInvitationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password set",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invitation invalid, used or expired, or password too weak",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests - see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Authenticate a user and receive a JWT token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful, token returned",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error - invalid input format",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - account is deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests - see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/signup": {
+            "post": {
+                "description": "Allow patients to register without authentication. Role is automatically set to PATIENT. When the active hospital configuration disables self-registration, an invite code from an admin is required",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Patient self-registration",
+                "parameters": [
+                    {
+                        "description": "Patient signup details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PatientSignUpRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Patient registered successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error - invalid input format, or an invalid, expired or used invite code",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Self-registration is disabled and no invite code was given",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - email or username already registered",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests - see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/consultations": {
+            "post": {
+                "description": "Create a new consultation for a completed appointment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Consultation Management"
+                ],
+                "summary": "Create a new consultation",
+                "parameters": [
+                    {
+                        "description": "Consultation creation details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateConsultationRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Consultation created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConsultationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Consultation already exists or appointment not completed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/consultations/{id}": {
+            "get": {
+                "description": "Get consultation details by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Consultation Management"
+                ],
+                "summary": "Get consultation by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Consultation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Consultation details",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConsultationResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version, to send back as If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Another patient's consultation",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Consultation not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "put": {
+                "description": "Update consultation details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Consultation Management"
+                ],
+                "summary": "Update a consultation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Consultation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Consultation update details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateConsultationRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from the last read",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Consultation updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConsultationResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version, to send back as If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Consultation not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Consultation is not editable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Modified since it was read - fetch again and retry",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) to a consultation. Only the fields sent are changed. Only doctors and admins may change the diagnosis or notes.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Consultation Management"
+                ],
+                "summary": "Partially update a consultation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Consultation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateConsultationRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from the last read",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Consultation updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConsultationResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version, to send back as If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not permitted to change one of the fields",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Consultation not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Consultation is not editable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Modified since it was read - fetch again and retry",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Body is not a JSON Merge Patch",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header missing",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/doctors": {
+            "get": {
+                "description": "Browse doctors with active accounts before booking, with their department and next free 30-minute slot in the coming two weeks (null if none or the doctor is unavailable). Walk the pages by passing next_cursor back as cursor. Requires a valid JWT token with any role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Doctor Directory"
+                ],
+                "summary": "Doctor directory",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "last_name",
+                        "description": "last_name; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by specialization",
+                        "name": "specialization",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by department",
+                        "name": "department_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by availability",
+                        "name": "is_available",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum consultation fee",
+                        "name": "fee_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum consultation fee",
+                        "name": "fee_max",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of doctors",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListResponse-dto_DoctorDirectoryEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/doctors/me": {
+            "get": {
+                "description": "Retrieve the calling doctor's profile and account details. Requires valid JWT token with DOCTOR role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Doctor Management"
+                ],
+                "summary": "Get my doctor profile",
+                "responses": {
+                    "200": {
+                        "description": "Profile",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DoctorProfileResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - doctor role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No doctor profile for this user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) to the calling doctor's own name, phone and availability. Other fields are managed by admins. Requires valid JWT token with DOCTOR role",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Doctor Management"
+                ],
+                "summary": "Update my doctor profile",
+                "parameters": [
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateDoctorProfileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile updated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DoctorProfileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error or a field doctors cannot change",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - doctor role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No doctor profile for this user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Body is not a JSON Merge Patch",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "Reports that the process is running. It does not check dependencies.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "root"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/nurses/me": {
+            "get": {
+                "description": "Retrieve the calling nurse's profile and account details. Requires valid JWT token with NURSE role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Nurse Management"
+                ],
+                "summary": "Get my nurse profile",
+                "responses": {
+                    "200": {
+                        "description": "Profile",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NurseProfileResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - nurse role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No nurse profile for this user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) to the calling nurse's own name and phone. Shift, department and license are managed by admins. Requires valid JWT token with NURSE role",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Nurse Management"
+                ],
+                "summary": "Update my nurse profile",
+                "parameters": [
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateNurseProfileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile updated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NurseProfileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error or a field nurses cannot change",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - nurse role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No nurse profile for this user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Body is not a JSON Merge Patch",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/patients": {
+            "get": {
+                "description": "Retrieve a page of patient profiles. Walk the pages by passing next_cursor back as cursor. Requires ADMIN, DOCTOR or NURSE role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patient Management"
+                ],
+                "summary": "List patients",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "created_at; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by gender",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by blood group",
+                        "name": "blood_group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Born on or after (date)",
+                        "name": "born_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Born on or before (date)",
+                        "name": "born_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by medical record number",
+                        "name": "mrn",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of patients",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListResponse-dto_PatientResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - staff role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/patients/me": {
+            "get": {
+                "description": "Retrieve the calling patient's profile and account details. Requires valid JWT token with PATIENT role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patient Portal"
+                ],
+                "summary": "Get my patient profile",
+                "responses": {
+                    "200": {
+                        "description": "Profile",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PatientProfileResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - patient role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No patient profile for this user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) to the calling patient's name, phone and emergency contact. Date of birth, blood group and medical history are kept by staff. Requires valid JWT token with PATIENT role",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patient Portal"
+                ],
+                "summary": "Update my patient profile",
+                "parameters": [
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdatePatientProfileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile updated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PatientProfileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error or a field patients cannot change",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - patient role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No patient profile for this user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Body is not a JSON Merge Patch",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/patients/me/appointments": {
+            "get": {
+                "description": "Retrieve a page of the calling patient's upcoming appointments, soonest first by default, or past ones (completed, cancelled or already started), most recent first by default. Walk the pages by passing next_cursor back as cursor. Requires valid JWT token with PATIENT role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patient Portal"
+                ],
+                "summary": "List my appointments",
+                "parameters": [
+                    {
+                        "enum": [
+                            "upcoming",
+                            "past"
+                        ],
+                        "type": "string",
+                        "default": "upcoming",
+                        "description": "Which appointments to list",
+                        "name": "when",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "appointment_date or created_at; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status, e.g. CONFIRMED",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Appointments at or after (date or RFC 3339)",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Appointments at or before (date or RFC 3339)",
+                        "name": "date_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of appointments",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListResponse-dto_AppointmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid when, filter, sort, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - patient role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No patient profile for this user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/patients/me/consultations": {
+            "get": {
+                "description": "Retrieve a page of the calling patient's consultations, newest first by default. Walk the pages by passing next_cursor back as cursor. Requires valid JWT token with PATIENT role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patient Portal"
+                ],
+                "summary": "List my consultations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "created_at; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Consultations at or after (date or RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Consultations at or before (date or RFC 3339)",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of consultations",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListResponse-dto_ConsultationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - patient role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No patient profile for this user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/patients/me/lab-results": {
+            "get": {
+                "description": "List the calling patient's completed lab tests, most recently completed first. Tests still requested or in progress are not shown. Requires valid JWT token with PATIENT role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patient Portal"
+                ],
+                "summary": "List my lab results",
+                "responses": {
+                    "200": {
+                        "description": "Lab results",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.LabResultResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - patient role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No patient profile for this user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/patients/me/prescriptions": {
+            "get": {
+                "description": "Retrieve a page of the calling patient's prescriptions, newest first by default. Walk the pages by passing next_cursor back as cursor. Requires valid JWT token with PATIENT role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patient Portal"
+                ],
+                "summary": "List my prescriptions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "created_at; prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Prescriptions at or after (date or RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Prescriptions at or before (date or RFC 3339)",
+                        "name": "created_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of prescriptions",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListResponse-dto_PrescriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - patient role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No patient profile for this user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/patients/me/records": {
+            "get": {
+                "description": "Download the calling patient's profile, appointments, consultations, prescriptions and completed lab results as one JSON file. Each download is audited. Requires valid JWT token with PATIENT role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patient Portal"
+                ],
+                "summary": "Download my records",
+                "responses": {
+                    "200": {
+                        "description": "Records, sent as an attachment",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PatientRecordResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - patient role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No patient profile for this user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/patients/patientprofile": {
+            "post": {
+                "description": "Create patient profile. A patient creates their own profile and user_id is taken from their token; staff must give user_id. Requires valid JWT token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patient Management"
+                ],
+                "summary": "Create patient profile",
+                "parameters": [
+                    {
+                        "description": "Patient Profile details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PatientSignUp"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe; the first response is replayed",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Patient Profile created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PatientResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error - invalid input or invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - a patient naming another user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - patient already exists for this user",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/patients/search": {
+            "get": {
+                "description": "Find patients by fuzzy name, phone number and exact date of birth, best match first. All criteria given must match; at least one is required. Requires ADMIN, DOCTOR or NURSE role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patient Management"
+                ],
+                "summary": "Search patients",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First and/or last name; misspellings are tolerated",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Phone number in any format; matched on its last digits",
+                        "name": "phone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact date of birth (2006-01-02)",
+                        "name": "dob",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum results (default: 20, max: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching patients",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListResponse-dto_PatientMatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "No criteria or invalid criteria",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - staff role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/patients/{id}": {
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) to a patient profile. Only the fields sent are changed. Requires valid JWT token with ADMIN, DOCTOR or NURSE role",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patient Management"
+                ],
+                "summary": "Partially update a patient profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Patient ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdatePatientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Patient profile updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PatientResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - staff role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Patient not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Body is not a JSON Merge Patch",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/patients/{id}/duplicates": {
+            "get": {
+                "description": "List patients that agree with this one on at least two of name (fuzzy), date of birth and phone, strongest candidates first. Requires ADMIN, DOCTOR or NURSE role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Patient Management"
+                ],
+                "summary": "Find possible duplicates of a patient",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Patient ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum results (default: 20, max: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Possible duplicates",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ListResponse-dto_DuplicateCandidateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid patient ID or limit",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - missing or invalid JWT token",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - staff role required",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Patient not found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Checks the database connection, schema version and connection pool saturation, and reports each component's status and latency. Returns 503 while any check fails or the server is draining.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "root"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.HealthResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.AcceptInvitationRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminCreateUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "first_name",
+                "last_name",
+                "password",
+                "role",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "last_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "DOCTOR",
+                        "NURSE",
+                        "ADMIN"
+                    ]
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                }
+            }
+        },
+        "dto.AppointmentResponse": {
+            "type": "object",
+            "properties": {
+                "appointment_date": {
+                    "type": "string"
+                },
+                "appointment_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "doctor_id": {
+                    "type": "string"
+                },
+                "duration_minutes": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.AvailabilityRequest": {
+            "type": "object",
+            "required": [
+                "day_of_week",
+                "doctor_id",
+                "end_time",
+                "max_appointments",
+                "start_time"
+            ],
+            "properties": {
+                "day_of_week": {
+                    "type": "string",
+                    "enum": [
+                        "Monday",
+                        "Tuesday",
+                        "Wednesday",
+                        "Thursday",
+                        "Friday",
+                        "Saturday",
+                        "Sunday"
+                    ]
+                },
+                "doctor_id": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "max_appointments": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AvailabilityResponse": {
+            "type": "object",
+            "properties": {
+                "availability_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "day_of_week": {
+                    "type": "string"
+                },
+                "doctor_id": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "max_appointments": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ComponentHealth": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "database ping failed"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "name": {
+                    "type": "string",
+                    "example": "database"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "dto.ConsultationResponse": {
+            "type": "object",
+            "properties": {
+                "appointment_id": {
+                    "type": "string"
+                },
+                "consultation_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "diagnosis": {
+                    "type": "string"
+                },
+                "doctor_id": {
+                    "type": "string"
+                },
+                "is_editable": {
+                    "type": "boolean"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CreateAppointmentRequest": {
+            "type": "object",
+            "required": [
+                "appointment_date",
+                "doctor_id",
+                "duration_minutes"
+            ],
+            "properties": {
+                "appointment_date": {
+                    "type": "string"
+                },
+                "doctor_id": {
+                    "type": "string"
+                },
+                "duration_minutes": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "description": "PatientID is required for staff. Patients book for themselves and may\nleave it out.",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateConsultationRequest": {
+            "type": "object",
+            "required": [
+                "appointment_id",
+                "diagnosis",
+                "doctor_id",
+                "patient_id"
+            ],
+            "properties": {
+                "appointment_id": {
+                    "type": "string"
+                },
+                "diagnosis": {
+                    "type": "string"
+                },
+                "doctor_id": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "patient_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateDepartmentRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                }
+            }
+        },
+        "dto.DeactivateDoctorRequest": {
+            "type": "object",
+            "properties": {
+                "reassign_to_doctor_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DepartmentResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.DoctorDeactivationResponse": {
+            "type": "object",
+            "properties": {
+                "appointments_cancelled": {
+                    "type": "integer"
+                },
+                "appointments_reassigned": {
+                    "type": "integer"
+                },
+                "doctor": {
+                    "$ref": "#/definitions/dto.DoctorResponse"
+                },
+                "reassigned_to": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DoctorDirectoryEntry": {
+            "type": "object",
+            "properties": {
+                "consultation_fee": {
+                    "type": "number"
+                },
+                "department_id": {
+                    "type": "string"
+                },
+                "department_name": {
+                    "type": "string"
+                },
+                "doctor_id": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "is_available": {
+                    "type": "boolean"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "next_available_slot": {
+                    "type": "string"
+                },
+                "specialization": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DoctorProfileResponse": {
+            "type": "object",
+            "properties": {
+                "doctor": {
+                    "$ref": "#/definitions/dto.DoctorResponse"
+                },
+                "user": {
+                    "$ref": "#/definitions/dto.UserResponse"
+                }
+            }
+        },
+        "dto.DoctorResponse": {
+            "type": "object",
+            "properties": {
+                "consultation_fee": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "department_id": {
+                    "type": "string"
+                },
+                "doctor_id": {
+                    "type": "string"
+                },
+                "is_available": {
+                    "type": "boolean"
+                },
+                "license_number": {
+                    "type": "string"
+                },
+                "specialization": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DoctorSignUpRequest": {
+            "type": "object",
+            "required": [
+                "consultation_fee",
+                "department_id",
+                "license_number",
+                "specialization",
+                "user_id"
+            ],
+            "properties": {
+                "consultation_fee": {
+                    "type": "number"
+                },
+                "department_id": {
+                    "type": "string"
+                },
+                "license_number": {
+                    "type": "string"
+                },
+                "specialization": {
+                    "type": "string"
                 },
-                "created_at": {
+                "user_id": {
                     "type": "string"
+                }
+            }
+        },
+        "dto.DuplicateCandidateResponse": {
+            "type": "object",
+            "properties": {
+                "date_of_birth": {
+                    "type": "string",
+                    "example": "1990-01-31"
                 },
-                "doctor_id": {
+                "email": {
                     "type": "string"
                 },
-                "duration_minutes": {
-                    "type": "integer"
+                "first_name": {
+                    "type": "string"
                 },
-                "notes": {
+                "gender": {
+                    "type": "string"
+                },
+                "last_name": {
                     "type": "string"
                 },
+                "matched_on": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "date_of_birth",
+                        "name"
+                    ]
+                },
+                "mrn": {
+                    "type": "string",
+                    "example": "MRN-2026-000042"
+                },
                 "patient_id": {
                     "type": "string"
                 },
-                "status": {
+                "phone": {
                     "type": "string"
                 },
-                "updated_at": {
+                "score": {
+                    "type": "number"
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "dto.AvailabilityRequest": {
+        "dto.ErrorResponse": {
             "type": "object",
             "properties": {
-                "day_of_week": {
-                    "type": "string"
+                "code": {
+                    "type": "string",
+                    "example": "appointment_not_found"
                 },
-                "doctor_id": {
-                    "type": "string"
+                "detail": {
+                    "type": "string",
+                    "example": "appointment not found"
                 },
-                "end_time": {
-                    "type": "string"
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldErrorResponse"
+                    }
                 },
-                "max_appointments": {
-                    "type": "integer"
+                "instance": {
+                    "type": "string",
+                    "example": "/appointments/3f0c2a9e-1c1b-4d3e-9d8a-6b1f0e2c7a11"
                 },
-                "start_time": {
-                    "type": "string"
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
                 }
             }
         },
-        "dto.AvailabilityResponse": {
+        "dto.FieldErrorResponse": {
             "type": "object",
             "properties": {
-                "availability_id": {
+                "field": {
+                    "type": "string",
+                    "example": "appointment_date"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "appointment date must be in the future"
+                }
+            }
+        },
+        "dto.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ComponentHealth"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "dto.HospitalConfigResponse": {
+            "type": "object",
+            "properties": {
+                "appointment_duration_minutes": {
+                    "type": "integer"
+                },
+                "config_id": {
                     "type": "string"
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "day_of_week": {
+                "created_by": {
                     "type": "string"
                 },
-                "doctor_id": {
+                "effective_from": {
                     "type": "string"
                 },
-                "end_time": {
-                    "type": "string"
+                "enable_patient_self_registration": {
+                    "type": "boolean"
                 },
-                "max_appointments": {
+                "is_active": {
+                    "type": "boolean"
+                },
+                "max_same_day_cancellation_hours": {
                     "type": "integer"
                 },
-                "start_time": {
+                "revision": {
+                    "type": "integer"
+                },
+                "rolled_back_from": {
+                    "type": "integer"
+                },
+                "working_hours_end": {
                     "type": "string"
                 },
-                "updated_at": {
+                "working_hours_start": {
                     "type": "string"
                 }
             }
         },
-        "dto.ConsultationResponse": {
+        "dto.InvitationResponse": {
             "type": "object",
             "properties": {
-                "appointment_id": {
+                "expires_at": {
                     "type": "string"
                 },
-                "consultation_id": {
+                "link": {
+                    "description": "Link is set only when the invitation was not sent.",
                     "type": "string"
                 },
-                "created_at": {
+                "sent": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.LabResultResponse": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
                     "type": "string"
                 },
-                "diagnosis": {
+                "doctor_id": {
                     "type": "string"
                 },
-                "doctor_id": {
+                "notes": {
                     "type": "string"
                 },
-                "is_editable": {
+                "requested_at": {
+                    "type": "string"
+                },
+                "result_file_path": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "COMPLETED"
+                },
+                "test_id": {
+                    "type": "string"
+                },
+                "test_name": {
+                    "type": "string",
+                    "example": "Full blood count"
+                },
+                "test_type": {
+                    "type": "string",
+                    "example": "Haematology"
+                }
+            }
+        },
+        "dto.ListResponse-dto_AppointmentResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AppointmentResponse"
+                    }
+                },
+                "has_more": {
                     "type": "boolean"
                 },
-                "notes": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
                     "type": "string"
+                }
+            }
+        },
+        "dto.ListResponse-dto_ConsultationResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ConsultationResponse"
+                    }
                 },
-                "patient_id": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
                     "type": "string"
                 }
             }
         },
-        "dto.CreateAppointmentRequest": {
+        "dto.ListResponse-dto_DepartmentResponse": {
             "type": "object",
-            "required": [
-                "appointment_date",
-                "doctor_id",
-                "duration_minutes",
-                "patient_id"
-            ],
             "properties": {
-                "appointment_date": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DepartmentResponse"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
                     "type": "string"
+                }
+            }
+        },
+        "dto.ListResponse-dto_DoctorDirectoryEntry": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DoctorDirectoryEntry"
+                    }
                 },
-                "doctor_id": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
                     "type": "string"
+                }
+            }
+        },
+        "dto.ListResponse-dto_DoctorResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DoctorResponse"
+                    }
                 },
-                "duration_minutes": {
-                    "type": "integer",
-                    "minimum": 1
+                "has_more": {
+                    "type": "boolean"
                 },
-                "notes": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
                     "type": "string"
+                }
+            }
+        },
+        "dto.ListResponse-dto_DuplicateCandidateResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DuplicateCandidateResponse"
+                    }
                 },
-                "patient_id": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
                     "type": "string"
                 }
             }
         },
-        "dto.CreateConsultationRequest": {
+        "dto.ListResponse-dto_HospitalConfigResponse": {
             "type": "object",
-            "required": [
-                "appointment_id",
-                "diagnosis",
-                "doctor_id",
-                "patient_id"
-            ],
             "properties": {
-                "appointment_id": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.HospitalConfigResponse"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
                     "type": "string"
+                }
+            }
+        },
+        "dto.ListResponse-dto_NurseResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NurseResponse"
+                    }
                 },
-                "diagnosis": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
                     "type": "string"
+                }
+            }
+        },
+        "dto.ListResponse-dto_PatientMatchResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PatientMatchResponse"
+                    }
                 },
-                "doctor_id": {
-                    "type": "string"
+                "has_more": {
+                    "type": "boolean"
                 },
-                "notes": {
-                    "type": "string"
+                "limit": {
+                    "type": "integer"
                 },
-                "patient_id": {
+                "next_cursor": {
                     "type": "string"
                 }
             }
         },
-        "dto.CreateDepartmentRequest": {
+        "dto.ListResponse-dto_PatientResponse": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "description": {
-                    "type": "string",
-                    "maxLength": 500
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PatientResponse"
+                    }
                 },
-                "name": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 1
+                "has_more": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
                 }
             }
         },
-        "dto.CreateHospitalConfigRequest": {
+        "dto.ListResponse-dto_PrescriptionResponse": {
             "type": "object",
-            "required": [
-                "appointment_duration_minutes",
-                "max_same_day_cancellation_hours",
-                "working_hours_end",
-                "working_hours_start"
-            ],
             "properties": {
-                "appointment_duration_minutes": {
-                    "type": "integer",
-                    "minimum": 1
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PrescriptionResponse"
+                    }
                 },
-                "enable_patient_self_registration": {
-                    "description": "pointer to distinguish false from unset",
+                "has_more": {
                     "type": "boolean"
                 },
-                "max_same_day_cancellation_hours": {
-                    "type": "integer",
-                    "minimum": 0
-                },
-                "working_hours_end": {
-                    "type": "string"
+                "limit": {
+                    "type": "integer"
                 },
-                "working_hours_start": {
+                "next_cursor": {
                     "type": "string"
                 }
             }
         },
-        "dto.DepartmentListResponse": {
+        "dto.ListResponse-dto_UserResponse": {
             "type": "object",
             "properties": {
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.DepartmentResponse"
+                        "$ref": "#/definitions/dto.UserResponse"
                     }
                 },
-                "page": {
-                    "type": "integer"
-                },
-                "page_size": {
-                    "type": "integer"
+                "has_more": {
+                    "type": "boolean"
                 },
-                "total_count": {
+                "limit": {
                     "type": "integer"
                 },
-                "total_pages": {
-                    "type": "integer"
+                "next_cursor": {
+                    "type": "string"
                 }
             }
         },
-        "dto.DepartmentResponse": {
+        "dto.LoginRequest": {
             "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
             "properties": {
-                "created_at": {
+                "email": {
                     "type": "string"
                 },
-                "description": {
+                "password": {
                     "type": "string"
-                },
-                "id": {
+                }
+            }
+        },
+        "dto.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
                     "type": "string"
-                },
-                "is_active": {
-                    "type": "boolean"
-                },
-                "name": {
+                }
+            }
+        },
+        "dto.MergePatientRequest": {
+            "type": "object",
+            "required": [
+                "duplicate_patient_id"
+            ],
+            "properties": {
+                "duplicate_patient_id": {
                     "type": "string"
-                },
-                "updated_at": {
+                }
+            }
+        },
+        "dto.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string"
                 }
             }
         },
-        "dto.DoctorResponse": {
+        "dto.NurseProfileResponse": {
             "type": "object",
             "properties": {
-                "consultation_fee": {
-                    "type": "number"
+                "nurse": {
+                    "$ref": "#/definitions/dto.NurseResponse"
                 },
+                "user": {
+                    "$ref": "#/definitions/dto.UserResponse"
+                }
+            }
+        },
+        "dto.NurseResponse": {
+            "type": "object",
+            "properties": {
                 "created_at": {
                     "type": "string"
                 },
                 "department_id": {
                     "type": "string"
                 },
-                "doctor_id": {
+                "license_number": {
                     "type": "string"
                 },
-                "is_available": {
-                    "type": "boolean"
-                },
-                "license_number": {
+                "nurse_id": {
                     "type": "string"
                 },
-                "specialization": {
+                "shift": {
                     "type": "string"
                 },
                 "updated_at": {
@@ -1816,26 +5382,22 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.DoctorSignUpRequest": {
+        "dto.NurseSignupRequest": {
             "type": "object",
             "required": [
-                "consultation_fee",
                 "department_id",
                 "license_number",
-                "specialization",
+                "shift",
                 "user_id"
             ],
             "properties": {
-                "consultation_fee": {
-                    "type": "number"
-                },
                 "department_id": {
                     "type": "string"
                 },
                 "license_number": {
                     "type": "string"
                 },
-                "specialization": {
+                "shift": {
                     "type": "string"
                 },
                 "user_id": {
@@ -1843,115 +5405,221 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.ErrorResponse": {
+        "dto.OnboardStaffRequest": {
             "type": "object",
+            "required": [
+                "department_id",
+                "email",
+                "first_name",
+                "last_name",
+                "license_number",
+                "role",
+                "username"
+            ],
             "properties": {
-                "code": {
-                    "type": "string"
+                "availability": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.StaffAvailability"
+                    }
                 },
-                "error": {
-                    "type": "string"
-                }
-            }
-        },
-        "dto.HospitalConfigResponse": {
-            "type": "object",
-            "properties": {
-                "appointment_duration_minutes": {
-                    "type": "integer"
+                "consultation_fee": {
+                    "type": "number",
+                    "minimum": 0
                 },
-                "config_id": {
+                "department_id": {
                     "type": "string"
                 },
-                "created_at": {
+                "email": {
                     "type": "string"
                 },
-                "enable_patient_self_registration": {
-                    "type": "boolean"
+                "first_name": {
+                    "type": "string",
+                    "maxLength": 255
                 },
-                "max_same_day_cancellation_hours": {
-                    "type": "integer"
+                "last_name": {
+                    "type": "string",
+                    "maxLength": 255
                 },
-                "updated_at": {
+                "license_number": {
                     "type": "string"
                 },
-                "working_hours_end": {
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "DOCTOR",
+                        "NURSE"
+                    ]
+                },
+                "shift": {
                     "type": "string"
                 },
-                "working_hours_start": {
+                "specialization": {
                     "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
                 }
             }
         },
-        "dto.LoginRequest": {
+        "dto.OnboardStaffResponse": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
             "properties": {
-                "email": {
+                "availability": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AvailabilityResponse"
+                    }
+                },
+                "doctor": {
+                    "$ref": "#/definitions/dto.DoctorResponse"
+                },
+                "invitation_expires_at": {
                     "type": "string"
                 },
-                "password": {
+                "invitation_link": {
+                    "description": "InvitationLink is set only when the invitation was not sent; pass it\non to the staff member. It is not shown again.",
                     "type": "string"
+                },
+                "invitation_sent": {
+                    "type": "boolean"
+                },
+                "nurse": {
+                    "$ref": "#/definitions/dto.NurseResponse"
+                },
+                "user": {
+                    "$ref": "#/definitions/dto.UserResponse"
                 }
             }
         },
-        "dto.LoginResponse": {
+        "dto.PatientInviteResponse": {
             "type": "object",
             "properties": {
-                "token": {
+                "code": {
+                    "type": "string",
+                    "example": "K3QX-7MZD-2PLA-9RTE"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "invite_id": {
                     "type": "string"
                 }
             }
         },
-        "dto.NurseResponse": {
+        "dto.PatientMatchResponse": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "date_of_birth": {
+                    "type": "string",
+                    "example": "1990-01-31"
+                },
+                "email": {
                     "type": "string"
                 },
-                "department_id": {
+                "first_name": {
                     "type": "string"
                 },
-                "license_number": {
+                "gender": {
                     "type": "string"
                 },
-                "nurse_id": {
+                "last_name": {
                     "type": "string"
                 },
-                "shift": {
+                "mrn": {
+                    "type": "string",
+                    "example": "MRN-2026-000042"
+                },
+                "patient_id": {
                     "type": "string"
                 },
-                "updated_at": {
+                "phone": {
                     "type": "string"
                 },
+                "score": {
+                    "type": "number"
+                },
                 "user_id": {
                     "type": "string"
                 }
             }
         },
-        "dto.NurseSignupRequest": {
+        "dto.PatientMergeResponse": {
+            "type": "object",
+            "properties": {
+                "filled_fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "merged_mrn": {
+                    "type": "string"
+                },
+                "merged_patient_id": {
+                    "type": "string"
+                },
+                "patient": {
+                    "$ref": "#/definitions/dto.PatientResponse"
+                },
+                "records_moved": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                }
+            }
+        },
+        "dto.PatientProfileResponse": {
+            "type": "object",
+            "properties": {
+                "patient": {
+                    "$ref": "#/definitions/dto.PatientResponse"
+                },
+                "user": {
+                    "$ref": "#/definitions/dto.UserResponse"
+                }
+            }
+        },
+        "dto.PatientRecordResponse": {
             "type": "object",
-            "required": [
-                "department_id",
-                "license_number",
-                "shift",
-                "user_id"
-            ],
             "properties": {
-                "department_id": {
-                    "type": "string"
+                "appointments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AppointmentResponse"
+                    }
                 },
-                "license_number": {
-                    "type": "string"
+                "consultations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ConsultationResponse"
+                    }
                 },
-                "shift": {
+                "generated_at": {
                     "type": "string"
                 },
-                "user_id": {
-                    "type": "string"
+                "lab_results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.LabResultResponse"
+                    }
+                },
+                "prescriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PrescriptionResponse"
+                    }
+                },
+                "profile": {
+                    "$ref": "#/definitions/dto.PatientProfileResponse"
                 }
             }
         },
@@ -1979,6 +5647,10 @@ const docTemplate = `{
                 "medical_history": {
                     "type": "string"
                 },
+                "mrn": {
+                    "type": "string",
+                    "example": "MRN-2026-000042"
+                },
                 "patient_id": {
                     "type": "string"
                 },
@@ -1992,6 +5664,9 @@ const docTemplate = `{
         },
         "dto.PatientSignUp": {
             "type": "object",
+            "required": [
+                "date_of_birth"
+            ],
             "properties": {
                 "blood_group": {
                     "type": "string"
@@ -2035,6 +5710,12 @@ const docTemplate = `{
                     "maxLength": 255,
                     "example": "John"
                 },
+                "invite_code": {
+                    "description": "Required only while self-registration is disabled",
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "K3QX-7MZD-2PLA-9RTE"
+                },
                 "last_name": {
                     "type": "string",
                     "maxLength": 255,
@@ -2058,6 +5739,90 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.PrescriptionResponse": {
+            "type": "object",
+            "properties": {
+                "consultation_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "doctor_id": {
+                    "type": "string"
+                },
+                "dosage": {
+                    "type": "string",
+                    "example": "500mg"
+                },
+                "duration_days": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "frequency": {
+                    "type": "string",
+                    "example": "Three times daily"
+                },
+                "instructions": {
+                    "type": "string"
+                },
+                "medication_name": {
+                    "type": "string",
+                    "example": "Amoxicillin"
+                },
+                "prescription_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RollbackHospitalConfigRequest": {
+            "type": "object",
+            "required": [
+                "revision"
+            ],
+            "properties": {
+                "effective_from": {
+                    "description": "defaults to now",
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "dto.StaffAvailability": {
+            "type": "object",
+            "required": [
+                "day_of_week",
+                "end_time",
+                "max_appointments",
+                "start_time"
+            ],
+            "properties": {
+                "day_of_week": {
+                    "type": "string",
+                    "enum": [
+                        "Monday",
+                        "Tuesday",
+                        "Wednesday",
+                        "Thursday",
+                        "Friday",
+                        "Saturday",
+                        "Sunday"
+                    ]
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "max_appointments": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.UpdateAppointmentRequest": {
             "type": "object",
             "required": [
@@ -2118,12 +5883,60 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.UpdateDoctorProfileRequest": {
+            "type": "object",
+            "required": [
+                "first_name",
+                "last_name"
+            ],
+            "properties": {
+                "first_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "is_available": {
+                    "type": "boolean"
+                },
+                "last_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20
+                }
+            }
+        },
+        "dto.UpdateDoctorRequest": {
+            "type": "object",
+            "required": [
+                "consultation_fee",
+                "department_id",
+                "license_number",
+                "specialization"
+            ],
+            "properties": {
+                "consultation_fee": {
+                    "type": "number"
+                },
+                "department_id": {
+                    "type": "string"
+                },
+                "is_available": {
+                    "type": "boolean"
+                },
+                "license_number": {
+                    "type": "string"
+                },
+                "specialization": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.UpdateHospitalConfigRequest": {
             "type": "object",
             "required": [
                 "appointment_duration_minutes",
-                "config_id",
-                "max_same_day_cancellation_hours",
                 "working_hours_end",
                 "working_hours_start"
             ],
@@ -2132,10 +5945,12 @@ const docTemplate = `{
                     "type": "integer",
                     "minimum": 1
                 },
-                "config_id": {
+                "effective_from": {
+                    "description": "defaults to now",
                     "type": "string"
                 },
                 "enable_patient_self_registration": {
+                    "description": "pointer to distinguish false from unset",
                     "type": "boolean"
                 },
                 "max_same_day_cancellation_hours": {
@@ -2150,6 +5965,139 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.UpdateNurseProfileRequest": {
+            "type": "object",
+            "required": [
+                "first_name",
+                "last_name"
+            ],
+            "properties": {
+                "first_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "last_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20
+                }
+            }
+        },
+        "dto.UpdateNurseRequest": {
+            "type": "object",
+            "required": [
+                "department_id",
+                "license_number",
+                "shift"
+            ],
+            "properties": {
+                "department_id": {
+                    "type": "string"
+                },
+                "license_number": {
+                    "type": "string"
+                },
+                "shift": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UpdatePatientProfileRequest": {
+            "type": "object",
+            "required": [
+                "first_name",
+                "last_name"
+            ],
+            "properties": {
+                "emergency_contact_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "emergency_contact_phone": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "first_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "last_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20
+                }
+            }
+        },
+        "dto.UpdatePatientRequest": {
+            "type": "object",
+            "required": [
+                "date_of_birth"
+            ],
+            "properties": {
+                "blood_group": {
+                    "type": "string",
+                    "maxLength": 5
+                },
+                "date_of_birth": {
+                    "type": "string",
+                    "example": "1990-01-31"
+                },
+                "emergency_contact_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "emergency_contact_phone": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "medical_history": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UpdateUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "first_name",
+                "last_name",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "last_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                }
+            }
+        },
         "dto.UserResponse": {
             "type": "object",
             "properties": {
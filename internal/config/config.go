@@ -3,10 +3,16 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/netip"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"github.com/falasefemi2/hms/internal/mrn"
 )
 
 // Config holds all application configuration
@@ -25,12 +31,80 @@ type Config struct {
 	ServerHost  string
 	Environment string
 
+	// HTTP server limits and shutdown behaviour
+	ServerReadTimeout       time.Duration
+	ServerReadHeaderTimeout time.Duration
+	ServerWriteTimeout      time.Duration
+	ServerIdleTimeout       time.Duration
+	ServerMaxHeaderBytes    int
+	ServerShutdownTimeout   time.Duration
+	ServerDrainDelay        time.Duration
+
+	// Readiness fails once this fraction of pool connections is in use
+	ReadyMaxPoolUtilization float64
+
+	// Rate limiting, keyed by route policy name
+	RateLimitEnabled  bool
+	RateLimitPolicies map[string]RateLimitPolicy
+
+	// Addresses or CIDR ranges of reverse proxies whose X-Forwarded-For and
+	// X-Real-IP headers are believed; from anyone else they are ignored
+	TrustedProxies []string
+
+	// Keys accepted in X-API-Key; only these are used to key rate limits
+	APIKeys []string
+
+	// Stored Idempotency-Key responses are kept this long
+	IdempotencyKeyTTL time.Duration
+
+	// Format of new medical record numbers; see package mrn
+	MRNFormat string
+
+	// Staff invitations: the set-password page they link to and how long
+	// they stay valid
+	InvitationURL string
+	InvitationTTL time.Duration
+
+	// How long a patient invite code stays valid when self-registration
+	// is turned off
+	PatientInviteTTL time.Duration
+
 	// JWT
 	JWTSecret string
 	JWTExpiry string
 
 	// Logging
-	LogLevel string
+	LogLevel           string
+	SlowQueryThreshold time.Duration
+
+	// Tracing
+	TracingEnabled     bool
+	TracingEndpoint    string
+	TracingServiceName string
+	TracingSampleRatio float64
+}
+
+// Rate limit policy names used by the router.
+const (
+	RateLimitLogin            = "login"
+	RateLimitSignup           = "signup"
+	RateLimitAcceptInvitation = "accept_invitation"
+	RateLimitWrite            = "write"
+)
+
+// What a rate limit bucket is keyed by.
+const (
+	RateLimitByIP     = "ip"
+	RateLimitByUser   = "user"
+	RateLimitByAPIKey = "api_key"
+)
+
+// RateLimitPolicy allows Limit requests per Period for each client
+// identified by KeyBy.
+type RateLimitPolicy struct {
+	Limit  int
+	Period time.Duration
+	KeyBy  string
 }
 
 // LoadConfig loads configuration from environment variables
@@ -65,12 +139,49 @@ func LoadConfig() *Config {
 		ServerHost:  getEnv("SERVER_HOST", "0.0.0.0"),
 		Environment: getEnv("ENVIRONMENT", "development"),
 
+		ServerReadTimeout:       getEnvAsDuration("SERVER_READ_TIMEOUT", 15*time.Second),
+		ServerReadHeaderTimeout: getEnvAsDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+		ServerWriteTimeout:      getEnvAsDuration("SERVER_WRITE_TIMEOUT", 30*time.Second),
+		ServerIdleTimeout:       getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
+		ServerMaxHeaderBytes:    getEnvAsInt("SERVER_MAX_HEADER_BYTES", 1<<20),
+		ServerShutdownTimeout:   getEnvAsDuration("SERVER_SHUTDOWN_TIMEOUT", 15*time.Second),
+		ServerDrainDelay:        getEnvAsDuration("SERVER_DRAIN_DELAY", 5*time.Second),
+
+		ReadyMaxPoolUtilization: getEnvAsFloat("READY_MAX_POOL_UTILIZATION", 0.9),
+
+		// Rate limit configuration
+		RateLimitEnabled: getEnvAsBool("RATE_LIMIT_ENABLED", true),
+		RateLimitPolicies: map[string]RateLimitPolicy{
+			RateLimitLogin:            getEnvAsRateLimit("RATE_LIMIT_LOGIN", RateLimitPolicy{Limit: 10, Period: time.Minute, KeyBy: RateLimitByIP}),
+			RateLimitSignup:           getEnvAsRateLimit("RATE_LIMIT_SIGNUP", RateLimitPolicy{Limit: 5, Period: time.Hour, KeyBy: RateLimitByIP}),
+			RateLimitAcceptInvitation: getEnvAsRateLimit("RATE_LIMIT_ACCEPT_INVITATION", RateLimitPolicy{Limit: 10, Period: time.Minute, KeyBy: RateLimitByIP}),
+			RateLimitWrite:            getEnvAsRateLimit("RATE_LIMIT_WRITE", RateLimitPolicy{Limit: 60, Period: time.Minute, KeyBy: RateLimitByUser}),
+		},
+		TrustedProxies: getEnvAsList("TRUSTED_PROXIES"),
+		APIKeys:        getEnvAsList("API_KEYS"),
+
+		IdempotencyKeyTTL: getEnvAsDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+
+		MRNFormat: getEnv("MRN_FORMAT", mrn.DefaultFormat),
+
+		InvitationURL: getEnv("INVITATION_URL", "http://localhost:3000/set-password"),
+		InvitationTTL: getEnvAsDuration("INVITATION_TTL", 72*time.Hour),
+
+		PatientInviteTTL: getEnvAsDuration("PATIENT_INVITE_TTL", 7*24*time.Hour),
+
 		// JWT configuration
 		JWTSecret: getEnv("JWT_SECRET", "default-secret-change-in-production"),
 		JWTExpiry: getEnv("JWT_EXPIRY", "24h"),
 
 		// Logging configuration
-		LogLevel: getEnv("LOG_LEVEL", "info"),
+		LogLevel:           getEnv("LOG_LEVEL", "info"),
+		SlowQueryThreshold: getEnvAsDuration("SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
+
+		// Tracing configuration
+		TracingEnabled:     getEnvAsBool("OTEL_TRACING_ENABLED", false),
+		TracingEndpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
+		TracingServiceName: getEnv("OTEL_SERVICE_NAME", "hms"),
+		TracingSampleRatio: getEnvAsFloat("OTEL_TRACES_SAMPLE_RATIO", 1.0),
 	}
 
 	return cfg
@@ -97,6 +208,24 @@ func (c *Config) GetDSN() string {
 	return dsn
 }
 
+// TrustedProxyPrefixes parses TrustedProxies. A bare address is taken as a
+// single-host range.
+func (c *Config) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(c.TrustedProxies))
+	for _, value := range c.TrustedProxies {
+		if addr, err := netip.ParseAddr(value); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES: invalid address or CIDR %q", value)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
 // GetServerAddress returns the server address (host:port)
 func (c *Config) GetServerAddress() string {
 	return fmt.Sprintf("%s:%d", c.ServerHost, c.ServerPort)
@@ -104,6 +233,39 @@ func (c *Config) GetServerAddress() string {
 
 // Validate checks if required configuration is present
 func (c *Config) Validate() error {
+	for name, policy := range c.RateLimitPolicies {
+		if policy.Limit <= 0 || policy.Period <= 0 {
+			return fmt.Errorf("rate limit policy %q must have a positive limit and period", name)
+		}
+		switch policy.KeyBy {
+		case RateLimitByIP, RateLimitByUser, RateLimitByAPIKey:
+		default:
+			return fmt.Errorf("rate limit policy %q: unknown key %q", name, policy.KeyBy)
+		}
+	}
+
+	if _, err := c.TrustedProxyPrefixes(); err != nil {
+		return err
+	}
+
+	if c.IdempotencyKeyTTL <= 0 {
+		return fmt.Errorf("IDEMPOTENCY_KEY_TTL must be positive")
+	}
+
+	if _, err := mrn.Parse(c.MRNFormat); err != nil {
+		return fmt.Errorf("MRN_FORMAT: %w", err)
+	}
+
+	if c.InvitationTTL <= 0 {
+		return fmt.Errorf("INVITATION_TTL must be positive")
+	}
+	if u, err := url.Parse(c.InvitationURL); err != nil || !u.IsAbs() {
+		return fmt.Errorf("INVITATION_URL must be an absolute URL")
+	}
+	if c.PatientInviteTTL <= 0 {
+		return fmt.Errorf("PATIENT_INVITE_TTL must be positive")
+	}
+
 	if c.DatabaseURL == "" && c.DBHost == "" {
 		return fmt.Errorf("database configuration missing: either DATABASE_URL or DB_HOST is required")
 	}
@@ -137,3 +299,72 @@ func getEnvAsInt(key string, defaultVal int) int {
 	}
 	return defaultVal
 }
+
+// getEnvAsDuration retrieves an environment variable as a time.Duration with a default fallback
+func getEnvAsDuration(key string, defaultVal time.Duration) time.Duration {
+	valStr := getEnv(key, "")
+	if val, err := time.ParseDuration(valStr); err == nil {
+		return val
+	}
+	return defaultVal
+}
+
+// getEnvAsBool retrieves an environment variable as a bool with a default fallback
+func getEnvAsBool(key string, defaultVal bool) bool {
+	valStr := getEnv(key, "")
+	if val, err := strconv.ParseBool(valStr); err == nil {
+		return val
+	}
+	return defaultVal
+}
+
+// getEnvAsFloat retrieves an environment variable as a float64 with a default fallback
+func getEnvAsFloat(key string, defaultVal float64) float64 {
+	valStr := getEnv(key, "")
+	if val, err := strconv.ParseFloat(valStr, 64); err == nil {
+		return val
+	}
+	return defaultVal
+}
+
+// getEnvAsList retrieves a comma-separated environment variable, dropping
+// blank entries
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvAsRateLimit parses a rate limit policy written as "limit/period" or
+// "limit/period/key", e.g. "10/1m" or "100/1h/api_key". Missing parts keep
+// their default and unparsable values fall back to defaultVal.
+func getEnvAsRateLimit(key string, defaultVal RateLimitPolicy) RateLimitPolicy {
+	valStr := getEnv(key, "")
+	if valStr == "" {
+		return defaultVal
+	}
+
+	parts := strings.Split(valStr, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return defaultVal
+	}
+
+	limit, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return defaultVal
+	}
+	period, err := time.ParseDuration(parts[1])
+	if err != nil {
+		return defaultVal
+	}
+
+	policy := RateLimitPolicy{Limit: limit, Period: period, KeyBy: defaultVal.KeyBy}
+	if len(parts) == 3 {
+		policy.KeyBy = parts[2]
+	}
+	return policy
+}
@@ -3,11 +3,11 @@ package database
 import (
 	"context"
 	"fmt"
-	"log"
-	"os"
-	"strings"
+	"log/slog"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -15,11 +15,24 @@ type DB struct {
 	pool *pgxpool.Pool
 }
 
-func NewDB(databaseURL string) (*DB, error) {
+// NewDB connects to databaseURL. Every query is traced, and queries slower
+// than slowQueryThreshold are logged; a zero threshold disables slow-query
+// logging.
+func NewDB(databaseURL string, slowQueryThreshold time.Duration) (*DB, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	pool, err := pgxpool.New(ctx, databaseURL)
+	poolConfig, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database url: %w", err)
+	}
+	tracers := []pgx.QueryTracer{otelQueryTracer{}}
+	if slowQueryThreshold > 0 {
+		tracers = append(tracers, &slowQueryTracer{threshold: slowQueryThreshold})
+	}
+	poolConfig.ConnConfig.Tracer = multitracer.New(tracers...)
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pgx pool: %w", err)
 	}
@@ -29,7 +42,7 @@ func NewDB(databaseURL string) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("Database connection established successfully")
+	slog.Info("database connection established")
 
 	return &DB{pool: pool}, nil
 }
@@ -46,96 +59,43 @@ func (db *DB) HealthCheck(ctx context.Context) error {
 	return db.pool.Ping(ctx)
 }
 
-func (db *DB) InitializeSchema(ctx context.Context) error {
-	log.Println("Reading schema.sql file...")
-
-	schemaSQL, err := readSchemaFile()
+// Migrate applies all pending schema migrations.
+func (db *DB) Migrate(ctx context.Context) error {
+	migrator, err := NewMigrator(db.pool)
 	if err != nil {
-		return fmt.Errorf("failed to read schema.sql: %w", err)
+		return err
 	}
 
-	statements := parseSQLStatements(schemaSQL)
-
-	for i, stmt := range statements {
-		if strings.TrimSpace(stmt) == "" {
-			continue
-		}
-
-		_, err := db.pool.Exec(ctx, stmt)
-		if err != nil {
-			return fmt.Errorf(
-				"failed to execute statement %d: %w\nStatement: %s",
-				i+1,
-				err,
-				stmt,
-			)
-		}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return err
 	}
 
-	log.Println("Database schema initialized successfully")
+	slog.Info("database schema up to date", "applied", applied)
 	return nil
 }
 
-func readSchemaFile() (string, error) {
-	// Try multiple possible locations for schema.sql
-	possiblePaths := []string{
-		"schema.sql",
-		"./schema.sql",
-		"../schema.sql",
-		"../../schema.sql",
+// SchemaVersion returns the applied and the latest embedded migration
+// versions.
+func (db *DB) SchemaVersion(ctx context.Context) (current, latest int64, err error) {
+	migrator, err := NewMigrator(db.pool)
+	if err != nil {
+		return 0, 0, err
 	}
 
-	var schemaContent string
-	var lastErr error
-
-	for _, path := range possiblePaths {
-		content, err := os.ReadFile(path)
-		if err == nil {
-			schemaContent = string(content)
-			log.Printf("Loaded schema.sql from: %s\n", path)
-			return schemaContent, nil
-		}
-		lastErr = err
+	current, err = migrator.Version(ctx)
+	if err != nil {
+		return 0, 0, err
 	}
-
-	return "", fmt.Errorf("schema.sql not found in expected locations: %w", lastErr)
+	return current, migrator.Latest(), nil
 }
 
-func parseSQLStatements(sqlContent string) []string {
-	var statements []string
-	var currentStatement strings.Builder
-
-	lines := strings.Split(sqlContent, "\n")
-
-	for _, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
-
-		// Skip empty lines and comments
-		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "--") {
-			continue
-		}
-
-		// Add line to current statement
-		currentStatement.WriteString(trimmedLine)
-		currentStatement.WriteString("\n")
-
-		// Check if this line ends a statement (ends with semicolon)
-		if strings.HasSuffix(trimmedLine, ";") {
-			stmt := currentStatement.String()
-			if strings.TrimSpace(stmt) != "" {
-				statements = append(statements, stmt)
-			}
-			currentStatement.Reset()
-		}
-	}
-
-	// Add any remaining statement
-	if currentStatement.Len() > 0 {
-		stmt := currentStatement.String()
-		if strings.TrimSpace(stmt) != "" {
-			statements = append(statements, stmt)
-		}
+// PoolUtilization returns the fraction of the pool's maximum connections
+// currently acquired.
+func (db *DB) PoolUtilization() float64 {
+	stat := db.pool.Stat()
+	if stat.MaxConns() == 0 {
+		return 0
 	}
-
-	return statements
+	return float64(stat.AcquiredConns()) / float64(stat.MaxConns())
 }
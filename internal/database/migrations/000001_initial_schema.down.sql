@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS hospital_config;
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS patient_care_notes;
+DROP TABLE IF EXISTS lab_tests;
+DROP TABLE IF EXISTS patient_vitals;
+DROP TABLE IF EXISTS prescriptions;
+DROP TABLE IF EXISTS consultations;
+DROP TABLE IF EXISTS doctor_availability;
+DROP TABLE IF EXISTS appointments;
+DROP TABLE IF EXISTS wards;
+DROP TABLE IF EXISTS nurses;
+DROP TABLE IF EXISTS doctors;
+DROP TABLE IF EXISTS departments;
+DROP TABLE IF EXISTS patients;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,4 @@
+ALTER TABLE hospital_config DROP COLUMN IF EXISTS version;
+ALTER TABLE departments DROP COLUMN IF EXISTS version;
+ALTER TABLE consultations DROP COLUMN IF EXISTS version;
+ALTER TABLE appointments DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE consultations ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE departments ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE hospital_config ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
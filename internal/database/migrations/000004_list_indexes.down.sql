@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_patients_created_keyset;
+DROP INDEX IF EXISTS idx_nurses_created_keyset;
+DROP INDEX IF EXISTS idx_doctors_created_keyset;
+DROP INDEX IF EXISTS idx_appointments_date_keyset;
+DROP INDEX IF EXISTS idx_departments_created_keyset;
+DROP INDEX IF EXISTS idx_users_created_keyset;
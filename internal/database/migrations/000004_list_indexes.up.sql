@@ -0,0 +1,8 @@
+-- Keyset pagination walks (sort column, primary key); these cover the
+-- default sort of each list endpoint.
+CREATE INDEX IF NOT EXISTS idx_users_created_keyset ON users(created_at, user_id);
+CREATE INDEX IF NOT EXISTS idx_departments_created_keyset ON departments(created_at, department_id);
+CREATE INDEX IF NOT EXISTS idx_appointments_date_keyset ON appointments(appointment_date, appointment_id);
+CREATE INDEX IF NOT EXISTS idx_doctors_created_keyset ON doctors(created_at, doctor_id);
+CREATE INDEX IF NOT EXISTS idx_nurses_created_keyset ON nurses(created_at, nurse_id);
+CREATE INDEX IF NOT EXISTS idx_patients_created_keyset ON patients(created_at, patient_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_patients_date_of_birth;
+DROP INDEX IF EXISTS idx_users_phone_digits_trgm;
+DROP INDEX IF EXISTS idx_users_full_name_trgm;
+-- pg_trgm is left installed; other objects may depend on it.
@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Must match patientNameExpr in internal/repository/patientsearch.go.
+CREATE INDEX IF NOT EXISTS idx_users_full_name_trgm ON users
+    USING gin (lower(COALESCE(first_name, '') || ' ' || COALESCE(last_name, '')) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_users_phone_digits_trgm ON users
+    USING gin (regexp_replace(COALESCE(phone, ''), '\D', '', 'g') gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_patients_date_of_birth ON patients(date_of_birth);
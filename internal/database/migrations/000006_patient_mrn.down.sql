@@ -0,0 +1,3 @@
+ALTER TABLE patients DROP CONSTRAINT IF EXISTS patients_mrn_key;
+ALTER TABLE patients DROP COLUMN IF EXISTS mrn;
+DROP SEQUENCE IF EXISTS patient_mrn_seq;
@@ -0,0 +1,16 @@
+CREATE SEQUENCE IF NOT EXISTS patient_mrn_seq;
+
+ALTER TABLE patients ADD COLUMN IF NOT EXISTS mrn VARCHAR(32);
+
+-- Existing patients get numbers in the default MRN-{YYYY}-{SEQ:6} format, in
+-- registration order.
+UPDATE patients p
+SET mrn = 'MRN-' || to_char(p.created_at, 'YYYY') || '-' || lpad(numbered.seq::text, 6, '0')
+FROM (
+    SELECT patient_id, nextval('patient_mrn_seq') AS seq
+    FROM (SELECT patient_id FROM patients WHERE mrn IS NULL ORDER BY created_at, patient_id) ordered
+) numbered
+WHERE p.patient_id = numbered.patient_id;
+
+ALTER TABLE patients ALTER COLUMN mrn SET NOT NULL;
+ALTER TABLE patients ADD CONSTRAINT patients_mrn_key UNIQUE (mrn);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_appointments_doctor_date;
+DROP INDEX IF EXISTS idx_doctor_availability_doctor;
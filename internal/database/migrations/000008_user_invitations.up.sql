@@ -0,0 +1,12 @@
+-- One-time links that let a new staff member choose their own password.
+-- Only a SHA-256 hash of the token is stored.
+CREATE TABLE IF NOT EXISTS user_invitations (
+    invitation_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_invitations_user ON user_invitations(user_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS patient_invites;
+DROP INDEX IF EXISTS idx_hospital_config_one_active;
+ALTER TABLE hospital_config DROP COLUMN IF EXISTS is_active;
@@ -0,0 +1,20 @@
+-- One hospital config is in force at a time. Existing installs keep the
+-- newest config they had.
+ALTER TABLE hospital_config ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT false;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_hospital_config_one_active ON hospital_config(is_active) WHERE is_active;
+
+UPDATE hospital_config SET is_active = true
+WHERE config_id = (SELECT config_id FROM hospital_config ORDER BY created_at DESC LIMIT 1);
+
+-- One-time codes for patients to sign up while self-registration is closed.
+-- Only a SHA-256 hash of the code is stored.
+CREATE TABLE IF NOT EXISTS patient_invites (
+    invite_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    used_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
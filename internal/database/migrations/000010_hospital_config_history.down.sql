@@ -0,0 +1,18 @@
+ALTER TABLE hospital_config ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE hospital_config ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE hospital_config ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE hospital_config SET is_active = true
+WHERE revision = (
+    SELECT revision FROM hospital_config
+    WHERE effective_from <= CURRENT_TIMESTAMP
+    ORDER BY revision DESC LIMIT 1
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_hospital_config_one_active ON hospital_config(is_active) WHERE is_active;
+
+ALTER TABLE hospital_config DROP CONSTRAINT IF EXISTS hospital_config_revision_key;
+ALTER TABLE hospital_config DROP COLUMN IF EXISTS rolled_back_from;
+ALTER TABLE hospital_config DROP COLUMN IF EXISTS created_by;
+ALTER TABLE hospital_config DROP COLUMN IF EXISTS effective_from;
+ALTER TABLE hospital_config DROP COLUMN IF EXISTS revision;
@@ -0,0 +1,28 @@
+-- The hospital config becomes a single setting with a history. Every change
+-- adds a revision; the one in force is the newest revision whose
+-- effective_from has passed. Revisions are never edited or deleted.
+ALTER TABLE hospital_config ADD COLUMN IF NOT EXISTS revision INT;
+ALTER TABLE hospital_config ADD COLUMN IF NOT EXISTS effective_from TIMESTAMP;
+ALTER TABLE hospital_config ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(user_id) ON DELETE SET NULL;
+ALTER TABLE hospital_config ADD COLUMN IF NOT EXISTS rolled_back_from INT;
+
+-- Existing configs become the history, oldest first, with the active one as
+-- the newest revision so it stays in force. If none was active the newest
+-- config takes effect.
+UPDATE hospital_config c
+SET revision = numbered.revision,
+    effective_from = CASE WHEN c.is_active THEN CURRENT_TIMESTAMP ELSE c.created_at END
+FROM (
+    SELECT config_id, ROW_NUMBER() OVER (ORDER BY is_active, created_at) AS revision
+    FROM hospital_config
+) numbered
+WHERE c.config_id = numbered.config_id;
+
+ALTER TABLE hospital_config ALTER COLUMN revision SET NOT NULL;
+ALTER TABLE hospital_config ALTER COLUMN effective_from SET NOT NULL;
+ALTER TABLE hospital_config ADD CONSTRAINT hospital_config_revision_key UNIQUE (revision);
+
+DROP INDEX IF EXISTS idx_hospital_config_one_active;
+ALTER TABLE hospital_config DROP COLUMN IF EXISTS is_active;
+ALTER TABLE hospital_config DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE hospital_config DROP COLUMN IF EXISTS version;
@@ -0,0 +1,6 @@
+ALTER TABLE appointments ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE patients ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE nurses ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE doctors ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE departments ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN created_at DROP NOT NULL;
@@ -0,0 +1,16 @@
+-- created_at is a sort key of the list endpoints, and keyset pagination
+-- skips rows whose sort value is NULL. Rows never had it left out, but the
+-- column allowed it; fill any gaps from updated_at and forbid NULL.
+UPDATE users SET created_at = COALESCE(updated_at, CURRENT_TIMESTAMP) WHERE created_at IS NULL;
+UPDATE departments SET created_at = COALESCE(updated_at, CURRENT_TIMESTAMP) WHERE created_at IS NULL;
+UPDATE doctors SET created_at = COALESCE(updated_at, CURRENT_TIMESTAMP) WHERE created_at IS NULL;
+UPDATE nurses SET created_at = COALESCE(updated_at, CURRENT_TIMESTAMP) WHERE created_at IS NULL;
+UPDATE patients SET created_at = COALESCE(updated_at, CURRENT_TIMESTAMP) WHERE created_at IS NULL;
+UPDATE appointments SET created_at = COALESCE(updated_at, CURRENT_TIMESTAMP) WHERE created_at IS NULL;
+
+ALTER TABLE users ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE departments ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE doctors ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE nurses ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE patients ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE appointments ALTER COLUMN created_at SET NOT NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_prescriptions_patient_keyset;
+DROP INDEX IF EXISTS idx_consultations_patient_keyset;
+DROP INDEX IF EXISTS idx_appointments_patient_keyset;
+
+ALTER TABLE prescriptions ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE consultations ALTER COLUMN created_at DROP NOT NULL;
@@ -0,0 +1,12 @@
+-- The patient portal pages consultations and prescriptions by created_at,
+-- so, as in 000011, the column may no longer be NULL.
+UPDATE consultations SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE prescriptions SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+
+ALTER TABLE consultations ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE prescriptions ALTER COLUMN created_at SET NOT NULL;
+
+-- Portal lists are always scoped to one patient.
+CREATE INDEX IF NOT EXISTS idx_appointments_patient_keyset ON appointments(patient_id, appointment_date, appointment_id);
+CREATE INDEX IF NOT EXISTS idx_consultations_patient_keyset ON consultations(patient_id, created_at, consultation_id);
+CREATE INDEX IF NOT EXISTS idx_prescriptions_patient_keyset ON prescriptions(patient_id, created_at, prescription_id);
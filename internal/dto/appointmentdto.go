@@ -5,15 +5,17 @@ import (
 )
 
 type CreateAppointmentRequest struct {
-	PatientID       string `json:"patient_id" validate:"required,uuid"`
+	// PatientID is required for staff. Patients book for themselves and may
+	// leave it out.
+	PatientID       string `json:"patient_id" validate:"omitempty,uuid"`
 	DoctorID        string `json:"doctor_id" validate:"required,uuid"`
-	AppointmentDate string `json:"appointment_date" validate:"required"`
+	AppointmentDate string `json:"appointment_date" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
 	DurationMinutes int    `json:"duration_minutes" validate:"required,min=1"`
 	Notes           string `json:"notes"`
 }
 
 type UpdateAppointmentRequest struct {
-	AppointmentDate string `json:"appointment_date" validate:"required"`
+	AppointmentDate string `json:"appointment_date" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
 	DurationMinutes int    `json:"duration_minutes" validate:"required,min=1"`
 	Status          string `json:"status" validate:"required,oneof=PENDING CONFIRMED COMPLETED CANCELLED"`
 	Notes           string `json:"notes"`
@@ -29,4 +31,5 @@ type AppointmentResponse struct {
 	Notes           string    `json:"notes"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
+	Version         int       `json:"version"`
 }
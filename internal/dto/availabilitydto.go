@@ -7,11 +7,11 @@ import (
 )
 
 type AvailabilityRequest struct {
-	DoctorID        uuid.UUID `json:"doctor_id"`
-	DayOfWeek       string    `json:"day_of_week"`
-	StartTime       string    `json:"start_time"`
-	EndTime         string    `json:"end_time"`
-	MaxAppointments int       `json:"max_appointments"`
+	DoctorID        uuid.UUID `json:"doctor_id" validate:"required"`
+	DayOfWeek       string    `json:"day_of_week" validate:"required,oneof=Monday Tuesday Wednesday Thursday Friday Saturday Sunday"`
+	StartTime       string    `json:"start_time" validate:"required,datetime=15:04"`
+	EndTime         string    `json:"end_time" validate:"required,datetime=15:04"`
+	MaxAppointments int       `json:"max_appointments" validate:"required,gt=0"`
 }
 
 type AvailabilityResponse struct {
@@ -26,4 +26,5 @@ type ConsultationResponse struct {
 	Notes          string    `json:"notes"`
 	CreatedAt      time.Time `json:"created_at"`
 	IsEditable     bool      `json:"is_editable"`
+	Version        int       `json:"version"`
 }
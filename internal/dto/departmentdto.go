@@ -24,17 +24,5 @@ type DepartmentResponse struct {
 	IsActive    bool      `json:"is_active"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
-}
-
-type DepartmentListResponse struct {
-	Data       []DepartmentResponse `json:"data"`
-	TotalCount int                  `json:"total_count"`
-	Page       int                  `json:"page"`
-	PageSize   int                  `json:"page_size"`
-	TotalPages int                  `json:"total_pages"`
-}
-
-type PaginationRequest struct {
-	Page     int `query:"page" validate:"min=1"`
-	PageSize int `query:"page_size" validate:"min=1,max=100"`
+	Version     int       `json:"version"`
 }
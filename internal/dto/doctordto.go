@@ -23,3 +23,52 @@ type DoctorResponse struct {
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
+
+type UpdateDoctorRequest struct {
+	Specialization  string  `json:"specialization" validate:"required"`
+	LicenseNumber   string  `json:"license_number" validate:"required"`
+	DepartmentID    string  `json:"department_id" validate:"required,uuid"`
+	ConsultationFee float64 `json:"consultation_fee" validate:"required,gt=0"`
+	IsAvailable     bool    `json:"is_available"`
+}
+
+// DoctorDirectoryEntry is a doctor as listed for patients. It leaves out the
+// license number and account details.
+type DoctorDirectoryEntry struct {
+	DoctorID          string     `json:"doctor_id"`
+	FirstName         string     `json:"first_name"`
+	LastName          string     `json:"last_name"`
+	Specialization    string     `json:"specialization"`
+	DepartmentID      string     `json:"department_id"`
+	DepartmentName    string     `json:"department_name"`
+	ConsultationFee   float64    `json:"consultation_fee"`
+	IsAvailable       bool       `json:"is_available"`
+	NextAvailableSlot *time.Time `json:"next_available_slot"`
+}
+
+// DeactivateDoctorRequest names the doctor who takes over the deactivated
+// doctor's upcoming appointments. Leave it out to cancel them instead.
+type DeactivateDoctorRequest struct {
+	ReassignToDoctorID *string `json:"reassign_to_doctor_id" validate:"omitempty,uuid"`
+}
+
+type DoctorDeactivationResponse struct {
+	Doctor                 DoctorResponse `json:"doctor"`
+	ReassignedTo           *string        `json:"reassigned_to"`
+	AppointmentsReassigned int64          `json:"appointments_reassigned"`
+	AppointmentsCancelled  int64          `json:"appointments_cancelled"`
+}
+
+// DoctorProfileResponse is a doctor's own view of their profile.
+type DoctorProfileResponse struct {
+	Doctor DoctorResponse `json:"doctor"`
+	User   UserResponse   `json:"user"`
+}
+
+// UpdateDoctorProfileRequest lists what doctors may change about themselves.
+type UpdateDoctorProfileRequest struct {
+	FirstName   *string `json:"first_name" validate:"required,max=255"`
+	LastName    *string `json:"last_name" validate:"required,max=255"`
+	Phone       *string `json:"phone" validate:"omitempty,max=20"`
+	IsAvailable bool    `json:"is_available"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+type HealthResponse struct {
+	Status     string            `json:"status" example:"ok"`
+	Components []ComponentHealth `json:"components,omitempty"`
+}
+
+type ComponentHealth struct {
+	Name      string  `json:"name" example:"database"`
+	Status    string  `json:"status" example:"ok"`
+	LatencyMS float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty" example:"database ping failed"`
+}
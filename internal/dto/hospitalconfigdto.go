@@ -2,32 +2,35 @@ package dto
 
 import (
 	"time"
+
+	"github.com/google/uuid"
 )
 
-type CreateHospitalConfigRequest struct {
-	WorkingHoursStart             string `json:"working_hours_start" validate:"required"`
-	WorkingHoursEnd               string `json:"working_hours_end" validate:"required"`
-	AppointmentDurationMinutes    int    `json:"appointment_duration_minutes" validate:"required,min=1"`
-	MaxSameDayCancellationHours   int    `json:"max_same_day_cancellation_hours" validate:"required,min=0"`
-	EnablePatientSelfRegistration *bool  `json:"enable_patient_self_registration"` // pointer to distinguish false from unset
+type UpdateHospitalConfigRequest struct {
+	WorkingHoursStart             string     `json:"working_hours_start" validate:"required"`
+	WorkingHoursEnd               string     `json:"working_hours_end" validate:"required"`
+	AppointmentDurationMinutes    int        `json:"appointment_duration_minutes" validate:"required,min=1"`
+	MaxSameDayCancellationHours   int        `json:"max_same_day_cancellation_hours" validate:"min=0"`
+	EnablePatientSelfRegistration *bool      `json:"enable_patient_self_registration"` // pointer to distinguish false from unset
+	EffectiveFrom                 *time.Time `json:"effective_from,omitempty"`         // defaults to now
 }
 
-type UpdateHospitalConfigRequest struct {
-	ConfigID                      string `json:"config_id" validate:"required,uuid"`
-	WorkingHoursStart             string `json:"working_hours_start" validate:"required"`
-	WorkingHoursEnd               string `json:"working_hours_end" validate:"required"`
-	AppointmentDurationMinutes    int    `json:"appointment_duration_minutes" validate:"required,min=1"`
-	MaxSameDayCancellationHours   int    `json:"max_same_day_cancellation_hours" validate:"required,min=0"`
-	EnablePatientSelfRegistration *bool  `json:"enable_patient_self_registration"`
+type RollbackHospitalConfigRequest struct {
+	Revision      int        `json:"revision" validate:"required,min=1"`
+	EffectiveFrom *time.Time `json:"effective_from,omitempty"` // defaults to now
 }
 
 type HospitalConfigResponse struct {
-	ConfigID                      string    `json:"config_id"`
-	WorkingHoursStart             string    `json:"working_hours_start"`
-	WorkingHoursEnd               string    `json:"working_hours_end"`
-	AppointmentDurationMinutes    int       `json:"appointment_duration_minutes"`
-	MaxSameDayCancellationHours   int       `json:"max_same_day_cancellation_hours"`
-	EnablePatientSelfRegistration bool      `json:"enable_patient_self_registration"`
-	CreatedAt                     time.Time `json:"created_at"`
-	UpdatedAt                     time.Time `json:"updated_at"`
+	ConfigID                      string     `json:"config_id"`
+	Revision                      int        `json:"revision"`
+	WorkingHoursStart             string     `json:"working_hours_start"`
+	WorkingHoursEnd               string     `json:"working_hours_end"`
+	AppointmentDurationMinutes    int        `json:"appointment_duration_minutes"`
+	MaxSameDayCancellationHours   int        `json:"max_same_day_cancellation_hours"`
+	EnablePatientSelfRegistration bool       `json:"enable_patient_self_registration"`
+	EffectiveFrom                 time.Time  `json:"effective_from"`
+	IsActive                      bool       `json:"is_active"`
+	RolledBackFrom                *int       `json:"rolled_back_from,omitempty"`
+	CreatedBy                     *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt                     time.Time  `json:"created_at"`
 }
@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LabResultResponse is a completed lab test. result_file_path points at the
+// stored report, when there is one.
+type LabResultResponse struct {
+	TestID         uuid.UUID  `json:"test_id"`
+	DoctorID       uuid.UUID  `json:"doctor_id"`
+	TestName       string     `json:"test_name" example:"Full blood count"`
+	TestType       string     `json:"test_type" example:"Haematology"`
+	Status         string     `json:"status" example:"COMPLETED"`
+	ResultFilePath string     `json:"result_file_path"`
+	Notes          string     `json:"notes"`
+	RequestedAt    time.Time  `json:"requested_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+}
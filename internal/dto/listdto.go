@@ -0,0 +1,28 @@
+package dto
+
+import "github.com/falasefemi2/hms/internal/listquery"
+
+// ListResponse is the envelope of every list endpoint. Pass NextCursor back
+// as the cursor parameter to fetch the following page; it is omitted on the
+// last one.
+type ListResponse[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Limit      int    `json:"limit"`
+}
+
+// NewListResponse converts the items of page with convert.
+func NewListResponse[M, R any](page *listquery.Page[M], convert func(M) R) *ListResponse[R] {
+	data := make([]R, len(page.Items))
+	for i, item := range page.Items {
+		data[i] = convert(item)
+	}
+
+	return &ListResponse[R]{
+		Data:       data,
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+		Limit:      page.Limit,
+	}
+}
@@ -18,3 +18,22 @@ type NurseResponse struct {
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
+
+type UpdateNurseRequest struct {
+	Shift         string `json:"shift" validate:"required"`
+	LicenseNumber string `json:"license_number" validate:"required"`
+	DepartmentID  string `json:"department_id" validate:"required,uuid"`
+}
+
+// NurseProfileResponse is a nurse's own view of their profile.
+type NurseProfileResponse struct {
+	Nurse NurseResponse `json:"nurse"`
+	User  UserResponse  `json:"user"`
+}
+
+// UpdateNurseProfileRequest lists what nurses may change about themselves.
+type UpdateNurseProfileRequest struct {
+	FirstName *string `json:"first_name" validate:"required,max=255"`
+	LastName  *string `json:"last_name" validate:"required,max=255"`
+	Phone     *string `json:"phone" validate:"omitempty,max=20"`
+}
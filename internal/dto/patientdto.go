@@ -6,9 +6,11 @@ import (
 	"github.com/google/uuid"
 )
 
+// PatientSignUp creates a patient profile. UserID is required for staff;
+// patients create their own profile and may leave it out.
 type PatientSignUp struct {
-	UserID                string    `json:"user_id"`
-	DateOfBirth           string    `json:"date_of_birth"`
+	UserID                string    `json:"user_id" validate:"omitempty,uuid"`
+	DateOfBirth           string    `json:"date_of_birth" validate:"required"`
 	Gender                string    `json:"gender"`
 	BloodGroup            string    `json:"blood_group"`
 	EmergencyContactName  string    `json:"emergency_contact_name"`
@@ -19,6 +21,7 @@ type PatientSignUp struct {
 type PatientResponse struct {
 	PatientID             uuid.UUID `json:"patient_id"`
 	UserID                uuid.UUID `json:"user_id"`
+	MRN                   string    `json:"mrn" example:"MRN-2026-000042"`
 	DateOfBirth           time.Time `json:"date_of_birth"`
 	Gender                string    `json:"gender"`
 	BloodGroup            string    `json:"blood_group"`
@@ -28,3 +31,71 @@ type PatientResponse struct {
 	CreatedAt             time.Time `json:"created_at"`
 	UpdatedAt             time.Time `json:"updated_at"`
 }
+
+type UpdatePatientRequest struct {
+	DateOfBirth           string `json:"date_of_birth" validate:"required,datetime=2006-01-02" example:"1990-01-31"`
+	Gender                string `json:"gender"`
+	BloodGroup            string `json:"blood_group" validate:"omitempty,max=5"`
+	EmergencyContactName  string `json:"emergency_contact_name" validate:"omitempty,max=255"`
+	EmergencyContactPhone string `json:"emergency_contact_phone" validate:"omitempty,max=20"`
+	MedicalHistory        string `json:"medical_history"`
+}
+
+type PatientMatchResponse struct {
+	PatientID   uuid.UUID `json:"patient_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	MRN         string    `json:"mrn" example:"MRN-2026-000042"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	Email       string    `json:"email"`
+	Phone       string    `json:"phone"`
+	DateOfBirth string    `json:"date_of_birth" example:"1990-01-31"`
+	Gender      string    `json:"gender"`
+	Score       float64   `json:"score"`
+}
+
+// DuplicateCandidateResponse is a patient that may be the same person as the
+// one checked. matched_on lists which of name, date_of_birth and phone agree.
+type DuplicateCandidateResponse struct {
+	PatientMatchResponse
+	MatchedOn []string `json:"matched_on" example:"date_of_birth,name"`
+}
+
+type MergePatientRequest struct {
+	DuplicatePatientID string `json:"duplicate_patient_id" validate:"required,uuid"`
+}
+
+type PatientMergeResponse struct {
+	Patient         PatientResponse  `json:"patient"`
+	MergedPatientID uuid.UUID        `json:"merged_patient_id"`
+	MergedMRN       string           `json:"merged_mrn"`
+	RecordsMoved    map[string]int64 `json:"records_moved"`
+	FilledFields    []string         `json:"filled_fields"`
+}
+
+// PatientProfileResponse is a patient's own view of their profile.
+type PatientProfileResponse struct {
+	Patient PatientResponse `json:"patient"`
+	User    UserResponse    `json:"user"`
+}
+
+// UpdatePatientProfileRequest lists what patients may change about
+// themselves.
+type UpdatePatientProfileRequest struct {
+	FirstName             *string `json:"first_name" validate:"required,max=255"`
+	LastName              *string `json:"last_name" validate:"required,max=255"`
+	Phone                 *string `json:"phone" validate:"omitempty,max=20"`
+	EmergencyContactName  string  `json:"emergency_contact_name" validate:"omitempty,max=255"`
+	EmergencyContactPhone string  `json:"emergency_contact_phone" validate:"omitempty,max=20"`
+}
+
+// PatientRecordResponse is the download of everything held about a patient.
+// lab_results only has completed tests.
+type PatientRecordResponse struct {
+	Profile       PatientProfileResponse `json:"profile"`
+	Appointments  []AppointmentResponse  `json:"appointments"`
+	Consultations []ConsultationResponse `json:"consultations"`
+	Prescriptions []PrescriptionResponse `json:"prescriptions"`
+	LabResults    []LabResultResponse    `json:"lab_results"`
+	GeneratedAt   time.Time              `json:"generated_at"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PrescriptionResponse struct {
+	PrescriptionID uuid.UUID `json:"prescription_id"`
+	ConsultationID uuid.UUID `json:"consultation_id"`
+	DoctorID       uuid.UUID `json:"doctor_id"`
+	MedicationName string    `json:"medication_name" example:"Amoxicillin"`
+	Dosage         string    `json:"dosage" example:"500mg"`
+	Frequency      string    `json:"frequency" example:"Three times daily"`
+	DurationDays   *int      `json:"duration_days,omitempty" example:"7"`
+	Instructions   string    `json:"instructions"`
+	CreatedAt      time.Time `json:"created_at"`
+}
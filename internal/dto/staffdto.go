@@ -0,0 +1,53 @@
+package dto
+
+import "time"
+
+// OnboardStaffRequest creates a doctor or nurse in one call. Specialization
+// and consultation_fee are required for doctors and shift for nurses;
+// availability may only be given for doctors.
+type OnboardStaffRequest struct {
+	Username        string              `json:"username" validate:"required,min=3,max=255"`
+	Email           string              `json:"email" validate:"required,email"`
+	FirstName       string              `json:"first_name" validate:"required,max=255"`
+	LastName        string              `json:"last_name" validate:"required,max=255"`
+	Phone           string              `json:"phone" validate:"omitempty,max=20"`
+	Role            string              `json:"role" validate:"required,oneof=DOCTOR NURSE"`
+	DepartmentID    string              `json:"department_id" validate:"required,uuid"`
+	LicenseNumber   string              `json:"license_number" validate:"required"`
+	Specialization  string              `json:"specialization"`
+	ConsultationFee float64             `json:"consultation_fee" validate:"gte=0"`
+	Shift           string              `json:"shift"`
+	Availability    []StaffAvailability `json:"availability" validate:"dive"`
+}
+
+// StaffAvailability is one weekly window in an onboarding request.
+type StaffAvailability struct {
+	DayOfWeek       string `json:"day_of_week" validate:"required,oneof=Monday Tuesday Wednesday Thursday Friday Saturday Sunday"`
+	StartTime       string `json:"start_time" validate:"required,datetime=15:04"`
+	EndTime         string `json:"end_time" validate:"required,datetime=15:04"`
+	MaxAppointments int    `json:"max_appointments" validate:"required,gt=0"`
+}
+
+type OnboardStaffResponse struct {
+	User                UserResponse           `json:"user"`
+	Doctor              *DoctorResponse        `json:"doctor,omitempty"`
+	Nurse               *NurseResponse         `json:"nurse,omitempty"`
+	Availability        []AvailabilityResponse `json:"availability"`
+	InvitationExpiresAt time.Time              `json:"invitation_expires_at"`
+	InvitationSent      bool                   `json:"invitation_sent"`
+	// InvitationLink is set only when the invitation was not sent; pass it
+	// on to the staff member. It is not shown again.
+	InvitationLink string `json:"invitation_link,omitempty"`
+}
+
+type InvitationResponse struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	Sent      bool      `json:"sent"`
+	// Link is set only when the invitation was not sent.
+	Link string `json:"link,omitempty"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
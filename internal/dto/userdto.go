@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type PatientSignUpRequest struct {
 	Username  string `json:"username" example:"john_doe" validate:"required,min=3,max=255"`
@@ -9,6 +13,16 @@ type PatientSignUpRequest struct {
 	FirstName string `json:"first_name" example:"John" validate:"required,max=255"`
 	LastName  string `json:"last_name" example:"Doe" validate:"required,max=255"`
 	Phone     string `json:"phone" example:"+2345694004" validate:"omitempty,max=20"`
+	// Required only while self-registration is disabled
+	InviteCode string `json:"invite_code,omitempty" example:"K3QX-7MZD-2PLA-9RTE" validate:"omitempty,max=32"`
+}
+
+// PatientInviteResponse carries a new invite code. The code is shown only
+// here; it cannot be looked up again.
+type PatientInviteResponse struct {
+	InviteID  uuid.UUID `json:"invite_id"`
+	Code      string    `json:"code" example:"K3QX-7MZD-2PLA-9RTE"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type AdminCreateUserRequest struct {
@@ -37,9 +51,21 @@ type MessageResponse struct {
 	Message string `json:"message"`
 }
 
+// ErrorResponse documents the RFC 7807 problem+json body returned for every
+// error. Code is stable and safe to switch on; Errors lists invalid fields.
 type ErrorResponse struct {
-	Error string `json:"error"`
-	Code  string `json:"code,omitempty"`
+	Type     string               `json:"type" example:"about:blank"`
+	Title    string               `json:"title" example:"Not Found"`
+	Status   int                  `json:"status" example:"404"`
+	Detail   string               `json:"detail,omitempty" example:"appointment not found"`
+	Instance string               `json:"instance,omitempty" example:"/appointments/3f0c2a9e-1c1b-4d3e-9d8a-6b1f0e2c7a11"`
+	Code     string               `json:"code" example:"appointment_not_found"`
+	Errors   []FieldErrorResponse `json:"errors,omitempty"`
+}
+
+type FieldErrorResponse struct {
+	Field   string `json:"field" example:"appointment_date"`
+	Message string `json:"message" example:"appointment date must be in the future"`
 }
 
 type LoginRequest struct {
@@ -51,10 +77,11 @@ type LoginResponse struct {
 	Token string `json:"token"`
 }
 
-type PaginatedUserResponse struct {
-	Data       []UserResponse `json:"data"`
-	Limit      int            `json:"limit"`
-	Offset     int            `json:"offset"`
-	TotalCount int64          `json:"total_count"`
-	TotalPages int            `json:"total_pages"`
+type UpdateUserRequest struct {
+	Username  string  `json:"username" validate:"required,min=3,max=255"`
+	Email     string  `json:"email" validate:"required,email"`
+	FirstName *string `json:"first_name" validate:"required,max=255"`
+	LastName  *string `json:"last_name" validate:"required,max=255"`
+	Phone     *string `json:"phone" validate:"omitempty,max=20"`
+	IsActive  bool    `json:"is_active"`
 }
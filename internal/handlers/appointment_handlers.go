@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 	"time"
 
@@ -9,7 +8,9 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
 	"github.com/falasefemi2/hms/internal/service"
 	"github.com/falasefemi2/hms/internal/utils"
 )
@@ -26,28 +27,34 @@ func NewAppointmentHandler(appointmentService *service.AppointmentService) *Appo
 
 // CreateAppointment godoc
 // @Summary Create a new appointment
-// @Description Create a new appointment. Requires valid JWT token
+// @Description Create a new appointment. A patient books for themselves and patient_id is taken from their token; staff must give patient_id. Requires valid JWT token
 // @Tags Appointment Management
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body dto.CreateAppointmentRequest true "Appointment creation details"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
 // @Success 201 {object} dto.AppointmentResponse "Appointment created successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - a patient booking for another patient"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
 // @Router /appointments [post]
 func (h *AppointmentHandler) CreateAppointment(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateAppointmentRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	patientID, err := uuid.Parse(req.PatientID)
-	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid patient id")
-		return
+	var patientID uuid.UUID
+	if req.PatientID != "" {
+		var err error
+		if patientID, err = uuid.Parse(req.PatientID); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid patient id")
+			return
+		}
 	}
 
 	doctorID, err := uuid.Parse(req.DoctorID)
@@ -74,25 +81,53 @@ func (h *AppointmentHandler) CreateAppointment(w http.ResponseWriter, r *http.Re
 
 	createdAppointment, err := h.appointmentService.CreateAppointment(r.Context(), appointment)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	response := &dto.AppointmentResponse{
-		AppointmentID:   createdAppointment.AppointmentID.String(),
-		PatientID:       createdAppointment.PatientID.String(),
-		DoctorID:        createdAppointment.DoctorID.String(),
-		AppointmentDate: createdAppointment.AppointmentDate,
-		DurationMinutes: createdAppointment.DurationMinutes,
-		Status:          createdAppointment.Status,
-		Notes:           createdAppointment.Notes,
-		CreatedAt:       createdAppointment.CreatedAt,
-		UpdatedAt:       createdAppointment.UpdatedAt,
-	}
+	response := appointmentToResponse(createdAppointment)
 
+	utils.SetETag(w, createdAppointment.Version)
 	utils.WriteJSON(w, http.StatusCreated, response)
 }
 
+// ListAppointments godoc
+// @Summary List appointments
+// @Description Retrieve a page of appointments. Walk the pages by passing next_cursor back as cursor. Requires ADMIN, DOCTOR or NURSE role
+// @Tags Appointment Management
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default: 20, max: 100)" default(20)
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param sort query string false "appointment_date or created_at; prefix with - for descending" default(-appointment_date)
+// @Param status query string false "Filter by status, e.g. CONFIRMED"
+// @Param patient_id query string false "Filter by patient"
+// @Param doctor_id query string false "Filter by doctor"
+// @Param date_from query string false "Appointments at or after (date or RFC 3339)"
+// @Param date_to query string false "Appointments at or before (date or RFC 3339)"
+// @Success 200 {object} dto.ListResponse[dto.AppointmentResponse] "Page of appointments"
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter, sort, limit or cursor"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - staff role required"
+// @Router /appointments [get]
+func (h *AppointmentHandler) ListAppointments(w http.ResponseWriter, r *http.Request) {
+	q, err := listquery.Parse(r.URL.Query(), repository.AppointmentList)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	page, err := h.appointmentService.ListAppointments(r.Context(), q)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.NewListResponse(page, func(appointment *models.Appointment) dto.AppointmentResponse {
+		return *appointmentToResponse(appointment)
+	}))
+}
+
 // GetAppointment godoc
 // @Summary Get appointment by ID
 // @Description Get appointment details by ID
@@ -102,7 +137,9 @@ func (h *AppointmentHandler) CreateAppointment(w http.ResponseWriter, r *http.Re
 // @Security BearerAuth
 // @Param id path string true "Appointment ID"
 // @Success 200 {object} dto.AppointmentResponse "Appointment details"
+// @Header 200 {string} ETag "Current version, to send back as If-Match"
 // @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 403 {object} dto.ErrorResponse "Another patient's appointment"
 // @Failure 404 {object} dto.ErrorResponse "Appointment not found"
 // @Router /appointments/{id} [get]
 func (h *AppointmentHandler) GetAppointment(w http.ResponseWriter, r *http.Request) {
@@ -116,22 +153,13 @@ func (h *AppointmentHandler) GetAppointment(w http.ResponseWriter, r *http.Reque
 
 	appointment, err := h.appointmentService.GetAppointmentByID(r.Context(), appointmentID)
 	if err != nil {
-		utils.WriteError(w, http.StatusNotFound, "appointment not found")
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	response := &dto.AppointmentResponse{
-		AppointmentID:   appointment.AppointmentID.String(),
-		PatientID:       appointment.PatientID.String(),
-		DoctorID:        appointment.DoctorID.String(),
-		AppointmentDate: appointment.AppointmentDate,
-		DurationMinutes: appointment.DurationMinutes,
-		Status:          appointment.Status,
-		Notes:           appointment.Notes,
-		CreatedAt:       appointment.CreatedAt,
-		UpdatedAt:       appointment.UpdatedAt,
-	}
+	response := appointmentToResponse(appointment)
 
+	utils.SetETag(w, appointment.Version)
 	utils.WriteJSON(w, http.StatusOK, response)
 }
 
@@ -144,9 +172,15 @@ func (h *AppointmentHandler) GetAppointment(w http.ResponseWriter, r *http.Reque
 // @Security BearerAuth
 // @Param id path string true "Appointment ID"
 // @Param request body dto.UpdateAppointmentRequest true "Appointment update details"
+// @Param If-Match header string true "ETag from the last read"
 // @Success 200 {object} dto.AppointmentResponse "Appointment updated successfully"
+// @Header 200 {string} ETag "Current version, to send back as If-Match"
 // @Failure 400 {object} dto.ErrorResponse "Validation error"
+// @Failure 403 {object} dto.ErrorResponse "Another patient's appointment, or a field the caller may not change"
 // @Failure 404 {object} dto.ErrorResponse "Appointment not found"
+// @Failure 409 {object} dto.ErrorResponse "Appointment is completed or cancelled"
+// @Failure 412 {object} dto.ErrorResponse "Modified since it was read - fetch again and retry"
+// @Failure 428 {object} dto.ErrorResponse "If-Match header missing"
 // @Router /appointments/{id} [put]
 func (h *AppointmentHandler) UpdateAppointment(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -157,9 +191,15 @@ func (h *AppointmentHandler) UpdateAppointment(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
 	var req dto.UpdateAppointmentRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -175,25 +215,111 @@ func (h *AppointmentHandler) UpdateAppointment(w http.ResponseWriter, r *http.Re
 		DurationMinutes: req.DurationMinutes,
 		Status:          req.Status,
 		Notes:           req.Notes,
+		Version:         version,
 	}
 
 	updatedAppointment, err := h.appointmentService.UpdateAppointment(r.Context(), appointment)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	response := &dto.AppointmentResponse{
-		AppointmentID:   updatedAppointment.AppointmentID.String(),
-		PatientID:       updatedAppointment.PatientID.String(),
-		DoctorID:        updatedAppointment.DoctorID.String(),
-		AppointmentDate: updatedAppointment.AppointmentDate,
-		DurationMinutes: updatedAppointment.DurationMinutes,
-		Status:          updatedAppointment.Status,
-		Notes:           updatedAppointment.Notes,
-		CreatedAt:       updatedAppointment.CreatedAt,
-		UpdatedAt:       updatedAppointment.UpdatedAt,
-	}
+	response := appointmentToResponse(updatedAppointment)
 
+	utils.SetETag(w, updatedAppointment.Version)
 	utils.WriteJSON(w, http.StatusOK, response)
 }
+
+// PatchAppointment godoc
+// @Summary Partially update an appointment
+// @Description Apply a JSON Merge Patch (RFC 7396) to an appointment. Only the fields sent are changed. Patients may only change notes; staff may also change the date, duration and status.
+// @Tags Appointment Management
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Appointment ID"
+// @Param request body dto.UpdateAppointmentRequest true "Fields to change"
+// @Param If-Match header string true "ETag from the last read"
+// @Success 200 {object} dto.AppointmentResponse "Appointment updated successfully"
+// @Header 200 {string} ETag "Current version, to send back as If-Match"
+// @Failure 400 {object} dto.ErrorResponse "Validation error"
+// @Failure 403 {object} dto.ErrorResponse "Another patient's appointment, or a field the caller may not change"
+// @Failure 404 {object} dto.ErrorResponse "Appointment not found"
+// @Failure 409 {object} dto.ErrorResponse "Appointment is completed or cancelled"
+// @Failure 412 {object} dto.ErrorResponse "Modified since it was read - fetch again and retry"
+// @Failure 415 {object} dto.ErrorResponse "Body is not a JSON Merge Patch"
+// @Failure 428 {object} dto.ErrorResponse "If-Match header missing"
+// @Router /appointments/{id} [patch]
+func (h *AppointmentHandler) PatchAppointment(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	appointmentID, err := uuid.Parse(id)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid appointment id")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	current, err := h.appointmentService.GetAppointmentByID(r.Context(), appointmentID)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	// The stored date is kept to the nanosecond so that a patch leaving it
+	// out does not look like a change of date.
+	req := dto.UpdateAppointmentRequest{
+		AppointmentDate: current.AppointmentDate.Format(time.RFC3339Nano),
+		DurationMinutes: current.DurationMinutes,
+		Status:          current.Status,
+		Notes:           current.Notes,
+	}
+	if err := utils.DecodeMergePatch(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	appointmentDate, err := time.Parse(time.RFC3339, req.AppointmentDate)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid appointment date format")
+		return
+	}
+
+	appointment := &models.Appointment{
+		AppointmentID:   appointmentID,
+		AppointmentDate: appointmentDate,
+		DurationMinutes: req.DurationMinutes,
+		Status:          req.Status,
+		Notes:           req.Notes,
+		Version:         version,
+	}
+
+	updatedAppointment, err := h.appointmentService.UpdateAppointment(r.Context(), appointment)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.SetETag(w, updatedAppointment.Version)
+	utils.WriteJSON(w, http.StatusOK, appointmentToResponse(updatedAppointment))
+}
+
+func appointmentToResponse(appointment *models.Appointment) *dto.AppointmentResponse {
+	return &dto.AppointmentResponse{
+		AppointmentID:   appointment.AppointmentID.String(),
+		PatientID:       appointment.PatientID.String(),
+		DoctorID:        appointment.DoctorID.String(),
+		AppointmentDate: appointment.AppointmentDate,
+		DurationMinutes: appointment.DurationMinutes,
+		Status:          appointment.Status,
+		Notes:           appointment.Notes,
+		CreatedAt:       appointment.CreatedAt,
+		UpdatedAt:       appointment.UpdatedAt,
+		Version:         appointment.Version,
+	}
+}
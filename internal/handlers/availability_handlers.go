@@ -1,14 +1,11 @@
 package handlers
 
 import (
-	"encoding/json"
-
-	"github.com/google/uuid"
-
 	"net/http"
-	"strings"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/falasefemi2/hms/internal/dto"
 	"github.com/falasefemi2/hms/internal/models"
 	"github.com/falasefemi2/hms/internal/service"
@@ -25,25 +22,6 @@ func NewAvailabilityHandlers(availabilityService *service.AvailabilityService) *
 	}
 }
 
-var validDays = map[string]bool{
-	"Monday":    true,
-	"Tuesday":   true,
-	"Wednesday": true,
-	"Thursday":  true,
-	"Friday":    true,
-	"Saturday":  true,
-	"Sunday":    true,
-}
-
-func (a *AvailabilityHandlers) validateTimeFormat(timeStr string) error {
-	_, err := time.Parse("15:04", timeStr)
-	return err
-}
-
-func (a *AvailabilityHandlers) validateDayOfWeek(day string) bool {
-	return validDays[day]
-}
-
 // CreateAvailability creates doctor availability
 // @Summary Create doctor availability
 // @Description Create doctor availability slot. Requires valid JWT token with ADMIN role
@@ -52,30 +30,17 @@ func (a *AvailabilityHandlers) validateDayOfWeek(day string) bool {
 // @Produce json
 // @Security BearerAuth
 // @Param request body dto.AvailabilityRequest true "Availability details"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
 // @Success 201 {object} dto.AvailabilityResponse "Availability created successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
 // @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
 // @Router /admin/doctors/availability [post]
 func (a *AvailabilityHandlers) CreateAvailability(w http.ResponseWriter, r *http.Request) {
 	var req dto.AvailabilityRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
-		return
-	}
-
-	if !a.validateDayOfWeek(req.DayOfWeek) {
-		utils.WriteError(w, http.StatusBadRequest, "invalid day of week. use: Monday, Tuesday, Wednesday, Thursday, Friday, Saturday, Sunday")
-		return
-	}
-
-	if err := a.validateTimeFormat(req.StartTime); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid start time format. use HH:MM (24-hour format)")
-		return
-	}
-
-	if err := a.validateTimeFormat(req.EndTime); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid end time format. use HH:MM (24-hour format)")
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -86,11 +51,6 @@ func (a *AvailabilityHandlers) CreateAvailability(w http.ResponseWriter, r *http
 		return
 	}
 
-	if req.MaxAppointments <= 0 {
-		utils.WriteError(w, http.StatusBadRequest, "max appointments must be greater than 0")
-		return
-	}
-
 	availability := &models.Availability{
 		AvailabilityID: uuid.New(),
 		DoctorID:       req.DoctorID,
@@ -102,25 +62,22 @@ func (a *AvailabilityHandlers) CreateAvailability(w http.ResponseWriter, r *http
 
 	createdAvailability, err := a.availabilityService.CreateDoctorAvailability(r.Context(), availability)
 	if err != nil {
-		errorMsg := err.Error()
-		if strings.Contains(errorMsg, "not found") {
-			utils.WriteError(w, http.StatusNotFound, errorMsg)
-			return
-		}
-		utils.WriteError(w, http.StatusBadRequest, errorMsg)
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	response := &dto.AvailabilityResponse{
-		AvailabilityID:  createdAvailability.AvailabilityID,
-		DoctorID:        createdAvailability.DoctorID,
-		DayOfWeek:       createdAvailability.DayOfWeek,
-		StartTime:       createdAvailability.StartTime,
-		EndTime:         createdAvailability.EndTime,
-		MaxAppointments: createdAvailability.MaxAppointment,
-		CreatedAt:       createdAvailability.CreatedAt,
-		UpdatedAt:       createdAvailability.UpdatedAt,
-	}
+	utils.WriteJSON(w, http.StatusCreated, availabilityToResponse(createdAvailability))
+}
 
-	utils.WriteJSON(w, http.StatusCreated, response)
+func availabilityToResponse(availability *models.Availability) *dto.AvailabilityResponse {
+	return &dto.AvailabilityResponse{
+		AvailabilityID:  availability.AvailabilityID,
+		DoctorID:        availability.DoctorID,
+		DayOfWeek:       availability.DayOfWeek,
+		StartTime:       availability.StartTime,
+		EndTime:         availability.EndTime,
+		MaxAppointments: availability.MaxAppointment,
+		CreatedAt:       availability.CreatedAt,
+		UpdatedAt:       availability.UpdatedAt,
+	}
 }
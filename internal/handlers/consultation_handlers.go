@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -31,15 +30,18 @@ func NewConsultationHandler(consultationService *service.ConsultationService) *C
 // @Produce json
 // @Security BearerAuth
 // @Param request body dto.CreateConsultationRequest true "Consultation creation details"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
 // @Success 201 {object} dto.ConsultationResponse "Consultation created successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 409 {object} dto.ErrorResponse "Consultation already exists or appointment not completed"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
 // @Router /consultations [post]
 func (h *ConsultationHandler) CreateConsultation(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateConsultationRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -73,21 +75,13 @@ func (h *ConsultationHandler) CreateConsultation(w http.ResponseWriter, r *http.
 
 	createdConsultation, err := h.consultationService.CreateConsultation(r.Context(), consultation)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	response := &dto.ConsultationResponse{
-		ConsultationID: createdConsultation.ConsultationID.String(),
-		AppointmentID:  createdConsultation.AppointmentID.String(),
-		PatientID:      createdConsultation.PatientID.String(),
-		DoctorID:       createdConsultation.DoctorID.String(),
-		Diagnosis:      createdConsultation.Diagnosis,
-		Notes:          createdConsultation.Notes,
-		CreatedAt:      createdConsultation.CreatedAt,
-		IsEditable:     createdConsultation.IsEditable,
-	}
+	response := consultationToResponse(createdConsultation)
 
+	utils.SetETag(w, createdConsultation.Version)
 	utils.WriteJSON(w, http.StatusCreated, response)
 }
 
@@ -100,7 +94,9 @@ func (h *ConsultationHandler) CreateConsultation(w http.ResponseWriter, r *http.
 // @Security BearerAuth
 // @Param id path string true "Consultation ID"
 // @Success 200 {object} dto.ConsultationResponse "Consultation details"
+// @Header 200 {string} ETag "Current version, to send back as If-Match"
 // @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 403 {object} dto.ErrorResponse "Another patient's consultation"
 // @Failure 404 {object} dto.ErrorResponse "Consultation not found"
 // @Router /consultations/{id} [get]
 func (h *ConsultationHandler) GetConsultation(w http.ResponseWriter, r *http.Request) {
@@ -114,21 +110,13 @@ func (h *ConsultationHandler) GetConsultation(w http.ResponseWriter, r *http.Req
 
 	consultation, err := h.consultationService.GetConsultationByID(r.Context(), consultationID)
 	if err != nil {
-		utils.WriteError(w, http.StatusNotFound, "consultation not found")
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	response := &dto.ConsultationResponse{
-		ConsultationID: consultation.ConsultationID.String(),
-		AppointmentID:  consultation.AppointmentID.String(),
-		PatientID:      consultation.PatientID.String(),
-		DoctorID:       consultation.DoctorID.String(),
-		Diagnosis:      consultation.Diagnosis,
-		Notes:          consultation.Notes,
-		CreatedAt:      consultation.CreatedAt,
-		IsEditable:     consultation.IsEditable,
-	}
+	response := consultationToResponse(consultation)
 
+	utils.SetETag(w, consultation.Version)
 	utils.WriteJSON(w, http.StatusOK, response)
 }
 
@@ -141,9 +129,14 @@ func (h *ConsultationHandler) GetConsultation(w http.ResponseWriter, r *http.Req
 // @Security BearerAuth
 // @Param id path string true "Consultation ID"
 // @Param request body dto.UpdateConsultationRequest true "Consultation update details"
+// @Param If-Match header string true "ETag from the last read"
 // @Success 200 {object} dto.ConsultationResponse "Consultation updated successfully"
+// @Header 200 {string} ETag "Current version, to send back as If-Match"
 // @Failure 400 {object} dto.ErrorResponse "Validation error"
 // @Failure 404 {object} dto.ErrorResponse "Consultation not found"
+// @Failure 409 {object} dto.ErrorResponse "Consultation is not editable"
+// @Failure 412 {object} dto.ErrorResponse "Modified since it was read - fetch again and retry"
+// @Failure 428 {object} dto.ErrorResponse "If-Match header missing"
 // @Router /consultations/{id} [put]
 func (h *ConsultationHandler) UpdateConsultation(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -154,9 +147,15 @@ func (h *ConsultationHandler) UpdateConsultation(w http.ResponseWriter, r *http.
 		return
 	}
 
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
 	var req dto.UpdateConsultationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -164,24 +163,98 @@ func (h *ConsultationHandler) UpdateConsultation(w http.ResponseWriter, r *http.
 		ConsultationID: consultationID,
 		Diagnosis:      req.Diagnosis,
 		Notes:          req.Notes,
+		Version:        version,
 	}
 
 	updatedConsultation, err := h.consultationService.UpdateConsultation(r.Context(), consultation)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	response := &dto.ConsultationResponse{
-		ConsultationID: updatedConsultation.ConsultationID.String(),
-		AppointmentID:  updatedConsultation.AppointmentID.String(),
-		PatientID:      updatedConsultation.PatientID.String(),
-		DoctorID:       updatedConsultation.DoctorID.String(),
-		Diagnosis:      updatedConsultation.Diagnosis,
-		Notes:          updatedConsultation.Notes,
-		CreatedAt:      updatedConsultation.CreatedAt,
-		IsEditable:     updatedConsultation.IsEditable,
-	}
+	response := consultationToResponse(updatedConsultation)
 
+	utils.SetETag(w, updatedConsultation.Version)
 	utils.WriteJSON(w, http.StatusOK, response)
 }
+
+// PatchConsultation godoc
+// @Summary Partially update a consultation
+// @Description Apply a JSON Merge Patch (RFC 7396) to a consultation. Only the fields sent are changed. Only doctors and admins may change the diagnosis or notes.
+// @Tags Consultation Management
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Consultation ID"
+// @Param request body dto.UpdateConsultationRequest true "Fields to change"
+// @Param If-Match header string true "ETag from the last read"
+// @Success 200 {object} dto.ConsultationResponse "Consultation updated successfully"
+// @Header 200 {string} ETag "Current version, to send back as If-Match"
+// @Failure 400 {object} dto.ErrorResponse "Validation error"
+// @Failure 403 {object} dto.ErrorResponse "Not permitted to change one of the fields"
+// @Failure 404 {object} dto.ErrorResponse "Consultation not found"
+// @Failure 409 {object} dto.ErrorResponse "Consultation is not editable"
+// @Failure 412 {object} dto.ErrorResponse "Modified since it was read - fetch again and retry"
+// @Failure 415 {object} dto.ErrorResponse "Body is not a JSON Merge Patch"
+// @Failure 428 {object} dto.ErrorResponse "If-Match header missing"
+// @Router /consultations/{id} [patch]
+func (h *ConsultationHandler) PatchConsultation(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	consultationID, err := uuid.Parse(id)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid consultation id")
+		return
+	}
+
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	current, err := h.consultationService.GetConsultationByID(r.Context(), consultationID)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	req := dto.UpdateConsultationRequest{
+		Diagnosis: current.Diagnosis,
+		Notes:     current.Notes,
+	}
+	if err := utils.DecodeMergePatch(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	consultation := &models.Consultation{
+		ConsultationID: consultationID,
+		Diagnosis:      req.Diagnosis,
+		Notes:          req.Notes,
+		Version:        version,
+	}
+
+	updatedConsultation, err := h.consultationService.UpdateConsultation(r.Context(), consultation)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.SetETag(w, updatedConsultation.Version)
+	utils.WriteJSON(w, http.StatusOK, consultationToResponse(updatedConsultation))
+}
+
+func consultationToResponse(consultation *models.Consultation) *dto.ConsultationResponse {
+	return &dto.ConsultationResponse{
+		ConsultationID: consultation.ConsultationID.String(),
+		AppointmentID:  consultation.AppointmentID.String(),
+		PatientID:      consultation.PatientID.String(),
+		DoctorID:       consultation.DoctorID.String(),
+		Diagnosis:      consultation.Diagnosis,
+		Notes:          consultation.Notes,
+		CreatedAt:      consultation.CreatedAt,
+		IsEditable:     consultation.IsEditable,
+		Version:        consultation.Version,
+	}
+}
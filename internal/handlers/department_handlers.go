@@ -1,14 +1,14 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/repository"
 	"github.com/falasefemi2/hms/internal/service"
 	"github.com/falasefemi2/hms/internal/utils"
 )
@@ -31,16 +31,18 @@ func NewDeptHandler(deptService *service.DepartmentService) *DepartmentHandler {
 // @Produce      json
 // @Security BearerAuth
 // @Param        request  body      dto.CreateDepartmentRequest  true  "Department details"
+// @Param        Idempotency-Key  header    string  false  "Makes retries safe; the first response is replayed"
 // @Success      201      {object}  dto.DepartmentResponse       "Department created successfully"
-// @Failure      400      {object}  map[string]string            "Invalid request or validation error"
-// @Failure      500      {object}  map[string]string            "Internal server error"
+// @Failure      400      {object}  dto.ErrorResponse            "Invalid request or validation error"
+// @Failure      422      {object}  dto.ErrorResponse            "Idempotency-Key reused with a different request"
+// @Failure      500      {object}  dto.ErrorResponse            "Internal server error"
 // @Security     Bearer
 // @Router       /admin/departments [post]
 func (dh *DepartmentHandler) CreateDepartment(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateDepartmentRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -49,10 +51,11 @@ func (dh *DepartmentHandler) CreateDepartment(w http.ResponseWriter, r *http.Req
 
 	createdDept, err := dh.deptService.CreateDepartment(r.Context(), &req)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
+	utils.SetETag(w, createdDept.Version)
 	utils.WriteJSON(w, http.StatusCreated, createdDept)
 }
 
@@ -64,9 +67,10 @@ func (dh *DepartmentHandler) CreateDepartment(w http.ResponseWriter, r *http.Req
 // @Security BearerAuth
 // @Param        id       path      string                   true  "Department ID (UUID)"
 // @Success      200      {object}  dto.DepartmentResponse   "Department retrieved successfully"
-// @Failure      400      {object}  map[string]string        "Invalid department ID"
-// @Failure      404      {object}  map[string]string        "Department not found"
-// @Failure      500      {object}  map[string]string        "Internal server error"
+// @Header       200      {string}  ETag                         "Current version, to send back as If-Match"
+// @Failure      400      {object}  dto.ErrorResponse        "Invalid department ID"
+// @Failure      404      {object}  dto.ErrorResponse        "Department not found"
+// @Failure      500      {object}  dto.ErrorResponse        "Internal server error"
 // @Security     Bearer
 // @Router       /admin/departments/{id} [get]
 func (dh *DepartmentHandler) GetDepartment(w http.ResponseWriter, r *http.Request) {
@@ -79,60 +83,39 @@ func (dh *DepartmentHandler) GetDepartment(w http.ResponseWriter, r *http.Reques
 
 	dept, err := dh.deptService.GetDepartmentByID(r.Context(), deptID)
 	if err != nil {
-		utils.WriteError(w, http.StatusNotFound, err.Error())
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
+	utils.SetETag(w, dept.Version)
 	utils.WriteJSON(w, http.StatusOK, dept)
 }
 
-// GetAllDepartments godoc
-// @Summary      List all departments
-// @Description  Retrieves all active departments with pagination support
+// ListDepartments godoc
+// @Summary      List departments
+// @Description  Retrieves a page of departments, active ones unless is_active says otherwise. Walk the pages by passing next_cursor back as cursor.
 // @Tags         departments
 // @Produce      json
 // @Security BearerAuth
-// @Param        page       query     int                          false  "Page number (default 1)"                         default(1)
-// @Param        page_size  query     int                          false  "Number of items per page (default 10, max 100)"  default(10)
-// @Success      200        {object}  dto.DepartmentListResponse   "Departments retrieved successfully"
-// @Failure      400        {object}  map[string]string            "Invalid pagination parameters"
-// @Failure      500        {object}  map[string]string            "Internal server error"
+// @Param        limit      query     int     false  "Page size (default 20, max 100)"                           default(20)
+// @Param        cursor     query     string  false  "next_cursor from the previous page"
+// @Param        sort       query     string  false  "created_at or name; prefix with - for descending"        default(-created_at)
+// @Param        is_active  query     bool    false  "Filter by active status"                                   default(true)
+// @Success      200        {object}  dto.ListResponse[dto.DepartmentResponse]  "Departments retrieved successfully"
+// @Failure      400        {object}  dto.ErrorResponse            "Invalid filter, sort, limit or cursor"
+// @Failure      500        {object}  dto.ErrorResponse            "Internal server error"
 // @Security     Bearer
 // @Router      /admin/departments [get]
-func (dh *DepartmentHandler) GetAllDepartments(w http.ResponseWriter, r *http.Request) {
-	pagination := &dto.PaginationRequest{
-		Page:     1,
-		PageSize: 10,
-	}
-
-	// Parse page parameter
-	pageStr := r.URL.Query().Get("page")
-	if pageStr != "" {
-		page, err := strconv.Atoi(pageStr)
-		if err != nil || page < 1 {
-			utils.WriteError(w, http.StatusBadRequest, "page must be a positive integer")
-			return
-		}
-		pagination.Page = page
-	}
-
-	// Parse page_size parameter
-	pageSizeStr := r.URL.Query().Get("page_size")
-	if pageSizeStr != "" {
-		pageSize, err := strconv.Atoi(pageSizeStr)
-		if err != nil || pageSize < 1 {
-			utils.WriteError(w, http.StatusBadRequest, "page_size must be a positive integer")
-			return
-		}
-		if pageSize > 100 {
-			pageSize = 100 // Cap max page size
-		}
-		pagination.PageSize = pageSize
+func (dh *DepartmentHandler) ListDepartments(w http.ResponseWriter, r *http.Request) {
+	q, err := listquery.Parse(r.URL.Query(), repository.DepartmentList)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
 	}
 
-	result, err := dh.deptService.GetAllDepartments(r.Context(), pagination)
+	result, err := dh.deptService.ListDepartments(r.Context(), q)
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err.Error())
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -148,10 +131,15 @@ func (dh *DepartmentHandler) GetAllDepartments(w http.ResponseWriter, r *http.Re
 // @Security BearerAuth
 // @Param        id       path      string                       true  "Department ID (UUID)"
 // @Param        request  body      dto.UpdateDepartmentRequest  true  "Fields to update (all optional)"
+// @Param        If-Match  header    string                       true  "ETag from the last read"
 // @Success      200      {object}  dto.DepartmentResponse       "Department updated successfully"
-// @Failure      400      {object}  map[string]string            "Invalid request or validation error"
-// @Failure      404      {object}  map[string]string            "Department not found"
-// @Failure      500      {object}  map[string]string            "Internal server error"
+// @Header       200      {string}  ETag                         "Current version, to send back as If-Match"
+// @Failure      400      {object}  dto.ErrorResponse            "Invalid request or validation error"
+// @Failure      404      {object}  dto.ErrorResponse            "Department not found"
+// @Failure      409      {object}  dto.ErrorResponse            "Department is inactive"
+// @Failure      412      {object}  dto.ErrorResponse            "Department changed since it was read"
+// @Failure      428      {object}  dto.ErrorResponse            "If-Match header missing"
+// @Failure      500      {object}  dto.ErrorResponse            "Internal server error"
 // @Security     Bearer
 // @Router       /admin/departments/{id} [put]
 func (dh *DepartmentHandler) UpdateDepartment(w http.ResponseWriter, r *http.Request) {
@@ -162,10 +150,16 @@ func (dh *DepartmentHandler) UpdateDepartment(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	version, err := utils.IfMatchVersion(r)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
 	var req dto.UpdateDepartmentRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -179,12 +173,13 @@ func (dh *DepartmentHandler) UpdateDepartment(w http.ResponseWriter, r *http.Req
 		req.Description = &trimmed
 	}
 
-	updated, err := dh.deptService.UpdateDepartment(r.Context(), deptID, &req)
+	updated, err := dh.deptService.UpdateDepartment(r.Context(), deptID, version, &req)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
+	utils.SetETag(w, updated.Version)
 	utils.WriteJSON(w, http.StatusOK, updated)
 }
 
@@ -196,9 +191,10 @@ func (dh *DepartmentHandler) UpdateDepartment(w http.ResponseWriter, r *http.Req
 // @Security BearerAuth
 // @Param        id  path      string             true  "Department ID (UUID)"
 // @Success      200 {object}  map[string]string  "Department deleted successfully"
-// @Failure      400 {object}  map[string]string  "Invalid request or department already deleted"
-// @Failure      404 {object}  map[string]string  "Department not found"
-// @Failure      500 {object}  map[string]string  "Internal server error"
+// @Failure      400 {object}  dto.ErrorResponse  "Invalid request"
+// @Failure      404 {object}  dto.ErrorResponse  "Department not found"
+// @Failure      409 {object}  dto.ErrorResponse  "Department already deleted"
+// @Failure      500 {object}  dto.ErrorResponse  "Internal server error"
 // @Security     Bearer
 // @Router       /admin/departments/{id} [delete]
 func (dh *DepartmentHandler) DeleteDepartment(w http.ResponseWriter, r *http.Request) {
@@ -211,7 +207,7 @@ func (dh *DepartmentHandler) DeleteDepartment(w http.ResponseWriter, r *http.Req
 
 	err := dh.deptService.DeleteDepartment(r.Context(), deptID)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+type DoctorDirectoryHandler struct {
+	directoryService *service.DoctorDirectoryService
+}
+
+func NewDoctorDirectoryHandler(directoryService *service.DoctorDirectoryService) *DoctorDirectoryHandler {
+	return &DoctorDirectoryHandler{
+		directoryService: directoryService,
+	}
+}
+
+// ListDoctors godoc
+// @Summary Doctor directory
+// @Description Browse doctors with active accounts before booking, with their department and next free 30-minute slot in the coming two weeks (null if none or the doctor is unavailable). Walk the pages by passing next_cursor back as cursor. Requires a valid JWT token with any role
+// @Tags Doctor Directory
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default: 20, max: 100)" default(20)
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param sort query string false "last_name; prefix with - for descending" default(last_name)
+// @Param specialization query string false "Filter by specialization"
+// @Param department_id query string false "Filter by department"
+// @Param is_available query bool false "Filter by availability"
+// @Param fee_min query number false "Minimum consultation fee"
+// @Param fee_max query number false "Maximum consultation fee"
+// @Success 200 {object} dto.ListResponse[dto.DoctorDirectoryEntry] "Page of doctors"
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter, sort, limit or cursor"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Router /doctors [get]
+func (h *DoctorDirectoryHandler) ListDoctors(w http.ResponseWriter, r *http.Request) {
+	q, err := listquery.Parse(r.URL.Query(), repository.DoctorDirectory)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	page, err := h.directoryService.ListDoctors(r.Context(), q)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.NewListResponse(page, func(listing *models.DoctorListing) dto.DoctorDirectoryEntry {
+		return dto.DoctorDirectoryEntry{
+			DoctorID:          listing.Doctor.DoctorID.String(),
+			FirstName:         listing.FirstName,
+			LastName:          listing.LastName,
+			Specialization:    listing.Doctor.Specialization,
+			DepartmentID:      listing.Doctor.DepartmentID.String(),
+			DepartmentName:    listing.DepartmentName,
+			ConsultationFee:   listing.Doctor.ConsultationFee,
+			IsAvailable:       listing.Doctor.IsAvailable,
+			NextAvailableSlot: listing.NextAvailableSlot,
+		}
+	}))
+}
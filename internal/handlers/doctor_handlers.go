@@ -1,14 +1,16 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 	"strings"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
 	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
 	"github.com/falasefemi2/hms/internal/service"
 	"github.com/falasefemi2/hms/internal/utils"
 )
@@ -31,17 +33,19 @@ func NewDoctorHandler(doctorService *service.DoctorService) *DoctorHandler {
 // @Produce json
 // @Security BearerAuth
 // @Param request body dto.DoctorSignUpRequest true "Doctor creation details"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
 // @Success 201 {object} dto.DoctorResponse "Doctor created successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error - invalid input or invalid role"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
 // @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
 // @Failure 409 {object} dto.ErrorResponse "Conflict - doctor already exists for this user"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
 // @Router /admin/doctors [post]
 func (h *DoctorHandler) CreateDoctor(w http.ResponseWriter, r *http.Request) {
 	var req dto.DoctorSignUpRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -68,26 +72,323 @@ func (h *DoctorHandler) CreateDoctor(w http.ResponseWriter, r *http.Request) {
 
 	createdDoctor, err := h.doctorService.CreateDoctor(r.Context(), doctor)
 	if err != nil {
-		errorMsg := err.Error()
-		if strings.Contains(errorMsg, "doctor already exists") {
-			utils.WriteError(w, http.StatusConflict, errorMsg)
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	response := doctorToResponse(createdDoctor)
+
+	utils.WriteJSON(w, http.StatusCreated, response)
+}
+
+// ListDoctors godoc
+// @Summary List doctors
+// @Description Retrieve a page of doctors. Walk the pages by passing next_cursor back as cursor. Requires valid JWT token with ADMIN role
+// @Tags Doctor Management
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default: 20, max: 100)" default(20)
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param sort query string false "created_at; prefix with - for descending" default(-created_at)
+// @Param department_id query string false "Filter by department"
+// @Param specialization query string false "Filter by specialization"
+// @Param is_available query bool false "Filter by availability"
+// @Param fee_min query number false "Minimum consultation fee"
+// @Param fee_max query number false "Maximum consultation fee"
+// @Success 200 {object} dto.ListResponse[dto.DoctorResponse] "Page of doctors"
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter, sort, limit or cursor"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Router /admin/doctors [get]
+func (h *DoctorHandler) ListDoctors(w http.ResponseWriter, r *http.Request) {
+	q, err := listquery.Parse(r.URL.Query(), repository.DoctorList)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	page, err := h.doctorService.ListDoctors(r.Context(), q)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.NewListResponse(page, func(doctor *models.Doctor) dto.DoctorResponse {
+		return *doctorToResponse(doctor)
+	}))
+}
+
+// PatchDoctor godoc
+// @Summary Partially update a doctor
+// @Description Apply a JSON Merge Patch (RFC 7396) to a doctor. Only the fields sent are changed. Requires valid JWT token with ADMIN role
+// @Tags Doctor Management
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Doctor ID"
+// @Param request body dto.UpdateDoctorRequest true "Fields to change"
+// @Success 200 {object} dto.DoctorResponse "Doctor updated successfully"
+// @Failure 400 {object} dto.ErrorResponse "Validation error"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 404 {object} dto.ErrorResponse "Doctor not found"
+// @Failure 409 {object} dto.ErrorResponse "License number already registered"
+// @Failure 415 {object} dto.ErrorResponse "Body is not a JSON Merge Patch"
+// @Router /admin/doctors/{id} [patch]
+func (h *DoctorHandler) PatchDoctor(w http.ResponseWriter, r *http.Request) {
+	doctorID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid doctor id")
+		return
+	}
+
+	current, err := h.doctorService.GetDoctorByID(r.Context(), doctorID)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	req := dto.UpdateDoctorRequest{
+		Specialization:  current.Specialization,
+		LicenseNumber:   current.LicenseNumber,
+		DepartmentID:    current.DepartmentID.String(),
+		ConsultationFee: current.ConsultationFee,
+		IsAvailable:     current.IsAvailable,
+	}
+	if err := utils.DecodeMergePatch(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	departmentID, err := uuid.Parse(req.DepartmentID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid department id")
+		return
+	}
+
+	doctor := &models.Doctor{
+		DoctorID:        doctorID,
+		Specialization:  strings.TrimSpace(req.Specialization),
+		LicenseNumber:   strings.TrimSpace(req.LicenseNumber),
+		DepartmentID:    departmentID,
+		ConsultationFee: req.ConsultationFee,
+		IsAvailable:     req.IsAvailable,
+	}
+
+	updatedDoctor, err := h.doctorService.UpdateDoctor(r.Context(), doctor)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	response := doctorToResponse(updatedDoctor)
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetDoctor godoc
+// @Summary Get a doctor
+// @Description Retrieve a doctor by ID. Requires valid JWT token with ADMIN role
+// @Tags Doctor Management
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Doctor ID"
+// @Success 200 {object} dto.DoctorResponse "Doctor"
+// @Failure 400 {object} dto.ErrorResponse "Invalid doctor ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 404 {object} dto.ErrorResponse "Doctor not found"
+// @Router /admin/doctors/{id} [get]
+func (h *DoctorHandler) GetDoctor(w http.ResponseWriter, r *http.Request) {
+	doctorID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid doctor id")
+		return
+	}
+
+	doctor, err := h.doctorService.GetDoctorByID(r.Context(), doctorID)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, doctorToResponse(doctor))
+}
+
+// DeactivateDoctor godoc
+// @Summary Deactivate a doctor
+// @Description Disable the doctor's account and mark them unavailable. Their upcoming pending and confirmed appointments move to reassign_to_doctor_id, or are cancelled when it is left out. Reassignment fails with 409 if any appointment would clash with the other doctor's bookings. Requires valid JWT token with ADMIN role
+// @Tags Doctor Management
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Doctor ID"
+// @Param request body dto.DeactivateDoctorRequest true "Who takes over the appointments"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
+// @Success 200 {object} dto.DoctorDeactivationResponse "Doctor deactivated"
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID or unusable replacement doctor"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 404 {object} dto.ErrorResponse "Doctor not found"
+// @Failure 409 {object} dto.ErrorResponse "Doctor already deactivated, or the replacement is already booked"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
+// @Router /admin/doctors/{id}/deactivate [post]
+func (h *DoctorHandler) DeactivateDoctor(w http.ResponseWriter, r *http.Request) {
+	doctorID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid doctor id")
+		return
+	}
+
+	var req dto.DeactivateDoctorRequest
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+	var reassignTo *uuid.UUID
+	if req.ReassignToDoctorID != nil {
+		id, err := uuid.Parse(*req.ReassignToDoctorID)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid reassign_to_doctor_id")
 			return
 		}
-		utils.WriteError(w, http.StatusBadRequest, errorMsg)
+		reassignTo = &id
+	}
+
+	deactivation, err := h.doctorService.DeactivateDoctor(r.Context(), doctorID, reassignTo)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	response := &dto.DoctorResponse{
-		DoctorID:        createdDoctor.DoctorID.String(),
-		UserID:          createdDoctor.UserID.String(),
-		Specialization:  createdDoctor.Specialization,
-		LicenseNumber:   createdDoctor.LicenseNumber,
-		DepartmentID:    createdDoctor.DepartmentID.String(),
-		ConsultationFee: createdDoctor.ConsultationFee,
-		IsAvailable:     createdDoctor.IsAvailable,
-		CreatedAt:       createdDoctor.CreatedAt,
-		UpdatedAt:       createdDoctor.UpdatedAt,
+	response := dto.DoctorDeactivationResponse{
+		Doctor:                 *doctorToResponse(&deactivation.Doctor),
+		AppointmentsReassigned: deactivation.AppointmentsReassigned,
+		AppointmentsCancelled:  deactivation.AppointmentsCancelled,
+	}
+	if deactivation.ReassignedTo != nil {
+		id := deactivation.ReassignedTo.String()
+		response.ReassignedTo = &id
 	}
+	utils.WriteJSON(w, http.StatusOK, response)
+}
 
-	utils.WriteJSON(w, http.StatusCreated, response)
+// ActivateDoctor godoc
+// @Summary Reactivate a doctor
+// @Description Re-enable a deactivated doctor's account and mark them available. Appointments moved or cancelled on deactivation are not restored. Requires valid JWT token with ADMIN role
+// @Tags Doctor Management
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Doctor ID"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
+// @Success 200 {object} dto.DoctorResponse "Doctor reactivated"
+// @Failure 400 {object} dto.ErrorResponse "Invalid doctor ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 404 {object} dto.ErrorResponse "Doctor not found"
+// @Failure 409 {object} dto.ErrorResponse "Doctor is already active"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
+// @Router /admin/doctors/{id}/activate [post]
+func (h *DoctorHandler) ActivateDoctor(w http.ResponseWriter, r *http.Request) {
+	doctorID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid doctor id")
+		return
+	}
+
+	doctor, err := h.doctorService.ActivateDoctor(r.Context(), doctorID)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, doctorToResponse(doctor))
+}
+
+// GetMyProfile godoc
+// @Summary Get my doctor profile
+// @Description Retrieve the calling doctor's profile and account details. Requires valid JWT token with DOCTOR role
+// @Tags Doctor Management
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.DoctorProfileResponse "Profile"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - doctor role required"
+// @Failure 404 {object} dto.ErrorResponse "No doctor profile for this user"
+// @Router /doctors/me [get]
+func (h *DoctorHandler) GetMyProfile(w http.ResponseWriter, r *http.Request) {
+	profile, err := h.doctorService.GetMyProfile(r.Context())
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, doctorProfileToResponse(profile))
+}
+
+// PatchMyProfile godoc
+// @Summary Update my doctor profile
+// @Description Apply a JSON Merge Patch (RFC 7396) to the calling doctor's own name, phone and availability. Other fields are managed by admins. Requires valid JWT token with DOCTOR role
+// @Tags Doctor Management
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateDoctorProfileRequest true "Fields to change"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
+// @Success 200 {object} dto.DoctorProfileResponse "Profile updated"
+// @Failure 400 {object} dto.ErrorResponse "Validation error or a field doctors cannot change"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - doctor role required"
+// @Failure 404 {object} dto.ErrorResponse "No doctor profile for this user"
+// @Failure 415 {object} dto.ErrorResponse "Body is not a JSON Merge Patch"
+// @Router /doctors/me [patch]
+func (h *DoctorHandler) PatchMyProfile(w http.ResponseWriter, r *http.Request) {
+	current, err := h.doctorService.GetMyProfile(r.Context())
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	req := dto.UpdateDoctorProfileRequest{
+		FirstName:   current.User.FirstName,
+		LastName:    current.User.LastName,
+		Phone:       current.User.Phone,
+		IsAvailable: current.Doctor.IsAvailable,
+	}
+	if err := utils.DecodeMergePatch(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	profile, err := h.doctorService.UpdateMyProfile(r.Context(), &models.DoctorProfile{
+		Doctor: models.Doctor{IsAvailable: req.IsAvailable},
+		User:   models.User{FirstName: req.FirstName, LastName: req.LastName, Phone: req.Phone},
+	})
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, doctorProfileToResponse(profile))
+}
+
+func doctorProfileToResponse(profile *models.DoctorProfile) dto.DoctorProfileResponse {
+	return dto.DoctorProfileResponse{
+		Doctor: *doctorToResponse(&profile.Doctor),
+		User:   *userToResponse(&profile.User),
+	}
+}
+
+func doctorToResponse(doctor *models.Doctor) *dto.DoctorResponse {
+	return &dto.DoctorResponse{
+		DoctorID:        doctor.DoctorID.String(),
+		UserID:          doctor.UserID.String(),
+		Specialization:  doctor.Specialization,
+		LicenseNumber:   doctor.LicenseNumber,
+		DepartmentID:    doctor.DepartmentID.String(),
+		ConsultationFee: doctor.ConsultationFee,
+		IsAvailable:     doctor.IsAvailable,
+		CreatedAt:       doctor.CreatedAt,
+		UpdatedAt:       doctor.UpdatedAt,
+	}
 }
@@ -1,16 +1,149 @@
 package handlers
 
-import "net/http"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
-// HealthCheck godoc
-// @Summary Show the status of server.
-// @Description get the status of server.
+	"github.com/falasefemi2/hms/internal/database"
+	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+const (
+	healthStatusOK          = "ok"
+	healthStatusUnavailable = "unavailable"
+	healthStatusDraining    = "draining"
+
+	healthCheckTimeout = 2 * time.Second
+)
+
+// healthCheck is a single readiness dependency check.
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	checks   []healthCheck
+	draining atomic.Bool
+}
+
+// NewHealthHandler returns a handler whose readiness depends on the database
+// answering pings, the schema being at the latest embedded migration, and
+// fewer than maxPoolUtilization of the pool's connections being in use.
+func NewHealthHandler(db *database.DB, maxPoolUtilization float64) *HealthHandler {
+	return &HealthHandler{
+		checks: []healthCheck{
+			{
+				name: "database",
+				check: func(ctx context.Context) error {
+					if err := db.HealthCheck(ctx); err != nil {
+						return errors.New("database ping failed")
+					}
+					return nil
+				},
+			},
+			{
+				name: "migrations",
+				check: func(ctx context.Context) error {
+					current, latest, err := db.SchemaVersion(ctx)
+					if err != nil {
+						return errors.New("could not read schema version")
+					}
+					if current < latest {
+						return fmt.Errorf("schema at version %d, expected %d", current, latest)
+					}
+					return nil
+				},
+			},
+			{
+				name: "connection_pool",
+				check: func(ctx context.Context) error {
+					if u := db.PoolUtilization(); u >= maxPoolUtilization {
+						return fmt.Errorf("pool %.0f%% utilized", u*100)
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// SetDraining makes readiness fail so load balancers stop routing new
+// requests while in-flight ones finish.
+func (h *HealthHandler) SetDraining() {
+	h.draining.Store(true)
+}
+
+// Livez godoc
+// @Summary Liveness probe
+// @Description Reports that the process is running. It does not check dependencies.
 // @Tags root
-// @Accept */*
 // @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Router /health [get]
-func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status": "ok"}`))
+// @Success 200 {object} dto.HealthResponse
+// @Router /livez [get]
+func (h *HealthHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, &dto.HealthResponse{Status: healthStatusOK})
+}
+
+// Readyz godoc
+// @Summary Readiness probe
+// @Description Checks the database connection, schema version and connection pool saturation, and reports each component's status and latency. Returns 503 while any check fails or the server is draining.
+// @Tags root
+// @Produce json
+// @Success 200 {object} dto.HealthResponse
+// @Failure 503 {object} dto.HealthResponse
+// @Router /readyz [get]
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if h.draining.Load() {
+		utils.WriteJSON(w, http.StatusServiceUnavailable, &dto.HealthResponse{Status: healthStatusDraining})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+
+	components := make([]dto.ComponentHealth, len(h.checks))
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			components[i] = runHealthCheck(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	response := &dto.HealthResponse{Status: healthStatusOK, Components: components}
+	status := http.StatusOK
+	for _, c := range components {
+		if c.Status != healthStatusOK {
+			response.Status = healthStatusUnavailable
+			status = http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	utils.WriteJSON(w, status, response)
+}
+
+func runHealthCheck(ctx context.Context, c healthCheck) dto.ComponentHealth {
+	start := time.Now()
+	err := c.check(ctx)
+
+	component := dto.ComponentHealth{
+		Name:      c.name,
+		Status:    healthStatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		component.Status = healthStatusUnavailable
+		component.Error = err.Error()
+	}
+	return component
 }
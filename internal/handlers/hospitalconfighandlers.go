@@ -1,15 +1,18 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
 
 	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
 	"github.com/falasefemi2/hms/internal/service"
 	"github.com/falasefemi2/hms/internal/utils"
 )
@@ -24,230 +27,227 @@ func NewHospitalConfigHandler(hospitalConfigService *service.HospitalConfigServi
 	}
 }
 
-// CreateHospitalConfig godoc
-// @Summary Create a new hospital configuration
-// @Description Create a new hospital configuration. Requires valid JWT token with ADMIN role
+// GetHospitalConfig godoc
+// @Summary Get the hospital configuration
+// @Description Get the revision of the hospital configuration in force. Self-registration, booking within working hours and the other policies follow it. Requires valid JWT token with ADMIN role
+// @Tags Hospital Configuration
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.HospitalConfigResponse "Hospital configuration in force"
+// @Header 200 {string} ETag "Revision in force; a change already scheduled must be read from the history"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 404 {object} dto.ErrorResponse "No revision in force yet; defaults apply"
+// @Router /admin/hospital-config [get]
+func (h *HospitalConfigHandler) GetHospitalConfig(w http.ResponseWriter, r *http.Request) {
+	config, err := h.hospitalConfigService.GetHospitalConfig(r.Context())
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.SetETag(w, config.Revision)
+	utils.WriteJSON(w, http.StatusOK, hospitalConfigToResponse(config))
+}
+
+// UpdateHospitalConfig godoc
+// @Summary Change the hospital configuration
+// @Description Add a new revision of the hospital configuration, in force from effective_from (default now). It may not be in the past or before an already scheduled revision. If-Match must name the newest revision; it may be left out only for the first. Requires valid JWT token with ADMIN role
 // @Tags Hospital Configuration
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body dto.CreateHospitalConfigRequest true "Hospital configuration details"
-// @Success 201 {object} dto.HospitalConfigResponse "Hospital configuration created successfully"
+// @Param request body dto.UpdateHospitalConfigRequest true "Hospital configuration"
+// @Param If-Match header string true "ETag of the newest revision"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
+// @Success 201 {object} dto.HospitalConfigResponse "Revision added"
+// @Header 201 {string} ETag "The new revision, to send back as If-Match"
 // @Failure 400 {object} dto.ErrorResponse "Validation error - invalid input"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
 // @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
-// @Router /admin/hospital-configs [post]
-func (h *HospitalConfigHandler) CreateHospitalConfig(w http.ResponseWriter, r *http.Request) {
-	var req dto.CreateHospitalConfigRequest
+// @Failure 412 {object} dto.ErrorResponse "Another revision was added since - fetch the history and retry"
+// @Failure 428 {object} dto.ErrorResponse "If-Match header missing"
+// @Router /admin/hospital-config [put]
+func (h *HospitalConfigHandler) UpdateHospitalConfig(w http.ResponseWriter, r *http.Request) {
+	expectedRevision, err := ifMatchRevision(r)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+	var req dto.UpdateHospitalConfigRequest
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
 	config := &models.HospitalConfig{
-		ConfigID:                      uuid.New(),
 		WorkingHoursStart:             strings.TrimSpace(req.WorkingHoursStart),
 		WorkingHoursEnd:               strings.TrimSpace(req.WorkingHoursEnd),
 		AppointmentDurationMinutes:    req.AppointmentDurationMinutes,
 		MaxSameDayCancellationHours:   req.MaxSameDayCancellationHours,
 		EnablePatientSelfRegistration: true, // default
+		EffectiveFrom:                 timeOrZero(req.EffectiveFrom),
 	}
 
 	if req.EnablePatientSelfRegistration != nil {
 		config.EnablePatientSelfRegistration = *req.EnablePatientSelfRegistration
 	}
 
-	createdConfig, err := h.hospitalConfigService.CreateHospitalConfig(r.Context(), config)
+	created, err := h.hospitalConfigService.UpdateHospitalConfig(r.Context(), config, expectedRevision)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	response := &dto.HospitalConfigResponse{
-		ConfigID:                      createdConfig.ConfigID.String(),
-		WorkingHoursStart:             createdConfig.WorkingHoursStart,
-		WorkingHoursEnd:               createdConfig.WorkingHoursEnd,
-		AppointmentDurationMinutes:    createdConfig.AppointmentDurationMinutes,
-		MaxSameDayCancellationHours:   createdConfig.MaxSameDayCancellationHours,
-		EnablePatientSelfRegistration: createdConfig.EnablePatientSelfRegistration,
-		CreatedAt:                     createdConfig.CreatedAt,
-		UpdatedAt:                     createdConfig.UpdatedAt,
-	}
-
-	utils.WriteJSON(w, http.StatusCreated, response)
+	utils.SetETag(w, created.Revision)
+	utils.WriteJSON(w, http.StatusCreated, hospitalConfigToResponse(created))
 }
 
-// GetHospitalConfig godoc
-// @Summary Get a hospital configuration by ID
-// @Description Get a specific hospital configuration. Requires valid JWT token with ADMIN role
+// ListHospitalConfigHistory godoc
+// @Summary List hospital configuration history
+// @Description Retrieve a page of hospital configuration revisions, newest first by default, including any scheduled to take effect later. is_active marks the one in force. Walk the pages by passing next_cursor back as cursor. Requires valid JWT token with ADMIN role
 // @Tags Hospital Configuration
 // @Produce json
 // @Security BearerAuth
-// @Param id path string true "Hospital configuration ID"
-// @Success 200 {object} dto.HospitalConfigResponse "Hospital configuration retrieved successfully"
-// @Failure 400 {object} dto.ErrorResponse "Invalid ID format"
+// @Param limit query int false "Page size (default: 20, max: 100)" default(20)
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param sort query string false "revision; prefix with - for descending" default(-revision)
+// @Param effective_from query string false "Revisions taking effect at or after (date or RFC 3339)"
+// @Param effective_to query string false "Revisions taking effect at or before (date or RFC 3339)"
+// @Success 200 {object} dto.ListResponse[dto.HospitalConfigResponse] "Page of revisions"
+// @Header 200 {string} ETag "Newest revision, to send back as If-Match"
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter, sort, limit or cursor"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
 // @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
-// @Failure 404 {object} dto.ErrorResponse "Hospital configuration not found"
-// @Router /admin/hospital-configs/{id} [get]
-func (h *HospitalConfigHandler) GetHospitalConfig(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-
-	configID, err := uuid.Parse(idStr)
+// @Router /admin/hospital-config/history [get]
+func (h *HospitalConfigHandler) ListHospitalConfigHistory(w http.ResponseWriter, r *http.Request) {
+	q, err := listquery.Parse(r.URL.Query(), repository.HospitalConfigHistory)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid config id")
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	config, err := h.hospitalConfigService.GetHospitalConfigByID(r.Context(), configID)
+	page, err := h.hospitalConfigService.ListHospitalConfigHistory(r.Context(), q)
 	if err != nil {
-		if strings.Contains(err.Error(), "no rows") {
-			utils.WriteError(w, http.StatusNotFound, "hospital configuration not found")
-			return
-		}
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		utils.HandleServiceError(w, r, err)
 		return
 	}
-
-	response := &dto.HospitalConfigResponse{
-		ConfigID:                      config.ConfigID.String(),
-		WorkingHoursStart:             config.WorkingHoursStart,
-		WorkingHoursEnd:               config.WorkingHoursEnd,
-		AppointmentDurationMinutes:    config.AppointmentDurationMinutes,
-		MaxSameDayCancellationHours:   config.MaxSameDayCancellationHours,
-		EnablePatientSelfRegistration: config.EnablePatientSelfRegistration,
-		CreatedAt:                     config.CreatedAt,
-		UpdatedAt:                     config.UpdatedAt,
+	latest, err := h.hospitalConfigService.LatestRevision(r.Context())
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, response)
+	if latest > 0 {
+		utils.SetETag(w, latest)
+	}
+	utils.WriteJSON(w, http.StatusOK, dto.NewListResponse(page, func(config *models.HospitalConfig) dto.HospitalConfigResponse {
+		return *hospitalConfigToResponse(config)
+	}))
 }
 
-// GetAllHospitalConfigs godoc
-// @Summary Get all hospital configurations
-// @Description Get all hospital configurations. Requires valid JWT token with ADMIN role
+// GetHospitalConfigRevision godoc
+// @Summary Get a hospital configuration revision
+// @Description Get one revision from the hospital configuration history. Requires valid JWT token with ADMIN role
 // @Tags Hospital Configuration
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} dto.HospitalConfigResponse "Hospital configurations retrieved successfully"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} dto.HospitalConfigResponse "Revision"
+// @Failure 400 {object} dto.ErrorResponse "Invalid revision"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
 // @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
-// @Router /admin/hospital-configs [get]
-func (h *HospitalConfigHandler) GetAllHospitalConfigs(w http.ResponseWriter, r *http.Request) {
-	configs, err := h.hospitalConfigService.GetAllHospitalConfigs(r.Context())
-	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+// @Failure 404 {object} dto.ErrorResponse "Revision not found"
+// @Router /admin/hospital-config/history/{revision} [get]
+func (h *HospitalConfigHandler) GetHospitalConfigRevision(w http.ResponseWriter, r *http.Request) {
+	revision, err := strconv.Atoi(chi.URLParam(r, "revision"))
+	if err != nil || revision < 1 {
+		utils.WriteError(w, http.StatusBadRequest, "invalid revision")
 		return
 	}
 
-	var responses []dto.HospitalConfigResponse
-	for _, config := range configs {
-		responses = append(responses, dto.HospitalConfigResponse{
-			ConfigID:                      config.ConfigID.String(),
-			WorkingHoursStart:             config.WorkingHoursStart,
-			WorkingHoursEnd:               config.WorkingHoursEnd,
-			AppointmentDurationMinutes:    config.AppointmentDurationMinutes,
-			MaxSameDayCancellationHours:   config.MaxSameDayCancellationHours,
-			EnablePatientSelfRegistration: config.EnablePatientSelfRegistration,
-			CreatedAt:                     config.CreatedAt,
-			UpdatedAt:                     config.UpdatedAt,
-		})
+	config, err := h.hospitalConfigService.GetHospitalConfigRevision(r.Context(), revision)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, responses)
+	utils.WriteJSON(w, http.StatusOK, hospitalConfigToResponse(config))
 }
 
-// UpdateHospitalConfig godoc
-// @Summary Update a hospital configuration
-// @Description Update an existing hospital configuration. Requires valid JWT token with ADMIN role
+// RollbackHospitalConfig godoc
+// @Summary Roll back the hospital configuration
+// @Description Restore the settings of an earlier revision by adding them as a new revision, in force from effective_from (default now). The history keeps both the change and its rollback. If-Match must name the newest revision. Requires valid JWT token with ADMIN role
 // @Tags Hospital Configuration
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param id path string true "Hospital configuration ID"
-// @Param request body dto.UpdateHospitalConfigRequest true "Updated hospital configuration details"
-// @Success 200 {object} dto.HospitalConfigResponse "Hospital configuration updated successfully"
-// @Failure 400 {object} dto.ErrorResponse "Validation error - invalid input or ID"
+// @Param request body dto.RollbackHospitalConfigRequest true "Revision to restore"
+// @Param If-Match header string true "ETag of the newest revision"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
+// @Success 201 {object} dto.HospitalConfigResponse "Revision added"
+// @Header 201 {string} ETag "The new revision, to send back as If-Match"
+// @Failure 400 {object} dto.ErrorResponse "Validation error or unknown revision"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
 // @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
-// @Failure 404 {object} dto.ErrorResponse "Hospital configuration not found"
-// @Router /admin/hospital-configs/{id} [put]
-func (h *HospitalConfigHandler) UpdateHospitalConfig(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-
-	configID, err := uuid.Parse(idStr)
+// @Failure 412 {object} dto.ErrorResponse "Another revision was added since - fetch the history and retry"
+// @Failure 428 {object} dto.ErrorResponse "If-Match header missing"
+// @Router /admin/hospital-config/rollback [post]
+func (h *HospitalConfigHandler) RollbackHospitalConfig(w http.ResponseWriter, r *http.Request) {
+	expectedRevision, err := utils.IfMatchVersion(r)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid config id")
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	var req dto.UpdateHospitalConfigRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+	var req dto.RollbackHospitalConfigRequest
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	config := &models.HospitalConfig{
-		ConfigID:                      configID,
-		WorkingHoursStart:             strings.TrimSpace(req.WorkingHoursStart),
-		WorkingHoursEnd:               strings.TrimSpace(req.WorkingHoursEnd),
-		AppointmentDurationMinutes:    req.AppointmentDurationMinutes,
-		MaxSameDayCancellationHours:   req.MaxSameDayCancellationHours,
-		EnablePatientSelfRegistration: true, // default
-	}
-
-	if req.EnablePatientSelfRegistration != nil {
-		config.EnablePatientSelfRegistration = *req.EnablePatientSelfRegistration
-	}
-
-	updatedConfig, err := h.hospitalConfigService.UpdateHospitalConfig(r.Context(), config)
+	created, err := h.hospitalConfigService.RollbackHospitalConfig(r.Context(), req.Revision, timeOrZero(req.EffectiveFrom), expectedRevision)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	response := &dto.HospitalConfigResponse{
-		ConfigID:                      updatedConfig.ConfigID.String(),
-		WorkingHoursStart:             updatedConfig.WorkingHoursStart,
-		WorkingHoursEnd:               updatedConfig.WorkingHoursEnd,
-		AppointmentDurationMinutes:    updatedConfig.AppointmentDurationMinutes,
-		MaxSameDayCancellationHours:   updatedConfig.MaxSameDayCancellationHours,
-		EnablePatientSelfRegistration: updatedConfig.EnablePatientSelfRegistration,
-		CreatedAt:                     updatedConfig.CreatedAt,
-		UpdatedAt:                     updatedConfig.UpdatedAt,
-	}
-
-	utils.WriteJSON(w, http.StatusOK, response)
+	utils.SetETag(w, created.Revision)
+	utils.WriteJSON(w, http.StatusCreated, hospitalConfigToResponse(created))
 }
 
-// DeleteHospitalConfig godoc
-// @Summary Delete a hospital configuration
-// @Description Delete an existing hospital configuration. Requires valid JWT token with ADMIN role
-// @Tags Hospital Configuration
-// @Produce json
-// @Security BearerAuth
-// @Param id path string true "Hospital configuration ID"
-// @Success 204 "Hospital configuration deleted successfully"
-// @Failure 400 {object} dto.ErrorResponse "Invalid ID format"
-// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
-// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
-// @Failure 404 {object} dto.ErrorResponse "Hospital configuration not found"
-// @Router /admin/hospital-configs/{id} [delete]
-func (h *HospitalConfigHandler) DeleteHospitalConfig(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-
-	configID, err := uuid.Parse(idStr)
-	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid config id")
-		return
+// ifMatchRevision reads the newest revision the caller knows of. Without an
+// If-Match header it is 0, which the service accepts only for the first
+// revision.
+func ifMatchRevision(r *http.Request) (int, error) {
+	revision, err := utils.IfMatchVersion(r)
+	if errors.Is(err, utils.ErrPreconditionRequired) {
+		return 0, nil
 	}
+	return revision, err
+}
 
-	err = h.hospitalConfigService.DeleteHospitalConfig(r.Context(), configID)
-	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
-		return
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
 	}
+	return *t
+}
 
-	w.WriteHeader(http.StatusNoContent)
+func hospitalConfigToResponse(config *models.HospitalConfig) *dto.HospitalConfigResponse {
+	return &dto.HospitalConfigResponse{
+		ConfigID:                      config.ConfigID.String(),
+		Revision:                      config.Revision,
+		WorkingHoursStart:             config.WorkingHoursStart,
+		WorkingHoursEnd:               config.WorkingHoursEnd,
+		AppointmentDurationMinutes:    config.AppointmentDurationMinutes,
+		MaxSameDayCancellationHours:   config.MaxSameDayCancellationHours,
+		EnablePatientSelfRegistration: config.EnablePatientSelfRegistration,
+		EffectiveFrom:                 config.EffectiveFrom,
+		IsActive:                      config.IsActive,
+		RolledBackFrom:                config.RolledBackFrom,
+		CreatedBy:                     config.CreatedBy,
+		CreatedAt:                     config.CreatedAt,
+	}
 }
@@ -1,14 +1,17 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
 	"net/http"
 	"strings"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
 	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
 	"github.com/falasefemi2/hms/internal/service"
 	"github.com/falasefemi2/hms/internal/utils"
 )
@@ -31,16 +34,18 @@ func NewNurseHandler(nurseService *service.NurseSerivce) *NurseHandler {
 // @Produce json
 // @Security BearerAuth
 // @Param request body dto.NurseSignupRequest true "Nurse creation details"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
 // @Success 201 {object} dto.NurseResponse "Nurse created successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error - invalid input or invalid role"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
 // @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
 // @Failure 409 {object} dto.ErrorResponse "Conflict - nurse already exists for this user"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
 // @Router /admin/nurses [post]
 func (n *NurseHandler) CreateNurse(w http.ResponseWriter, r *http.Request) {
 	var req dto.NurseSignupRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -66,23 +71,276 @@ func (n *NurseHandler) CreateNurse(w http.ResponseWriter, r *http.Request) {
 
 	createdNurse, err := n.nurseService.CreateNurse(r.Context(), nurse)
 	if err != nil {
-		errorMsg := err.Error()
-		if strings.Contains(errorMsg, "nurse already exists") {
-			utils.WriteError(w, http.StatusConflict, errorMsg)
-			return
-		}
-		utils.WriteError(w, http.StatusBadRequest, errorMsg)
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	response := &dto.NurseResponse{
-		NurseID:       createdNurse.NurseID.String(),
-		UserID:        createdNurse.NurseID.String(),
-		DepartmentID:  createdNurse.DepartmentID.String(),
-		Shift:         createdNurse.Shift,
-		LicenseNumber: createdNurse.LicenseNumber,
-		CreatedAt:     createdNurse.CreatedAt,
-		UpdatedAt:     createdNurse.UpdatedAt,
-	}
+	response := nurseToResponse(createdNurse)
 	utils.WriteJSON(w, http.StatusCreated, response)
 }
+
+// ListNurses godoc
+// @Summary List nurses
+// @Description Retrieve a page of nurses. Walk the pages by passing next_cursor back as cursor. Requires valid JWT token with ADMIN role
+// @Tags Nurse Management
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default: 20, max: 100)" default(20)
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param sort query string false "created_at; prefix with - for descending" default(-created_at)
+// @Param department_id query string false "Filter by department"
+// @Param shift query string false "Filter by shift"
+// @Success 200 {object} dto.ListResponse[dto.NurseResponse] "Page of nurses"
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter, sort, limit or cursor"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Router /admin/nurses [get]
+func (n *NurseHandler) ListNurses(w http.ResponseWriter, r *http.Request) {
+	q, err := listquery.Parse(r.URL.Query(), repository.NurseList)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	page, err := n.nurseService.ListNurses(r.Context(), q)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.NewListResponse(page, func(nurse *models.Nurse) dto.NurseResponse {
+		return *nurseToResponse(nurse)
+	}))
+}
+
+// PatchNurse godoc
+// @Summary Partially update a nurse
+// @Description Apply a JSON Merge Patch (RFC 7396) to a nurse. Only the fields sent are changed. Requires valid JWT token with ADMIN role
+// @Tags Nurse Management
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Nurse ID"
+// @Param request body dto.UpdateNurseRequest true "Fields to change"
+// @Success 200 {object} dto.NurseResponse "Nurse updated successfully"
+// @Failure 400 {object} dto.ErrorResponse "Validation error"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 404 {object} dto.ErrorResponse "Nurse not found"
+// @Failure 409 {object} dto.ErrorResponse "License number already registered"
+// @Failure 415 {object} dto.ErrorResponse "Body is not a JSON Merge Patch"
+// @Router /admin/nurses/{id} [patch]
+func (n *NurseHandler) PatchNurse(w http.ResponseWriter, r *http.Request) {
+	nurseID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid nurse id")
+		return
+	}
+
+	current, err := n.nurseService.GetNurseByID(r.Context(), nurseID)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	req := dto.UpdateNurseRequest{
+		Shift:         current.Shift,
+		LicenseNumber: current.LicenseNumber,
+		DepartmentID:  current.DepartmentID.String(),
+	}
+	if err := utils.DecodeMergePatch(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	departmentID, err := uuid.Parse(req.DepartmentID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid department id")
+		return
+	}
+
+	nurse := &models.Nurse{
+		NurseID:       nurseID,
+		DepartmentID:  departmentID,
+		Shift:         strings.TrimSpace(req.Shift),
+		LicenseNumber: strings.TrimSpace(req.LicenseNumber),
+	}
+
+	updatedNurse, err := n.nurseService.UpdateNurse(r.Context(), nurse)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	response := nurseToResponse(updatedNurse)
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetNurse godoc
+// @Summary Get a nurse
+// @Description Retrieve a nurse by ID. Requires valid JWT token with ADMIN role
+// @Tags Nurse Management
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Nurse ID"
+// @Success 200 {object} dto.NurseResponse "Nurse"
+// @Failure 400 {object} dto.ErrorResponse "Invalid nurse ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 404 {object} dto.ErrorResponse "Nurse not found"
+// @Router /admin/nurses/{id} [get]
+func (n *NurseHandler) GetNurse(w http.ResponseWriter, r *http.Request) {
+	nurseID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid nurse id")
+		return
+	}
+
+	nurse, err := n.nurseService.GetNurseByID(r.Context(), nurseID)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nurseToResponse(nurse))
+}
+
+// DeactivateNurse godoc
+// @Summary Deactivate a nurse
+// @Description Disable the nurse's account. The nurse record and the care they recorded are kept. Requires valid JWT token with ADMIN role
+// @Tags Nurse Management
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Nurse ID"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
+// @Success 200 {object} dto.NurseResponse "Nurse deactivated"
+// @Failure 400 {object} dto.ErrorResponse "Invalid nurse ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 404 {object} dto.ErrorResponse "Nurse not found"
+// @Failure 409 {object} dto.ErrorResponse "Nurse is already deactivated"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
+// @Router /admin/nurses/{id}/deactivate [post]
+func (n *NurseHandler) DeactivateNurse(w http.ResponseWriter, r *http.Request) {
+	n.setActive(w, r, n.nurseService.DeactivateNurse)
+}
+
+// ActivateNurse godoc
+// @Summary Reactivate a nurse
+// @Description Re-enable a deactivated nurse's account. Requires valid JWT token with ADMIN role
+// @Tags Nurse Management
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Nurse ID"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
+// @Success 200 {object} dto.NurseResponse "Nurse reactivated"
+// @Failure 400 {object} dto.ErrorResponse "Invalid nurse ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 404 {object} dto.ErrorResponse "Nurse not found"
+// @Failure 409 {object} dto.ErrorResponse "Nurse is already active"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
+// @Router /admin/nurses/{id}/activate [post]
+func (n *NurseHandler) ActivateNurse(w http.ResponseWriter, r *http.Request) {
+	n.setActive(w, r, n.nurseService.ActivateNurse)
+}
+
+func (n *NurseHandler) setActive(w http.ResponseWriter, r *http.Request, apply func(context.Context, uuid.UUID) (*models.Nurse, error)) {
+	nurseID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid nurse id")
+		return
+	}
+
+	nurse, err := apply(r.Context(), nurseID)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nurseToResponse(nurse))
+}
+
+// GetMyProfile godoc
+// @Summary Get my nurse profile
+// @Description Retrieve the calling nurse's profile and account details. Requires valid JWT token with NURSE role
+// @Tags Nurse Management
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.NurseProfileResponse "Profile"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - nurse role required"
+// @Failure 404 {object} dto.ErrorResponse "No nurse profile for this user"
+// @Router /nurses/me [get]
+func (n *NurseHandler) GetMyProfile(w http.ResponseWriter, r *http.Request) {
+	profile, err := n.nurseService.GetMyProfile(r.Context())
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nurseProfileToResponse(profile))
+}
+
+// PatchMyProfile godoc
+// @Summary Update my nurse profile
+// @Description Apply a JSON Merge Patch (RFC 7396) to the calling nurse's own name and phone. Shift, department and license are managed by admins. Requires valid JWT token with NURSE role
+// @Tags Nurse Management
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateNurseProfileRequest true "Fields to change"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
+// @Success 200 {object} dto.NurseProfileResponse "Profile updated"
+// @Failure 400 {object} dto.ErrorResponse "Validation error or a field nurses cannot change"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - nurse role required"
+// @Failure 404 {object} dto.ErrorResponse "No nurse profile for this user"
+// @Failure 415 {object} dto.ErrorResponse "Body is not a JSON Merge Patch"
+// @Router /nurses/me [patch]
+func (n *NurseHandler) PatchMyProfile(w http.ResponseWriter, r *http.Request) {
+	current, err := n.nurseService.GetMyProfile(r.Context())
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	req := dto.UpdateNurseProfileRequest{
+		FirstName: current.User.FirstName,
+		LastName:  current.User.LastName,
+		Phone:     current.User.Phone,
+	}
+	if err := utils.DecodeMergePatch(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	profile, err := n.nurseService.UpdateMyProfile(r.Context(), &models.NurseProfile{
+		User: models.User{FirstName: req.FirstName, LastName: req.LastName, Phone: req.Phone},
+	})
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, nurseProfileToResponse(profile))
+}
+
+func nurseProfileToResponse(profile *models.NurseProfile) dto.NurseProfileResponse {
+	return dto.NurseProfileResponse{
+		Nurse: *nurseToResponse(&profile.Nurse),
+		User:  *userToResponse(&profile.User),
+	}
+}
+
+func nurseToResponse(nurse *models.Nurse) *dto.NurseResponse {
+	return &dto.NurseResponse{
+		NurseID:       nurse.NurseID.String(),
+		UserID:        nurse.UserID.String(),
+		DepartmentID:  nurse.DepartmentID.String(),
+		Shift:         nurse.Shift,
+		LicenseNumber: nurse.LicenseNumber,
+		CreatedAt:     nurse.CreatedAt,
+		UpdatedAt:     nurse.UpdatedAt,
+	}
+}
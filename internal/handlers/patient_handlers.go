@@ -1,15 +1,18 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
 	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
 	"github.com/falasefemi2/hms/internal/service"
 	"github.com/falasefemi2/hms/internal/utils"
 )
@@ -26,29 +29,34 @@ func NewPatientHandlers(patientService *service.PatientService) *PatientHandlers
 
 // PatientProfile creates a patient profile.
 // @Summary Create patient profile
-// @Description Create patient profile. Requires valid JWT token with PATIENT role
+// @Description Create patient profile. A patient creates their own profile and user_id is taken from their token; staff must give user_id. Requires valid JWT token
 // @Tags Patient Management
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body dto.PatientSignUp true "Patient Profile details"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
 // @Success 201 {object} dto.PatientResponse "Patient Profile created successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error - invalid input or invalid role"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
-// @Failure 403 {object} dto.ErrorResponse "Forbidden - patient role required"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - a patient naming another user"
 // @Failure 409 {object} dto.ErrorResponse "Conflict - patient already exists for this user"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
 // @Router /patients/patientprofile [post]
 func (p *PatientHandlers) PatientProfile(w http.ResponseWriter, r *http.Request) {
 	var req dto.PatientSignUp
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	userID, err := uuid.Parse(req.UserID)
-	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid user ID")
-		return
+	var userID uuid.UUID
+	if req.UserID != "" {
+		var err error
+		if userID, err = uuid.Parse(req.UserID); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "invalid user ID")
+			return
+		}
 	}
 
 	// Clean the date string by removing ordinal suffixes
@@ -77,26 +85,194 @@ func (p *PatientHandlers) PatientProfile(w http.ResponseWriter, r *http.Request)
 
 	patientProfile, err := p.patientService.PatientProfile(r.Context(), patient)
 	if err != nil {
-		errorMsg := err.Error()
-		if strings.Contains(errorMsg, "patient profile already exists") {
-			utils.WriteError(w, http.StatusConflict, errorMsg)
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	response := patientToResponse(patientProfile)
+	utils.WriteJSON(w, http.StatusCreated, response)
+}
+
+// ListPatients godoc
+// @Summary List patients
+// @Description Retrieve a page of patient profiles. Walk the pages by passing next_cursor back as cursor. Requires ADMIN, DOCTOR or NURSE role
+// @Tags Patient Management
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default: 20, max: 100)" default(20)
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param sort query string false "created_at; prefix with - for descending" default(-created_at)
+// @Param gender query string false "Filter by gender"
+// @Param blood_group query string false "Filter by blood group"
+// @Param born_from query string false "Born on or after (date)"
+// @Param born_to query string false "Born on or before (date)"
+// @Param mrn query string false "Filter by medical record number"
+// @Success 200 {object} dto.ListResponse[dto.PatientResponse] "Page of patients"
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter, sort, limit or cursor"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - staff role required"
+// @Router /patients [get]
+func (p *PatientHandlers) ListPatients(w http.ResponseWriter, r *http.Request) {
+	q, err := listquery.Parse(r.URL.Query(), repository.PatientList)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	page, err := p.patientService.ListPatients(r.Context(), q)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.NewListResponse(page, func(patient *models.Patient) dto.PatientResponse {
+		return *patientToResponse(patient)
+	}))
+}
+
+// SearchPatients godoc
+// @Summary Search patients
+// @Description Find patients by fuzzy name, phone number and exact date of birth, best match first. All criteria given must match; at least one is required. Requires ADMIN, DOCTOR or NURSE role
+// @Tags Patient Management
+// @Produce json
+// @Security BearerAuth
+// @Param name query string false "First and/or last name; misspellings are tolerated"
+// @Param phone query string false "Phone number in any format; matched on its last digits"
+// @Param dob query string false "Exact date of birth (2006-01-02)"
+// @Param limit query int false "Maximum results (default: 20, max: 50)" default(20)
+// @Success 200 {object} dto.ListResponse[dto.PatientMatchResponse] "Matching patients"
+// @Failure 400 {object} dto.ErrorResponse "No criteria or invalid criteria"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - staff role required"
+// @Router /patients/search [get]
+func (p *PatientHandlers) SearchPatients(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	search := repository.PatientSearch{
+		Name:  query.Get("name"),
+		Phone: query.Get("phone"),
+	}
+
+	if raw := query.Get("dob"); raw != "" {
+		dob, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "dob must be a date (2006-01-02)")
 			return
 		}
-		utils.WriteError(w, http.StatusBadRequest, errorMsg)
+		search.DateOfBirth = &dob
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "limit must be an integer")
+			return
+		}
+		search.Limit = limit
+	}
+
+	page, err := p.patientService.SearchPatients(r.Context(), search)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.NewListResponse(page, patientMatchToResponse))
+}
+
+// PatchPatient updates part of a patient profile.
+// @Summary Partially update a patient profile
+// @Description Apply a JSON Merge Patch (RFC 7396) to a patient profile. Only the fields sent are changed. Requires valid JWT token with ADMIN, DOCTOR or NURSE role
+// @Tags Patient Management
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Patient ID"
+// @Param request body dto.UpdatePatientRequest true "Fields to change"
+// @Success 200 {object} dto.PatientResponse "Patient profile updated successfully"
+// @Failure 400 {object} dto.ErrorResponse "Validation error"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - staff role required"
+// @Failure 404 {object} dto.ErrorResponse "Patient not found"
+// @Failure 415 {object} dto.ErrorResponse "Body is not a JSON Merge Patch"
+// @Router /patients/{id} [patch]
+func (p *PatientHandlers) PatchPatient(w http.ResponseWriter, r *http.Request) {
+	patientID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid patient id")
 		return
 	}
 
-	response := &dto.PatientResponse{
-		PatientID:             patientProfile.PatientID,
-		UserID:                patientProfile.UserID,
-		DateOfBirth:           patientProfile.DateOfBirth,
-		Gender:                patientProfile.Gender,
-		BloodGroup:            patientProfile.BloodGroup,
-		EmergencyContactName:  patientProfile.EmergencyContactName,
-		EmergencyContactPhone: patientProfile.EmergencyContactPhone,
-		MedicalHistory:        patientProfile.MedicalHistory,
-		CreatedAt:             patientProfile.CreatedAt,
-		UpdatedAt:             patientProfile.UpdatedAt,
+	current, err := p.patientService.GetPatientByID(r.Context(), patientID)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	req := dto.UpdatePatientRequest{
+		DateOfBirth:           current.DateOfBirth.Format(time.DateOnly),
+		Gender:                current.Gender,
+		BloodGroup:            current.BloodGroup,
+		EmergencyContactName:  current.EmergencyContactName,
+		EmergencyContactPhone: current.EmergencyContactPhone,
+		MedicalHistory:        current.MedicalHistory,
+	}
+	if err := utils.DecodeMergePatch(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	dob, err := time.Parse(time.DateOnly, req.DateOfBirth)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid date of birth format")
+		return
+	}
+
+	patient := &models.Patient{
+		PatientID:             patientID,
+		DateOfBirth:           dob,
+		Gender:                req.Gender,
+		BloodGroup:            req.BloodGroup,
+		EmergencyContactName:  req.EmergencyContactName,
+		EmergencyContactPhone: req.EmergencyContactPhone,
+		MedicalHistory:        req.MedicalHistory,
+	}
+
+	updated, err := p.patientService.UpdatePatient(r.Context(), patient)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	response := patientToResponse(updated)
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+func patientToResponse(patient *models.Patient) *dto.PatientResponse {
+	return &dto.PatientResponse{
+		PatientID:             patient.PatientID,
+		UserID:                patient.UserID,
+		MRN:                   patient.MRN,
+		DateOfBirth:           patient.DateOfBirth,
+		Gender:                patient.Gender,
+		BloodGroup:            patient.BloodGroup,
+		EmergencyContactName:  patient.EmergencyContactName,
+		EmergencyContactPhone: patient.EmergencyContactPhone,
+		MedicalHistory:        patient.MedicalHistory,
+		CreatedAt:             patient.CreatedAt,
+		UpdatedAt:             patient.UpdatedAt,
+	}
+}
+
+func patientMatchToResponse(match *models.PatientMatch) dto.PatientMatchResponse {
+	return dto.PatientMatchResponse{
+		PatientID:   match.Patient.PatientID,
+		UserID:      match.Patient.UserID,
+		MRN:         match.Patient.MRN,
+		FirstName:   match.FirstName,
+		LastName:    match.LastName,
+		Email:       match.Email,
+		Phone:       match.Phone,
+		DateOfBirth: match.Patient.DateOfBirth.Format(time.DateOnly),
+		Gender:      match.Patient.Gender,
+		Score:       match.Score,
 	}
-	utils.WriteJSON(w, http.StatusCreated, response)
 }
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+type PatientMergeHandlers struct {
+	mergeService *service.PatientMergeService
+}
+
+func NewPatientMergeHandlers(mergeService *service.PatientMergeService) *PatientMergeHandlers {
+	return &PatientMergeHandlers{
+		mergeService: mergeService,
+	}
+}
+
+// FindDuplicates godoc
+// @Summary Find possible duplicates of a patient
+// @Description List patients that agree with this one on at least two of name (fuzzy), date of birth and phone, strongest candidates first. Requires ADMIN, DOCTOR or NURSE role
+// @Tags Patient Management
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Patient ID"
+// @Param limit query int false "Maximum results (default: 20, max: 50)" default(20)
+// @Success 200 {object} dto.ListResponse[dto.DuplicateCandidateResponse] "Possible duplicates"
+// @Failure 400 {object} dto.ErrorResponse "Invalid patient ID or limit"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - staff role required"
+// @Failure 404 {object} dto.ErrorResponse "Patient not found"
+// @Router /patients/{id}/duplicates [get]
+func (h *PatientMergeHandlers) FindDuplicates(w http.ResponseWriter, r *http.Request) {
+	patientID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid patient id")
+		return
+	}
+
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "limit must be an integer")
+			return
+		}
+	}
+
+	page, err := h.mergeService.FindDuplicates(r.Context(), patientID, limit)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.NewListResponse(page, duplicateCandidateToResponse))
+}
+
+func duplicateCandidateToResponse(candidate *models.DuplicateCandidate) dto.DuplicateCandidateResponse {
+	return dto.DuplicateCandidateResponse{
+		PatientMatchResponse: patientMatchToResponse(&candidate.PatientMatch),
+		MatchedOn:            candidate.MatchedOn,
+	}
+}
+
+// MergePatients godoc
+// @Summary Merge a duplicate patient into this one
+// @Description Move the duplicate's appointments, consultations, prescriptions, vitals, lab tests and care notes to this patient, fill this patient's blank details from the duplicate, then delete the duplicate and deactivate its user account. The merge is recorded in the audit log with a snapshot of the duplicate. Requires ADMIN role
+// @Tags Patient Management
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID of the patient to keep"
+// @Param request body dto.MergePatientRequest true "Patient to merge away"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
+// @Success 200 {object} dto.PatientMergeResponse "Patients merged"
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID, same patient twice, or duplicate not found"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 404 {object} dto.ErrorResponse "Patient not found"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
+// @Router /admin/patients/{id}/merge [post]
+func (h *PatientMergeHandlers) MergePatients(w http.ResponseWriter, r *http.Request) {
+	survivorID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid patient id")
+		return
+	}
+
+	var req dto.MergePatientRequest
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+	duplicateID, err := uuid.Parse(req.DuplicatePatientID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid duplicate patient id")
+		return
+	}
+
+	merge, err := h.mergeService.MergePatients(r.Context(), survivorID, duplicateID)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.PatientMergeResponse{
+		Patient:         *patientToResponse(&merge.Survivor),
+		MergedPatientID: merge.MergedPatientID,
+		MergedMRN:       merge.MergedMRN,
+		RecordsMoved:    merge.RecordsMoved,
+		FilledFields:    merge.FilledFields,
+	})
+}
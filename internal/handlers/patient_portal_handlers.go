@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+type PatientPortalHandlers struct {
+	portalService *service.PatientPortalService
+}
+
+func NewPatientPortalHandlers(portalService *service.PatientPortalService) *PatientPortalHandlers {
+	return &PatientPortalHandlers{
+		portalService: portalService,
+	}
+}
+
+// GetMyProfile godoc
+// @Summary Get my patient profile
+// @Description Retrieve the calling patient's profile and account details. Requires valid JWT token with PATIENT role
+// @Tags Patient Portal
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.PatientProfileResponse "Profile"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - patient role required"
+// @Failure 404 {object} dto.ErrorResponse "No patient profile for this user"
+// @Router /patients/me [get]
+func (h *PatientPortalHandlers) GetMyProfile(w http.ResponseWriter, r *http.Request) {
+	profile, err := h.portalService.GetProfile(r.Context())
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, patientProfileToResponse(profile))
+}
+
+// PatchMyProfile godoc
+// @Summary Update my patient profile
+// @Description Apply a JSON Merge Patch (RFC 7396) to the calling patient's name, phone and emergency contact. Date of birth, blood group and medical history are kept by staff. Requires valid JWT token with PATIENT role
+// @Tags Patient Portal
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdatePatientProfileRequest true "Fields to change"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
+// @Success 200 {object} dto.PatientProfileResponse "Profile updated"
+// @Failure 400 {object} dto.ErrorResponse "Validation error or a field patients cannot change"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - patient role required"
+// @Failure 404 {object} dto.ErrorResponse "No patient profile for this user"
+// @Failure 415 {object} dto.ErrorResponse "Body is not a JSON Merge Patch"
+// @Router /patients/me [patch]
+func (h *PatientPortalHandlers) PatchMyProfile(w http.ResponseWriter, r *http.Request) {
+	current, err := h.portalService.GetProfile(r.Context())
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	req := dto.UpdatePatientProfileRequest{
+		FirstName:             current.User.FirstName,
+		LastName:              current.User.LastName,
+		Phone:                 current.User.Phone,
+		EmergencyContactName:  current.Patient.EmergencyContactName,
+		EmergencyContactPhone: current.Patient.EmergencyContactPhone,
+	}
+	if err := utils.DecodeMergePatch(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	profile, err := h.portalService.UpdateProfile(r.Context(), &models.PatientProfile{
+		Patient: models.Patient{EmergencyContactName: req.EmergencyContactName, EmergencyContactPhone: req.EmergencyContactPhone},
+		User:    models.User{FirstName: req.FirstName, LastName: req.LastName, Phone: req.Phone},
+	})
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, patientProfileToResponse(profile))
+}
+
+// ListMyAppointments godoc
+// @Summary List my appointments
+// @Description Retrieve a page of the calling patient's upcoming appointments, soonest first by default, or past ones (completed, cancelled or already started), most recent first by default. Walk the pages by passing next_cursor back as cursor. Requires valid JWT token with PATIENT role
+// @Tags Patient Portal
+// @Produce json
+// @Security BearerAuth
+// @Param when query string false "Which appointments to list" Enums(upcoming, past) default(upcoming)
+// @Param limit query int false "Page size (default: 20, max: 100)" default(20)
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param sort query string false "appointment_date or created_at; prefix with - for descending"
+// @Param status query string false "Filter by status, e.g. CONFIRMED"
+// @Param date_from query string false "Appointments at or after (date or RFC 3339)"
+// @Param date_to query string false "Appointments at or before (date or RFC 3339)"
+// @Success 200 {object} dto.ListResponse[dto.AppointmentResponse] "Page of appointments"
+// @Failure 400 {object} dto.ErrorResponse "Invalid when, filter, sort, limit or cursor"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - patient role required"
+// @Failure 404 {object} dto.ErrorResponse "No patient profile for this user"
+// @Router /patients/me/appointments [get]
+func (h *PatientPortalHandlers) ListMyAppointments(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	when := values.Get("when")
+	if when == "" {
+		when = service.AppointmentsUpcoming
+	}
+	values.Del("when")
+
+	spec := repository.PatientAppointmentList
+	if when == service.AppointmentsUpcoming {
+		spec.DefaultSort = "appointment_date"
+	}
+	q, err := listquery.Parse(values, spec)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	page, err := h.portalService.ListAppointments(r.Context(), when, q)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.NewListResponse(page, func(appointment *models.Appointment) dto.AppointmentResponse {
+		return *appointmentToResponse(appointment)
+	}))
+}
+
+// ListMyConsultations godoc
+// @Summary List my consultations
+// @Description Retrieve a page of the calling patient's consultations, newest first by default. Walk the pages by passing next_cursor back as cursor. Requires valid JWT token with PATIENT role
+// @Tags Patient Portal
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default: 20, max: 100)" default(20)
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param sort query string false "created_at; prefix with - for descending" default(-created_at)
+// @Param created_from query string false "Consultations at or after (date or RFC 3339)"
+// @Param created_to query string false "Consultations at or before (date or RFC 3339)"
+// @Success 200 {object} dto.ListResponse[dto.ConsultationResponse] "Page of consultations"
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter, sort, limit or cursor"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - patient role required"
+// @Failure 404 {object} dto.ErrorResponse "No patient profile for this user"
+// @Router /patients/me/consultations [get]
+func (h *PatientPortalHandlers) ListMyConsultations(w http.ResponseWriter, r *http.Request) {
+	q, err := listquery.Parse(r.URL.Query(), repository.ConsultationList)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	page, err := h.portalService.ListConsultations(r.Context(), q)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.NewListResponse(page, func(consultation *models.Consultation) dto.ConsultationResponse {
+		return *consultationToResponse(consultation)
+	}))
+}
+
+// ListMyPrescriptions godoc
+// @Summary List my prescriptions
+// @Description Retrieve a page of the calling patient's prescriptions, newest first by default. Walk the pages by passing next_cursor back as cursor. Requires valid JWT token with PATIENT role
+// @Tags Patient Portal
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default: 20, max: 100)" default(20)
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param sort query string false "created_at; prefix with - for descending" default(-created_at)
+// @Param created_from query string false "Prescriptions at or after (date or RFC 3339)"
+// @Param created_to query string false "Prescriptions at or before (date or RFC 3339)"
+// @Success 200 {object} dto.ListResponse[dto.PrescriptionResponse] "Page of prescriptions"
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter, sort, limit or cursor"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - patient role required"
+// @Failure 404 {object} dto.ErrorResponse "No patient profile for this user"
+// @Router /patients/me/prescriptions [get]
+func (h *PatientPortalHandlers) ListMyPrescriptions(w http.ResponseWriter, r *http.Request) {
+	q, err := listquery.Parse(r.URL.Query(), repository.PrescriptionList)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	page, err := h.portalService.ListPrescriptions(r.Context(), q)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.NewListResponse(page, prescriptionToResponse))
+}
+
+// ListMyLabResults godoc
+// @Summary List my lab results
+// @Description List the calling patient's completed lab tests, most recently completed first. Tests still requested or in progress are not shown. Requires valid JWT token with PATIENT role
+// @Tags Patient Portal
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.LabResultResponse "Lab results"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - patient role required"
+// @Failure 404 {object} dto.ErrorResponse "No patient profile for this user"
+// @Router /patients/me/lab-results [get]
+func (h *PatientPortalHandlers) ListMyLabResults(w http.ResponseWriter, r *http.Request) {
+	tests, err := h.portalService.ListLabResults(r.Context())
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, labResultsToResponse(tests))
+}
+
+// DownloadMyRecords godoc
+// @Summary Download my records
+// @Description Download the calling patient's profile, appointments, consultations, prescriptions and completed lab results as one JSON file. Each download is audited. Requires valid JWT token with PATIENT role
+// @Tags Patient Portal
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.PatientRecordResponse "Records, sent as an attachment"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - patient role required"
+// @Failure 404 {object} dto.ErrorResponse "No patient profile for this user"
+// @Router /patients/me/records [get]
+func (h *PatientPortalHandlers) DownloadMyRecords(w http.ResponseWriter, r *http.Request) {
+	record, err := h.portalService.ExportRecords(r.Context())
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-records-%s.json", record.Profile.Patient.MRN, record.GeneratedAt.Format("20060102"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	utils.WriteJSON(w, http.StatusOK, dto.PatientRecordResponse{
+		Profile:       patientProfileToResponse(&record.Profile),
+		Appointments:  appointmentsToResponse(record.Appointments),
+		Consultations: consultationsToResponse(record.Consultations),
+		Prescriptions: prescriptionsToResponse(record.Prescriptions),
+		LabResults:    labResultsToResponse(record.LabResults),
+		GeneratedAt:   record.GeneratedAt,
+	})
+}
+
+func patientProfileToResponse(profile *models.PatientProfile) dto.PatientProfileResponse {
+	return dto.PatientProfileResponse{
+		Patient: *patientToResponse(&profile.Patient),
+		User:    *userToResponse(&profile.User),
+	}
+}
+
+func appointmentsToResponse(appointments []*models.Appointment) []dto.AppointmentResponse {
+	response := make([]dto.AppointmentResponse, len(appointments))
+	for i, appointment := range appointments {
+		response[i] = *appointmentToResponse(appointment)
+	}
+	return response
+}
+
+func consultationsToResponse(consultations []*models.Consultation) []dto.ConsultationResponse {
+	response := make([]dto.ConsultationResponse, len(consultations))
+	for i, consultation := range consultations {
+		response[i] = *consultationToResponse(consultation)
+	}
+	return response
+}
+
+func prescriptionsToResponse(prescriptions []*models.Prescription) []dto.PrescriptionResponse {
+	response := make([]dto.PrescriptionResponse, len(prescriptions))
+	for i, prescription := range prescriptions {
+		response[i] = prescriptionToResponse(prescription)
+	}
+	return response
+}
+
+func prescriptionToResponse(p *models.Prescription) dto.PrescriptionResponse {
+	return dto.PrescriptionResponse{
+		PrescriptionID: p.PrescriptionID,
+		ConsultationID: p.ConsultationID,
+		DoctorID:       p.DoctorID,
+		MedicationName: p.MedicationName,
+		Dosage:         p.Dosage,
+		Frequency:      p.Frequency,
+		DurationDays:   p.DurationDays,
+		Instructions:   p.Instructions,
+		CreatedAt:      p.CreatedAt,
+	}
+}
+
+func labResultsToResponse(tests []*models.LabTest) []dto.LabResultResponse {
+	response := make([]dto.LabResultResponse, len(tests))
+	for i, test := range tests {
+		response[i] = dto.LabResultResponse{
+			TestID:         test.TestID,
+			DoctorID:       test.DoctorID,
+			TestName:       test.TestName,
+			TestType:       test.TestType,
+			Status:         test.Status,
+			ResultFilePath: test.ResultFilePath,
+			Notes:          test.Notes,
+			RequestedAt:    test.RequestedAt,
+			CompletedAt:    test.CompletedAt,
+		}
+	}
+	return response
+}
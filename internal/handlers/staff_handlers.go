@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+type StaffHandler struct {
+	onboardingService *service.StaffOnboardingService
+}
+
+func NewStaffHandler(onboardingService *service.StaffOnboardingService) *StaffHandler {
+	return &StaffHandler{
+		onboardingService: onboardingService,
+	}
+}
+
+// OnboardStaff godoc
+// @Summary Onboard a doctor or nurse
+// @Description Create the user account, the doctor or nurse profile in their department and, for doctors, their weekly availability in one transaction: either all of it is created or none. The account has no usable password; an invitation to set one is sent afterwards, and invitation_sent reports whether that worked. If it was not sent, invitation_link holds the set-password link for the admin to pass on; it is not shown again. Requires valid JWT token with ADMIN role
+// @Tags Staff Management
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.OnboardStaffRequest true "New staff member"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
+// @Success 201 {object} dto.OnboardStaffResponse "Staff member onboarded"
+// @Failure 400 {object} dto.ErrorResponse "Validation error, unknown or inactive department"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 409 {object} dto.ErrorResponse "Username, email or license number already registered"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
+// @Router /admin/staff [post]
+func (h *StaffHandler) OnboardStaff(w http.ResponseWriter, r *http.Request) {
+	var req dto.OnboardStaffRequest
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	departmentID, err := uuid.Parse(req.DepartmentID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid department id")
+		return
+	}
+
+	onboarding := &models.StaffOnboarding{
+		User: models.User{
+			Username:  strings.TrimSpace(req.Username),
+			Email:     strings.TrimSpace(req.Email),
+			FirstName: &req.FirstName,
+			LastName:  &req.LastName,
+			Phone:     &req.Phone,
+			Role:      req.Role,
+		},
+	}
+	switch req.Role {
+	case "DOCTOR":
+		onboarding.Doctor = &models.Doctor{
+			Specialization:  strings.TrimSpace(req.Specialization),
+			LicenseNumber:   req.LicenseNumber,
+			DepartmentID:    departmentID,
+			ConsultationFee: req.ConsultationFee,
+		}
+	case "NURSE":
+		onboarding.Nurse = &models.Nurse{
+			Shift:         strings.TrimSpace(req.Shift),
+			LicenseNumber: req.LicenseNumber,
+			DepartmentID:  departmentID,
+		}
+	}
+	for _, window := range req.Availability {
+		onboarding.Availability = append(onboarding.Availability, &models.Availability{
+			DayOfWeek:      window.DayOfWeek,
+			StartTime:      window.StartTime,
+			EndTime:        window.EndTime,
+			MaxAppointment: window.MaxAppointments,
+		})
+	}
+
+	created, err := h.onboardingService.OnboardStaff(r.Context(), onboarding)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	response := dto.OnboardStaffResponse{
+		User:                *userToResponse(&created.User),
+		Availability:        make([]dto.AvailabilityResponse, len(created.Availability)),
+		InvitationExpiresAt: created.Invitation.ExpiresAt,
+		InvitationSent:      created.Invitation.Sent,
+		InvitationLink:      created.Invitation.Link,
+	}
+	if created.Doctor != nil {
+		response.Doctor = doctorToResponse(created.Doctor)
+	}
+	if created.Nurse != nil {
+		response.Nurse = nurseToResponse(created.Nurse)
+	}
+	for i, availability := range created.Availability {
+		response.Availability[i] = *availabilityToResponse(availability)
+	}
+	utils.WriteJSON(w, http.StatusCreated, response)
+}
+
+// ResendInvitation godoc
+// @Summary Resend a staff invitation
+// @Description Revoke the user's unused set-password invitations and send a new one. If it cannot be sent, sent is false and link holds the set-password link for the admin to pass on. Requires valid JWT token with ADMIN role
+// @Tags Staff Management
+// @Produce json
+// @Security BearerAuth
+// @Param user_id path string true "User ID"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
+// @Success 200 {object} dto.InvitationResponse "New invitation issued"
+// @Failure 400 {object} dto.ErrorResponse "Invalid user ID, or the user is a patient"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Failure 409 {object} dto.ErrorResponse "User is deactivated"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
+// @Router /admin/staff/{user_id}/invitation [post]
+func (h *StaffHandler) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	invitation, err := h.onboardingService.ResendInvitation(r.Context(), userID)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.InvitationResponse{
+		ExpiresAt: invitation.ExpiresAt,
+		Sent:      invitation.Sent,
+		Link:      invitation.Link,
+	})
+}
+
+// AcceptInvitation godoc
+// @Summary Set a password from an invitation
+// @Description Redeem the token from a staff invitation by choosing a password. Each invitation works once and only until it expires
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.AcceptInvitationRequest true "Invitation token and new password"
+// @Success 200 {object} dto.MessageResponse "Password set"
+// @Failure 400 {object} dto.ErrorResponse "Invitation invalid, used or expired, or password too weak"
+// @Failure 429 {object} dto.ErrorResponse "Too many requests - see Retry-After"
+// @Router /auth/accept-invitation [post]
+func (h *StaffHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req dto.AcceptInvitationRequest
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	if err := h.onboardingService.AcceptInvitation(r.Context(), req.Token, req.Password); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, dto.MessageResponse{Message: "password set; you can now log in"})
+}
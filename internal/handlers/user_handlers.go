@@ -1,45 +1,51 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
 	"github.com/falasefemi2/hms/internal/service"
 	"github.com/falasefemi2/hms/internal/utils"
 )
 
 type UserHandler struct {
-	userService *service.UserService
+	userService         *service.UserService
+	registrationService *service.PatientRegistrationService
 }
 
-func NewUserHandler(userService *service.UserService) *UserHandler {
+func NewUserHandler(userService *service.UserService, registrationService *service.PatientRegistrationService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:         userService,
+		registrationService: registrationService,
 	}
 }
 
 // SignUpPatient godoc
 // @Summary Patient self-registration
-// @Description Allow patients to register without authentication. Role is automatically set to PATIENT
+// @Description Allow patients to register without authentication. Role is automatically set to PATIENT. When the active hospital configuration disables self-registration, an invite code from an admin is required
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param request body dto.PatientSignUpRequest true "Patient signup details"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
 // @Success 201 {object} dto.UserResponse "Patient registered successfully"
-// @Failure 400 {object} dto.ErrorResponse "Validation error - invalid input format"
+// @Failure 400 {object} dto.ErrorResponse "Validation error - invalid input format, or an invalid, expired or used invite code"
+// @Failure 403 {object} dto.ErrorResponse "Self-registration is disabled and no invite code was given"
 // @Failure 409 {object} dto.ErrorResponse "Conflict - email or username already registered"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
+// @Failure 429 {object} dto.ErrorResponse "Too many requests - see Retry-After"
 // @Router /auth/signup [post]
 func (u *UserHandler) SignUpPatient(w http.ResponseWriter, r *http.Request) {
 	var req dto.PatientSignUpRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid bad request")
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -49,7 +55,7 @@ func (u *UserHandler) SignUpPatient(w http.ResponseWriter, r *http.Request) {
 	req.LastName = strings.TrimSpace(req.LastName)
 	req.Phone = strings.TrimSpace(req.Phone)
 
-	createdUser, err := u.userService.CreatePatientUser(
+	createdUser, err := u.registrationService.SignUp(
 		r.Context(),
 		req.Username,
 		req.Email,
@@ -57,23 +63,44 @@ func (u *UserHandler) SignUpPatient(w http.ResponseWriter, r *http.Request) {
 		req.FirstName,
 		req.LastName,
 		req.Phone,
+		req.InviteCode,
 	)
 
 	if err != nil {
-		errorMsg := err.Error()
-		if strings.Contains(errorMsg, "email already registered") || strings.Contains(errorMsg, "username already taken") {
-			utils.WriteError(w, http.StatusConflict, errorMsg)
-			return
-		}
-		utils.WriteError(w, http.StatusBadRequest, errorMsg)
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	response := u.userToResponse(createdUser)
+	response := userToResponse(createdUser)
 
 	utils.WriteJSON(w, http.StatusCreated, response)
 }
 
+// CreatePatientInvite godoc
+// @Summary Issue a patient invite code (Admin only)
+// @Description Issue a one-time code a patient can sign up with while self-registration is disabled. The code is returned only in this response. Requires valid JWT token with ADMIN role
+// @Tags User Management
+// @Produce json
+// @Security BearerAuth
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
+// @Success 201 {object} dto.PatientInviteResponse "Invite code issued"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Router /admin/patient-invites [post]
+func (u *UserHandler) CreatePatientInvite(w http.ResponseWriter, r *http.Request) {
+	code, invite, err := u.registrationService.CreateInvite(r.Context())
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, dto.PatientInviteResponse{
+		InviteID:  invite.InviteID,
+		Code:      code,
+		ExpiresAt: invite.ExpiresAt,
+	})
+}
+
 // CreateUser godoc
 // @Summary Create a new user (Admin only)
 // @Description Create a new doctor, nurse, or admin user. Requires valid JWT token with ADMIN role
@@ -82,17 +109,19 @@ func (u *UserHandler) SignUpPatient(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security BearerAuth
 // @Param request body dto.AdminCreateUserRequest true "User creation details with role"
+// @Param Idempotency-Key header string false "Makes retries safe; the first response is replayed"
 // @Success 201 {object} dto.UserResponse "User created successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error - invalid input or invalid role"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized - missing or invalid JWT token"
 // @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
 // @Failure 409 {object} dto.ErrorResponse "Conflict - email or username already registered"
+// @Failure 422 {object} dto.ErrorResponse "Idempotency-Key reused with a different request"
 // @Router /admin/users [post]
 func (u *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var req dto.AdminCreateUserRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
@@ -115,22 +144,10 @@ func (u *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	)
 
 	if err != nil {
-		// Check for specific validation errors
-		errorMsg := err.Error()
-		if strings.Contains(errorMsg, "email already registered") ||
-			strings.Contains(errorMsg, "username already taken") {
-			utils.WriteError(w, http.StatusConflict, errorMsg)
-			return
-		}
-		if strings.Contains(errorMsg, "invalid role") ||
-			strings.Contains(errorMsg, "must self-register") {
-			utils.WriteError(w, http.StatusBadRequest, errorMsg)
-			return
-		}
-		utils.WriteError(w, http.StatusBadRequest, errorMsg)
+		utils.HandleServiceError(w, r, err)
 		return
 	}
-	response := u.userToResponse(createdUser)
+	response := userToResponse(createdUser)
 
 	utils.WriteJSON(w, http.StatusCreated, response)
 }
@@ -157,75 +174,110 @@ func (u *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 
 	user, err := u.userService.GetUserByID(r.Context(), userID)
 	if err != nil {
-		utils.WriteError(w, http.StatusNotFound, "user not found")
+		utils.HandleServiceError(w, r, err)
 		return
 	}
-	response := u.userToResponse(user)
+	response := userToResponse(user)
 	utils.WriteJSON(w, http.StatusOK, response)
 }
 
-// ListUsers godoc
-// @Summary List all users with pagination (Admin only)
-// @Description Retrieve a paginated list of all users in the system
+// PatchUser godoc
+// @Summary Partially update a user (Admin only)
+// @Description Apply a JSON Merge Patch (RFC 7396) to a user. Only the fields sent are changed. The role and password cannot be changed here, nor is_active of a doctor or nurse: use their deactivate and activate endpoints, which also handle appointments and the audit log.
 // @Tags User Management
-// @Accept json
+// @Accept application/merge-patch+json
 // @Produce json
 // @Security BearerAuth
-// @Param limit query int false "Number of users per page (default: 10, max: 100)" default(10)
-// @Param offset query int false "Number of users to skip for pagination (default: 0)" default(0)
-// @Success 200 {array} dto.UserResponse "List of users retrieved successfully"
+// @Param id path string true "User ID (UUID format)"
+// @Param request body dto.UpdateUserRequest true "Fields to change"
+// @Success 200 {object} dto.UserResponse "User updated successfully"
+// @Failure 400 {object} dto.ErrorResponse "Validation error"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid JWT token"
 // @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
-// @Failure 400 {object} dto.ErrorResponse "Invalid pagination parameters"
-// @Failure 500 {object} dto.ErrorResponse "Internal server error"
-// @Router /admin/users [get]
-func (u *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
-	const (
-		defaultLimit  = 10
-		maxLimit      = 100
-		defaultOffset = 0
-	)
-	limitStr := chi.URLParam(r, "limit")
-	limit := defaultLimit
-
-	if limitStr != "" {
-		var err error
-		limit, err := strconv.Atoi(limitStr)
-		if err != nil || limit < 1 {
-			utils.WriteError(w, http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if limit > maxLimit {
-			limit = maxLimit
-		}
+// @Failure 404 {object} dto.ErrorResponse "Not found - user does not exist"
+// @Failure 409 {object} dto.ErrorResponse "Username or email already taken"
+// @Failure 415 {object} dto.ErrorResponse "Body is not a JSON Merge Patch"
+// @Router /admin/users/{id} [patch]
+func (u *UserHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		utils.WriteError(w, http.StatusBadRequest, "userID required")
+		return
 	}
 
-	offsetStr := chi.URLParam(r, "offset")
-	offset := defaultOffset
-	if offsetStr != "" {
-		var err error
-		offset, err = strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			utils.WriteError(w, http.StatusBadRequest, "offset must be a non-negative integer")
-			return
-		}
+	user, err := u.userService.GetUserByID(r.Context(), userID)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
 	}
 
-	users, totalCount, err := u.userService.ListUsers(r.Context(), limit, offset)
+	req := dto.UpdateUserRequest{
+		Username:  user.Username,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Phone:     user.Phone,
+		IsActive:  user.IsActive,
+	}
+	if err := utils.DecodeMergePatch(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	user.Username = strings.TrimSpace(req.Username)
+	user.Email = strings.TrimSpace(req.Email)
+	user.FirstName = req.FirstName
+	user.LastName = req.LastName
+	user.Phone = req.Phone
+	user.IsActive = req.IsActive
+
+	updatedUser, err := u.userService.UpdateUser(r.Context(), user)
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, "failed to retrieve users")
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
-	responses := make([]dto.UserResponse, totalCount)
-	for i, user := range users {
-		responses[i] = *u.userToResponse(user)
+	utils.WriteJSON(w, http.StatusOK, userToResponse(updatedUser))
+}
+
+// ListUsers godoc
+// @Summary List users (Admin only)
+// @Description Retrieve a page of users. Walk the pages by passing next_cursor back as cursor.
+// @Tags User Management
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default: 20, max: 100)" default(20)
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param sort query string false "created_at, username or email; prefix with - for descending" default(-created_at)
+// @Param role query string false "Filter by role"
+// @Param is_active query bool false "Filter by active status"
+// @Param created_from query string false "Created at or after (date or RFC 3339)"
+// @Param created_to query string false "Created at or before (date or RFC 3339)"
+// @Success 200 {object} dto.ListResponse[dto.UserResponse] "Page of users"
+// @Failure 400 {object} dto.ErrorResponse "Invalid filter, sort, limit or cursor"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid JWT token"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - admin role required"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /admin/users [get]
+func (u *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	q, err := listquery.Parse(r.URL.Query(), repository.UserList)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
+	}
+
+	page, err := u.userService.ListUsers(r.Context(), q)
+	if err != nil {
+		utils.HandleServiceError(w, r, err)
+		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, responses)
+	utils.WriteJSON(w, http.StatusOK, dto.NewListResponse(page, func(user *models.User) dto.UserResponse {
+		return *userToResponse(user)
+	}))
 }
 
-func (u *UserHandler) userToResponse(user *models.User) *dto.UserResponse {
+func userToResponse(user *models.User) *dto.UserResponse {
 	return &dto.UserResponse{
 		ID:        user.ID.String(),
 		Username:  user.Username,
@@ -249,18 +301,20 @@ func (u *UserHandler) userToResponse(user *models.User) *dto.UserResponse {
 // @Success 200 {object} dto.LoginResponse "Login successful, token returned"
 // @Failure 400 {object} dto.ErrorResponse "Validation error - invalid input format"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized - invalid credentials"
+// @Failure 403 {object} dto.ErrorResponse "Forbidden - account is deactivated"
+// @Failure 429 {object} dto.ErrorResponse "Too many requests - see Retry-After"
 // @Router /auth/login [post]
 func (u *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req dto.LoginRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "invalid request body")
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
 	token, err := u.userService.Login(r.Context(), req.Email, req.Password)
 	if err != nil {
-		utils.WriteError(w, http.StatusUnauthorized, err.Error())
+		utils.HandleServiceError(w, r, err)
 		return
 	}
 
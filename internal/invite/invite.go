@@ -0,0 +1,43 @@
+// Package invite delivers set-password invitations to newly onboarded
+// staff.
+package invite
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/falasefemi2/hms/internal/logging"
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+// ErrNoDelivery is returned by a sender that cannot deliver invitations.
+// The invitation is still valid; the admin is given the link instead.
+var ErrNoDelivery = errors.New("invitation delivery is not configured")
+
+// LogSender stands in for a mail integration. It records in the
+// application log that an invitation was issued, never the link, which
+// carries a credential, and returns ErrNoDelivery so the link is handed to
+// the admin instead.
+type LogSender struct{}
+
+func (LogSender) SendInvitation(ctx context.Context, user *models.User, link string, expiresAt time.Time) error {
+	logging.FromContext(ctx).InfoContext(ctx, "staff invitation issued",
+		"user_id", user.ID,
+		"expires_at", expiresAt,
+	)
+	return ErrNoDelivery
+}
+
+// Link returns baseURL with token added as the token query parameter.
+func Link(baseURL, token string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
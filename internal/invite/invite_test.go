@@ -0,0 +1,49 @@
+package invite_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/invite"
+	"github.com/falasefemi2/hms/internal/logging"
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+func TestLink(t *testing.T) {
+	for base, want := range map[string]string{
+		"https://hms.example.com/set-password":          "https://hms.example.com/set-password?token=abc-_1",
+		"https://hms.example.com/welcome?lang=en":       "https://hms.example.com/welcome?lang=en&token=abc-_1",
+		"https://hms.example.com/set-password?token=xx": "https://hms.example.com/set-password?token=abc-_1",
+	} {
+		got, err := invite.Link(base, "abc-_1")
+		if err != nil {
+			t.Fatalf("Link(%q): %v", base, err)
+		}
+		if got != want {
+			t.Errorf("Link(%q) = %q, want %q", base, got, want)
+		}
+	}
+}
+
+func TestLogSenderNeverLogsToken(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := logging.NewContext(context.Background(), logging.New("debug", &buf))
+	user := &models.User{ID: uuid.New()}
+
+	link := "https://hms.example.com/set-password?token=secret-token"
+	if err := (invite.LogSender{}).SendInvitation(ctx, user, link, time.Now().Add(time.Hour)); !errors.Is(err, invite.ErrNoDelivery) {
+		t.Fatalf("expected ErrNoDelivery, got %v", err)
+	}
+	if strings.Contains(buf.String(), "secret-token") {
+		t.Errorf("expected the token kept out of the log, got %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), user.ID.String()) {
+		t.Errorf("expected the user logged, got %s", buf.String())
+	}
+}
@@ -0,0 +1,55 @@
+package listquery
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Cursor marks the last row of a page: the value of the sort field and the
+// row's ID. The next page starts strictly after it.
+type Cursor struct {
+	Sort  string
+	Value any
+	ID    any
+}
+
+type cursorJSON struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// encodeCursor returns c as an opaque URL-safe token.
+func encodeCursor(c *Cursor) string {
+	b, _ := json.Marshal(cursorJSON{
+		Sort:  c.Sort,
+		Value: formatValue(c.Value),
+		ID:    formatValue(c.ID),
+	})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor parses a token from encodeCursor, typing its values as the
+// sort field and ID of the query it is used with.
+func decodeCursor(token string, sortType, idType Type) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw cursorJSON
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	value, err := parseValue(sortType, raw.Value)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseValue(idType, raw.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cursor{Sort: raw.Sort, Value: value, ID: id}, nil
+}
@@ -0,0 +1,291 @@
+// Package listquery parses the query string of list endpoints into a
+// validated Query. Each resource declares a Spec naming the fields clients
+// may filter and sort on; anything else is rejected. Pages are walked with
+// opaque keyset cursors rather than offsets, so results stay stable while
+// rows are inserted.
+//
+// Requests look like
+//
+//	GET /appointments?status=CONFIRMED&date_from=2026-01-01&sort=-appointment_date&limit=20
+//	GET /appointments?cursor=<next_cursor from the previous page>
+package listquery
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+
+	limitParam  = "limit"
+	cursorParam = "cursor"
+	sortParam   = "sort"
+)
+
+// Type is the type of a field's values. It decides how filter values and
+// cursors are parsed.
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Float
+	Bool
+	Time
+	UUID
+)
+
+// Field is a property of T that can be filtered or sorted on. Column is the
+// SQL expression used by the Postgres repositories and Get returns the same
+// value from a loaded item, for cursors and the in-memory repositories.
+type Field[T any] struct {
+	Column   string
+	Type     Type
+	Get      func(T) any
+	Sortable bool
+}
+
+// Op is a comparison applied by a filter.
+type Op string
+
+const (
+	Eq  Op = "="
+	Gte Op = ">="
+	Lt  Op = "<"
+	Lte Op = "<="
+)
+
+// Filter maps a query parameter onto a comparison with a field.
+type Filter struct {
+	Field string
+	Op    Op
+}
+
+// Spec lists what a list endpoint accepts. Filters are keyed by query
+// parameter. ID must be unique per row; it breaks ties so that keyset
+// pagination never skips or repeats rows. Defaults are filters applied when
+// the request does not set the parameter.
+type Spec[T any] struct {
+	Fields      map[string]Field[T]
+	Filters     map[string]Filter
+	ID          Field[T]
+	DefaultSort string
+	Defaults    map[string]string
+}
+
+// Condition is a parsed filter.
+type Condition[T any] struct {
+	Field Field[T]
+	Op    Op
+	Value any
+}
+
+// Query is a validated list request.
+type Query[T any] struct {
+	Conditions []Condition[T]
+	SortName   string
+	Sort       Field[T]
+	Desc       bool
+	ID         Field[T]
+	After      *Cursor
+	Limit      int
+}
+
+// Parse validates values against spec. Unknown parameters, fields that are
+// not whitelisted and malformed values are reported as validation errors
+// naming the offending parameter.
+func Parse[T any](values url.Values, spec Spec[T]) (Query[T], error) {
+	q := Query[T]{ID: spec.ID, Limit: DefaultLimit}
+
+	for param := range values {
+		if param == limitParam || param == cursorParam || param == sortParam {
+			continue
+		}
+		if _, ok := spec.Filters[param]; !ok {
+			return q, invalidParam("invalid_filter", param, param+" is not a supported filter; use one of "+strings.Join(sortedKeys(spec.Filters), ", "))
+		}
+	}
+
+	if raw := values.Get(limitParam); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return q, invalidParam("invalid_limit", limitParam, fmt.Sprintf("limit must be between 1 and %d", MaxLimit))
+		}
+		q.Limit = limit
+	}
+
+	sortSpec := values.Get(sortParam)
+	if sortSpec == "" {
+		sortSpec = spec.DefaultSort
+	}
+	name, desc := strings.CutPrefix(sortSpec, "-")
+	field, ok := spec.Fields[name]
+	if !ok || !field.Sortable {
+		return q, invalidParam("invalid_sort", sortParam, "sort must be one of "+strings.Join(sortable(spec), ", ")+", optionally prefixed with -")
+	}
+	q.SortName, q.Sort, q.Desc = sortSpec, field, desc
+
+	for _, param := range sortedKeys(spec.Filters) {
+		raw, set := values.Get(param), values.Has(param)
+		if !set {
+			raw, set = spec.Defaults[param]
+		}
+		if !set {
+			continue
+		}
+
+		filter := spec.Filters[param]
+		field := spec.Fields[filter.Field]
+		op := filter.Op
+		value, err := parseValue(field.Type, raw)
+		if err != nil {
+			return q, invalidParam("invalid_filter", param, param+" "+err.Error())
+		}
+		// A bare date as an upper bound covers the whole day.
+		if op == Lte && field.Type == Time && isDate(raw) {
+			value, op = value.(time.Time).AddDate(0, 0, 1), Lt
+		}
+		q.Conditions = append(q.Conditions, Condition[T]{Field: field, Op: op, Value: value})
+	}
+
+	if raw := values.Get(cursorParam); raw != "" {
+		cursor, err := decodeCursor(raw, q.Sort.Type, q.ID.Type)
+		if err != nil || cursor.Sort != q.SortName {
+			return q, invalidParam("invalid_cursor", cursorParam, "cursor is invalid or was issued for a different sort order")
+		}
+		q.After = cursor
+	}
+
+	return q, nil
+}
+
+// Where returns a copy of q with one more condition. Services use it to
+// scope a list, for instance to the caller's own rows, with fields that are
+// not client filters.
+func (q Query[T]) Where(field Field[T], op Op, value any) Query[T] {
+	q.Conditions = append(slices.Clip(q.Conditions), Condition[T]{Field: field, Op: op, Value: value})
+	return q
+}
+
+// Page is one page of results. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+	HasMore    bool
+	Limit      int
+}
+
+// NewPage builds the page for q from items, which repositories fetch with a
+// limit of q.Limit+1 so the extra row tells whether another page follows.
+func NewPage[T any](items []T, q Query[T]) *Page[T] {
+	page := &Page[T]{Items: items, Limit: q.Limit}
+	if page.Items == nil {
+		page.Items = make([]T, 0)
+	}
+
+	if len(items) > q.Limit {
+		page.Items = items[:q.Limit]
+		page.HasMore = true
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = encodeCursor(&Cursor{
+			Sort:  q.SortName,
+			Value: q.Sort.Get(last),
+			ID:    q.ID.Get(last),
+		})
+	}
+
+	return page
+}
+
+func parseValue(t Type, raw string) (any, error) {
+	switch t {
+	case Int:
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return v, nil
+	case Float:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return v, nil
+	case Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return v, nil
+	case Time:
+		if isDate(raw) {
+			v, err := time.Parse(time.DateOnly, raw)
+			if err != nil {
+				return nil, fmt.Errorf("must be a date (2006-01-02) or an RFC 3339 timestamp")
+			}
+			return v, nil
+		}
+		v, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a date (2006-01-02) or an RFC 3339 timestamp")
+		}
+		return v.UTC(), nil
+	case UUID:
+		v, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a UUID")
+		}
+		return v, nil
+	default:
+		return raw, nil
+	}
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func isDate(raw string) bool {
+	return len(raw) == len(time.DateOnly)
+}
+
+func invalidParam(code, param, message string) error {
+	return utils.NewValidationError(code, "invalid list query", utils.FieldError{Field: param, Message: message})
+}
+
+func sortable[T any](spec Spec[T]) []string {
+	var names []string
+	for name, field := range spec.Fields {
+		if field.Sortable {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
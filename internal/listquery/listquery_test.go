@@ -0,0 +1,135 @@
+package listquery_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+type visit struct {
+	ID     uuid.UUID
+	Status string
+	At     time.Time
+}
+
+var visitList = listquery.Spec[visit]{
+	Fields: map[string]listquery.Field[visit]{
+		"at":     {Column: "at", Type: listquery.Time, Sortable: true, Get: func(v visit) any { return v.At }},
+		"status": {Column: "status", Type: listquery.String, Get: func(v visit) any { return v.Status }},
+	},
+	Filters: map[string]listquery.Filter{
+		"status":  {Field: "status", Op: listquery.Eq},
+		"at_from": {Field: "at", Op: listquery.Gte},
+		"at_to":   {Field: "at", Op: listquery.Lte},
+	},
+	ID:          listquery.Field[visit]{Column: "id", Type: listquery.UUID, Get: func(v visit) any { return v.ID }},
+	DefaultSort: "-at",
+	Defaults:    map[string]string{"status": "OPEN"},
+}
+
+func TestParse(t *testing.T) {
+	q, err := listquery.Parse(url.Values{"at_to": {"2026-03-01"}, "limit": {"5"}}, visitList)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if q.Limit != 5 || q.SortName != "-at" || !q.Desc {
+		t.Errorf("unexpected limit or sort: %+v", q)
+	}
+	if len(q.Conditions) != 2 {
+		t.Fatalf("expected the default status and at_to conditions, got %+v", q.Conditions)
+	}
+
+	// Filters are applied in parameter order: at_to, then status.
+	upper := q.Conditions[0]
+	if upper.Op != listquery.Lt || !upper.Value.(time.Time).Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("a date upper bound should cover the whole day, got %s %v", upper.Op, upper.Value)
+	}
+	if status := q.Conditions[1]; status.Op != listquery.Eq || status.Value != "OPEN" {
+		t.Errorf("expected default status=OPEN, got %s %v", status.Op, status.Value)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		values url.Values
+		code   string
+	}{
+		{"unknown filter", url.Values{"colour": {"red"}}, "invalid_filter"},
+		{"malformed value", url.Values{"at_from": {"yesterday"}}, "invalid_filter"},
+		{"limit too large", url.Values{"limit": {"101"}}, "invalid_limit"},
+		{"limit not a number", url.Values{"limit": {"ten"}}, "invalid_limit"},
+		{"unsortable field", url.Values{"sort": {"status"}}, "invalid_sort"},
+		{"garbage cursor", url.Values{"cursor": {"not-a-cursor"}}, "invalid_cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := listquery.Parse(tt.values, visitList)
+
+			var appErr *utils.AppError
+			if !errors.As(err, &appErr) || !errors.Is(err, utils.ErrInvalidInput) || appErr.Code != tt.code {
+				t.Fatalf("expected %s validation error, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestPageCursor(t *testing.T) {
+	base := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	items := []visit{
+		{ID: uuid.New(), At: base.Add(2 * time.Hour)},
+		{ID: uuid.New(), At: base.Add(time.Hour)},
+		{ID: uuid.New(), At: base},
+	}
+
+	q, err := listquery.Parse(url.Values{"limit": {"2"}}, visitList)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	page := listquery.NewPage(items, q)
+	if len(page.Items) != 2 || !page.HasMore || page.NextCursor == "" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+
+	next, err := listquery.Parse(url.Values{"limit": {"2"}, "cursor": {page.NextCursor}}, visitList)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next.After == nil || next.After.ID != items[1].ID || !next.After.Value.(time.Time).Equal(items[1].At) {
+		t.Errorf("cursor should point at the last item of the page, got %+v", next.After)
+	}
+
+	_, err = listquery.Parse(url.Values{"sort": {"at"}, "cursor": {page.NextCursor}}, visitList)
+	if !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected a cursor from another sort order to be rejected, got %v", err)
+	}
+
+	last := listquery.NewPage(items[2:], next)
+	if last.HasMore || last.NextCursor != "" {
+		t.Errorf("expected the last page to have no cursor, got %+v", last)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	q, err := listquery.Parse(url.Values{}, visitList)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	scoped := q.Where(visitList.ID, listquery.Eq, uuid.New())
+	if len(scoped.Conditions) != 2 || scoped.Conditions[1].Field.Column != "id" {
+		t.Errorf("expected the condition appended, got %+v", scoped.Conditions)
+	}
+	if len(q.Conditions) != 1 {
+		t.Errorf("expected the original query left alone, got %+v", q.Conditions)
+	}
+}
@@ -0,0 +1,95 @@
+// Package logging configures the application's slog logger and carries a
+// request-scoped logger through the context.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the value of any attribute whose key is in redactedKeys.
+const Redacted = "[REDACTED]"
+
+// redactedKeys lists credentials and protected health information that must
+// never reach the logs. Keys are matched case-insensitively.
+var redactedKeys = map[string]bool{
+	"password":                true,
+	"password_hash":           true,
+	"token":                   true,
+	"invitation_link":         true,
+	"authorization":           true,
+	"email":                   true,
+	"phone":                   true,
+	"date_of_birth":           true,
+	"blood_group":             true,
+	"medical_history":         true,
+	"emergency_contact_name":  true,
+	"emergency_contact_phone": true,
+	"diagnosis":               true,
+	"symptoms":                true,
+	"prescription":            true,
+	"notes":                   true,
+}
+
+// New returns a JSON logger writing to w at the given level. Unknown levels
+// fall back to info.
+func New(level string, w io.Writer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       ParseLevel(level),
+		ReplaceAttr: redact,
+	}))
+}
+
+// ParseLevel maps a LOG_LEVEL value such as "debug" or "WARN" to a slog level.
+func ParseLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if redactedKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+type loggerKey struct{}
+
+// requestLogger lets middleware further down the chain, such as
+// authentication, add attributes that the access log written by the outer
+// middleware also sees.
+type requestLogger struct {
+	mu     sync.RWMutex
+	logger *slog.Logger
+}
+
+// NewContext returns a copy of ctx carrying logger.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, &requestLogger{logger: logger})
+}
+
+// FromContext returns the logger stored in ctx, or slog.Default().
+func FromContext(ctx context.Context) *slog.Logger {
+	if rl, ok := ctx.Value(loggerKey{}).(*requestLogger); ok {
+		rl.mu.RLock()
+		defer rl.mu.RUnlock()
+		return rl.logger
+	}
+	return slog.Default()
+}
+
+// AddAttrs adds attributes to the logger stored in ctx for the rest of the
+// request. It is a no-op when ctx has no logger.
+func AddAttrs(ctx context.Context, args ...any) {
+	if rl, ok := ctx.Value(loggerKey{}).(*requestLogger); ok {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		rl.logger = rl.logger.With(args...)
+	}
+}
@@ -0,0 +1,77 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/falasefemi2/hms/internal/logging"
+)
+
+func TestNewRedactsSensitiveFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New("info", &buf)
+
+	logger.Info("patient updated",
+		"patient_id", "p-1",
+		"Diagnosis", "hypertension",
+		"invitation_link", "https://hms.example.com/set-password?token=secret",
+		slog.Group("patient", "date_of_birth", "1990-01-01", "gender", "F"),
+	)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("decode log entry: %v", err)
+	}
+
+	if entry["patient_id"] != "p-1" {
+		t.Errorf("expected patient_id to be logged, got %v", entry["patient_id"])
+	}
+	if entry["Diagnosis"] != logging.Redacted {
+		t.Errorf("expected Diagnosis to be redacted, got %v", entry["Diagnosis"])
+	}
+	if entry["invitation_link"] != logging.Redacted {
+		t.Errorf("expected invitation_link to be redacted, got %v", entry["invitation_link"])
+	}
+	patient, _ := entry["patient"].(map[string]any)
+	if patient["date_of_birth"] != logging.Redacted || patient["gender"] != "F" {
+		t.Errorf("unexpected patient group: %v", patient)
+	}
+}
+
+func TestNewHonoursLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New("WARN", &buf)
+
+	logger.Info("ignored")
+	if buf.Len() != 0 {
+		t.Fatalf("expected info to be filtered, got %s", buf.String())
+	}
+
+	logger.Warn("kept")
+	if buf.Len() == 0 {
+		t.Fatal("expected warn to be logged")
+	}
+}
+
+func TestAddAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := logging.NewContext(context.Background(), logging.New("info", &buf))
+
+	logging.AddAttrs(ctx, "user_id", "u-1", "role", "DOCTOR")
+	logging.FromContext(ctx).Info("done")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("decode log entry: %v", err)
+	}
+	if entry["user_id"] != "u-1" || entry["role"] != "DOCTOR" {
+		t.Errorf("expected request attributes, got %v", entry)
+	}
+
+	if logging.FromContext(context.Background()) != slog.Default() {
+		t.Error("expected default logger without a request logger")
+	}
+}
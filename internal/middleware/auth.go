@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/logging"
 	"github.com/falasefemi2/hms/internal/utils"
 )
 
@@ -28,6 +31,7 @@ func JWTAuth(next http.Handler) http.Handler {
 		}
 		ctx := context.WithValue(r.Context(), utils.UserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, utils.RoleKey, claims.Role)
+		logging.AddAttrs(ctx, "user_id", claims.UserID.String(), "role", claims.Role)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -116,10 +120,12 @@ func HasAnyRole(allowedRoles ...string) func(http.Handler) http.Handler {
 	}
 }
 
-func GetUserIDFromContext(ctx context.Context) string {
-	userID, ok := ctx.Value(utils.UserIDKey).(string)
-	if !ok {
-		return ""
+// GetUserIDFromContext returns the ID JWTAuth stored for the caller, or
+// uuid.Nil when the request is not authenticated.
+func GetUserIDFromContext(ctx context.Context) uuid.UUID {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return uuid.Nil
 	}
 	return userID
 }
@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/falasefemi2/hms/internal/logging"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client-chosen key for a POST request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from a stored key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// replayedHeaders are the response headers stored with a key and sent again
+// on replay. Anything else, such as rate limit headers, describes the retry
+// rather than the original request.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// IdempotencyStore persists the outcome of requests sent with an
+// Idempotency-Key. Begin returns nil when the caller has claimed the key.
+type IdempotencyStore interface {
+	Begin(ctx context.Context, scope, key, requestHash string, lockTimeout time.Duration) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, record *models.IdempotencyRecord) error
+	Release(ctx context.Context, scope, key string) error
+}
+
+// Idempotency makes POST requests that carry an Idempotency-Key safe to
+// retry. The first response for a key is stored per user (or per client IP
+// on unauthenticated routes) and replayed for later requests with the same
+// body; reusing the key with a different request is rejected with 422, and a
+// retry that arrives while the first request is still running gets 409.
+// Server errors are not stored so the client can retry them. A claim that
+// has been in flight longer than lockTimeout is treated as abandoned.
+func Idempotency(store IdempotencyStore, lockTimeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				utils.WriteProblem(w, utils.Problem{
+					Status: http.StatusBadRequest,
+					Detail: fmt.Sprintf("%s must not exceed %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength),
+					Code:   "idempotency_key_invalid",
+				})
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, utils.MaxRequestBodyBytes))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					utils.HandleServiceError(w, r, &utils.AppError{
+						Kind:    utils.ErrPayloadTooLarge,
+						Code:    "request_too_large",
+						Message: fmt.Sprintf("request body must not exceed %d bytes", maxBytesErr.Limit),
+						Err:     err,
+					})
+					return
+				}
+				utils.HandleServiceError(w, r, utils.NewValidationError("invalid_body", "request body could not be read"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := r.Context()
+			scope := idempotencyScope(r)
+			hash := requestHash(r, body)
+
+			existing, err := store.Begin(ctx, scope, key, hash, lockTimeout)
+			if err != nil {
+				logging.FromContext(ctx).ErrorContext(ctx, "idempotency store failed", "error", err)
+				utils.HandleServiceError(w, r, err)
+				return
+			}
+
+			if existing != nil {
+				switch {
+				case existing.RequestHash != hash:
+					utils.WriteProblem(w, utils.Problem{
+						Status: http.StatusUnprocessableEntity,
+						Detail: IdempotencyKeyHeader + " has already been used for a different request",
+						Code:   "idempotency_key_reused",
+					})
+				case existing.StatusCode == 0:
+					utils.WriteProblem(w, utils.Problem{
+						Status: http.StatusConflict,
+						Detail: "a request with this " + IdempotencyKeyHeader + " is still being processed",
+						Code:   "idempotency_key_in_progress",
+					})
+				default:
+					replay(w, existing)
+				}
+				return
+			}
+
+			var buf bytes.Buffer
+			ww := chimw.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&buf)
+
+			// Store the outcome even if the client has gone away, otherwise
+			// the key would stay locked until lockTimeout.
+			storeCtx := context.WithoutCancel(ctx)
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				if err := store.Release(storeCtx, scope, key); err != nil {
+					logging.FromContext(ctx).ErrorContext(ctx, "idempotency key release failed", "error", err)
+				}
+			}()
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status >= http.StatusInternalServerError {
+				return
+			}
+
+			record := &models.IdempotencyRecord{
+				Scope:       scope,
+				Key:         key,
+				RequestHash: hash,
+				StatusCode:  status,
+				Headers:     make(map[string]string),
+				Body:        buf.Bytes(),
+			}
+			for _, name := range replayedHeaders {
+				if v := ww.Header().Get(name); v != "" {
+					record.Headers[name] = v
+				}
+			}
+
+			if err := store.Complete(storeCtx, record); err != nil {
+				logging.FromContext(ctx).ErrorContext(ctx, "idempotency key completion failed", "error", err)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+func replay(w http.ResponseWriter, record *models.IdempotencyRecord) {
+	for name, value := range record.Headers {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	_, _ = w.Write(record.Body)
+}
+
+// idempotencyScope keeps keys from different users apart, so one client
+// cannot read another's response by guessing its key.
+func idempotencyScope(r *http.Request) string {
+	if userID := r.Context().Value(utils.UserIDKey); userID != nil {
+		return "user:" + fmt.Sprint(userID)
+	}
+	return "ip:" + clientIP(r)
+}
+
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.RawQuery))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
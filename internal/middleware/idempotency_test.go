@@ -0,0 +1,182 @@
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/hms/internal/middleware"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+type fakeIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*models.IdempotencyRecord
+}
+
+func newFakeIdempotencyStore() *fakeIdempotencyStore {
+	return &fakeIdempotencyStore{records: make(map[string]*models.IdempotencyRecord)}
+}
+
+func (s *fakeIdempotencyStore) Begin(_ context.Context, scope, key, hash string, _ time.Duration) (*models.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rec, ok := s.records[scope+"|"+key]; ok {
+		copied := *rec
+		return &copied, nil
+	}
+	s.records[scope+"|"+key] = &models.IdempotencyRecord{Scope: scope, Key: key, RequestHash: hash}
+	return nil, nil
+}
+
+func (s *fakeIdempotencyStore) Complete(_ context.Context, rec *models.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[rec.Scope+"|"+rec.Key] = rec
+	return nil
+}
+
+func (s *fakeIdempotencyStore) Release(_ context.Context, scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rec, ok := s.records[scope+"|"+key]; ok && rec.StatusCode == 0 {
+		delete(s.records, scope+"|"+key)
+	}
+	return nil
+}
+
+func idempotentRequest(key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/appointments", strings.NewReader(body))
+	req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	return req.WithContext(context.WithValue(req.Context(), utils.UserIDKey, "user-1"))
+}
+
+func TestIdempotencyReplaysFirstResponse(t *testing.T) {
+	calls := 0
+	handler := middleware.Idempotency(newFakeIdempotencyStore(), time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Location", "/appointments/1")
+		utils.WriteJSON(w, http.StatusCreated, map[string]string{"echo": string(body), "call": fmt.Sprint(calls)})
+	}))
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, idempotentRequest("abc", `{"a":1}`))
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, idempotentRequest("abc", `{"a":1}`))
+
+	if calls != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("expected replay of %d %q, got %d %q", first.Code, first.Body.String(), second.Code, second.Body.String())
+	}
+	if second.Header().Get("Location") != "/appointments/1" || second.Header().Get(middleware.IdempotentReplayedHeader) != "true" {
+		t.Errorf("expected replayed headers, got %v", second.Header())
+	}
+	if first.Header().Get(middleware.IdempotentReplayedHeader) != "" {
+		t.Error("first response must not be marked as replayed")
+	}
+}
+
+func TestIdempotencyRejectsReusedKey(t *testing.T) {
+	handler := middleware.Idempotency(newFakeIdempotencyStore(), time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("abc", `{"a":1}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest("abc", `{"a":2}`))
+
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "idempotency_key_reused") {
+		t.Errorf("expected 422 idempotency_key_reused, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	store := newFakeIdempotencyStore()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := middleware.Idempotency(store, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("abc", `{}`))
+		close(done)
+	}()
+	<-started
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest("abc", `{}`))
+	close(release)
+	<-done
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 while first request is running, got %d", rec.Code)
+	}
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	calls := 0
+	handler := middleware.Idempotency(newFakeIdempotencyStore(), time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("abc", `{}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest("abc", `{}`))
+
+	if calls != 2 || rec.Code != http.StatusCreated {
+		t.Errorf("expected retry after a server error to run again, got %d calls and status %d", calls, rec.Code)
+	}
+}
+
+func TestIdempotencyKeysAreScopedPerUser(t *testing.T) {
+	calls := 0
+	handler := middleware.Idempotency(newFakeIdempotencyStore(), time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("abc", `{}`))
+	other := idempotentRequest("abc", `{}`)
+	other = other.WithContext(context.WithValue(other.Context(), utils.UserIDKey, "user-2"))
+	handler.ServeHTTP(httptest.NewRecorder(), other)
+
+	if calls != 2 {
+		t.Errorf("expected the same key from another user to run the handler, got %d calls", calls)
+	}
+}
+
+func TestIdempotencyIgnoresRequestsWithoutKey(t *testing.T) {
+	calls := 0
+	handler := middleware.Idempotency(newFakeIdempotencyStore(), time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	for range 2 {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/appointments", strings.NewReader(`{}`)))
+	}
+	if calls != 2 {
+		t.Errorf("expected both requests to reach the handler, got %d", calls)
+	}
+}
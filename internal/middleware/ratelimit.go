@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/falasefemi2/hms/internal/config"
+	"github.com/falasefemi2/hms/internal/logging"
+	"github.com/falasefemi2/hms/internal/ratelimit"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+// APIKeyHeader identifies API clients for policies keyed by API key.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeys marks requests whose X-API-Key is one of keys, so policies keyed
+// by API key can count them per key. Any other key is ignored rather than
+// rejected: the request is limited by IP like one without a key, and a
+// client cannot get a fresh bucket by sending a new made-up key.
+func APIKeys(keys []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" && knownAPIKey(apiKey, keys) {
+				sum := sha256.Sum256([]byte(apiKey))
+				ctx := context.WithValue(r.Context(), utils.APIKeyIDKey, hex.EncodeToString(sum[:8]))
+				r = r.WithContext(ctx)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func knownAPIKey(apiKey string, keys []string) bool {
+	known := false
+	for _, key := range keys {
+		if subtle.ConstantTimeCompare([]byte(apiKey), []byte(key)) == 1 {
+			known = true
+		}
+	}
+	return known
+}
+
+// RateLimit limits requests under the named policy. Every response carries
+// RateLimit-Policy, RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers, and rejected requests get 429 with Retry-After. If the store
+// fails the request is let through, since an unavailable limiter should not
+// take the API down with it.
+func RateLimit(store ratelimit.Store, name string, policy config.RateLimitPolicy) func(http.Handler) http.Handler {
+	p := ratelimit.Policy{Limit: policy.Limit, Period: policy.Period}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := name + ":" + rateLimitKey(r, policy.KeyBy)
+
+			result, err := store.Take(r.Context(), key, p)
+			if err != nil {
+				logging.FromContext(r.Context()).ErrorContext(r.Context(), "rate limit store failed", "policy", name, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Policy", p.String())
+			h.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			h.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+
+			if !result.Allowed {
+				h.Set("Retry-After", ceilSeconds(result.RetryAfter))
+				utils.WriteError(w, http.StatusTooManyRequests, "rate limit exceeded, retry later")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitWrites applies RateLimit to unsafe methods only, so reads under
+// the same routes are not counted.
+func RateLimitWrites(store ratelimit.Store, name string, policy config.RateLimitPolicy) func(http.Handler) http.Handler {
+	limit := RateLimit(store, name, policy)
+
+	return func(next http.Handler) http.Handler {
+		limited := limit(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+			default:
+				limited.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// rateLimitKey identifies the client for keyBy. User and API key policies
+// fall back to the client IP for requests with no authenticated user or
+// known API key.
+func rateLimitKey(r *http.Request, keyBy string) string {
+	switch keyBy {
+	case config.RateLimitByUser:
+		if userID := r.Context().Value(utils.UserIDKey); userID != nil {
+			return "user:" + fmt.Sprint(userID)
+		}
+	case config.RateLimitByAPIKey:
+		if keyID, ok := r.Context().Value(utils.APIKeyIDKey).(string); ok {
+			return "key:" + keyID
+		}
+	}
+
+	return "ip:" + clientIP(r)
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
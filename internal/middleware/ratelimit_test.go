@@ -0,0 +1,82 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/hms/internal/config"
+	"github.com/falasefemi2/hms/internal/middleware"
+	"github.com/falasefemi2/hms/internal/ratelimit"
+)
+
+// limited builds the middleware chain the server uses, allowing one request
+// per hour under keyBy, behind a proxy at 10.0.0.1.
+func limited(keyBy string) http.Handler {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.1/32")}
+	policy := config.RateLimitPolicy{Limit: 1, Period: time.Hour, KeyBy: keyBy}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return middleware.RealIP(trusted)(
+		middleware.APIKeys([]string{"known-key"})(
+			middleware.RateLimit(ratelimit.NewMemoryStore(), "test", policy)(ok)))
+}
+
+func send(h http.Handler, remoteAddr string, headers map[string]string) int {
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestRateLimitIgnoresSpoofedForwardingHeaders(t *testing.T) {
+	h := limited(config.RateLimitByIP)
+
+	if code := send(h, "203.0.113.5:4000", map[string]string{"X-Forwarded-For": "198.51.100.1"}); code != http.StatusOK {
+		t.Fatalf("expected first request allowed, got %d", code)
+	}
+	for _, header := range []string{"X-Forwarded-For", "X-Real-IP", "True-Client-IP"} {
+		code := send(h, "203.0.113.5:4001", map[string]string{header: "198.51.100.2"})
+		if code != http.StatusTooManyRequests {
+			t.Errorf("expected a spoofed %s not to reset the bucket, got %d", header, code)
+		}
+	}
+}
+
+func TestRateLimitTrustsForwardingHeadersFromProxies(t *testing.T) {
+	h := limited(config.RateLimitByIP)
+
+	// The proxy appends the address it saw; anything before it came from
+	// the client and is not believed.
+	if code := send(h, "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "192.0.2.9, 198.51.100.1"}); code != http.StatusOK {
+		t.Fatalf("expected first client allowed, got %d", code)
+	}
+	if code := send(h, "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "192.0.2.10, 198.51.100.1"}); code != http.StatusTooManyRequests {
+		t.Errorf("expected a spoofed leading address not to reset the bucket, got %d", code)
+	}
+	if code := send(h, "10.0.0.1:4000", map[string]string{"X-Forwarded-For": "198.51.100.2"}); code != http.StatusOK {
+		t.Errorf("expected another client behind the proxy allowed, got %d", code)
+	}
+}
+
+func TestRateLimitKeysOnlyKnownAPIKeys(t *testing.T) {
+	h := limited(config.RateLimitByAPIKey)
+
+	if code := send(h, "203.0.113.5:4000", map[string]string{middleware.APIKeyHeader: "made-up-1"}); code != http.StatusOK {
+		t.Fatalf("expected first request allowed, got %d", code)
+	}
+	if code := send(h, "203.0.113.5:4000", map[string]string{middleware.APIKeyHeader: "made-up-2"}); code != http.StatusTooManyRequests {
+		t.Errorf("expected an unknown key limited by IP, got %d", code)
+	}
+	if code := send(h, "203.0.113.5:4000", map[string]string{middleware.APIKeyHeader: "known-key"}); code != http.StatusOK {
+		t.Errorf("expected a known key to get its own bucket, got %d", code)
+	}
+}
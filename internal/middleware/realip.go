@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP replaces RemoteAddr with the client address reported in
+// X-Forwarded-For or X-Real-IP, but only when the request comes straight
+// from one of the trusted proxies. Anyone else could put any address there,
+// so their headers are ignored and RemoteAddr stays the peer address.
+//
+// X-Forwarded-For is read from the right, skipping trusted proxies, so an
+// address the client prepended itself is never used.
+func RealIP(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if peer, ok := parseAddr(clientIP(r)); ok && isTrusted(peer, trusted) {
+				if ip, ok := forwardedFor(r, trusted); ok {
+					r.RemoteAddr = ip.String()
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedFor returns the nearest untrusted address in X-Forwarded-For,
+// falling back to X-Real-IP when there is none.
+func forwardedFor(r *http.Request, trusted []netip.Prefix) (netip.Addr, bool) {
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip, ok := parseAddr(strings.TrimSpace(hops[i]))
+		if !ok {
+			break
+		}
+		if !isTrusted(ip, trusted) {
+			return ip, true
+		}
+	}
+
+	return parseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+}
+
+func isTrusted(ip netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseAddr(s string) (netip.Addr, bool) {
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
+
+// clientIP returns the client address without its port. RealIP has already
+// replaced RemoteAddr if the request came through a trusted proxy.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
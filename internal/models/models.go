@@ -27,6 +27,7 @@ type Department struct {
 	IsActive    bool
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	Version     int
 }
 
 type Doctor struct {
@@ -41,6 +42,33 @@ type Doctor struct {
 	UpdatedAt       time.Time
 }
 
+// DoctorListing is a doctor as shown in the directory. NextAvailableSlot is
+// nil when nothing is free within the booking horizon.
+type DoctorListing struct {
+	Doctor            Doctor
+	FirstName         string
+	LastName          string
+	DepartmentName    string
+	NextAvailableSlot *time.Time
+}
+
+// DoctorProfile is a doctor together with their user account, as seen by
+// the doctor themselves.
+type DoctorProfile struct {
+	Doctor Doctor
+	User   User
+}
+
+// DoctorDeactivation describes a doctor taken off the roster. Their upcoming
+// appointments were either moved to ReassignedTo or, when it is nil,
+// cancelled.
+type DoctorDeactivation struct {
+	Doctor                 Doctor
+	ReassignedTo           *uuid.UUID
+	AppointmentsReassigned int64
+	AppointmentsCancelled  int64
+}
+
 type Nurse struct {
 	NurseID       uuid.UUID
 	UserID        uuid.UUID
@@ -51,9 +79,17 @@ type Nurse struct {
 	UpdatedAt     time.Time
 }
 
+// NurseProfile is a nurse together with their user account, as seen by the
+// nurse themselves.
+type NurseProfile struct {
+	Nurse Nurse
+	User  User
+}
+
 type Patient struct {
 	PatientID             uuid.UUID
 	UserID                uuid.UUID
+	MRN                   string
 	DateOfBirth           time.Time
 	Gender                string
 	BloodGroup            string
@@ -64,6 +100,36 @@ type Patient struct {
 	UpdatedAt             time.Time
 }
 
+// PatientMatch is a patient found by search, with the user details searched
+// on and how well they matched.
+type PatientMatch struct {
+	Patient   Patient
+	FirstName string
+	LastName  string
+	Email     string
+	Phone     string
+	Score     float64
+}
+
+// DuplicateCandidate is a patient that may be the same person as another.
+// MatchedOn lists which of "name", "date_of_birth" and "phone" agree; Score
+// is the name similarity, or zero when the names do not match.
+type DuplicateCandidate struct {
+	PatientMatch
+	MatchedOn []string
+}
+
+// PatientMerge describes a duplicate patient folded into a survivor.
+// RecordsMoved counts the rows moved per table and FilledFields names the
+// survivor's fields completed from the duplicate.
+type PatientMerge struct {
+	Survivor        Patient
+	MergedPatientID uuid.UUID
+	MergedMRN       string
+	RecordsMoved    map[string]int64
+	FilledFields    []string
+}
+
 type Availability struct {
 	AvailabilityID uuid.UUID
 	DoctorID       uuid.UUID
@@ -75,15 +141,22 @@ type Availability struct {
 	UpdatedAt      time.Time
 }
 
+// HospitalConfig is one revision of the hospital-wide settings. A change
+// adds a revision instead of editing one, and the revision in force is the
+// newest whose EffectiveFrom has passed.
 type HospitalConfig struct {
 	ConfigID                      uuid.UUID
+	Revision                      int
 	WorkingHoursStart             string
 	WorkingHoursEnd               string
 	AppointmentDurationMinutes    int
 	MaxSameDayCancellationHours   int
 	EnablePatientSelfRegistration bool
+	EffectiveFrom                 time.Time
+	CreatedBy                     *uuid.UUID
+	RolledBackFrom                *int // the revision this one restored, if any
+	IsActive                      bool // in force now; worked out when read, not stored
 	CreatedAt                     time.Time
-	UpdatedAt                     time.Time
 }
 
 type Appointment struct {
@@ -96,6 +169,7 @@ type Appointment struct {
 	Notes           string
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
+	Version         int
 }
 
 type Consultation struct {
@@ -107,4 +181,123 @@ type Consultation struct {
 	Notes          string
 	CreatedAt      time.Time
 	IsEditable     bool
+	Version        int
+}
+
+// Prescription is a medication ordered during a consultation. DurationDays
+// is nil for open-ended courses.
+type Prescription struct {
+	PrescriptionID uuid.UUID
+	ConsultationID uuid.UUID
+	PatientID      uuid.UUID
+	DoctorID       uuid.UUID
+	MedicationName string
+	Dosage         string
+	Frequency      string
+	DurationDays   *int
+	Instructions   string
+	CreatedAt      time.Time
+	IsImmutable    bool
+}
+
+// LabTest is a test a doctor requested for a patient. CompletedAt is set
+// once Status is COMPLETED.
+type LabTest struct {
+	TestID         uuid.UUID
+	PatientID      uuid.UUID
+	DoctorID       uuid.UUID
+	TestName       string
+	TestType       string
+	Status         string
+	ResultFilePath string
+	Notes          string
+	RequestedAt    time.Time
+	CompletedAt    *time.Time
+}
+
+// IdempotencyRecord is the stored outcome of a request sent with an
+// Idempotency-Key. StatusCode is zero while the first request is in flight.
+type IdempotencyRecord struct {
+	Scope       string
+	Key         string
+	RequestHash string
+	StatusCode  int
+	Headers     map[string]string
+	Body        []byte
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// AuditLog records who did what to which resource. Changes is a JSON
+// document whose shape depends on Action.
+type AuditLog struct {
+	LogID        uuid.UUID
+	UserID       *uuid.UUID
+	Action       string
+	ResourceType string
+	ResourceID   uuid.UUID
+	Changes      string
+	IPAddress    string
+	Timestamp    time.Time
+}
+
+// Invitation is a one-time link for a user to set their password. Only the
+// SHA-256 hash of the token is kept; UsedAt is set once it has been redeemed.
+type Invitation struct {
+	InvitationID uuid.UUID
+	UserID       uuid.UUID
+	TokenHash    string
+	ExpiresAt    time.Time
+	UsedAt       *time.Time
+	CreatedAt    time.Time
+}
+
+// StaffOnboarding is a new doctor or nurse created in one step: their user
+// account, exactly one of Doctor and Nurse, and for doctors their weekly
+// availability.
+type StaffOnboarding struct {
+	User         User
+	Doctor       *Doctor
+	Nurse        *Nurse
+	Availability []*Availability
+	Invitation   IssuedInvitation
+}
+
+// IssuedInvitation is an invitation just issued to a user. Link is set only
+// when the invitation could not be sent, so that the admin can pass it on;
+// it is never stored.
+type IssuedInvitation struct {
+	ExpiresAt time.Time
+	Sent      bool
+	Link      string
+}
+
+// PatientProfile is a patient together with their user account, as seen by
+// the patient themselves.
+type PatientProfile struct {
+	Patient Patient
+	User    User
+}
+
+// PatientRecord is everything the hospital holds about a patient that they
+// may download themselves. LabResults only has completed tests.
+type PatientRecord struct {
+	Profile       PatientProfile
+	Appointments  []*Appointment
+	Consultations []*Consultation
+	Prescriptions []*Prescription
+	LabResults    []*LabTest
+	GeneratedAt   time.Time
+}
+
+// PatientInvite is a one-time code that lets a patient sign up while
+// self-registration is closed. Only the SHA-256 hash of the code is kept.
+type PatientInvite struct {
+	InviteID  uuid.UUID
+	CodeHash  string
+	CreatedBy *uuid.UUID
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	UsedBy    *uuid.UUID
+	CreatedAt time.Time
 }
@@ -0,0 +1,120 @@
+// Package mrn formats medical record numbers (MRNs), the human-readable
+// identifiers printed on patient wristbands and folders.
+//
+// A format is literal text with placeholders:
+//
+//	{YYYY}   four-digit year of registration
+//	{YY}     two-digit year
+//	{MM}     two-digit month
+//	{SEQ:n}  the next number of a hospital-wide sequence, zero-padded to n
+//	         digits (1-12)
+//
+// For example "MRN-{YYYY}-{SEQ:6}" gives MRN-2026-000042. The format must
+// contain exactly one {SEQ:n} so that every MRN is unique.
+package mrn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultFormat = "MRN-{YYYY}-{SEQ:6}"
+
+// maxLength is the width of the patients.mrn column.
+const maxLength = 32
+
+type part struct {
+	literal string
+	token   string
+	width   int
+}
+
+// Format is a parsed MRN format.
+type Format struct {
+	source string
+	parts  []part
+}
+
+// Parse validates format.
+func Parse(format string) (Format, error) {
+	f := Format{source: format}
+	seqs := 0
+
+	rest := format
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			f.parts = append(f.parts, part{literal: rest})
+			break
+		}
+		if open > 0 {
+			f.parts = append(f.parts, part{literal: rest[:open]})
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return Format{}, fmt.Errorf("mrn format %q: unclosed {", format)
+		}
+		token := rest[open+1 : open+end]
+		rest = rest[open+end+1:]
+
+		switch {
+		case token == "YYYY" || token == "YY" || token == "MM":
+			f.parts = append(f.parts, part{token: token})
+		case strings.HasPrefix(token, "SEQ:"):
+			width, err := strconv.Atoi(strings.TrimPrefix(token, "SEQ:"))
+			if err != nil || width < 1 || width > 12 {
+				return Format{}, fmt.Errorf("mrn format %q: {%s} needs a width from 1 to 12", format, token)
+			}
+			f.parts = append(f.parts, part{token: "SEQ", width: width})
+			seqs++
+		default:
+			return Format{}, fmt.Errorf("mrn format %q: unknown placeholder {%s}", format, token)
+		}
+	}
+
+	if seqs != 1 {
+		return Format{}, fmt.Errorf("mrn format %q must contain exactly one {SEQ:n}", format)
+	}
+	if n := len(f.Generate(0, time.Time{})); n > maxLength {
+		return Format{}, fmt.Errorf("mrn format %q gives %d characters, more than %d", format, n, maxLength)
+	}
+	return f, nil
+}
+
+// MustParse is like Parse but panics on error. It is meant for formats fixed
+// at compile time.
+func MustParse(format string) Format {
+	f, err := Parse(format)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Generate returns the MRN for sequence number seq issued at t. A sequence
+// number wider than the {SEQ:n} padding is written in full rather than
+// truncated.
+func (f Format) Generate(seq int64, t time.Time) string {
+	var b strings.Builder
+	for _, p := range f.parts {
+		switch p.token {
+		case "":
+			b.WriteString(p.literal)
+		case "YYYY":
+			fmt.Fprintf(&b, "%04d", t.Year())
+		case "YY":
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+		case "MM":
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case "SEQ":
+			fmt.Fprintf(&b, "%0*d", p.width, seq)
+		}
+	}
+	return b.String()
+}
+
+func (f Format) String() string {
+	return f.source
+}
@@ -0,0 +1,49 @@
+package mrn_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/hms/internal/mrn"
+)
+
+func TestGenerate(t *testing.T) {
+	at := time.Date(2026, time.March, 9, 10, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		format string
+		seq    int64
+		want   string
+	}{
+		{mrn.DefaultFormat, 42, "MRN-2026-000042"},
+		{"H{YY}{MM}-{SEQ:4}", 7, "H2603-0007"},
+		{"{SEQ:8}", 123, "00000123"},
+		{"P{SEQ:3}", 12345, "P12345"},
+	} {
+		f, err := mrn.Parse(tc.format)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.format, err)
+		}
+		if got := f.Generate(tc.seq, at); got != tc.want {
+			t.Errorf("%q.Generate(%d) = %q, want %q", tc.format, tc.seq, got, tc.want)
+		}
+	}
+}
+
+func TestParseRejectsBadFormats(t *testing.T) {
+	for name, format := range map[string]string{
+		"no sequence":        "MRN-{YYYY}",
+		"two sequences":      "{SEQ:4}-{SEQ:4}",
+		"unknown token":      "{DD}-{SEQ:4}",
+		"unclosed brace":     "MRN-{SEQ:4",
+		"missing width":      "MRN-{SEQ}",
+		"width out of range": "MRN-{SEQ:13}",
+		"too long":           "MEDICAL-RECORD-NUMBER-{YYYY}-{SEQ:12}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := mrn.Parse(format); err == nil {
+				t.Fatalf("expected %q to be rejected", format)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package ratelimit_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/hms/internal/ratelimit"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	ctx := context.Background()
+	store := ratelimit.NewMemoryStore()
+	policy := ratelimit.Policy{Limit: 3, Period: 300 * time.Millisecond}
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(ctx, "login:ip:10.0.0.1", policy)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.Allowed || result.Remaining != i || result.Limit != 3 {
+			t.Fatalf("expected allowed with %d remaining, got %+v", i, result)
+		}
+	}
+
+	denied, err := store.Take(ctx, "login:ip:10.0.0.1", policy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if denied.Allowed {
+		t.Fatal("expected fourth request to be denied")
+	}
+	if denied.RetryAfter <= 0 || denied.RetryAfter > 100*time.Millisecond {
+		t.Errorf("expected retry after at most one refill interval, got %v", denied.RetryAfter)
+	}
+
+	other, _ := store.Take(ctx, "login:ip:10.0.0.2", policy)
+	if !other.Allowed {
+		t.Error("expected a different client to have its own bucket")
+	}
+
+	time.Sleep(denied.RetryAfter + 10*time.Millisecond)
+	if result, _ := store.Take(ctx, "login:ip:10.0.0.1", policy); !result.Allowed {
+		t.Errorf("expected a token to have refilled, got %+v", result)
+	}
+}
+
+func TestMemoryStoreConcurrentTake(t *testing.T) {
+	ctx := context.Background()
+	store := ratelimit.NewMemoryStore()
+	policy := ratelimit.Policy{Limit: 10, Period: time.Hour}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, _ := store.Take(ctx, "write:user:1", policy)
+			if result.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 10 {
+		t.Errorf("expected exactly 10 requests allowed, got %d", allowed)
+	}
+}
+
+func TestPolicyString(t *testing.T) {
+	p := ratelimit.Policy{Limit: 10, Period: time.Minute}
+	if got := p.String(); got != "10;w=60" {
+		t.Errorf("expected 10;w=60, got %q", got)
+	}
+}
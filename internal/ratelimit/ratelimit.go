@@ -0,0 +1,75 @@
+// Package ratelimit implements token-bucket rate limiting behind a pluggable
+// Store so buckets can live in memory or in a shared backend.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Policy allows Limit requests per Period. Tokens refill continuously, so a
+// client that has used its whole allowance gets one request back every
+// Period/Limit.
+type Policy struct {
+	Limit  int
+	Period time.Duration
+}
+
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// String formats p as a RateLimit-Policy header value, e.g. "10;w=60".
+func (p Policy) String() string {
+	return fmt.Sprintf("%d;w=%d", p.Limit, int(math.Ceil(p.Period.Seconds())))
+}
+
+// Result describes the state of a bucket after a Take.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next request is allowed; zero when Allowed
+}
+
+// Store takes one token from the bucket identified by key.
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// bucket is the token-bucket state shared by Store implementations.
+type bucket struct {
+	tokens float64
+	last   time.Time
+	period time.Duration
+}
+
+// take refills b for the time elapsed since its last use and tries to
+// consume one token.
+func (b *bucket) take(now time.Time, policy Policy) Result {
+	capacity := float64(policy.Limit)
+	rate := policy.rate()
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	b.period = policy.Period
+
+	result := Result{Limit: policy.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -2,11 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
 )
 
@@ -30,9 +33,9 @@ func (r *AppointmentRepository) Create(ctx context.Context, appointment *models.
 	query := `
     INSERT INTO appointments (appointment_id, patient_id, doctor_id, appointment_date, duration_minutes, status, notes)
     VALUES ($1, $2, $3, $4, $5, $6, $7)
-    RETURNING created_at, updated_at
+    RETURNING created_at, updated_at, version
 `
-	err := r.pool.QueryRow(ctx, query,
+	err := querier(ctx, r.pool).QueryRow(ctx, query,
 		appointment.AppointmentID,
 		appointment.PatientID,
 		appointment.DoctorID,
@@ -40,10 +43,10 @@ func (r *AppointmentRepository) Create(ctx context.Context, appointment *models.
 		appointment.DurationMinutes,
 		appointment.Status,
 		appointment.Notes,
-	).Scan(&appointment.CreatedAt, &appointment.UpdatedAt)
+	).Scan(&appointment.CreatedAt, &appointment.UpdatedAt, &appointment.Version)
 
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "appointment")
 	}
 
 	return appointment, nil
@@ -57,13 +60,13 @@ func (r *AppointmentRepository) GetByID(ctx context.Context, appointmentID uuid.
 	}
 
 	query := `
-		SELECT appointment_id, patient_id, doctor_id, appointment_date, duration_minutes, status, notes, created_at, updated_at
+		SELECT appointment_id, patient_id, doctor_id, appointment_date, duration_minutes, status, notes, created_at, updated_at, version
 		FROM appointments
 		WHERE appointment_id = $1
 	`
 
 	var appointment models.Appointment
-	err := r.pool.QueryRow(ctx, query, appointmentID).Scan(
+	err := querier(ctx, r.pool).QueryRow(ctx, query, appointmentID).Scan(
 		&appointment.AppointmentID,
 		&appointment.PatientID,
 		&appointment.DoctorID,
@@ -73,9 +76,10 @@ func (r *AppointmentRepository) GetByID(ctx context.Context, appointmentID uuid.
 		&appointment.Notes,
 		&appointment.CreatedAt,
 		&appointment.UpdatedAt,
+		&appointment.Version,
 	)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "appointment")
 	}
 
 	return &appointment, nil
@@ -89,15 +93,15 @@ func (r *AppointmentRepository) GetByPatientID(ctx context.Context, patientID uu
 	}
 
 	query := `
-		SELECT appointment_id, patient_id, doctor_id, appointment_date, duration_minutes, status, notes, created_at, updated_at
+		SELECT appointment_id, patient_id, doctor_id, appointment_date, duration_minutes, status, notes, created_at, updated_at, version
 		FROM appointments
 		WHERE patient_id = $1
 		ORDER BY appointment_date DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, patientID)
+	rows, err := querier(ctx, r.pool).Query(ctx, query, patientID)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "appointment")
 	}
 	defer rows.Close()
 
@@ -114,6 +118,7 @@ func (r *AppointmentRepository) GetByPatientID(ctx context.Context, patientID uu
 			&appointment.Notes,
 			&appointment.CreatedAt,
 			&appointment.UpdatedAt,
+			&appointment.Version,
 		)
 		if err != nil {
 			return nil, err
@@ -132,15 +137,15 @@ func (r *AppointmentRepository) GetByDoctorID(ctx context.Context, doctorID uuid
 	}
 
 	query := `
-		SELECT appointment_id, patient_id, doctor_id, appointment_date, duration_minutes, status, notes, created_at, updated_at
+		SELECT appointment_id, patient_id, doctor_id, appointment_date, duration_minutes, status, notes, created_at, updated_at, version
 		FROM appointments
 		WHERE doctor_id = $1
 		ORDER BY appointment_date DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, doctorID)
+	rows, err := querier(ctx, r.pool).Query(ctx, query, doctorID)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "appointment")
 	}
 	defer rows.Close()
 
@@ -157,6 +162,7 @@ func (r *AppointmentRepository) GetByDoctorID(ctx context.Context, doctorID uuid
 			&appointment.Notes,
 			&appointment.CreatedAt,
 			&appointment.UpdatedAt,
+			&appointment.Version,
 		)
 		if err != nil {
 			return nil, err
@@ -167,6 +173,103 @@ func (r *AppointmentRepository) GetByDoctorID(ctx context.Context, doctorID uuid
 	return appointments, nil
 }
 
+// ListBooked returns the pending and confirmed appointments of the given
+// doctors starting in [from, to), earliest first.
+func (r *AppointmentRepository) ListBooked(ctx context.Context, doctorIDs []uuid.UUID, from, to time.Time) ([]*models.Appointment, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT appointment_id, patient_id, doctor_id, appointment_date, COALESCE(duration_minutes, 30), status, COALESCE(notes, ''), created_at, updated_at, version
+		FROM appointments
+		WHERE doctor_id = ANY($1)
+		  AND appointment_date >= $2 AND appointment_date < $3
+		  AND status IN ('PENDING', 'CONFIRMED')
+		ORDER BY appointment_date
+	`
+
+	rows, err := querier(ctx, r.pool).Query(ctx, query, doctorIDs, from, to)
+	if err != nil {
+		return nil, TranslateError(err, "appointment")
+	}
+	defer rows.Close()
+
+	var appointments []*models.Appointment
+	for rows.Next() {
+		var appointment models.Appointment
+		err := rows.Scan(
+			&appointment.AppointmentID,
+			&appointment.PatientID,
+			&appointment.DoctorID,
+			&appointment.AppointmentDate,
+			&appointment.DurationMinutes,
+			&appointment.Status,
+			&appointment.Notes,
+			&appointment.CreatedAt,
+			&appointment.UpdatedAt,
+			&appointment.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, &appointment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err, "appointment")
+	}
+
+	return appointments, nil
+}
+
+// ReassignDoctor moves the pending and confirmed appointments of doctor from
+// starting after after to doctor to, and returns how many moved.
+func (r *AppointmentRepository) ReassignDoctor(ctx context.Context, from, to uuid.UUID, after time.Time) (int64, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		UPDATE appointments
+		SET doctor_id = $2, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE doctor_id = $1 AND appointment_date > $3 AND status IN ('PENDING', 'CONFIRMED')
+	`
+
+	result, err := querier(ctx, r.pool).Exec(ctx, query, from, to, after)
+	if err != nil {
+		return 0, TranslateError(err, "appointment")
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// CancelUpcoming cancels the pending and confirmed appointments of doctorID
+// starting after after, and returns how many were cancelled.
+func (r *AppointmentRepository) CancelUpcoming(ctx context.Context, doctorID uuid.UUID, after time.Time) (int64, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		UPDATE appointments
+		SET status = 'CANCELLED', updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE doctor_id = $1 AND appointment_date > $2 AND status IN ('PENDING', 'CONFIRMED')
+	`
+
+	result, err := querier(ctx, r.pool).Exec(ctx, query, doctorID, after)
+	if err != nil {
+		return 0, TranslateError(err, "appointment")
+	}
+
+	return result.RowsAffected(), nil
+}
+
 func (r *AppointmentRepository) Update(ctx context.Context, appointment *models.Appointment) (*models.Appointment, error) {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
@@ -174,25 +277,43 @@ func (r *AppointmentRepository) Update(ctx context.Context, appointment *models.
 		defer cancel()
 	}
 
+	// appointment.Version is the version the caller read; the update only
+	// applies if nobody has changed the row since.
 	query := `
     UPDATE appointments
-    SET appointment_date = $2, duration_minutes = $3, status = $4, notes = $5, updated_at = CURRENT_TIMESTAMP
-    WHERE appointment_id = $1
-    RETURNING updated_at
+    SET appointment_date = $2, duration_minutes = $3, status = $4, notes = $5, updated_at = CURRENT_TIMESTAMP, version = version + 1
+    WHERE appointment_id = $1 AND version = $6
+    RETURNING appointment_id, patient_id, doctor_id, appointment_date, duration_minutes, status, notes, created_at, updated_at, version
 `
-	err := r.pool.QueryRow(ctx, query,
+	var updated models.Appointment
+	err := querier(ctx, r.pool).QueryRow(ctx, query,
 		appointment.AppointmentID,
 		appointment.AppointmentDate,
 		appointment.DurationMinutes,
 		appointment.Status,
 		appointment.Notes,
-	).Scan(&appointment.UpdatedAt)
+		appointment.Version,
+	).Scan(
+		&updated.AppointmentID,
+		&updated.PatientID,
+		&updated.DoctorID,
+		&updated.AppointmentDate,
+		&updated.DurationMinutes,
+		&updated.Status,
+		&updated.Notes,
+		&updated.CreatedAt,
+		&updated.UpdatedAt,
+		&updated.Version,
+	)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.missingOrStale(ctx, appointment.AppointmentID)
+	}
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "appointment")
 	}
 
-	return appointment, nil
+	return &updated, nil
 }
 
 func (r *AppointmentRepository) Delete(ctx context.Context, appointmentID uuid.UUID) error {
@@ -204,6 +325,53 @@ func (r *AppointmentRepository) Delete(ctx context.Context, appointmentID uuid.U
 
 	query := `DELETE FROM appointments WHERE appointment_id = $1`
 
-	_, err := r.pool.Exec(ctx, query, appointmentID)
-	return err
+	result, err := querier(ctx, r.pool).Exec(ctx, query, appointmentID)
+	if err != nil {
+		return TranslateError(err, "appointment")
+	}
+
+	if result.RowsAffected() == 0 {
+		return TranslateError(pgx.ErrNoRows, "appointment")
+	}
+
+	return nil
+}
+
+// missingOrStale explains why a versioned update matched no row.
+func (r *AppointmentRepository) missingOrStale(ctx context.Context, appointmentID uuid.UUID) error {
+	if _, err := r.GetByID(ctx, appointmentID); err != nil {
+		return err
+	}
+	return VersionConflict("appointment")
+}
+
+// List returns one page of appointments matching q.
+func (r *AppointmentRepository) List(ctx context.Context, q listquery.Query[*models.Appointment]) (*listquery.Page[*models.Appointment], error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT appointment_id, patient_id, doctor_id, appointment_date, duration_minutes, status, notes, created_at, updated_at, version
+		FROM appointments
+	`
+
+	return list(ctx, querier(ctx, r.pool), query, q, "appointment", func(rows pgx.Rows) (*models.Appointment, error) {
+		var appointment models.Appointment
+		err := rows.Scan(
+			&appointment.AppointmentID,
+			&appointment.PatientID,
+			&appointment.DoctorID,
+			&appointment.AppointmentDate,
+			&appointment.DurationMinutes,
+			&appointment.Status,
+			&appointment.Notes,
+			&appointment.CreatedAt,
+			&appointment.UpdatedAt,
+			&appointment.Version,
+		)
+		return &appointment, err
+	})
 }
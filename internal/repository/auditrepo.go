@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type AuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{
+		pool: pool,
+	}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, entry *models.AuditLog) (*models.AuditLog, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		INSERT INTO audit_logs (log_id, user_id, action, resource_type, resource_id, changes, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+		RETURNING timestamp
+	`
+	err := querier(ctx, r.pool).QueryRow(ctx, query,
+		entry.LogID,
+		entry.UserID,
+		entry.Action,
+		entry.ResourceType,
+		entry.ResourceID,
+		entry.Changes,
+		entry.IPAddress,
+	).Scan(&entry.Timestamp)
+	if err != nil {
+		return nil, TranslateError(err, "audit log")
+	}
+
+	return entry, nil
+}
+
+// ListByResource returns the audit trail of one resource, oldest first.
+func (r *AuditRepository) ListByResource(ctx context.Context, resourceType string, resourceID uuid.UUID) ([]*models.AuditLog, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT log_id, user_id, action, resource_type, resource_id, COALESCE(changes, ''), COALESCE(ip_address, ''), timestamp
+		FROM audit_logs
+		WHERE resource_type = $1 AND resource_id = $2
+		ORDER BY timestamp, log_id
+	`
+
+	rows, err := querier(ctx, r.pool).Query(ctx, query, resourceType, resourceID)
+	if err != nil {
+		return nil, TranslateError(err, "audit log")
+	}
+
+	defer rows.Close()
+
+	var entries []*models.AuditLog
+	for rows.Next() {
+		var entry models.AuditLog
+		err := rows.Scan(
+			&entry.LogID,
+			&entry.UserID,
+			&entry.Action,
+			&entry.ResourceType,
+			&entry.ResourceID,
+			&entry.Changes,
+			&entry.IPAddress,
+			&entry.Timestamp,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err, "audit log")
+	}
+
+	return entries, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/falasefemi2/hms/internal/models"
@@ -30,7 +31,7 @@ func (a *AvailabilityRepository) CreateAvailability(ctx context.Context, availab
 	VALUES ($1, $2, $3, $4::TIME, $5::TIME, $6)
 	RETURNING created_at, updated_at
 	`
-	err := a.pool.QueryRow(ctx, query,
+	err := querier(ctx, a.pool).QueryRow(ctx, query,
 		availability.AvailabilityID,
 		availability.DoctorID,
 		availability.DayOfWeek,
@@ -39,7 +40,56 @@ func (a *AvailabilityRepository) CreateAvailability(ctx context.Context, availab
 		availability.MaxAppointment,
 	).Scan(&availability.CreatedAt, &availability.UpdatedAt)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "availability")
 	}
 	return availability, nil
 }
+
+// ListByDoctorIDs returns the weekly availability of the given doctors, with
+// times formatted as HH:MM.
+func (a *AvailabilityRepository) ListByDoctorIDs(ctx context.Context, doctorIDs []uuid.UUID) ([]*models.Availability, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT availability_id, doctor_id, COALESCE(day_of_week, ''),
+		       COALESCE(to_char(start_time, 'HH24:MI'), ''), COALESCE(to_char(end_time, 'HH24:MI'), ''),
+		       COALESCE(max_appointments, 0), created_at, updated_at
+		FROM doctor_availability
+		WHERE doctor_id = ANY($1)
+		ORDER BY doctor_id, start_time
+	`
+
+	rows, err := querier(ctx, a.pool).Query(ctx, query, doctorIDs)
+	if err != nil {
+		return nil, TranslateError(err, "availability")
+	}
+	defer rows.Close()
+
+	var availability []*models.Availability
+	for rows.Next() {
+		var slot models.Availability
+		err := rows.Scan(
+			&slot.AvailabilityID,
+			&slot.DoctorID,
+			&slot.DayOfWeek,
+			&slot.StartTime,
+			&slot.EndTime,
+			&slot.MaxAppointment,
+			&slot.CreatedAt,
+			&slot.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		availability = append(availability, &slot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err, "availability")
+	}
+
+	return availability, nil
+}
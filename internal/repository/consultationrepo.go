@@ -2,11 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
 )
 
@@ -30,9 +33,9 @@ func (r *ConsultationRepository) Create(ctx context.Context, consultation *model
 	query := `
     INSERT INTO consultations (consultation_id, appointment_id, patient_id, doctor_id, diagnosis, notes, is_editable)
     VALUES ($1, $2, $3, $4, $5, $6, $7)
-    RETURNING created_at
+    RETURNING created_at, version
 `
-	err := r.pool.QueryRow(ctx, query,
+	err := querier(ctx, r.pool).QueryRow(ctx, query,
 		consultation.ConsultationID,
 		consultation.AppointmentID,
 		consultation.PatientID,
@@ -40,10 +43,10 @@ func (r *ConsultationRepository) Create(ctx context.Context, consultation *model
 		consultation.Diagnosis,
 		consultation.Notes,
 		consultation.IsEditable,
-	).Scan(&consultation.CreatedAt)
+	).Scan(&consultation.CreatedAt, &consultation.Version)
 
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "consultation")
 	}
 
 	return consultation, nil
@@ -57,13 +60,13 @@ func (r *ConsultationRepository) GetByID(ctx context.Context, consultationID uui
 	}
 
 	query := `
-		SELECT consultation_id, appointment_id, patient_id, doctor_id, diagnosis, notes, created_at, is_editable
+		SELECT consultation_id, appointment_id, patient_id, doctor_id, diagnosis, notes, created_at, is_editable, version
 		FROM consultations
 		WHERE consultation_id = $1
 	`
 
 	var consultation models.Consultation
-	err := r.pool.QueryRow(ctx, query, consultationID).Scan(
+	err := querier(ctx, r.pool).QueryRow(ctx, query, consultationID).Scan(
 		&consultation.ConsultationID,
 		&consultation.AppointmentID,
 		&consultation.PatientID,
@@ -72,9 +75,10 @@ func (r *ConsultationRepository) GetByID(ctx context.Context, consultationID uui
 		&consultation.Notes,
 		&consultation.CreatedAt,
 		&consultation.IsEditable,
+		&consultation.Version,
 	)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "consultation")
 	}
 
 	return &consultation, nil
@@ -88,13 +92,13 @@ func (r *ConsultationRepository) GetByAppointmentID(ctx context.Context, appoint
 	}
 
 	query := `
-		SELECT consultation_id, appointment_id, patient_id, doctor_id, diagnosis, notes, created_at, is_editable
+		SELECT consultation_id, appointment_id, patient_id, doctor_id, diagnosis, notes, created_at, is_editable, version
 		FROM consultations
 		WHERE appointment_id = $1
 	`
 
 	var consultation models.Consultation
-	err := r.pool.QueryRow(ctx, query, appointmentID).Scan(
+	err := querier(ctx, r.pool).QueryRow(ctx, query, appointmentID).Scan(
 		&consultation.ConsultationID,
 		&consultation.AppointmentID,
 		&consultation.PatientID,
@@ -103,9 +107,10 @@ func (r *ConsultationRepository) GetByAppointmentID(ctx context.Context, appoint
 		&consultation.Notes,
 		&consultation.CreatedAt,
 		&consultation.IsEditable,
+		&consultation.Version,
 	)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "consultation")
 	}
 
 	return &consultation, nil
@@ -119,15 +124,15 @@ func (r *ConsultationRepository) GetByPatientID(ctx context.Context, patientID u
 	}
 
 	query := `
-		SELECT consultation_id, appointment_id, patient_id, doctor_id, diagnosis, notes, created_at, is_editable
+		SELECT consultation_id, appointment_id, patient_id, doctor_id, diagnosis, notes, created_at, is_editable, version
 		FROM consultations
 		WHERE patient_id = $1
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, patientID)
+	rows, err := querier(ctx, r.pool).Query(ctx, query, patientID)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "consultation")
 	}
 	defer rows.Close()
 
@@ -143,6 +148,7 @@ func (r *ConsultationRepository) GetByPatientID(ctx context.Context, patientID u
 			&consultation.Notes,
 			&consultation.CreatedAt,
 			&consultation.IsEditable,
+			&consultation.Version,
 		)
 		if err != nil {
 			return nil, err
@@ -153,6 +159,36 @@ func (r *ConsultationRepository) GetByPatientID(ctx context.Context, patientID u
 	return consultations, nil
 }
 
+// List returns one page of consultations matching q.
+func (r *ConsultationRepository) List(ctx context.Context, q listquery.Query[*models.Consultation]) (*listquery.Page[*models.Consultation], error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT consultation_id, appointment_id, patient_id, doctor_id, diagnosis, notes, created_at, is_editable, version
+		FROM consultations
+	`
+
+	return list(ctx, querier(ctx, r.pool), query, q, "consultation", func(rows pgx.Rows) (*models.Consultation, error) {
+		var consultation models.Consultation
+		err := rows.Scan(
+			&consultation.ConsultationID,
+			&consultation.AppointmentID,
+			&consultation.PatientID,
+			&consultation.DoctorID,
+			&consultation.Diagnosis,
+			&consultation.Notes,
+			&consultation.CreatedAt,
+			&consultation.IsEditable,
+			&consultation.Version,
+		)
+		return &consultation, err
+	})
+}
+
 func (r *ConsultationRepository) Update(ctx context.Context, consultation *models.Consultation) (*models.Consultation, error) {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
@@ -160,20 +196,46 @@ func (r *ConsultationRepository) Update(ctx context.Context, consultation *model
 		defer cancel()
 	}
 
+	// consultation.Version is the version the caller read; the update only
+	// applies if nobody has changed the row since.
 	query := `
     UPDATE consultations
-    SET diagnosis = $2, notes = $3
-    WHERE consultation_id = $1
+    SET diagnosis = $2, notes = $3, version = version + 1
+    WHERE consultation_id = $1 AND version = $4
+    RETURNING consultation_id, appointment_id, patient_id, doctor_id, diagnosis, notes, created_at, is_editable, version
 `
-	_, err := r.pool.Exec(ctx, query,
+	var updated models.Consultation
+	err := querier(ctx, r.pool).QueryRow(ctx, query,
 		consultation.ConsultationID,
 		consultation.Diagnosis,
 		consultation.Notes,
+		consultation.Version,
+	).Scan(
+		&updated.ConsultationID,
+		&updated.AppointmentID,
+		&updated.PatientID,
+		&updated.DoctorID,
+		&updated.Diagnosis,
+		&updated.Notes,
+		&updated.CreatedAt,
+		&updated.IsEditable,
+		&updated.Version,
 	)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.missingOrStale(ctx, consultation.ConsultationID)
+	}
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "consultation")
 	}
 
-	return consultation, nil
+	return &updated, nil
+}
+
+// missingOrStale explains why a versioned update matched no row.
+func (r *ConsultationRepository) missingOrStale(ctx context.Context, consultationID uuid.UUID) error {
+	if _, err := r.GetByID(ctx, consultationID); err != nil {
+		return err
+	}
+	return VersionConflict("consultation")
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
 )
 
@@ -20,16 +21,7 @@ type UpdateDepartmentRequest struct {
 	Name        *string
 	Description *string
 	IsActive    *bool
-}
-
-type PaginationParams struct {
-	Limit  int
-	Offset int
-}
-
-type PaginatedResponse struct {
-	Data       []*models.Department
-	TotalCount int
+	Version     int // the version the caller read; the update fails if it is stale
 }
 
 func NewDepartmentRepository(pool *pgxpool.Pool) *DepartmentRepository {
@@ -58,9 +50,10 @@ func (dept *DepartmentRepository) CreateDepartment(ctx context.Context, departme
 	description,
 	is_active,
 	created_at,
-	updated_at
+	updated_at,
+	version
 	`
-	row := dept.pool.QueryRow(
+	row := querier(ctx, dept.pool).QueryRow(
 		ctx,
 		query,
 		department.Name,
@@ -76,9 +69,10 @@ func (dept *DepartmentRepository) CreateDepartment(ctx context.Context, departme
 		&created.IsActive,
 		&created.CreatedAt,
 		&created.UpdatedAt,
+		&created.Version,
 	)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "department")
 	}
 
 	return &created, nil
@@ -98,11 +92,12 @@ func (dept *DepartmentRepository) GetByID(ctx context.Context, deptID string) (*
 	description,
 	is_active,
 	created_at,
-	updated_at
+	updated_at,
+	version
 	FROM departments 
 	WHERE department_id = $1
 	`
-	row := dept.pool.QueryRow(ctx, query, deptID)
+	row := querier(ctx, dept.pool).QueryRow(ctx, query, deptID)
 
 	var department models.Department
 	err := row.Scan(
@@ -112,41 +107,22 @@ func (dept *DepartmentRepository) GetByID(ctx context.Context, deptID string) (*
 		&department.IsActive,
 		&department.CreatedAt,
 		&department.UpdatedAt,
+		&department.Version,
 	)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("department not found")
-		}
-		return nil, err
+		return nil, TranslateError(err, "department")
 	}
 	return &department, nil
 }
 
-func (dept *DepartmentRepository) GetAll(ctx context.Context, pagination PaginationParams) (*PaginatedResponse, error) {
+// List returns one page of departments matching q.
+func (dept *DepartmentRepository) List(ctx context.Context, q listquery.Query[*models.Department]) (*listquery.Page[*models.Department], error) {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
 	}
 
-	if pagination.Limit <= 0 {
-		pagination.Limit = 10
-	}
-	if pagination.Offset < 0 {
-		pagination.Offset = 0
-	}
-
-	countQuery := `
-	SELECT COUNT(*)
-	FROM departments
-	WHERE is_active = true
-	`
-
-	var totalCount int
-	err := dept.pool.QueryRow(ctx, countQuery).Scan(&totalCount)
-	if err != nil {
-		return nil, err
-	}
 	query := `
 	SELECT
 	department_id,
@@ -154,21 +130,12 @@ func (dept *DepartmentRepository) GetAll(ctx context.Context, pagination Paginat
 	description,
 	is_active,
 	created_at,
-	updated_at
-	FROM departments 
-	WHERE is_active = true
-	ORDER BY created_at DESC
-	LIMIT $1 OFFSET $2
+	updated_at,
+	version
+	FROM departments
 	`
-	rows, err := dept.pool.Query(ctx, query, pagination.Limit, pagination.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
 
-	departments := make([]*models.Department, 0)
-
-	for rows.Next() {
+	return list(ctx, querier(ctx, dept.pool), query, q, "department", func(rows pgx.Rows) (*models.Department, error) {
 		var department models.Department
 		err := rows.Scan(
 			&department.ID,
@@ -177,21 +144,10 @@ func (dept *DepartmentRepository) GetAll(ctx context.Context, pagination Paginat
 			&department.IsActive,
 			&department.CreatedAt,
 			&department.UpdatedAt,
+			&department.Version,
 		)
-		if err != nil {
-			return nil, err
-		}
-		departments = append(departments, &department)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return &PaginatedResponse{
-		Data:       departments,
-		TotalCount: totalCount,
-	}, nil
+		return &department, err
+	})
 }
 
 func (dept *DepartmentRepository) UpdateDepartment(ctx context.Context, deptID string, request *UpdateDepartmentRequest) (*models.Department, error) {
@@ -203,7 +159,10 @@ func (dept *DepartmentRepository) UpdateDepartment(ctx context.Context, deptID s
 
 	existing, err := dept.GetByID(ctx, deptID)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "department")
+	}
+	if existing.Version != request.Version {
+		return nil, VersionConflict("department")
 	}
 	query := `UPDATE departments SET `
 	args := []interface{}{}
@@ -242,9 +201,12 @@ func (dept *DepartmentRepository) UpdateDepartment(ctx context.Context, deptID s
 	if !hasUpdates {
 		return existing, nil
 	}
-	query += `, updated_at = CURRENT_TIMESTAMP `
+	query += `, updated_at = CURRENT_TIMESTAMP, version = version + 1 `
 	query += `WHERE department_id = $` + fmt.Sprintf("%d", argCounter)
 	args = append(args, deptID)
+	argCounter++
+	query += ` AND version = $` + fmt.Sprintf("%d", argCounter)
+	args = append(args, request.Version)
 
 	query += `
 	RETURNING 
@@ -253,10 +215,11 @@ func (dept *DepartmentRepository) UpdateDepartment(ctx context.Context, deptID s
 	description,
 	is_active,
 	created_at,
-	updated_at
+	updated_at,
+	version
 	`
 
-	row := dept.pool.QueryRow(ctx, query, args...)
+	row := querier(ctx, dept.pool).QueryRow(ctx, query, args...)
 
 	var updated models.Department
 	err = row.Scan(
@@ -266,10 +229,14 @@ func (dept *DepartmentRepository) UpdateDepartment(ctx context.Context, deptID s
 		&updated.IsActive,
 		&updated.CreatedAt,
 		&updated.UpdatedAt,
+		&updated.Version,
 	)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, VersionConflict("department")
+	}
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "department")
 	}
 
 	return &updated, nil
@@ -284,23 +251,41 @@ func (dept *DepartmentRepository) DeleteDepartment(ctx context.Context, deptID s
 
 	_, err := dept.GetByID(ctx, deptID)
 	if err != nil {
-		return err
+		return TranslateError(err, "department")
 	}
 
 	query := `
 	UPDATE departments 
-	SET is_active = false, updated_at = CURRENT_TIMESTAMP
+	SET is_active = false, updated_at = CURRENT_TIMESTAMP, version = version + 1
 	WHERE department_id = $1
 	`
 
-	result, err := dept.pool.Exec(ctx, query, deptID)
+	result, err := querier(ctx, dept.pool).Exec(ctx, query, deptID)
 	if err != nil {
-		return err
+		return TranslateError(err, "department")
 	}
 
 	if result.RowsAffected() == 0 {
-		return errors.New("department not found")
+		return TranslateError(pgx.ErrNoRows, "department")
 	}
 
 	return nil
 }
+
+func (dept *DepartmentRepository) ExistsByName(ctx context.Context, name string) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `SELECT EXISTS(SELECT 1 FROM departments WHERE LOWER(name) = LOWER($1) AND is_active = true)`
+
+	var exists bool
+	err := querier(ctx, dept.pool).QueryRow(ctx, query, name).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
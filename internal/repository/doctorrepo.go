@@ -5,8 +5,10 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
 )
 
@@ -32,7 +34,7 @@ func (r *DoctorRepository) Create(ctx context.Context, doctor *models.Doctor) (*
     VALUES ($1, $2, $3, $4, $5, $6)
     RETURNING created_at, updated_at
 `
-	err := r.pool.QueryRow(ctx, query,
+	err := querier(ctx, r.pool).QueryRow(ctx, query,
 		doctor.DoctorID,
 		doctor.UserID,
 		doctor.DepartmentID,
@@ -42,7 +44,7 @@ func (r *DoctorRepository) Create(ctx context.Context, doctor *models.Doctor) (*
 	).Scan(&doctor.CreatedAt, &doctor.UpdatedAt)
 
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "doctor")
 	}
 
 	return doctor, nil
@@ -56,23 +58,25 @@ func (r *DoctorRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*
 	}
 
 	query := `
-		SELECT doctor_id, user_id, department_id, specialization, license_number, created_at, updated_at
+		SELECT doctor_id, user_id, department_id, specialization, license_number, consultation_fee, is_available, created_at, updated_at
 		FROM doctors
 		WHERE user_id = $1
 	`
 
 	var doctor models.Doctor
-	err := r.pool.QueryRow(ctx, query, userID).Scan(
+	err := querier(ctx, r.pool).QueryRow(ctx, query, userID).Scan(
 		&doctor.DoctorID,
 		&doctor.UserID,
 		&doctor.DepartmentID,
 		&doctor.Specialization,
 		&doctor.LicenseNumber,
+		&doctor.ConsultationFee,
+		&doctor.IsAvailable,
 		&doctor.CreatedAt,
 		&doctor.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "doctor")
 	}
 
 	return &doctor, nil
@@ -87,25 +91,140 @@ func (r *DoctorRepository) GetDoctorID(ctx context.Context, doctorID uuid.UUID)
 	}
 
 	query := `
-		SELECT doctor_id, user_id, department_id, specialization, license_number, created_at, updated_at
+		SELECT doctor_id, user_id, department_id, specialization, license_number, consultation_fee, is_available, created_at, updated_at
 		FROM doctors
 		WHERE doctor_id = $1
 	`
 
 	var doctor models.Doctor
-	err := r.pool.QueryRow(ctx, query, doctorID).Scan(
+	err := querier(ctx, r.pool).QueryRow(ctx, query, doctorID).Scan(
 		&doctor.DoctorID,
 		&doctor.UserID,
 		&doctor.DepartmentID,
 		&doctor.Specialization,
 		&doctor.LicenseNumber,
+		&doctor.ConsultationFee,
+		&doctor.IsAvailable,
 		&doctor.CreatedAt,
 		&doctor.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "doctor")
 	}
 
 	return &doctor, nil
 
 }
+
+func (r *DoctorRepository) Update(ctx context.Context, doctor *models.Doctor) (*models.Doctor, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+    UPDATE doctors
+    SET department_id = $2, specialization = $3, license_number = $4, consultation_fee = $5, is_available = $6, updated_at = CURRENT_TIMESTAMP
+    WHERE doctor_id = $1
+    RETURNING doctor_id, user_id, department_id, specialization, license_number, consultation_fee, is_available, created_at, updated_at
+`
+	var updated models.Doctor
+	err := querier(ctx, r.pool).QueryRow(ctx, query,
+		doctor.DoctorID,
+		doctor.DepartmentID,
+		doctor.Specialization,
+		doctor.LicenseNumber,
+		doctor.ConsultationFee,
+		doctor.IsAvailable,
+	).Scan(
+		&updated.DoctorID,
+		&updated.UserID,
+		&updated.DepartmentID,
+		&updated.Specialization,
+		&updated.LicenseNumber,
+		&updated.ConsultationFee,
+		&updated.IsAvailable,
+		&updated.CreatedAt,
+		&updated.UpdatedAt,
+	)
+	if err != nil {
+		return nil, TranslateError(err, "doctor")
+	}
+
+	return &updated, nil
+}
+
+// List returns one page of doctors matching q.
+func (r *DoctorRepository) List(ctx context.Context, q listquery.Query[*models.Doctor]) (*listquery.Page[*models.Doctor], error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT doctor_id, user_id, department_id, specialization, license_number, consultation_fee, is_available, created_at, updated_at
+		FROM doctors
+	`
+
+	return list(ctx, querier(ctx, r.pool), query, q, "doctor", func(rows pgx.Rows) (*models.Doctor, error) {
+		var doctor models.Doctor
+		err := rows.Scan(
+			&doctor.DoctorID,
+			&doctor.UserID,
+			&doctor.DepartmentID,
+			&doctor.Specialization,
+			&doctor.LicenseNumber,
+			&doctor.ConsultationFee,
+			&doctor.IsAvailable,
+			&doctor.CreatedAt,
+			&doctor.UpdatedAt,
+		)
+		return &doctor, err
+	})
+}
+
+// Directory returns one page of the doctor directory: doctors whose user
+// account is active, with their names and department.
+func (r *DoctorRepository) Directory(ctx context.Context, q listquery.Query[*models.DoctorListing]) (*listquery.Page[*models.DoctorListing], error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT doctor_id, user_id, department_id, specialization, license_number, consultation_fee, is_available,
+		       created_at, updated_at, first_name, last_name, department_name
+		FROM (
+			SELECT d.doctor_id, d.user_id, d.department_id, d.specialization, d.license_number,
+			       d.consultation_fee, d.is_available, d.created_at, d.updated_at,
+			       COALESCE(u.first_name, '') AS first_name, COALESCE(u.last_name, '') AS last_name,
+			       COALESCE(dep.name, '') AS department_name
+			FROM doctors d
+			JOIN users u ON u.user_id = d.user_id
+			LEFT JOIN departments dep ON dep.department_id = d.department_id
+			WHERE u.is_active
+		) directory
+	`
+
+	return list(ctx, querier(ctx, r.pool), query, q, "doctor", func(rows pgx.Rows) (*models.DoctorListing, error) {
+		var listing models.DoctorListing
+		err := rows.Scan(
+			&listing.Doctor.DoctorID,
+			&listing.Doctor.UserID,
+			&listing.Doctor.DepartmentID,
+			&listing.Doctor.Specialization,
+			&listing.Doctor.LicenseNumber,
+			&listing.Doctor.ConsultationFee,
+			&listing.Doctor.IsAvailable,
+			&listing.Doctor.CreatedAt,
+			&listing.Doctor.UpdatedAt,
+			&listing.FirstName,
+			&listing.LastName,
+			&listing.DepartmentName,
+		)
+		return &listing, err
+	})
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+	checkViolationCode      = "23514"
+	invalidTextCode         = "22P02"
+)
+
+// uniqueConstraints maps unique constraints to the error reported when they
+// are violated, so callers get a stable code instead of the Postgres message.
+var uniqueConstraints = map[string]struct{ code, message string }{
+	"users_username_key":         {"username_taken", "username already taken"},
+	"users_email_key":            {"email_already_registered", "email already registered"},
+	"patients_user_id_key":       {"patient_exists", "patient already exists for this user"},
+	"doctors_user_id_key":        {"doctor_exists", "doctor already exists for this user"},
+	"doctors_license_number_key": {"license_number_taken", "license number already registered"},
+	"nurses_user_id_key":         {"nurse_exists", "nurse already exists for this user"},
+	"nurses_license_number_key":  {"license_number_taken", "license number already registered"},
+	// Two changes to the hospital config raced for the same revision number.
+	"hospital_config_revision_key": {"hospital_config_changed", "hospital config was changed at the same time; fetch it again and retry"},
+}
+
+// TranslateError converts pgx.ErrNoRows and constraint violations into
+// utils.AppErrors describing resource. Other errors are returned unchanged.
+// It is exported so the in-memory repositories report errors identically.
+func TranslateError(err error, resource string) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &utils.AppError{
+			Kind:    utils.ErrNotFound,
+			Code:    errorCode(resource, "not_found"),
+			Message: resource + " not found",
+			Err:     err,
+		}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case uniqueViolationCode:
+		appErr := &utils.AppError{
+			Kind:    utils.ErrAlreadyExists,
+			Code:    errorCode(resource, "exists"),
+			Message: resource + " already exists",
+			Err:     err,
+		}
+		if c, ok := uniqueConstraints[pgErr.ConstraintName]; ok {
+			appErr.Code = c.code
+			appErr.Message = c.message
+		}
+		return appErr
+
+	case foreignKeyViolationCode:
+		field := strings.TrimSuffix(strings.TrimPrefix(pgErr.ConstraintName, pgErr.TableName+"_"), "_fkey")
+		return &utils.AppError{
+			Kind:    utils.ErrInvalidInput,
+			Code:    "invalid_reference",
+			Message: "referenced record does not exist",
+			Fields:  []utils.FieldError{{Field: field, Message: "references a record that does not exist"}},
+			Err:     err,
+		}
+
+	case checkViolationCode:
+		return &utils.AppError{
+			Kind:    utils.ErrInvalidInput,
+			Code:    "invalid_value",
+			Message: "invalid " + resource + " value",
+			Err:     err,
+		}
+
+	case invalidTextCode:
+		return &utils.AppError{
+			Kind:    utils.ErrInvalidInput,
+			Code:    errorCode(resource, "id_invalid"),
+			Message: "invalid " + resource + " id",
+			Err:     err,
+		}
+	}
+
+	return err
+}
+
+func errorCode(resource, suffix string) string {
+	return strings.ReplaceAll(resource, " ", "_") + "_" + suffix
+}
+
+// VersionConflict is returned when an update names a row version that is no
+// longer current, meaning someone else changed the row since it was read.
+// It is exported so the services and in-memory repositories report it the
+// same way.
+func VersionConflict(resource string) error {
+	return utils.NewPreconditionFailedError("version_mismatch", resource+" has been modified since it was read; fetch it again and retry")
+}
@@ -0,0 +1,52 @@
+package repository_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantKind  error
+		wantCode  string
+		wantField string
+	}{
+		{"no rows", pgx.ErrNoRows, utils.ErrNotFound, "hospital_config_not_found", ""},
+		{"wrapped no rows", fmt.Errorf("scan: %w", pgx.ErrNoRows), utils.ErrNotFound, "hospital_config_not_found", ""},
+		{"known unique constraint", &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"}, utils.ErrAlreadyExists, "email_already_registered", ""},
+		{"other unique constraint", &pgconn.PgError{Code: "23505", ConstraintName: "hospital_config_pkey"}, utils.ErrAlreadyExists, "hospital_config_exists", ""},
+		{"foreign key", &pgconn.PgError{Code: "23503", TableName: "appointments", ConstraintName: "appointments_doctor_id_fkey"}, utils.ErrInvalidInput, "invalid_reference", "doctor_id"},
+		{"invalid uuid", &pgconn.PgError{Code: "22P02"}, utils.ErrInvalidInput, "hospital_config_id_invalid", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repository.TranslateError(tt.err, "hospital config")
+
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("expected %v, got %v", tt.wantKind, err)
+			}
+			var appErr *utils.AppError
+			if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+				t.Fatalf("expected code %q, got %v", tt.wantCode, err)
+			}
+			if tt.wantField != "" && (len(appErr.Fields) != 1 || appErr.Fields[0].Field != tt.wantField) {
+				t.Errorf("expected field %q, got %+v", tt.wantField, appErr.Fields)
+			}
+		})
+	}
+
+	other := errors.New("connection refused")
+	if err := repository.TranslateError(other, "user"); err != other {
+		t.Errorf("expected unrelated errors to pass through, got %v", err)
+	}
+}
@@ -4,9 +4,10 @@ import (
 	"context"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
 )
 
@@ -20,6 +21,31 @@ func NewHospitalConfigRepository(pool *pgxpool.Pool) *HospitalConfigRepository {
 	}
 }
 
+const hospitalConfigColumns = `config_id, revision, working_hours_start, working_hours_end, appointment_duration_minutes, max_same_day_cancellation_hours, enable_patient_self_registration, effective_from, created_by, rolled_back_from, created_at`
+
+func scanHospitalConfig(row pgx.Row) (*models.HospitalConfig, error) {
+	var config models.HospitalConfig
+	err := row.Scan(
+		&config.ConfigID,
+		&config.Revision,
+		&config.WorkingHoursStart,
+		&config.WorkingHoursEnd,
+		&config.AppointmentDurationMinutes,
+		&config.MaxSameDayCancellationHours,
+		&config.EnablePatientSelfRegistration,
+		&config.EffectiveFrom,
+		&config.CreatedBy,
+		&config.RolledBackFrom,
+		&config.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Create adds config as the next revision. Two revisions created at once
+// get the same number and the second fails with a conflict.
 func (r *HospitalConfigRepository) Create(ctx context.Context, config *models.HospitalConfig) (*models.HospitalConfig, error) {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
@@ -28,27 +54,31 @@ func (r *HospitalConfigRepository) Create(ctx context.Context, config *models.Ho
 	}
 
 	query := `
-    INSERT INTO hospital_config (config_id, working_hours_start, working_hours_end, appointment_duration_minutes, max_same_day_cancellation_hours, enable_patient_self_registration)
-    VALUES ($1, $2, $3, $4, $5, $6)
-    RETURNING created_at, updated_at
+    INSERT INTO hospital_config (config_id, revision, working_hours_start, working_hours_end, appointment_duration_minutes, max_same_day_cancellation_hours, enable_patient_self_registration, effective_from, created_by, rolled_back_from)
+    SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5, $6, $7, $8, $9
+    FROM hospital_config
+    RETURNING revision, created_at
 `
-	err := r.pool.QueryRow(ctx, query,
+	err := querier(ctx, r.pool).QueryRow(ctx, query,
 		config.ConfigID,
 		config.WorkingHoursStart,
 		config.WorkingHoursEnd,
 		config.AppointmentDurationMinutes,
 		config.MaxSameDayCancellationHours,
 		config.EnablePatientSelfRegistration,
-	).Scan(&config.CreatedAt, &config.UpdatedAt)
+		config.EffectiveFrom,
+		config.CreatedBy,
+		config.RolledBackFrom,
+	).Scan(&config.Revision, &config.CreatedAt)
 
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "hospital config")
 	}
 
 	return config, nil
 }
 
-func (r *HospitalConfigRepository) GetByID(ctx context.Context, configID uuid.UUID) (*models.HospitalConfig, error) {
+func (r *HospitalConfigRepository) GetByRevision(ctx context.Context, revision int) (*models.HospitalConfig, error) {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
@@ -56,30 +86,23 @@ func (r *HospitalConfigRepository) GetByID(ctx context.Context, configID uuid.UU
 	}
 
 	query := `
-		SELECT config_id, working_hours_start, working_hours_end, appointment_duration_minutes, max_same_day_cancellation_hours, enable_patient_self_registration, created_at, updated_at
+		SELECT ` + hospitalConfigColumns + `
 		FROM hospital_config
-		WHERE config_id = $1
+		WHERE revision = $1
 	`
 
-	var config models.HospitalConfig
-	err := r.pool.QueryRow(ctx, query, configID).Scan(
-		&config.ConfigID,
-		&config.WorkingHoursStart,
-		&config.WorkingHoursEnd,
-		&config.AppointmentDurationMinutes,
-		&config.MaxSameDayCancellationHours,
-		&config.EnablePatientSelfRegistration,
-		&config.CreatedAt,
-		&config.UpdatedAt,
-	)
+	config, err := scanHospitalConfig(querier(ctx, r.pool).QueryRow(ctx, query, revision))
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "hospital config")
 	}
 
-	return &config, nil
+	return config, nil
 }
 
-func (r *HospitalConfigRepository) GetAll(ctx context.Context) ([]*models.HospitalConfig, error) {
+// GetEffective returns the revision in force at at: the newest one whose
+// effective_from is not after it. effective_from is a UTC timestamp without
+// a time zone, so at is compared in UTC.
+func (r *HospitalConfigRepository) GetEffective(ctx context.Context, at time.Time) (*models.HospitalConfig, error) {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
@@ -87,44 +110,24 @@ func (r *HospitalConfigRepository) GetAll(ctx context.Context) ([]*models.Hospit
 	}
 
 	query := `
-		SELECT config_id, working_hours_start, working_hours_end, appointment_duration_minutes, max_same_day_cancellation_hours, enable_patient_self_registration, created_at, updated_at
+		SELECT ` + hospitalConfigColumns + `
 		FROM hospital_config
-		ORDER BY created_at DESC
+		WHERE effective_from <= $1
+		ORDER BY revision DESC
+		LIMIT 1
 	`
 
-	rows, err := r.pool.Query(ctx, query)
+	config, err := scanHospitalConfig(querier(ctx, r.pool).QueryRow(ctx, query, at.UTC()))
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var configs []*models.HospitalConfig
-	for rows.Next() {
-		var config models.HospitalConfig
-		err := rows.Scan(
-			&config.ConfigID,
-			&config.WorkingHoursStart,
-			&config.WorkingHoursEnd,
-			&config.AppointmentDurationMinutes,
-			&config.MaxSameDayCancellationHours,
-			&config.EnablePatientSelfRegistration,
-			&config.CreatedAt,
-			&config.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		configs = append(configs, &config)
+		return nil, TranslateError(err, "hospital config")
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return configs, nil
+	return config, nil
 }
 
-func (r *HospitalConfigRepository) Update(ctx context.Context, config *models.HospitalConfig) (*models.HospitalConfig, error) {
+// GetLatest returns the newest revision, which may not be in force yet, and
+// locks it until the transaction ends.
+func (r *HospitalConfigRepository) GetLatest(ctx context.Context) (*models.HospitalConfig, error) {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
@@ -132,28 +135,23 @@ func (r *HospitalConfigRepository) Update(ctx context.Context, config *models.Ho
 	}
 
 	query := `
-    UPDATE hospital_config
-    SET working_hours_start = $2, working_hours_end = $3, appointment_duration_minutes = $4, max_same_day_cancellation_hours = $5, enable_patient_self_registration = $6, updated_at = CURRENT_TIMESTAMP
-    WHERE config_id = $1
-    RETURNING updated_at
-`
-	err := r.pool.QueryRow(ctx, query,
-		config.ConfigID,
-		config.WorkingHoursStart,
-		config.WorkingHoursEnd,
-		config.AppointmentDurationMinutes,
-		config.MaxSameDayCancellationHours,
-		config.EnablePatientSelfRegistration,
-	).Scan(&config.UpdatedAt)
+		SELECT ` + hospitalConfigColumns + `
+		FROM hospital_config
+		ORDER BY revision DESC
+		LIMIT 1
+		FOR UPDATE
+	`
 
+	config, err := scanHospitalConfig(querier(ctx, r.pool).QueryRow(ctx, query))
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "hospital config")
 	}
 
 	return config, nil
 }
 
-func (r *HospitalConfigRepository) Delete(ctx context.Context, configID uuid.UUID) error {
+// List returns one page of revisions matching q.
+func (r *HospitalConfigRepository) List(ctx context.Context, q listquery.Query[*models.HospitalConfig]) (*listquery.Page[*models.HospitalConfig], error) {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
@@ -161,9 +159,11 @@ func (r *HospitalConfigRepository) Delete(ctx context.Context, configID uuid.UUI
 	}
 
 	query := `
-    DELETE FROM hospital_config
-    WHERE config_id = $1
-`
-	_, err := r.pool.Exec(ctx, query, configID)
-	return err
+		SELECT ` + hospitalConfigColumns + `
+		FROM hospital_config
+	`
+
+	return list(ctx, querier(ctx, r.pool), query, q, "hospital config", func(rows pgx.Rows) (*models.HospitalConfig, error) {
+		return scanHospitalConfig(rows)
+	})
 }
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type IdempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(pool *pgxpool.Pool) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		pool: pool,
+	}
+}
+
+// Begin claims scope+key for a new request. It returns nil when the caller
+// now owns the key, or the existing record when the key was already used.
+// An in-flight claim older than lockTimeout is assumed abandoned and is
+// taken over.
+func (r *IdempotencyRepository) Begin(ctx context.Context, scope, key, requestHash string, lockTimeout time.Duration) (*models.IdempotencyRecord, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	claim := `
+		INSERT INTO idempotency_keys (scope, idempotency_key, request_hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (scope, idempotency_key) DO UPDATE
+			SET request_hash = EXCLUDED.request_hash, created_at = CURRENT_TIMESTAMP
+			WHERE idempotency_keys.status_code IS NULL
+			  AND idempotency_keys.created_at < CURRENT_TIMESTAMP - make_interval(secs => $4)
+		RETURNING scope
+	`
+
+	var claimed string
+	err := querier(ctx, r.pool).QueryRow(ctx, claim, scope, key, requestHash, lockTimeout.Seconds()).Scan(&claimed)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	query := `
+		SELECT scope, idempotency_key, request_hash, COALESCE(status_code, 0), response_headers, response_body, created_at, completed_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2
+	`
+
+	var record models.IdempotencyRecord
+	err = querier(ctx, r.pool).QueryRow(ctx, query, scope, key).Scan(
+		&record.Scope,
+		&record.Key,
+		&record.RequestHash,
+		&record.StatusCode,
+		&record.Headers,
+		&record.Body,
+		&record.CreatedAt,
+		&record.CompletedAt,
+	)
+	if err != nil {
+		return nil, TranslateError(err, "idempotency key")
+	}
+
+	return &record, nil
+}
+
+// Complete stores the response for a claimed key.
+func (r *IdempotencyRepository) Complete(ctx context.Context, record *models.IdempotencyRecord) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $3, response_headers = $4, response_body = $5, completed_at = CURRENT_TIMESTAMP
+		WHERE scope = $1 AND idempotency_key = $2
+	`
+
+	tag, err := querier(ctx, r.pool).Exec(ctx, query, record.Scope, record.Key, record.StatusCode, record.Headers, record.Body)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return TranslateError(pgx.ErrNoRows, "idempotency key")
+	}
+
+	return nil
+}
+
+// Release drops an unfinished claim so the client can retry with the same key.
+func (r *IdempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `DELETE FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2 AND status_code IS NULL`
+
+	_, err := querier(ctx, r.pool).Exec(ctx, query, scope, key)
+	return err
+}
+
+// DeleteExpired removes records older than ttl and returns how many were
+// deleted.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, ttl time.Duration) (int64, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+	}
+
+	tag, err := querier(ctx, r.pool).Exec(ctx, `DELETE FROM idempotency_keys WHERE created_at < CURRENT_TIMESTAMP - make_interval(secs => $1)`, ttl.Seconds())
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type InvitationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewInvitationRepository(pool *pgxpool.Pool) *InvitationRepository {
+	return &InvitationRepository{
+		pool: pool,
+	}
+}
+
+func (r *InvitationRepository) Create(ctx context.Context, invitation *models.Invitation) (*models.Invitation, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		INSERT INTO user_invitations (invitation_id, user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+	err := querier(ctx, r.pool).QueryRow(ctx, query,
+		invitation.InvitationID,
+		invitation.UserID,
+		invitation.TokenHash,
+		invitation.ExpiresAt,
+	).Scan(&invitation.CreatedAt)
+	if err != nil {
+		return nil, TranslateError(err, "invitation")
+	}
+
+	return invitation, nil
+}
+
+// GetByTokenHash returns the invitation for a token hash, locking it for the
+// rest of the transaction so it can only be redeemed once.
+func (r *InvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT invitation_id, user_id, token_hash, expires_at, used_at, created_at
+		FROM user_invitations
+		WHERE token_hash = $1
+		FOR UPDATE
+	`
+
+	var invitation models.Invitation
+	err := querier(ctx, r.pool).QueryRow(ctx, query, tokenHash).Scan(
+		&invitation.InvitationID,
+		&invitation.UserID,
+		&invitation.TokenHash,
+		&invitation.ExpiresAt,
+		&invitation.UsedAt,
+		&invitation.CreatedAt,
+	)
+	if err != nil {
+		return nil, TranslateError(err, "invitation")
+	}
+
+	return &invitation, nil
+}
+
+// MarkUsed records that an unused invitation has been redeemed.
+func (r *InvitationRepository) MarkUsed(ctx context.Context, invitationID uuid.UUID) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		UPDATE user_invitations
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE invitation_id = $1 AND used_at IS NULL
+	`
+
+	result, err := querier(ctx, r.pool).Exec(ctx, query, invitationID)
+	if err != nil {
+		return TranslateError(err, "invitation")
+	}
+	if result.RowsAffected() == 0 {
+		return TranslateError(pgx.ErrNoRows, "invitation")
+	}
+
+	return nil
+}
+
+// DeleteUnused removes a user's outstanding invitations, so that only the
+// newest link works after an invitation is resent.
+func (r *InvitationRepository) DeleteUnused(ctx context.Context, userID uuid.UUID) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `DELETE FROM user_invitations WHERE user_id = $1 AND used_at IS NULL`
+
+	if _, err := querier(ctx, r.pool).Exec(ctx, query, userID); err != nil {
+		return TranslateError(err, "invitation")
+	}
+
+	return nil
+}
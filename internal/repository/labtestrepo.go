@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type LabTestRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLabTestRepository(pool *pgxpool.Pool) *LabTestRepository {
+	return &LabTestRepository{
+		pool: pool,
+	}
+}
+
+// GetCompletedByPatientID returns a patient's completed lab tests, most
+// recently completed first. Tests still requested or in progress have no
+// result to show.
+func (r *LabTestRepository) GetCompletedByPatientID(ctx context.Context, patientID uuid.UUID) ([]*models.LabTest, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT test_id, patient_id, doctor_id, test_name, COALESCE(test_type, ''), status,
+		       COALESCE(result_file_path, ''), COALESCE(notes, ''), requested_at, completed_at
+		FROM lab_tests
+		WHERE patient_id = $1 AND status = 'COMPLETED'
+		ORDER BY completed_at DESC NULLS LAST
+	`
+
+	rows, err := querier(ctx, r.pool).Query(ctx, query, patientID)
+	if err != nil {
+		return nil, TranslateError(err, "lab test")
+	}
+	defer rows.Close()
+
+	var tests []*models.LabTest
+	for rows.Next() {
+		var test models.LabTest
+		err := rows.Scan(
+			&test.TestID,
+			&test.PatientID,
+			&test.DoctorID,
+			&test.TestName,
+			&test.TestType,
+			&test.Status,
+			&test.ResultFilePath,
+			&test.Notes,
+			&test.RequestedAt,
+			&test.CompletedAt,
+		)
+		if err != nil {
+			return nil, TranslateError(err, "lab test")
+		}
+		tests = append(tests, &test)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err, "lab test")
+	}
+
+	return tests, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+)
+
+// listClauses renders the WHERE, ORDER BY and LIMIT clauses for q. Column
+// names come from the listquery.Spec, never from the request; filter values
+// and the cursor are bound as parameters. One row more than the page size is
+// fetched so listquery.NewPage can tell whether another page follows.
+func listClauses[T any](q listquery.Query[T]) (string, []any) {
+	var (
+		where []string
+		args  []any
+	)
+	bind := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	for _, c := range q.Conditions {
+		where = append(where, fmt.Sprintf("%s %s %s", c.Field.Column, c.Op, bind(c.Value)))
+	}
+
+	dir, after := "ASC", ">"
+	if q.Desc {
+		dir, after = "DESC", "<"
+	}
+	if q.After != nil {
+		where = append(where, fmt.Sprintf("(%s, %s) %s (%s, %s)",
+			q.Sort.Column, q.ID.Column, after, bind(q.After.Value), bind(q.After.ID)))
+	}
+
+	var b strings.Builder
+	if len(where) > 0 {
+		b.WriteString("WHERE " + strings.Join(where, " AND ") + "\n")
+	}
+	fmt.Fprintf(&b, "ORDER BY %s %s, %s %s\nLIMIT %s", q.Sort.Column, dir, q.ID.Column, dir, bind(q.Limit+1))
+
+	return b.String(), args
+}
+
+// list runs selectFrom followed by the clauses for q and scans each row with
+// scan.
+func list[T any](ctx context.Context, db DBTX, selectFrom string, q listquery.Query[T], resource string, scan func(pgx.Rows) (T, error)) (*listquery.Page[T], error) {
+	clauses, args := listClauses(q)
+
+	rows, err := db.Query(ctx, selectFrom+"\n"+clauses, args...)
+	if err != nil {
+		return nil, TranslateError(err, resource)
+	}
+	defer rows.Close()
+
+	var items []T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err, resource)
+	}
+
+	return listquery.NewPage(items, q), nil
+}
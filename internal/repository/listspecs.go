@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+// The specs below whitelist the filters and sort orders of each list
+// endpoint. Sortable columns must be NOT NULL (created_at is since
+// migrations 000011 and 000012), since keyset comparisons skip rows whose sort value is
+// NULL; nullable ones such as date_of_birth, specialization and
+// consultation_fee can only be filtered on.
+
+var UserList = listquery.Spec[*models.User]{
+	Fields: map[string]listquery.Field[*models.User]{
+		"created_at": {Column: "created_at", Type: listquery.Time, Sortable: true, Get: func(u *models.User) any { return u.CreatedAt }},
+		"username":   {Column: "username", Type: listquery.String, Sortable: true, Get: func(u *models.User) any { return u.Username }},
+		"email":      {Column: "email", Type: listquery.String, Sortable: true, Get: func(u *models.User) any { return u.Email }},
+		"role":       {Column: "role", Type: listquery.String, Get: func(u *models.User) any { return u.Role }},
+		"is_active":  {Column: "is_active", Type: listquery.Bool, Get: func(u *models.User) any { return u.IsActive }},
+	},
+	Filters: map[string]listquery.Filter{
+		"role":         {Field: "role", Op: listquery.Eq},
+		"is_active":    {Field: "is_active", Op: listquery.Eq},
+		"created_from": {Field: "created_at", Op: listquery.Gte},
+		"created_to":   {Field: "created_at", Op: listquery.Lte},
+	},
+	ID:          listquery.Field[*models.User]{Column: "user_id", Type: listquery.UUID, Get: func(u *models.User) any { return u.ID }},
+	DefaultSort: "-created_at",
+}
+
+var DepartmentList = listquery.Spec[*models.Department]{
+	Fields: map[string]listquery.Field[*models.Department]{
+		"created_at": {Column: "created_at", Type: listquery.Time, Sortable: true, Get: func(d *models.Department) any { return d.CreatedAt }},
+		"name":       {Column: "name", Type: listquery.String, Sortable: true, Get: func(d *models.Department) any { return d.Name }},
+		"is_active":  {Column: "is_active", Type: listquery.Bool, Get: func(d *models.Department) any { return d.IsActive }},
+	},
+	Filters: map[string]listquery.Filter{
+		"is_active": {Field: "is_active", Op: listquery.Eq},
+	},
+	ID:          listquery.Field[*models.Department]{Column: "department_id", Type: listquery.UUID, Get: func(d *models.Department) any { return d.ID }},
+	DefaultSort: "-created_at",
+	Defaults:    map[string]string{"is_active": "true"},
+}
+
+var AppointmentList = listquery.Spec[*models.Appointment]{
+	Fields: map[string]listquery.Field[*models.Appointment]{
+		"appointment_date": {Column: "appointment_date", Type: listquery.Time, Sortable: true, Get: func(a *models.Appointment) any { return a.AppointmentDate }},
+		"created_at":       {Column: "created_at", Type: listquery.Time, Sortable: true, Get: func(a *models.Appointment) any { return a.CreatedAt }},
+		"status":           {Column: "status", Type: listquery.String, Get: func(a *models.Appointment) any { return a.Status }},
+		"patient_id":       {Column: "patient_id", Type: listquery.UUID, Get: func(a *models.Appointment) any { return a.PatientID }},
+		"doctor_id":        {Column: "doctor_id", Type: listquery.UUID, Get: func(a *models.Appointment) any { return a.DoctorID }},
+	},
+	Filters: map[string]listquery.Filter{
+		"status":     {Field: "status", Op: listquery.Eq},
+		"patient_id": {Field: "patient_id", Op: listquery.Eq},
+		"doctor_id":  {Field: "doctor_id", Op: listquery.Eq},
+		"date_from":  {Field: "appointment_date", Op: listquery.Gte},
+		"date_to":    {Field: "appointment_date", Op: listquery.Lte},
+	},
+	ID:          listquery.Field[*models.Appointment]{Column: "appointment_id", Type: listquery.UUID, Get: func(a *models.Appointment) any { return a.AppointmentID }},
+	DefaultSort: "-appointment_date",
+}
+
+// PatientAppointmentList is the patient portal's view of the caller's own
+// appointments. The service scopes it with patient_id and upcoming, which
+// clients cannot set. upcoming holds for pending and confirmed appointments
+// still to come; appointment_date is stored as UTC.
+var PatientAppointmentList = listquery.Spec[*models.Appointment]{
+	Fields: map[string]listquery.Field[*models.Appointment]{
+		"appointment_date": AppointmentList.Fields["appointment_date"],
+		"created_at":       AppointmentList.Fields["created_at"],
+		"status":           AppointmentList.Fields["status"],
+		"patient_id":       AppointmentList.Fields["patient_id"],
+		"upcoming": {
+			Column: "(appointment_date > (now() AT TIME ZONE 'UTC') AND status IN ('PENDING', 'CONFIRMED'))",
+			Type:   listquery.Bool,
+			Get: func(a *models.Appointment) any {
+				return a.AppointmentDate.After(time.Now()) && (a.Status == "PENDING" || a.Status == "CONFIRMED")
+			},
+		},
+	},
+	Filters: map[string]listquery.Filter{
+		"status":    {Field: "status", Op: listquery.Eq},
+		"date_from": {Field: "appointment_date", Op: listquery.Gte},
+		"date_to":   {Field: "appointment_date", Op: listquery.Lte},
+	},
+	ID:          AppointmentList.ID,
+	DefaultSort: "-appointment_date",
+}
+
+// ConsultationList and PrescriptionList back the patient portal, which
+// scopes them to the caller with patient_id.
+var ConsultationList = listquery.Spec[*models.Consultation]{
+	Fields: map[string]listquery.Field[*models.Consultation]{
+		"created_at": {Column: "created_at", Type: listquery.Time, Sortable: true, Get: func(c *models.Consultation) any { return c.CreatedAt }},
+		"patient_id": {Column: "patient_id", Type: listquery.UUID, Get: func(c *models.Consultation) any { return c.PatientID }},
+	},
+	Filters: map[string]listquery.Filter{
+		"created_from": {Field: "created_at", Op: listquery.Gte},
+		"created_to":   {Field: "created_at", Op: listquery.Lte},
+	},
+	ID:          listquery.Field[*models.Consultation]{Column: "consultation_id", Type: listquery.UUID, Get: func(c *models.Consultation) any { return c.ConsultationID }},
+	DefaultSort: "-created_at",
+}
+
+var PrescriptionList = listquery.Spec[*models.Prescription]{
+	Fields: map[string]listquery.Field[*models.Prescription]{
+		"created_at": {Column: "created_at", Type: listquery.Time, Sortable: true, Get: func(p *models.Prescription) any { return p.CreatedAt }},
+		"patient_id": {Column: "patient_id", Type: listquery.UUID, Get: func(p *models.Prescription) any { return p.PatientID }},
+	},
+	Filters: map[string]listquery.Filter{
+		"created_from": {Field: "created_at", Op: listquery.Gte},
+		"created_to":   {Field: "created_at", Op: listquery.Lte},
+	},
+	ID:          listquery.Field[*models.Prescription]{Column: "prescription_id", Type: listquery.UUID, Get: func(p *models.Prescription) any { return p.PrescriptionID }},
+	DefaultSort: "-created_at",
+}
+
+var DoctorList = listquery.Spec[*models.Doctor]{
+	Fields: map[string]listquery.Field[*models.Doctor]{
+		"created_at":       {Column: "created_at", Type: listquery.Time, Sortable: true, Get: func(d *models.Doctor) any { return d.CreatedAt }},
+		"specialization":   {Column: "specialization", Type: listquery.String, Get: func(d *models.Doctor) any { return d.Specialization }},
+		"consultation_fee": {Column: "consultation_fee", Type: listquery.Float, Get: func(d *models.Doctor) any { return d.ConsultationFee }},
+		"department_id":    {Column: "department_id", Type: listquery.UUID, Get: func(d *models.Doctor) any { return d.DepartmentID }},
+		"is_available":     {Column: "is_available", Type: listquery.Bool, Get: func(d *models.Doctor) any { return d.IsAvailable }},
+	},
+	Filters: map[string]listquery.Filter{
+		"department_id":  {Field: "department_id", Op: listquery.Eq},
+		"specialization": {Field: "specialization", Op: listquery.Eq},
+		"is_available":   {Field: "is_available", Op: listquery.Eq},
+		"fee_min":        {Field: "consultation_fee", Op: listquery.Gte},
+		"fee_max":        {Field: "consultation_fee", Op: listquery.Lte},
+	},
+	ID:          listquery.Field[*models.Doctor]{Column: "doctor_id", Type: listquery.UUID, Get: func(d *models.Doctor) any { return d.DoctorID }},
+	DefaultSort: "-created_at",
+}
+
+// DoctorDirectory lists active doctors with their names and departments.
+// Its columns are those of the directory subquery in DoctorRepository.Directory.
+var DoctorDirectory = listquery.Spec[*models.DoctorListing]{
+	Fields: map[string]listquery.Field[*models.DoctorListing]{
+		"last_name":        {Column: "last_name", Type: listquery.String, Sortable: true, Get: func(d *models.DoctorListing) any { return d.LastName }},
+		"specialization":   {Column: "specialization", Type: listquery.String, Get: func(d *models.DoctorListing) any { return d.Doctor.Specialization }},
+		"consultation_fee": {Column: "consultation_fee", Type: listquery.Float, Get: func(d *models.DoctorListing) any { return d.Doctor.ConsultationFee }},
+		"department_id":    {Column: "department_id", Type: listquery.UUID, Get: func(d *models.DoctorListing) any { return d.Doctor.DepartmentID }},
+		"is_available":     {Column: "is_available", Type: listquery.Bool, Get: func(d *models.DoctorListing) any { return d.Doctor.IsAvailable }},
+	},
+	Filters: map[string]listquery.Filter{
+		"department_id":  {Field: "department_id", Op: listquery.Eq},
+		"specialization": {Field: "specialization", Op: listquery.Eq},
+		"is_available":   {Field: "is_available", Op: listquery.Eq},
+		"fee_min":        {Field: "consultation_fee", Op: listquery.Gte},
+		"fee_max":        {Field: "consultation_fee", Op: listquery.Lte},
+	},
+	ID:          listquery.Field[*models.DoctorListing]{Column: "doctor_id", Type: listquery.UUID, Get: func(d *models.DoctorListing) any { return d.Doctor.DoctorID }},
+	DefaultSort: "last_name",
+}
+
+var NurseList = listquery.Spec[*models.Nurse]{
+	Fields: map[string]listquery.Field[*models.Nurse]{
+		"created_at":    {Column: "created_at", Type: listquery.Time, Sortable: true, Get: func(n *models.Nurse) any { return n.CreatedAt }},
+		"department_id": {Column: "department_id", Type: listquery.UUID, Get: func(n *models.Nurse) any { return n.DepartmentID }},
+		"shift":         {Column: "shift", Type: listquery.String, Get: func(n *models.Nurse) any { return n.Shift }},
+	},
+	Filters: map[string]listquery.Filter{
+		"department_id": {Field: "department_id", Op: listquery.Eq},
+		"shift":         {Field: "shift", Op: listquery.Eq},
+	},
+	ID:          listquery.Field[*models.Nurse]{Column: "nurse_id", Type: listquery.UUID, Get: func(n *models.Nurse) any { return n.NurseID }},
+	DefaultSort: "-created_at",
+}
+
+var PatientList = listquery.Spec[*models.Patient]{
+	Fields: map[string]listquery.Field[*models.Patient]{
+		"created_at":    {Column: "created_at", Type: listquery.Time, Sortable: true, Get: func(p *models.Patient) any { return p.CreatedAt }},
+		"date_of_birth": {Column: "date_of_birth", Type: listquery.Time, Get: func(p *models.Patient) any { return p.DateOfBirth }},
+		"gender":        {Column: "gender", Type: listquery.String, Get: func(p *models.Patient) any { return p.Gender }},
+		"blood_group":   {Column: "blood_group", Type: listquery.String, Get: func(p *models.Patient) any { return p.BloodGroup }},
+		"mrn":           {Column: "mrn", Type: listquery.String, Get: func(p *models.Patient) any { return p.MRN }},
+	},
+	Filters: map[string]listquery.Filter{
+		"gender":      {Field: "gender", Op: listquery.Eq},
+		"blood_group": {Field: "blood_group", Op: listquery.Eq},
+		"mrn":         {Field: "mrn", Op: listquery.Eq},
+		"born_from":   {Field: "date_of_birth", Op: listquery.Gte},
+		"born_to":     {Field: "date_of_birth", Op: listquery.Lte},
+	},
+	ID:          listquery.Field[*models.Patient]{Column: "patient_id", Type: listquery.UUID, Get: func(p *models.Patient) any { return p.PatientID }},
+	DefaultSort: "-created_at",
+}
+
+// HospitalConfigHistory pages the revisions of the hospital config. Revision
+// numbers are unique, so the revision is its own tiebreaker.
+var HospitalConfigHistory = listquery.Spec[*models.HospitalConfig]{
+	Fields: map[string]listquery.Field[*models.HospitalConfig]{
+		"revision":       {Column: "revision", Type: listquery.Int, Sortable: true, Get: func(c *models.HospitalConfig) any { return c.Revision }},
+		"effective_from": {Column: "effective_from", Type: listquery.Time, Get: func(c *models.HospitalConfig) any { return c.EffectiveFrom }},
+	},
+	Filters: map[string]listquery.Filter{
+		"effective_from": {Field: "effective_from", Op: listquery.Gte},
+		"effective_to":   {Field: "effective_from", Op: listquery.Lte},
+	},
+	ID:          listquery.Field[*models.HospitalConfig]{Column: "revision", Type: listquery.Int, Get: func(c *models.HospitalConfig) any { return c.Revision }},
+	DefaultSort: "-revision",
+}
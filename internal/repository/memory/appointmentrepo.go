@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+)
+
+type AppointmentRepository struct {
+	mu           sync.RWMutex
+	appointments map[uuid.UUID]models.Appointment
+}
+
+func NewAppointmentRepository() *AppointmentRepository {
+	return &AppointmentRepository{appointments: make(map[uuid.UUID]models.Appointment)}
+}
+
+func (r *AppointmentRepository) Create(ctx context.Context, appointment *models.Appointment) (*models.Appointment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.appointments[appointment.AppointmentID]; exists {
+		return nil, uniqueViolation("appointment", "appointments_pkey")
+	}
+
+	appointment.CreatedAt = time.Now()
+	appointment.UpdatedAt = appointment.CreatedAt
+	appointment.Version = 1
+	r.appointments[appointment.AppointmentID] = *appointment
+
+	return appointment, nil
+}
+
+func (r *AppointmentRepository) GetByID(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	appointment, ok := r.appointments[appointmentID]
+	if !ok {
+		return nil, notFound("appointment")
+	}
+	return &appointment, nil
+}
+
+func (r *AppointmentRepository) GetByPatientID(ctx context.Context, patientID uuid.UUID) ([]*models.Appointment, error) {
+	return r.filter(func(a *models.Appointment) bool { return a.PatientID == patientID }), nil
+}
+
+func (r *AppointmentRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.Appointment, error) {
+	return r.filter(func(a *models.Appointment) bool { return a.DoctorID == doctorID }), nil
+}
+
+func (r *AppointmentRepository) Update(ctx context.Context, appointment *models.Appointment) (*models.Appointment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.appointments[appointment.AppointmentID]
+	if !ok {
+		return nil, notFound("appointment")
+	}
+	if existing.Version != appointment.Version {
+		return nil, repository.VersionConflict("appointment")
+	}
+
+	existing.AppointmentDate = appointment.AppointmentDate
+	existing.DurationMinutes = appointment.DurationMinutes
+	existing.Status = appointment.Status
+	existing.Notes = appointment.Notes
+	existing.UpdatedAt = time.Now()
+	existing.Version++
+	r.appointments[existing.AppointmentID] = existing
+
+	return &existing, nil
+}
+
+func (r *AppointmentRepository) Delete(ctx context.Context, appointmentID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.appointments[appointmentID]; !ok {
+		return notFound("appointment")
+	}
+	delete(r.appointments, appointmentID)
+	return nil
+}
+
+func (r *AppointmentRepository) ListBooked(ctx context.Context, doctorIDs []uuid.UUID, from, to time.Time) ([]*models.Appointment, error) {
+	appointments := r.filter(func(a *models.Appointment) bool {
+		return slices.Contains(doctorIDs, a.DoctorID) &&
+			!a.AppointmentDate.Before(from) && a.AppointmentDate.Before(to) &&
+			(a.Status == "PENDING" || a.Status == "CONFIRMED")
+	})
+	slices.Reverse(appointments)
+	return appointments, nil
+}
+
+func (r *AppointmentRepository) ReassignDoctor(ctx context.Context, from, to uuid.UUID, after time.Time) (int64, error) {
+	return r.modifyUpcoming(from, after, func(a *models.Appointment) { a.DoctorID = to }), nil
+}
+
+func (r *AppointmentRepository) CancelUpcoming(ctx context.Context, doctorID uuid.UUID, after time.Time) (int64, error) {
+	return r.modifyUpcoming(doctorID, after, func(a *models.Appointment) { a.Status = "CANCELLED" }), nil
+}
+
+// modifyUpcoming applies fn to the pending and confirmed appointments of
+// doctorID starting after after.
+func (r *AppointmentRepository) modifyUpcoming(doctorID uuid.UUID, after time.Time, fn func(a *models.Appointment)) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for id, appointment := range r.appointments {
+		if appointment.DoctorID != doctorID || !appointment.AppointmentDate.After(after) ||
+			(appointment.Status != "PENDING" && appointment.Status != "CONFIRMED") {
+			continue
+		}
+		fn(&appointment)
+		appointment.UpdatedAt = time.Now()
+		appointment.Version++
+		r.appointments[id] = appointment
+		n++
+	}
+	return n
+}
+
+func (r *AppointmentRepository) filter(match func(a *models.Appointment) bool) []*models.Appointment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var appointments []*models.Appointment
+	for _, appointment := range r.appointments {
+		if match(&appointment) {
+			appointments = append(appointments, &appointment)
+		}
+	}
+	sort.SliceStable(appointments, func(i, j int) bool {
+		return appointments[i].AppointmentDate.After(appointments[j].AppointmentDate)
+	})
+
+	return appointments
+}
+
+func (r *AppointmentRepository) List(ctx context.Context, q listquery.Query[*models.Appointment]) (*listquery.Page[*models.Appointment], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	appointments := make([]*models.Appointment, 0, len(r.appointments))
+	for _, appointment := range r.appointments {
+		appointments = append(appointments, &appointment)
+	}
+	return list(appointments, q), nil
+}
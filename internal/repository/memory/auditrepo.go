@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type AuditRepository struct {
+	mu      sync.RWMutex
+	entries []models.AuditLog
+}
+
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, entry *models.AuditLog) (*models.AuditLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.Timestamp = time.Now()
+	r.entries = append(r.entries, *entry)
+
+	return entry, nil
+}
+
+func (r *AuditRepository) ListByResource(ctx context.Context, resourceType string, resourceID uuid.UUID) ([]*models.AuditLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []*models.AuditLog
+	for _, entry := range r.entries {
+		if entry.ResourceType == resourceType && entry.ResourceID == resourceID {
+			entries = append(entries, &entry)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type AvailabilityRepository struct {
+	mu           sync.RWMutex
+	availability map[uuid.UUID]models.Availability
+}
+
+func NewAvailabilityRepository() *AvailabilityRepository {
+	return &AvailabilityRepository{availability: make(map[uuid.UUID]models.Availability)}
+}
+
+func (r *AvailabilityRepository) CreateAvailability(ctx context.Context, availability *models.Availability) (*models.Availability, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	availability.CreatedAt = time.Now()
+	availability.UpdatedAt = availability.CreatedAt
+	r.availability[availability.AvailabilityID] = *availability
+
+	return availability, nil
+}
+
+func (r *AvailabilityRepository) ListByDoctorIDs(ctx context.Context, doctorIDs []uuid.UUID) ([]*models.Availability, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var availability []*models.Availability
+	for _, slot := range r.availability {
+		if slices.Contains(doctorIDs, slot.DoctorID) {
+			availability = append(availability, &slot)
+		}
+	}
+	slices.SortFunc(availability, func(a, b *models.Availability) int {
+		return cmp.Or(
+			strings.Compare(a.DoctorID.String(), b.DoctorID.String()),
+			strings.Compare(a.StartTime, b.StartTime),
+		)
+	})
+	return availability, nil
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+)
+
+type ConsultationRepository struct {
+	mu            sync.RWMutex
+	consultations map[uuid.UUID]models.Consultation
+}
+
+func NewConsultationRepository() *ConsultationRepository {
+	return &ConsultationRepository{consultations: make(map[uuid.UUID]models.Consultation)}
+}
+
+func (r *ConsultationRepository) Create(ctx context.Context, consultation *models.Consultation) (*models.Consultation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.consultations[consultation.ConsultationID]; exists {
+		return nil, uniqueViolation("consultation", "consultations_pkey")
+	}
+
+	consultation.CreatedAt = time.Now()
+	consultation.Version = 1
+	r.consultations[consultation.ConsultationID] = *consultation
+
+	return consultation, nil
+}
+
+func (r *ConsultationRepository) GetByID(ctx context.Context, consultationID uuid.UUID) (*models.Consultation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	consultation, ok := r.consultations[consultationID]
+	if !ok {
+		return nil, notFound("consultation")
+	}
+	return &consultation, nil
+}
+
+func (r *ConsultationRepository) GetByAppointmentID(ctx context.Context, appointmentID uuid.UUID) (*models.Consultation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, consultation := range r.consultations {
+		if consultation.AppointmentID == appointmentID {
+			return &consultation, nil
+		}
+	}
+	return nil, notFound("consultation")
+}
+
+func (r *ConsultationRepository) GetByPatientID(ctx context.Context, patientID uuid.UUID) ([]*models.Consultation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var consultations []*models.Consultation
+	for _, consultation := range r.consultations {
+		if consultation.PatientID == patientID {
+			consultations = append(consultations, &consultation)
+		}
+	}
+	sort.SliceStable(consultations, func(i, j int) bool {
+		return consultations[i].CreatedAt.After(consultations[j].CreatedAt)
+	})
+
+	return consultations, nil
+}
+
+func (r *ConsultationRepository) List(ctx context.Context, q listquery.Query[*models.Consultation]) (*listquery.Page[*models.Consultation], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	consultations := make([]*models.Consultation, 0, len(r.consultations))
+	for _, consultation := range r.consultations {
+		consultations = append(consultations, &consultation)
+	}
+	return list(consultations, q), nil
+}
+
+func (r *ConsultationRepository) Update(ctx context.Context, consultation *models.Consultation) (*models.Consultation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.consultations[consultation.ConsultationID]
+	if !ok {
+		return nil, notFound("consultation")
+	}
+	if existing.Version != consultation.Version {
+		return nil, repository.VersionConflict("consultation")
+	}
+
+	existing.Diagnosis = consultation.Diagnosis
+	existing.Notes = consultation.Notes
+	existing.Version++
+	r.consultations[existing.ConsultationID] = existing
+
+	return &existing, nil
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+)
+
+type DepartmentRepository struct {
+	mu          sync.RWMutex
+	departments map[uuid.UUID]models.Department
+}
+
+func NewDepartmentRepository() *DepartmentRepository {
+	return &DepartmentRepository{departments: make(map[uuid.UUID]models.Department)}
+}
+
+func (r *DepartmentRepository) CreateDepartment(ctx context.Context, department *models.Department) (*models.Department, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := models.Department{
+		ID:          uuid.New(),
+		Name:        department.Name,
+		Description: department.Description,
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+		Version:     1,
+	}
+	created.UpdatedAt = created.CreatedAt
+	r.departments[created.ID] = created
+
+	return &created, nil
+}
+
+func (r *DepartmentRepository) GetByID(ctx context.Context, deptID string) (*models.Department, error) {
+	id, err := uuid.Parse(deptID)
+	if err != nil {
+		return nil, invalidID("department", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	department, ok := r.departments[id]
+	if !ok {
+		return nil, notFound("department")
+	}
+	return &department, nil
+}
+
+func (r *DepartmentRepository) List(ctx context.Context, q listquery.Query[*models.Department]) (*listquery.Page[*models.Department], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	departments := make([]*models.Department, 0, len(r.departments))
+	for _, department := range r.departments {
+		departments = append(departments, &department)
+	}
+	return list(departments, q), nil
+}
+
+func (r *DepartmentRepository) UpdateDepartment(ctx context.Context, deptID string, request *repository.UpdateDepartmentRequest) (*models.Department, error) {
+	existing, err := r.GetByID(ctx, deptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.Version != request.Version {
+		return nil, repository.VersionConflict("department")
+	}
+	if request.Name == nil && request.Description == nil && request.IsActive == nil {
+		return existing, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if request.Name != nil {
+		existing.Name = *request.Name
+	}
+	if request.Description != nil {
+		existing.Description = *request.Description
+	}
+	if request.IsActive != nil {
+		existing.IsActive = *request.IsActive
+	}
+	existing.UpdatedAt = time.Now()
+	existing.Version++
+	r.departments[existing.ID] = *existing
+
+	return existing, nil
+}
+
+func (r *DepartmentRepository) DeleteDepartment(ctx context.Context, deptID string) error {
+	existing, err := r.GetByID(ctx, deptID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing.IsActive = false
+	existing.UpdatedAt = time.Now()
+	existing.Version++
+	r.departments[existing.ID] = *existing
+
+	return nil
+}
+
+func (r *DepartmentRepository) ExistsByName(ctx context.Context, name string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, department := range r.departments {
+		if department.IsActive && strings.EqualFold(department.Name, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
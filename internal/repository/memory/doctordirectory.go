@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+// DoctorDirectory joins the doctors, users and departments of in-memory
+// repositories, as DoctorRepository.Directory does in SQL.
+type DoctorDirectory struct {
+	doctors     *DoctorRepository
+	users       *UserRepository
+	departments *DepartmentRepository
+}
+
+func NewDoctorDirectory(doctors *DoctorRepository, users *UserRepository, departments *DepartmentRepository) *DoctorDirectory {
+	return &DoctorDirectory{doctors: doctors, users: users, departments: departments}
+}
+
+func (d *DoctorDirectory) Directory(ctx context.Context, q listquery.Query[*models.DoctorListing]) (*listquery.Page[*models.DoctorListing], error) {
+	d.doctors.mu.RLock()
+	defer d.doctors.mu.RUnlock()
+	d.users.mu.RLock()
+	defer d.users.mu.RUnlock()
+	d.departments.mu.RLock()
+	defer d.departments.mu.RUnlock()
+
+	listings := make([]*models.DoctorListing, 0, len(d.doctors.doctors))
+	for _, doctor := range d.doctors.doctors {
+		user, ok := d.users.users[doctor.UserID]
+		if !ok || !user.IsActive {
+			continue
+		}
+		listings = append(listings, &models.DoctorListing{
+			Doctor:         doctor,
+			FirstName:      deref(user.FirstName),
+			LastName:       deref(user.LastName),
+			DepartmentName: d.departments.departments[doctor.DepartmentID].Name,
+		})
+	}
+	return list(listings, q), nil
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type DoctorRepository struct {
+	mu      sync.RWMutex
+	doctors map[uuid.UUID]models.Doctor
+}
+
+func NewDoctorRepository() *DoctorRepository {
+	return &DoctorRepository{doctors: make(map[uuid.UUID]models.Doctor)}
+}
+
+func (r *DoctorRepository) Create(ctx context.Context, doctor *models.Doctor) (*models.Doctor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.doctors {
+		if existing.UserID == doctor.UserID {
+			return nil, uniqueViolation("doctor", "doctors_user_id_key")
+		}
+		if doctor.LicenseNumber != "" && existing.LicenseNumber == doctor.LicenseNumber {
+			return nil, uniqueViolation("doctor", "doctors_license_number_key")
+		}
+	}
+
+	doctor.IsAvailable = true
+	doctor.CreatedAt = time.Now()
+	doctor.UpdatedAt = doctor.CreatedAt
+	r.doctors[doctor.DoctorID] = *doctor
+
+	return doctor, nil
+}
+
+func (r *DoctorRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.Doctor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, doctor := range r.doctors {
+		if doctor.UserID == userID {
+			return &doctor, nil
+		}
+	}
+	return nil, notFound("doctor")
+}
+
+func (r *DoctorRepository) GetDoctorID(ctx context.Context, doctorID uuid.UUID) (*models.Doctor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	doctor, ok := r.doctors[doctorID]
+	if !ok {
+		return nil, notFound("doctor")
+	}
+	return &doctor, nil
+}
+
+func (r *DoctorRepository) Update(ctx context.Context, doctor *models.Doctor) (*models.Doctor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.doctors[doctor.DoctorID]
+	if !ok {
+		return nil, notFound("doctor")
+	}
+	for id, other := range r.doctors {
+		if id != doctor.DoctorID && doctor.LicenseNumber != "" && other.LicenseNumber == doctor.LicenseNumber {
+			return nil, uniqueViolation("doctor", "doctors_license_number_key")
+		}
+	}
+
+	existing.DepartmentID = doctor.DepartmentID
+	existing.Specialization = doctor.Specialization
+	existing.LicenseNumber = doctor.LicenseNumber
+	existing.ConsultationFee = doctor.ConsultationFee
+	existing.IsAvailable = doctor.IsAvailable
+	existing.UpdatedAt = time.Now()
+	r.doctors[existing.DoctorID] = existing
+
+	return &existing, nil
+}
+
+func (r *DoctorRepository) List(ctx context.Context, q listquery.Query[*models.Doctor]) (*listquery.Page[*models.Doctor], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	doctors := make([]*models.Doctor, 0, len(r.doctors))
+	for _, doctor := range r.doctors {
+		doctors = append(doctors, &doctor)
+	}
+	return list(doctors, q), nil
+}
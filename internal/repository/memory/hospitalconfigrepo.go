@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+// HospitalConfigRepository keeps revisions in order; revision n is at index
+// n-1.
+type HospitalConfigRepository struct {
+	mu        sync.RWMutex
+	revisions []models.HospitalConfig
+}
+
+func NewHospitalConfigRepository() *HospitalConfigRepository {
+	return &HospitalConfigRepository{}
+}
+
+func (r *HospitalConfigRepository) Create(ctx context.Context, config *models.HospitalConfig) (*models.HospitalConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	config.Revision = len(r.revisions) + 1
+	config.CreatedAt = time.Now()
+	r.revisions = append(r.revisions, *config)
+
+	return config, nil
+}
+
+func (r *HospitalConfigRepository) GetByRevision(ctx context.Context, revision int) (*models.HospitalConfig, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if revision < 1 || revision > len(r.revisions) {
+		return nil, notFound("hospital config")
+	}
+	config := r.revisions[revision-1]
+	return &config, nil
+}
+
+func (r *HospitalConfigRepository) GetEffective(ctx context.Context, at time.Time) (*models.HospitalConfig, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.revisions) - 1; i >= 0; i-- {
+		if config := r.revisions[i]; !config.EffectiveFrom.After(at) {
+			return &config, nil
+		}
+	}
+	return nil, notFound("hospital config")
+}
+
+func (r *HospitalConfigRepository) GetLatest(ctx context.Context) (*models.HospitalConfig, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.revisions) == 0 {
+		return nil, notFound("hospital config")
+	}
+	config := r.revisions[len(r.revisions)-1]
+	return &config, nil
+}
+
+func (r *HospitalConfigRepository) List(ctx context.Context, q listquery.Query[*models.HospitalConfig]) (*listquery.Page[*models.HospitalConfig], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	configs := make([]*models.HospitalConfig, 0, len(r.revisions))
+	for _, config := range r.revisions {
+		configs = append(configs, &config)
+	}
+	return list(configs, q), nil
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type InvitationRepository struct {
+	mu          sync.RWMutex
+	invitations map[uuid.UUID]models.Invitation
+}
+
+func NewInvitationRepository() *InvitationRepository {
+	return &InvitationRepository{invitations: make(map[uuid.UUID]models.Invitation)}
+}
+
+func (r *InvitationRepository) Create(ctx context.Context, invitation *models.Invitation) (*models.Invitation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.invitations {
+		if existing.TokenHash == invitation.TokenHash {
+			return nil, uniqueViolation("invitation", "user_invitations_token_hash_key")
+		}
+	}
+
+	invitation.CreatedAt = time.Now()
+	r.invitations[invitation.InvitationID] = *invitation
+
+	return invitation, nil
+}
+
+func (r *InvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash {
+			return &invitation, nil
+		}
+	}
+	return nil, notFound("invitation")
+}
+
+func (r *InvitationRepository) MarkUsed(ctx context.Context, invitationID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[invitationID]
+	if !ok || invitation.UsedAt != nil {
+		return notFound("invitation")
+	}
+	now := time.Now()
+	invitation.UsedAt = &now
+	r.invitations[invitationID] = invitation
+
+	return nil
+}
+
+func (r *InvitationRepository) DeleteUnused(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, invitation := range r.invitations {
+		if invitation.UserID == userID && invitation.UsedAt == nil {
+			delete(r.invitations, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type LabTestRepository struct {
+	mu    sync.RWMutex
+	tests map[uuid.UUID]models.LabTest
+}
+
+func NewLabTestRepository() *LabTestRepository {
+	return &LabTestRepository{tests: make(map[uuid.UUID]models.LabTest)}
+}
+
+func (r *LabTestRepository) Create(ctx context.Context, test *models.LabTest) (*models.LabTest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tests[test.TestID]; exists {
+		return nil, uniqueViolation("lab test", "lab_tests_pkey")
+	}
+
+	if test.Status == "" {
+		test.Status = "REQUESTED"
+	}
+	if test.RequestedAt.IsZero() {
+		test.RequestedAt = time.Now()
+	}
+	r.tests[test.TestID] = *test
+
+	return test, nil
+}
+
+func (r *LabTestRepository) GetCompletedByPatientID(ctx context.Context, patientID uuid.UUID) ([]*models.LabTest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tests []*models.LabTest
+	for _, test := range r.tests {
+		if test.PatientID == patientID && test.Status == "COMPLETED" {
+			tests = append(tests, &test)
+		}
+	}
+	sort.SliceStable(tests, func(i, j int) bool {
+		if tests[i].CompletedAt == nil || tests[j].CompletedAt == nil {
+			return tests[j].CompletedAt == nil && tests[i].CompletedAt != nil
+		}
+		return tests[i].CompletedAt.After(*tests[j].CompletedAt)
+	})
+
+	return tests, nil
+}
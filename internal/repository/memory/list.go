@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+)
+
+// list applies q to items the way the Postgres repositories' WHERE, ORDER BY
+// and LIMIT clauses do.
+func list[T any](items []T, q listquery.Query[T]) *listquery.Page[T] {
+	matched := make([]T, 0, len(items))
+	for _, item := range items {
+		if matches(item, q) {
+			matched = append(matched, item)
+		}
+	}
+
+	order := func(a, b T) int {
+		return cmp.Or(
+			compare(q.Sort.Get(a), q.Sort.Get(b)),
+			compare(q.ID.Get(a), q.ID.Get(b)),
+		)
+	}
+	if q.Desc {
+		slices.SortFunc(matched, func(a, b T) int { return order(b, a) })
+	} else {
+		slices.SortFunc(matched, order)
+	}
+
+	if len(matched) > q.Limit+1 {
+		matched = matched[:q.Limit+1]
+	}
+	return listquery.NewPage(matched, q)
+}
+
+func matches[T any](item T, q listquery.Query[T]) bool {
+	for _, c := range q.Conditions {
+		n := compare(c.Field.Get(item), c.Value)
+		switch c.Op {
+		case listquery.Eq:
+			if n != 0 {
+				return false
+			}
+		case listquery.Gte:
+			if n < 0 {
+				return false
+			}
+		case listquery.Lt:
+			if n >= 0 {
+				return false
+			}
+		case listquery.Lte:
+			if n > 0 {
+				return false
+			}
+		}
+	}
+
+	if q.After != nil {
+		n := cmp.Or(
+			compare(q.Sort.Get(item), q.After.Value),
+			compare(q.ID.Get(item), q.After.ID),
+		)
+		if q.Desc {
+			return n < 0
+		}
+		return n > 0
+	}
+	return true
+}
+
+// compare orders two values of the same listquery.Type.
+func compare(a, b any) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case int:
+		return cmp.Compare(a, b.(int))
+	case float64:
+		return cmp.Compare(a, b.(float64))
+	case bool:
+		switch {
+		case a == b.(bool):
+			return 0
+		case a:
+			return 1
+		default:
+			return -1
+		}
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case uuid.UUID:
+		b := b.(uuid.UUID)
+		return bytes.Compare(a[:], b[:])
+	default:
+		return 0
+	}
+}
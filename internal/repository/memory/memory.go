@@ -0,0 +1,36 @@
+// Package memory provides in-memory implementations of the repositories
+// consumed by the service layer. They mirror the observable behaviour of the
+// Postgres repositories closely enough to unit test service rules without a
+// database.
+package memory
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/falasefemi2/hms/internal/repository"
+)
+
+// notFound is the error the Postgres repositories return when no row matches.
+func notFound(resource string) error {
+	return repository.TranslateError(pgx.ErrNoRows, resource)
+}
+
+// uniqueViolation mimics the error Postgres returns for a duplicate key.
+func uniqueViolation(resource, constraint string) error {
+	return repository.TranslateError(&pgconn.PgError{
+		Code:           "23505",
+		Message:        fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+		ConstraintName: constraint,
+	}, resource)
+}
+
+// invalidID mimics Postgres rejecting a malformed UUID parameter.
+func invalidID(resource string, err error) error {
+	return repository.TranslateError(&pgconn.PgError{
+		Code:    "22P02",
+		Message: err.Error(),
+	}, resource)
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type NurseRepository struct {
+	mu     sync.RWMutex
+	nurses map[uuid.UUID]models.Nurse
+}
+
+func NewNurseRepository() *NurseRepository {
+	return &NurseRepository{nurses: make(map[uuid.UUID]models.Nurse)}
+}
+
+func (r *NurseRepository) Create(ctx context.Context, nurse *models.Nurse) (*models.Nurse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.nurses {
+		if existing.UserID == nurse.UserID {
+			return nil, uniqueViolation("nurse", "nurses_user_id_key")
+		}
+		if nurse.LicenseNumber != "" && existing.LicenseNumber == nurse.LicenseNumber {
+			return nil, uniqueViolation("nurse", "nurses_license_number_key")
+		}
+	}
+
+	nurse.CreatedAt = time.Now()
+	nurse.UpdatedAt = nurse.CreatedAt
+	r.nurses[nurse.NurseID] = *nurse
+
+	return nurse, nil
+}
+
+func (r *NurseRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.Nurse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, nurse := range r.nurses {
+		if nurse.UserID == userID {
+			return &nurse, nil
+		}
+	}
+	return nil, notFound("nurse")
+}
+
+func (r *NurseRepository) GetByNurseID(ctx context.Context, nurseID uuid.UUID) (*models.Nurse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nurse, ok := r.nurses[nurseID]
+	if !ok {
+		return nil, notFound("nurse")
+	}
+	return &nurse, nil
+}
+
+func (r *NurseRepository) Update(ctx context.Context, nurse *models.Nurse) (*models.Nurse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.nurses[nurse.NurseID]
+	if !ok {
+		return nil, notFound("nurse")
+	}
+	for id, other := range r.nurses {
+		if id != nurse.NurseID && nurse.LicenseNumber != "" && other.LicenseNumber == nurse.LicenseNumber {
+			return nil, uniqueViolation("nurse", "nurses_license_number_key")
+		}
+	}
+
+	existing.DepartmentID = nurse.DepartmentID
+	existing.Shift = nurse.Shift
+	existing.LicenseNumber = nurse.LicenseNumber
+	existing.UpdatedAt = time.Now()
+	r.nurses[existing.NurseID] = existing
+
+	return &existing, nil
+}
+
+func (r *NurseRepository) List(ctx context.Context, q listquery.Query[*models.Nurse]) (*listquery.Page[*models.Nurse], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nurses := make([]*models.Nurse, 0, len(r.nurses))
+	for _, nurse := range r.nurses {
+		nurses = append(nurses, &nurse)
+	}
+	return list(nurses, q), nil
+}
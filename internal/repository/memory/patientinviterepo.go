@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type PatientInviteRepository struct {
+	mu      sync.RWMutex
+	invites map[uuid.UUID]models.PatientInvite
+}
+
+func NewPatientInviteRepository() *PatientInviteRepository {
+	return &PatientInviteRepository{invites: make(map[uuid.UUID]models.PatientInvite)}
+}
+
+func (r *PatientInviteRepository) Create(ctx context.Context, invite *models.PatientInvite) (*models.PatientInvite, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.invites {
+		if existing.CodeHash == invite.CodeHash {
+			return nil, uniqueViolation("patient invite", "patient_invites_code_hash_key")
+		}
+	}
+
+	invite.CreatedAt = time.Now()
+	r.invites[invite.InviteID] = *invite
+
+	return invite, nil
+}
+
+func (r *PatientInviteRepository) GetByCodeHash(ctx context.Context, codeHash string) (*models.PatientInvite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, invite := range r.invites {
+		if invite.CodeHash == codeHash {
+			return &invite, nil
+		}
+	}
+	return nil, notFound("patient invite")
+}
+
+func (r *PatientInviteRepository) MarkUsed(ctx context.Context, inviteID, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invite, ok := r.invites[inviteID]
+	if !ok || invite.UsedAt != nil {
+		return notFound("patient invite")
+	}
+	now := time.Now()
+	invite.UsedAt = &now
+	invite.UsedBy = &userID
+	r.invites[inviteID] = invite
+
+	return nil
+}
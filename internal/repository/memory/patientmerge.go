@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/repository"
+)
+
+// PatientRecords reassigns the records the in-memory repositories hold for a
+// patient, as PatientRepository.ReassignRecords does across tables in
+// Postgres. Tables without an in-memory repository report zero rows.
+type PatientRecords struct {
+	appointments  *AppointmentRepository
+	consultations *ConsultationRepository
+}
+
+func NewPatientRecords(appointments *AppointmentRepository, consultations *ConsultationRepository) *PatientRecords {
+	return &PatientRecords{appointments: appointments, consultations: consultations}
+}
+
+func (r *PatientRecords) ReassignRecords(ctx context.Context, from, to uuid.UUID) (map[string]int64, error) {
+	moved := make(map[string]int64, len(repository.PatientRecordTables))
+	for _, table := range repository.PatientRecordTables {
+		moved[table] = 0
+	}
+
+	r.appointments.mu.Lock()
+	for id, appointment := range r.appointments.appointments {
+		if appointment.PatientID == from {
+			appointment.PatientID = to
+			r.appointments.appointments[id] = appointment
+			moved["appointments"]++
+		}
+	}
+	r.appointments.mu.Unlock()
+
+	r.consultations.mu.Lock()
+	for id, consultation := range r.consultations.consultations {
+		if consultation.PatientID == from {
+			consultation.PatientID = to
+			r.consultations.consultations[id] = consultation
+			moved["consultations"]++
+		}
+	}
+	r.consultations.mu.Unlock()
+
+	return moved, nil
+}
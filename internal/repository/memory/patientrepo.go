@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type PatientRepository struct {
+	mu       sync.RWMutex
+	patients map[uuid.UUID]models.Patient
+	mrnSeq   int64
+}
+
+func NewPatientRepository() *PatientRepository {
+	return &PatientRepository{patients: make(map[uuid.UUID]models.Patient)}
+}
+
+func (r *PatientRepository) PatientProfile(ctx context.Context, patient *models.Patient) (*models.Patient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.patients {
+		if existing.UserID == patient.UserID {
+			return nil, uniqueViolation("patient", "patients_user_id_key")
+		}
+		if patient.MRN != "" && existing.MRN == patient.MRN {
+			return nil, uniqueViolation("patient", "patients_mrn_key")
+		}
+	}
+
+	patient.CreatedAt = time.Now()
+	patient.UpdatedAt = patient.CreatedAt
+	r.patients[patient.PatientID] = *patient
+
+	return patient, nil
+}
+
+func (r *PatientRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.Patient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, patient := range r.patients {
+		if patient.UserID == userID {
+			return &patient, nil
+		}
+	}
+	return nil, notFound("patient")
+}
+
+func (r *PatientRepository) GetByPatientID(ctx context.Context, patientID uuid.UUID) (*models.Patient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	patient, ok := r.patients[patientID]
+	if !ok {
+		return nil, notFound("patient")
+	}
+	return &patient, nil
+}
+
+func (r *PatientRepository) Update(ctx context.Context, patient *models.Patient) (*models.Patient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.patients[patient.PatientID]
+	if !ok {
+		return nil, notFound("patient")
+	}
+
+	existing.DateOfBirth = patient.DateOfBirth
+	existing.Gender = patient.Gender
+	existing.BloodGroup = patient.BloodGroup
+	existing.EmergencyContactName = patient.EmergencyContactName
+	existing.EmergencyContactPhone = patient.EmergencyContactPhone
+	existing.MedicalHistory = patient.MedicalHistory
+	existing.UpdatedAt = time.Now()
+	r.patients[existing.PatientID] = existing
+
+	return &existing, nil
+}
+
+func (r *PatientRepository) List(ctx context.Context, q listquery.Query[*models.Patient]) (*listquery.Page[*models.Patient], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	patients := make([]*models.Patient, 0, len(r.patients))
+	for _, patient := range r.patients {
+		patients = append(patients, &patient)
+	}
+	return list(patients, q), nil
+}
+
+func (r *PatientRepository) NextMRNSequence(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mrnSeq++
+	return r.mrnSeq, nil
+}
+
+func (r *PatientRepository) Delete(ctx context.Context, patientID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.patients[patientID]; !ok {
+		return notFound("patient")
+	}
+	delete(r.patients, patientID)
+	return nil
+}
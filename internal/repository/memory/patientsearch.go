@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+)
+
+// pg_trgm's default pg_trgm.similarity_threshold and
+// pg_trgm.word_similarity_threshold.
+const (
+	similarityThreshold     = 0.3
+	wordSimilarityThreshold = 0.6
+)
+
+// PatientSearcher searches the patients of one repository joined with the
+// users of another, as the Postgres PatientRepository does in SQL.
+type PatientSearcher struct {
+	patients *PatientRepository
+	users    *UserRepository
+}
+
+func NewPatientSearcher(patients *PatientRepository, users *UserRepository) *PatientSearcher {
+	return &PatientSearcher{patients: patients, users: users}
+}
+
+func (s *PatientSearcher) Search(ctx context.Context, search repository.PatientSearch) ([]*models.PatientMatch, error) {
+	s.patients.mu.RLock()
+	defer s.patients.mu.RUnlock()
+	s.users.mu.RLock()
+	defer s.users.mu.RUnlock()
+
+	query := strings.ToLower(search.Name)
+	phone := repository.PhoneKey(search.Phone)
+
+	var matches []*models.PatientMatch
+	for _, patient := range s.patients.patients {
+		user, ok := s.users.users[patient.UserID]
+		if !ok {
+			continue
+		}
+
+		match := &models.PatientMatch{
+			Patient:   patient,
+			FirstName: deref(user.FirstName),
+			LastName:  deref(user.LastName),
+			Email:     user.Email,
+			Phone:     deref(user.Phone),
+			Score:     1,
+		}
+
+		if query != "" {
+			name := strings.ToLower(match.FirstName + " " + match.LastName)
+			sim, wordSim := similarity(name, query), wordSimilarity(query, name)
+			if sim < similarityThreshold && wordSim < wordSimilarityThreshold {
+				continue
+			}
+			match.Score = max(sim, wordSim)
+		}
+		if search.Phone != "" && !strings.HasSuffix(repository.PhoneKey(match.Phone), phone) {
+			continue
+		}
+		if search.DateOfBirth != nil && !patient.DateOfBirth.Equal(*search.DateOfBirth) {
+			continue
+		}
+
+		matches = append(matches, match)
+	}
+
+	slices.SortFunc(matches, func(a, b *models.PatientMatch) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			strings.Compare(a.LastName, b.LastName),
+			strings.Compare(a.Patient.PatientID.String(), b.Patient.PatientID.String()),
+		)
+	})
+	if len(matches) > search.Limit {
+		matches = matches[:search.Limit]
+	}
+	return matches, nil
+}
+
+// trigrams returns the trigrams of s in order, the way pg_trgm builds them:
+// each alphanumeric word is padded with two spaces in front and one behind.
+func trigrams(s string) []string {
+	var out []string
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			out = append(out, string(padded[i:i+3]))
+		}
+	}
+	return out
+}
+
+func trigramSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, t := range trigrams(s) {
+		set[t] = true
+	}
+	return set
+}
+
+// similarity is pg_trgm's similarity: shared trigrams over all trigrams.
+func similarity(a, b string) float64 {
+	ta, tb := trigramSet(a), trigramSet(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// wordSimilarity is pg_trgm's word_similarity(query, text): the best
+// similarity between the trigrams of query and any contiguous run of the
+// trigrams of text.
+func wordSimilarity(query, text string) float64 {
+	q, t := trigramSet(query), trigrams(text)
+	if len(q) == 0 {
+		return 0
+	}
+
+	best := 0.0
+	for i := range t {
+		shared, extra := map[string]bool{}, map[string]bool{}
+		for _, tri := range t[i:] {
+			if q[tri] {
+				shared[tri] = true
+			} else {
+				extra[tri] = true
+			}
+			best = max(best, float64(len(shared))/float64(len(q)+len(extra)))
+		}
+	}
+	return best
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type PrescriptionRepository struct {
+	mu            sync.RWMutex
+	prescriptions map[uuid.UUID]models.Prescription
+}
+
+func NewPrescriptionRepository() *PrescriptionRepository {
+	return &PrescriptionRepository{prescriptions: make(map[uuid.UUID]models.Prescription)}
+}
+
+func (r *PrescriptionRepository) Create(ctx context.Context, prescription *models.Prescription) (*models.Prescription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.prescriptions[prescription.PrescriptionID]; exists {
+		return nil, uniqueViolation("prescription", "prescriptions_pkey")
+	}
+
+	if prescription.CreatedAt.IsZero() {
+		prescription.CreatedAt = time.Now()
+	}
+	r.prescriptions[prescription.PrescriptionID] = *prescription
+
+	return prescription, nil
+}
+
+func (r *PrescriptionRepository) GetByPatientID(ctx context.Context, patientID uuid.UUID) ([]*models.Prescription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var prescriptions []*models.Prescription
+	for _, prescription := range r.prescriptions {
+		if prescription.PatientID == patientID {
+			prescriptions = append(prescriptions, &prescription)
+		}
+	}
+	sort.SliceStable(prescriptions, func(i, j int) bool {
+		return prescriptions[i].CreatedAt.After(prescriptions[j].CreatedAt)
+	})
+
+	return prescriptions, nil
+}
+
+func (r *PrescriptionRepository) List(ctx context.Context, q listquery.Query[*models.Prescription]) (*listquery.Page[*models.Prescription], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prescriptions := make([]*models.Prescription, 0, len(r.prescriptions))
+	for _, prescription := range r.prescriptions {
+		prescriptions = append(prescriptions, &prescription)
+	}
+	return list(prescriptions, q), nil
+}
@@ -0,0 +1,30 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+type txKey struct{}
+
+// Transactor serializes transactions so check-then-write sequences in the
+// services behave atomically. Unlike Postgres it does not roll back writes
+// made before fn returns an error.
+type Transactor struct {
+	mu sync.Mutex
+}
+
+func NewTransactor() *Transactor {
+	return &Transactor{}
+}
+
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return fn(context.WithValue(ctx, txKey{}, true))
+}
@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type UserRepository struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]models.User
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{users: make(map[uuid.UUID]models.User)}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Username == user.Username {
+			return nil, uniqueViolation("user", "users_username_key")
+		}
+		if existing.Email == user.Email {
+			return nil, uniqueViolation("user", "users_email_key")
+		}
+	}
+
+	created := *user
+	created.ID = uuid.New()
+	created.IsActive = true
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	r.users[created.ID] = created
+
+	created.PasswordHash = ""
+	return &created, nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, userID string) (*models.User, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, invalidID("user", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, notFound("user")
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, notFound("user")
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.users[user.ID]
+	if !ok {
+		return nil, notFound("user")
+	}
+
+	existing.Username = user.Username
+	existing.Email = user.Email
+	existing.FirstName = user.FirstName
+	existing.LastName = user.LastName
+	existing.Phone = user.Phone
+	existing.Role = user.Role
+	existing.IsActive = user.IsActive
+	existing.UpdatedAt = time.Now()
+	r.users[existing.ID] = existing
+
+	existing.PasswordHash = ""
+	return &existing, nil
+}
+
+// Delete mirrors the Postgres repository, whose int64 id can never match a
+// UUID primary key.
+func (r *UserRepository) Delete(ctx context.Context, userID int64) error {
+	return notFound("user")
+}
+
+func (r *UserRepository) List(ctx context.Context, q listquery.Query[*models.User]) (*listquery.Page[*models.User], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*models.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, &user)
+	}
+	return list(users, q), nil
+}
+
+func (r *UserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Username == username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *UserRepository) Count(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.users)), nil
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
+	return r.modify(userID, func(user *models.User) {
+		user.PasswordHash = passwordHash
+	})
+}
+
+func (r *UserRepository) SetActive(ctx context.Context, userID string, active bool) error {
+	return r.modify(userID, func(user *models.User) {
+		user.IsActive = active
+	})
+}
+
+func (r *UserRepository) modify(userID string, fn func(user *models.User)) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return invalidID("user", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return notFound("user")
+	}
+	fn(&user)
+	user.UpdatedAt = time.Now()
+	r.users[id] = user
+	return nil
+}
@@ -5,8 +5,10 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
 )
 
@@ -33,7 +35,7 @@ func (n *NurseRepository) Create(ctx context.Context, nurse *models.Nurse) (*mod
   RETURNING created_at, updated_at
 	`
 
-	err := n.pool.QueryRow(ctx, query,
+	err := querier(ctx, n.pool).QueryRow(ctx, query,
 		nurse.NurseID,
 		nurse.UserID,
 		nurse.DepartmentID,
@@ -42,7 +44,7 @@ func (n *NurseRepository) Create(ctx context.Context, nurse *models.Nurse) (*mod
 	).Scan(&nurse.CreatedAt, &nurse.UpdatedAt)
 
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "nurse")
 	}
 	return nurse, nil
 }
@@ -61,7 +63,7 @@ func (n *NurseRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*m
 		`
 
 	var nurse models.Nurse
-	err := n.pool.QueryRow(ctx, query, userID).Scan(
+	err := querier(ctx, n.pool).QueryRow(ctx, query, userID).Scan(
 		&nurse.NurseID,
 		&nurse.UserID,
 		&nurse.DepartmentID,
@@ -71,8 +73,101 @@ func (n *NurseRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*m
 		&nurse.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "nurse")
 	}
 
 	return &nurse, nil
 }
+
+func (n *NurseRepository) GetByNurseID(ctx context.Context, nurseID uuid.UUID) (*models.Nurse, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT nurse_id, user_id, department_id, shift, license_number, created_at, updated_at
+		FROM nurses
+		WHERE nurse_id = $1
+		`
+
+	var nurse models.Nurse
+	err := querier(ctx, n.pool).QueryRow(ctx, query, nurseID).Scan(
+		&nurse.NurseID,
+		&nurse.UserID,
+		&nurse.DepartmentID,
+		&nurse.Shift,
+		&nurse.LicenseNumber,
+		&nurse.CreatedAt,
+		&nurse.UpdatedAt,
+	)
+	if err != nil {
+		return nil, TranslateError(err, "nurse")
+	}
+
+	return &nurse, nil
+}
+
+func (n *NurseRepository) Update(ctx context.Context, nurse *models.Nurse) (*models.Nurse, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+    UPDATE nurses
+    SET department_id = $2, shift = $3, license_number = $4, updated_at = CURRENT_TIMESTAMP
+    WHERE nurse_id = $1
+    RETURNING nurse_id, user_id, department_id, shift, license_number, created_at, updated_at
+`
+	var updated models.Nurse
+	err := querier(ctx, n.pool).QueryRow(ctx, query,
+		nurse.NurseID,
+		nurse.DepartmentID,
+		nurse.Shift,
+		nurse.LicenseNumber,
+	).Scan(
+		&updated.NurseID,
+		&updated.UserID,
+		&updated.DepartmentID,
+		&updated.Shift,
+		&updated.LicenseNumber,
+		&updated.CreatedAt,
+		&updated.UpdatedAt,
+	)
+	if err != nil {
+		return nil, TranslateError(err, "nurse")
+	}
+
+	return &updated, nil
+}
+
+// List returns one page of nurses matching q.
+func (n *NurseRepository) List(ctx context.Context, q listquery.Query[*models.Nurse]) (*listquery.Page[*models.Nurse], error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT nurse_id, user_id, department_id, shift, license_number, created_at, updated_at
+		FROM nurses
+	`
+
+	return list(ctx, querier(ctx, n.pool), query, q, "nurse", func(rows pgx.Rows) (*models.Nurse, error) {
+		var nurse models.Nurse
+		err := rows.Scan(
+			&nurse.NurseID,
+			&nurse.UserID,
+			&nurse.DepartmentID,
+			&nurse.Shift,
+			&nurse.LicenseNumber,
+			&nurse.CreatedAt,
+			&nurse.UpdatedAt,
+		)
+		return &nurse, err
+	})
+}
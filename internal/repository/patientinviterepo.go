@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type PatientInviteRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPatientInviteRepository(pool *pgxpool.Pool) *PatientInviteRepository {
+	return &PatientInviteRepository{
+		pool: pool,
+	}
+}
+
+func (r *PatientInviteRepository) Create(ctx context.Context, invite *models.PatientInvite) (*models.PatientInvite, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		INSERT INTO patient_invites (invite_id, code_hash, created_by, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+	err := querier(ctx, r.pool).QueryRow(ctx, query,
+		invite.InviteID,
+		invite.CodeHash,
+		invite.CreatedBy,
+		invite.ExpiresAt,
+	).Scan(&invite.CreatedAt)
+	if err != nil {
+		return nil, TranslateError(err, "patient invite")
+	}
+
+	return invite, nil
+}
+
+// GetByCodeHash returns the invite for a code hash, locking it for the rest
+// of the transaction so it can only be redeemed once.
+func (r *PatientInviteRepository) GetByCodeHash(ctx context.Context, codeHash string) (*models.PatientInvite, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT invite_id, code_hash, created_by, expires_at, used_at, used_by, created_at
+		FROM patient_invites
+		WHERE code_hash = $1
+		FOR UPDATE
+	`
+
+	var invite models.PatientInvite
+	err := querier(ctx, r.pool).QueryRow(ctx, query, codeHash).Scan(
+		&invite.InviteID,
+		&invite.CodeHash,
+		&invite.CreatedBy,
+		&invite.ExpiresAt,
+		&invite.UsedAt,
+		&invite.UsedBy,
+		&invite.CreatedAt,
+	)
+	if err != nil {
+		return nil, TranslateError(err, "patient invite")
+	}
+
+	return &invite, nil
+}
+
+// MarkUsed records that an unused invite was redeemed by userID.
+func (r *PatientInviteRepository) MarkUsed(ctx context.Context, inviteID, userID uuid.UUID) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		UPDATE patient_invites
+		SET used_at = CURRENT_TIMESTAMP, used_by = $2
+		WHERE invite_id = $1 AND used_at IS NULL
+	`
+
+	result, err := querier(ctx, r.pool).Exec(ctx, query, inviteID, userID)
+	if err != nil {
+		return TranslateError(err, "patient invite")
+	}
+	if result.RowsAffected() == 0 {
+		return TranslateError(pgx.ErrNoRows, "patient invite")
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PatientRecordTables are the tables holding a patient's clinical records,
+// in the order ReassignRecords moves them.
+var PatientRecordTables = []string{
+	"appointments",
+	"consultations",
+	"prescriptions",
+	"patient_vitals",
+	"lab_tests",
+	"patient_care_notes",
+}
+
+// ReassignRecords moves every clinical record of patient from to patient to
+// and returns how many rows of each table were moved. It should run inside a
+// transaction so that a merge never leaves records split between the two.
+func (p *PatientRepository) ReassignRecords(ctx context.Context, from, to uuid.UUID) (map[string]int64, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	db := querier(ctx, p.pool)
+	moved := make(map[string]int64, len(PatientRecordTables))
+	for _, table := range PatientRecordTables {
+		// table comes from the fixed list above, never from input.
+		result, err := db.Exec(ctx, `UPDATE `+table+` SET patient_id = $2 WHERE patient_id = $1`, from, to)
+		if err != nil {
+			return nil, TranslateError(err, "patient")
+		}
+		moved[table] = result.RowsAffected()
+	}
+
+	return moved, nil
+}
@@ -5,8 +5,10 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
 )
 
@@ -29,13 +31,14 @@ func (p *PatientRepository) PatientProfile(ctx context.Context, patient *models.
 
 	query := `
 
-	INSERT INTO patients (patient_id, user_id, date_of_birth, gender, blood_group, emergency_contact_name, emergency_contact_phone, medical_history)
-	VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+	INSERT INTO patients (patient_id, user_id, mrn, date_of_birth, gender, blood_group, emergency_contact_name, emergency_contact_phone, medical_history)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
 	RETURNING created_at, updated_at
 	`
-	err := p.pool.QueryRow(ctx, query,
+	err := querier(ctx, p.pool).QueryRow(ctx, query,
 		patient.PatientID,
 		patient.UserID,
+		patient.MRN,
 		patient.DateOfBirth,
 		patient.Gender,
 		patient.BloodGroup,
@@ -45,7 +48,7 @@ func (p *PatientRepository) PatientProfile(ctx context.Context, patient *models.
 	).Scan(&patient.CreatedAt, &patient.UpdatedAt)
 
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "patient")
 	}
 	return patient, nil
 }
@@ -58,15 +61,16 @@ func (p *PatientRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (
 	}
 
 	query := `
-		SELECT patient_id, user_id, date_of_birth, gender, blood_group, emergency_contact_name, emergency_contact_phone, medical_history, created_at, updated_at
+		SELECT patient_id, user_id, mrn, date_of_birth, gender, blood_group, emergency_contact_name, emergency_contact_phone, medical_history, created_at, updated_at
 		FROM patients
 		WHERE user_id = $1
 	`
 
 	var patient models.Patient
-	err := p.pool.QueryRow(ctx, query, userID).Scan(
+	err := querier(ctx, p.pool).QueryRow(ctx, query, userID).Scan(
 		&patient.PatientID,
 		&patient.UserID,
+		&patient.MRN,
 		&patient.DateOfBirth,
 		&patient.Gender,
 		&patient.BloodGroup,
@@ -77,7 +81,7 @@ func (p *PatientRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (
 		&patient.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "patient")
 	}
 
 	return &patient, nil
@@ -91,7 +95,7 @@ func (p *PatientRepository) GetByPatientID(ctx context.Context, patientID uuid.U
 	}
 
 	query := `
-		SELECT patient_id, user_id, date_of_birth, gender, blood_group,
+		SELECT patient_id, user_id, mrn, date_of_birth, gender, blood_group,
 		       emergency_contact_name, emergency_contact_phone,
 		       medical_history, created_at, updated_at
 		FROM patients
@@ -99,9 +103,10 @@ func (p *PatientRepository) GetByPatientID(ctx context.Context, patientID uuid.U
 	`
 
 	var patient models.Patient
-	err := p.pool.QueryRow(ctx, query, patientID).Scan(
+	err := querier(ctx, p.pool).QueryRow(ctx, query, patientID).Scan(
 		&patient.PatientID,
 		&patient.UserID,
+		&patient.MRN,
 		&patient.DateOfBirth,
 		&patient.Gender,
 		&patient.BloodGroup,
@@ -112,8 +117,122 @@ func (p *PatientRepository) GetByPatientID(ctx context.Context, patientID uuid.U
 		&patient.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "patient")
 	}
 
 	return &patient, nil
 }
+
+func (p *PatientRepository) Update(ctx context.Context, patient *models.Patient) (*models.Patient, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+	UPDATE patients
+	SET date_of_birth = $2, gender = $3, blood_group = $4, emergency_contact_name = $5,
+	    emergency_contact_phone = $6, medical_history = $7, updated_at = CURRENT_TIMESTAMP
+	WHERE patient_id = $1
+	RETURNING patient_id, user_id, mrn, date_of_birth, gender, blood_group, emergency_contact_name, emergency_contact_phone, medical_history, created_at, updated_at
+	`
+
+	var updated models.Patient
+	err := querier(ctx, p.pool).QueryRow(ctx, query,
+		patient.PatientID,
+		patient.DateOfBirth,
+		patient.Gender,
+		patient.BloodGroup,
+		patient.EmergencyContactName,
+		patient.EmergencyContactPhone,
+		patient.MedicalHistory,
+	).Scan(
+		&updated.PatientID,
+		&updated.UserID,
+		&updated.MRN,
+		&updated.DateOfBirth,
+		&updated.Gender,
+		&updated.BloodGroup,
+		&updated.EmergencyContactName,
+		&updated.EmergencyContactPhone,
+		&updated.MedicalHistory,
+		&updated.CreatedAt,
+		&updated.UpdatedAt,
+	)
+	if err != nil {
+		return nil, TranslateError(err, "patient")
+	}
+
+	return &updated, nil
+}
+
+// List returns one page of patients matching q.
+func (p *PatientRepository) List(ctx context.Context, q listquery.Query[*models.Patient]) (*listquery.Page[*models.Patient], error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT patient_id, user_id, mrn, date_of_birth, gender, blood_group, emergency_contact_name, emergency_contact_phone, medical_history, created_at, updated_at
+		FROM patients
+	`
+
+	return list(ctx, querier(ctx, p.pool), query, q, "patient", func(rows pgx.Rows) (*models.Patient, error) {
+		var patient models.Patient
+		err := rows.Scan(
+			&patient.PatientID,
+			&patient.UserID,
+			&patient.MRN,
+			&patient.DateOfBirth,
+			&patient.Gender,
+			&patient.BloodGroup,
+			&patient.EmergencyContactName,
+			&patient.EmergencyContactPhone,
+			&patient.MedicalHistory,
+			&patient.CreatedAt,
+			&patient.UpdatedAt,
+		)
+		return &patient, err
+	})
+}
+
+// NextMRNSequence draws the next number for a medical record number.
+func (p *PatientRepository) NextMRNSequence(ctx context.Context) (int64, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	var seq int64
+	err := querier(ctx, p.pool).QueryRow(ctx, `SELECT nextval('patient_mrn_seq')`).Scan(&seq)
+	if err != nil {
+		return 0, TranslateError(err, "patient")
+	}
+
+	return seq, nil
+}
+
+func (p *PatientRepository) Delete(ctx context.Context, patientID uuid.UUID) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `DELETE FROM patients WHERE patient_id = $1`
+
+	result, err := querier(ctx, p.pool).Exec(ctx, query, patientID)
+	if err != nil {
+		return TranslateError(err, "patient")
+	}
+
+	if result.RowsAffected() == 0 {
+		return TranslateError(pgx.ErrNoRows, "patient")
+	}
+
+	return nil
+}
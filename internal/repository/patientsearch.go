@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+// PatientSearch holds the criteria of a patient search. Empty criteria are
+// ignored; the ones set must all match.
+type PatientSearch struct {
+	Name        string
+	Phone       string
+	DateOfBirth *time.Time
+	Limit       int
+}
+
+// PhoneKey reduces a phone number to the digits compared by search: at most
+// the last ten, so that "+234 803 123 4567" and "0803-123-4567" match.
+func PhoneKey(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+	if len(digits) > 10 {
+		digits = digits[len(digits)-10:]
+	}
+	return digits
+}
+
+// patientNameExpr must match the expression indexed by
+// idx_users_full_name_trgm.
+const patientNameExpr = `lower(COALESCE(u.first_name, '') || ' ' || COALESCE(u.last_name, ''))`
+
+// Search finds patients by fuzzy name (pg_trgm), phone suffix and exact date
+// of birth, best name matches first.
+func (p *PatientRepository) Search(ctx context.Context, search PatientSearch) ([]*models.PatientMatch, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	var (
+		where []string
+		args  []any
+	)
+	bind := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	score := "1.0"
+	if search.Name != "" {
+		name := bind(strings.ToLower(search.Name))
+		score = fmt.Sprintf("GREATEST(similarity(%[1]s, %[2]s), word_similarity(%[2]s, %[1]s))", patientNameExpr, name)
+		where = append(where, fmt.Sprintf("(%[1]s %% %[2]s OR %[2]s <%% %[1]s)", patientNameExpr, name))
+	}
+	if search.Phone != "" {
+		where = append(where, fmt.Sprintf(`regexp_replace(COALESCE(u.phone, ''), '\D', '', 'g') LIKE '%%' || %s`, bind(PhoneKey(search.Phone))))
+	}
+	if search.DateOfBirth != nil {
+		where = append(where, "p.date_of_birth = "+bind(*search.DateOfBirth))
+	}
+	if len(where) == 0 {
+		where = append(where, "TRUE")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT p.patient_id, p.user_id, p.mrn, p.date_of_birth, p.gender, p.blood_group,
+		       p.emergency_contact_name, p.emergency_contact_phone,
+		       p.medical_history, p.created_at, p.updated_at,
+		       COALESCE(u.first_name, ''), COALESCE(u.last_name, ''), u.email, COALESCE(u.phone, ''),
+		       %s AS score
+		FROM patients p
+		JOIN users u ON u.user_id = p.user_id
+		WHERE %s
+		ORDER BY score DESC, u.last_name, p.patient_id
+		LIMIT %s
+	`, score, strings.Join(where, " AND "), bind(search.Limit))
+
+	rows, err := querier(ctx, p.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, TranslateError(err, "patient")
+	}
+	defer rows.Close()
+
+	var matches []*models.PatientMatch
+	for rows.Next() {
+		var match models.PatientMatch
+		err := rows.Scan(
+			&match.Patient.PatientID,
+			&match.Patient.UserID,
+			&match.Patient.MRN,
+			&match.Patient.DateOfBirth,
+			&match.Patient.Gender,
+			&match.Patient.BloodGroup,
+			&match.Patient.EmergencyContactName,
+			&match.Patient.EmergencyContactPhone,
+			&match.Patient.MedicalHistory,
+			&match.Patient.CreatedAt,
+			&match.Patient.UpdatedAt,
+			&match.FirstName,
+			&match.LastName,
+			&match.Email,
+			&match.Phone,
+			&match.Score,
+		)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, &match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err, "patient")
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+)
+
+type PrescriptionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPrescriptionRepository(pool *pgxpool.Pool) *PrescriptionRepository {
+	return &PrescriptionRepository{
+		pool: pool,
+	}
+}
+
+// GetByPatientID returns a patient's prescriptions, newest first.
+func (r *PrescriptionRepository) GetByPatientID(ctx context.Context, patientID uuid.UUID) ([]*models.Prescription, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT prescription_id, consultation_id, patient_id, doctor_id, medication_name, dosage, frequency,
+		       duration_days, COALESCE(instructions, ''), created_at, COALESCE(is_immutable, false)
+		FROM prescriptions
+		WHERE patient_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := querier(ctx, r.pool).Query(ctx, query, patientID)
+	if err != nil {
+		return nil, TranslateError(err, "prescription")
+	}
+	defer rows.Close()
+
+	var prescriptions []*models.Prescription
+	for rows.Next() {
+		var prescription models.Prescription
+		err := rows.Scan(
+			&prescription.PrescriptionID,
+			&prescription.ConsultationID,
+			&prescription.PatientID,
+			&prescription.DoctorID,
+			&prescription.MedicationName,
+			&prescription.Dosage,
+			&prescription.Frequency,
+			&prescription.DurationDays,
+			&prescription.Instructions,
+			&prescription.CreatedAt,
+			&prescription.IsImmutable,
+		)
+		if err != nil {
+			return nil, TranslateError(err, "prescription")
+		}
+		prescriptions = append(prescriptions, &prescription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, TranslateError(err, "prescription")
+	}
+
+	return prescriptions, nil
+}
+
+// List returns one page of prescriptions matching q.
+func (r *PrescriptionRepository) List(ctx context.Context, q listquery.Query[*models.Prescription]) (*listquery.Page[*models.Prescription], error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
+	query := `
+		SELECT prescription_id, consultation_id, patient_id, doctor_id, medication_name, dosage, frequency,
+		       duration_days, COALESCE(instructions, ''), created_at, COALESCE(is_immutable, false)
+		FROM prescriptions
+	`
+
+	return list(ctx, querier(ctx, r.pool), query, q, "prescription", func(rows pgx.Rows) (*models.Prescription, error) {
+		var prescription models.Prescription
+		err := rows.Scan(
+			&prescription.PrescriptionID,
+			&prescription.ConsultationID,
+			&prescription.PatientID,
+			&prescription.DoctorID,
+			&prescription.MedicationName,
+			&prescription.Dosage,
+			&prescription.Frequency,
+			&prescription.DurationDays,
+			&prescription.Instructions,
+			&prescription.CreatedAt,
+			&prescription.IsImmutable,
+		)
+		return &prescription, err
+	})
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/falasefemi2/hms/internal/logging"
+)
+
+const (
+	defaultTxAttempts = 3
+	txRetryBackoff    = 25 * time.Millisecond
+)
+
+// DBTX is the subset of pgxpool.Pool and pgx.Tx used by the repositories, so a
+// query runs the same way whether or not it is part of a transaction.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+// querier returns the transaction stored in ctx by TxManager, or the pool
+// when the caller is not inside a transaction.
+func querier(ctx context.Context, pool *pgxpool.Pool) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return pool
+}
+
+// TxManager runs a function inside a database transaction that repositories
+// pick up from the context.
+type TxManager struct {
+	pool     *pgxpool.Pool
+	attempts int
+}
+
+func NewTxManager(pool *pgxpool.Pool) *TxManager {
+	return &TxManager{
+		pool:     pool,
+		attempts: defaultTxAttempts,
+	}
+}
+
+// WithinTransaction runs fn in a serializable transaction and commits it if
+// fn returns nil. Serialization failures and deadlocks are retried, so fn may
+// run more than once and must not have side effects outside the database.
+// When ctx already carries a transaction, fn joins it and the outer caller
+// owns commit and rollback.
+func (m *TxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	var err error
+	for attempt := 1; attempt <= m.attempts; attempt++ {
+		err = m.runOnce(ctx, fn)
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+
+		logging.FromContext(ctx).WarnContext(ctx, "retrying transaction", "attempt", attempt, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * txRetryBackoff):
+		}
+	}
+
+	return fmt.Errorf("transaction failed after %d attempts: %w", m.attempts, err)
+}
+
+func (m *TxManager) runOnce(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := m.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	// 40001 serialization_failure, 40P01 deadlock_detected
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
-	"github.com/falasefemi2/hms/internal/utils"
 )
 
 type UserRepository struct {
@@ -50,7 +51,7 @@ func (ur *UserRepository) Create(ctx context.Context, user *models.User) (*model
 			updated_at
 	`
 
-	row := ur.pool.QueryRow(
+	row := querier(ctx, ur.pool).QueryRow(
 		ctx,
 		query,
 		user.Username,
@@ -77,7 +78,7 @@ func (ur *UserRepository) Create(ctx context.Context, user *models.User) (*model
 		&created.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "user")
 	}
 
 	return &created, nil
@@ -101,7 +102,7 @@ func (ur *UserRepository) GetByID(ctx context.Context, userID string) (*models.U
 		WHERE user_id = $1
 	`
 
-	row := ur.pool.QueryRow(ctx, query, userID)
+	row := querier(ctx, ur.pool).QueryRow(ctx, query, userID)
 
 	var user models.User
 	err := row.Scan(
@@ -118,7 +119,7 @@ func (ur *UserRepository) GetByID(ctx context.Context, userID string) (*models.U
 		&user.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "user")
 	}
 
 	return &user, nil
@@ -142,7 +143,7 @@ func (ur *UserRepository) GetByEmail(ctx context.Context, email string) (*models
 		WHERE email = $1
 	`
 
-	row := ur.pool.QueryRow(ctx, query, email)
+	row := querier(ctx, ur.pool).QueryRow(ctx, query, email)
 
 	var user models.User
 	err := row.Scan(
@@ -159,7 +160,7 @@ func (ur *UserRepository) GetByEmail(ctx context.Context, email string) (*models
 		&user.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "user")
 	}
 
 	return &user, nil
@@ -191,7 +192,7 @@ func (ur *UserRepository) Update(ctx context.Context, user *models.User) (*model
 			updated_at
 	`
 
-	row := ur.pool.QueryRow(
+	row := querier(ctx, ur.pool).QueryRow(
 		ctx,
 		query,
 		user.Username,
@@ -218,7 +219,7 @@ func (ur *UserRepository) Update(ctx context.Context, user *models.User) (*model
 		&updated.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, TranslateError(err, "user")
 	}
 
 	return &updated, nil
@@ -226,19 +227,26 @@ func (ur *UserRepository) Update(ctx context.Context, user *models.User) (*model
 
 func (ur *UserRepository) Delete(ctx context.Context, userID int64) error {
 	query := `DELETE FROM users WHERE user_id = $1`
-	commandTag, err := ur.pool.Exec(ctx, query, userID)
+	commandTag, err := querier(ctx, ur.pool).Exec(ctx, query, userID)
 	if err != nil {
-		return err
+		return TranslateError(err, "user")
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		return utils.ErrUserNotFound
+		return TranslateError(pgx.ErrNoRows, "user")
 	}
 
 	return nil
 }
 
-func (ur *UserRepository) List(ctx context.Context, limit, offset int) ([]*models.User, error) {
+// List returns one page of users matching q.
+func (ur *UserRepository) List(ctx context.Context, q listquery.Query[*models.User]) (*listquery.Page[*models.User], error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+
 	query := `
 		SELECT
 			user_id,
@@ -253,18 +261,9 @@ func (ur *UserRepository) List(ctx context.Context, limit, offset int) ([]*model
 			created_at,
 			updated_at
 		FROM users
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := ur.pool.Query(ctx, query, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var users []*models.User
-	for rows.Next() {
+	return list(ctx, querier(ctx, ur.pool), query, q, "user", func(rows pgx.Rows) (*models.User, error) {
 		var user models.User
 		err := rows.Scan(
 			&user.ID,
@@ -279,24 +278,15 @@ func (ur *UserRepository) List(ctx context.Context, limit, offset int) ([]*model
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
-		if err != nil {
-			return nil, err
-		}
-		users = append(users, &user)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return users, nil
+		return &user, err
+	})
 }
 
 func (ur *UserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`
 
 	var exists bool
-	err := ur.pool.QueryRow(ctx, query, username).Scan(&exists)
+	err := querier(ctx, ur.pool).QueryRow(ctx, query, username).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -308,7 +298,7 @@ func (ur *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
 
 	var exists bool
-	err := ur.pool.QueryRow(ctx, query, email).Scan(&exists)
+	err := querier(ctx, ur.pool).QueryRow(ctx, query, email).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
@@ -320,10 +310,48 @@ func (ur *UserRepository) Count(ctx context.Context) (int64, error) {
 	query := `SELECT COUNT(*) FROM users`
 
 	var total int64
-	err := ur.pool.QueryRow(ctx, query).Scan(&total)
+	err := querier(ctx, ur.pool).QueryRow(ctx, query).Scan(&total)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count users: %w", err)
 	}
 
 	return total, nil
 }
+
+func (ur *UserRepository) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
+	query := `
+		UPDATE users
+		SET password_hash = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $2
+	`
+
+	commandTag, err := querier(ctx, ur.pool).Exec(ctx, query, passwordHash, userID)
+	if err != nil {
+		return TranslateError(err, "user")
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return TranslateError(pgx.ErrNoRows, "user")
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) SetActive(ctx context.Context, userID string, active bool) error {
+	query := `
+		UPDATE users
+		SET is_active = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $2
+	`
+
+	commandTag, err := querier(ctx, ur.pool).Exec(ctx, query, active, userID)
+	if err != nil {
+		return TranslateError(err, "user")
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return TranslateError(pgx.ErrNoRows, "user")
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// worker is a background job started with the server. run must return once
+// its context is cancelled.
+type worker struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// AddWorker registers a background job that runs for the lifetime of the
+// server. Workers are stopped in reverse registration order after the HTTP
+// server has drained and before the database pool is closed.
+func (s *Server) AddWorker(name string, run func(ctx context.Context) error) {
+	s.workers = append(s.workers, worker{name: name, run: run})
+}
+
+// Run serves HTTP on the configured address until ctx is cancelled, then
+// shuts down in order: readiness starts failing, in-flight requests drain,
+// background workers stop, and finally the database pool is closed.
+func (s *Server) Run(ctx context.Context) error {
+	httpServer := &http.Server{
+		Addr:              s.cfg.GetServerAddress(),
+		Handler:           s.routes(),
+		ReadTimeout:       s.cfg.ServerReadTimeout,
+		ReadHeaderTimeout: s.cfg.ServerReadHeaderTimeout,
+		WriteTimeout:      s.cfg.ServerWriteTimeout,
+		IdleTimeout:       s.cfg.ServerIdleTimeout,
+		MaxHeaderBytes:    s.cfg.ServerMaxHeaderBytes,
+		BaseContext:       func(net.Listener) context.Context { return context.WithoutCancel(ctx) },
+	}
+
+	listener, err := net.Listen("tcp", httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	stopWorkers := s.startWorkers(ctx)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		s.logger.Info("server starting", "addr", listener.Addr().String())
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		stopWorkers(context.Background())
+		s.db.Close()
+		return err
+	case <-ctx.Done():
+	}
+
+	return s.shutdown(httpServer, serveErr, stopWorkers)
+}
+
+func (s *Server) shutdown(httpServer *http.Server, serveErr <-chan error, stopWorkers func(context.Context)) error {
+	s.logger.Info("shutting down", "drain_delay", s.cfg.ServerDrainDelay, "timeout", s.cfg.ServerShutdownTimeout)
+
+	// Fail readiness first and keep serving for a moment so load balancers
+	// stop sending new traffic before the listener closes.
+	s.health.SetDraining()
+	time.Sleep(s.cfg.ServerDrainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ServerShutdownTimeout)
+	defer cancel()
+
+	shutdownErr := httpServer.Shutdown(ctx)
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		shutdownErr = errors.Join(shutdownErr, err)
+	}
+
+	stopWorkers(ctx)
+	s.db.Close()
+
+	if shutdownErr != nil {
+		return shutdownErr
+	}
+	s.logger.Info("server stopped gracefully")
+	return nil
+}
+
+// startWorkers runs every registered worker and returns a function that stops
+// them one at a time in reverse order, waiting for each to return or for ctx
+// to expire.
+func (s *Server) startWorkers(ctx context.Context) func(context.Context) {
+	type running struct {
+		name   string
+		cancel context.CancelFunc
+		done   chan struct{}
+	}
+
+	started := make([]running, 0, len(s.workers))
+	for _, w := range s.workers {
+		workerCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+			if err := w.run(workerCtx); err != nil && !errors.Is(err, context.Canceled) {
+				s.logger.Error("background worker failed", "worker", w.name, "error", err)
+			}
+		}()
+
+		started = append(started, running{name: w.name, cancel: cancel, done: done})
+	}
+
+	var once sync.Once
+	return func(stopCtx context.Context) {
+		once.Do(func() {
+			for i := len(started) - 1; i >= 0; i-- {
+				w := started[i]
+				w.cancel()
+				select {
+				case <-w.done:
+					s.logger.Info("background worker stopped", "worker", w.name)
+				case <-stopCtx.Done():
+					s.logger.Warn("background worker did not stop in time", "worker", w.name)
+				}
+			}
+		})
+	}
+}
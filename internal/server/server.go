@@ -1,41 +1,68 @@
 package server
 
 import (
-	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"time"
 
 	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	_ "github.com/falasefemi2/hms/docs"
+	"github.com/falasefemi2/hms/internal/config"
 	"github.com/falasefemi2/hms/internal/database"
 	"github.com/falasefemi2/hms/internal/handlers"
+	"github.com/falasefemi2/hms/internal/invite"
+	"github.com/falasefemi2/hms/internal/metrics"
 	"github.com/falasefemi2/hms/internal/middleware"
+	"github.com/falasefemi2/hms/internal/mrn"
+	"github.com/falasefemi2/hms/internal/ratelimit"
 	"github.com/falasefemi2/hms/internal/repository"
 	"github.com/falasefemi2/hms/internal/service"
 )
 
 type Server struct {
-	db     *database.DB
-	server *http.Server
+	cfg     *config.Config
+	db      *database.DB
+	logger  *slog.Logger
+	health  *handlers.HealthHandler
+	limits  ratelimit.Store
+	workers []worker
 }
 
-func NewServer(db *database.DB) *Server {
-	return &Server{db: db}
+func NewServer(cfg *config.Config, db *database.DB, logger *slog.Logger) *Server {
+	limits := ratelimit.NewMemoryStore()
+
+	s := &Server{
+		cfg:    cfg,
+		db:     db,
+		logger: logger,
+		health: handlers.NewHealthHandler(db, cfg.ReadyMaxPoolUtilization),
+		limits: limits,
+	}
+	s.AddWorker("rate limit sweeper", limits.Run)
+	return s
 }
 
-func (s *Server) Start(port string) error {
+func (s *Server) routes() http.Handler {
 	r := chi.NewRouter()
 
+	// Config.Validate has checked these; a bad entry would only mean
+	// trusting fewer proxies.
+	trustedProxies, _ := s.cfg.TrustedProxyPrefixes()
+
 	r.Use(chimw.RequestID)
-	r.Use(chimw.RealIP)
-	r.Use(chimw.Logger)
+	r.Use(middleware.RealIP(trustedProxies))
+	r.Use(middleware.APIKeys(s.cfg.APIKeys))
+	r.Use(middleware.Tracing)
+	r.Use(middleware.RequestLogger(s.logger))
+	r.Use(middleware.Metrics)
 	r.Use(chimw.Recoverer)
 
+	metrics.Registry.MustRegister(metrics.NewPoolCollector(s.db.Pool()))
+
 	userRepo := repository.NewUserRepository(s.db.Pool())
 	deptRepo := repository.NewDepartmentRepository(s.db.Pool())
 	doctorRepo := repository.NewDoctorRepository(s.db.Pool())
@@ -45,22 +72,44 @@ func (s *Server) Start(port string) error {
 	hospitalConfigRepo := repository.NewHospitalConfigRepository(s.db.Pool())
 	appointmentRepo := repository.NewAppointmentRepository(s.db.Pool())
 	consultationRepo := repository.NewConsultationRepository(s.db.Pool())
+	idempotencyRepo := repository.NewIdempotencyRepository(s.db.Pool())
+	auditRepo := repository.NewAuditRepository(s.db.Pool())
+	invitationRepo := repository.NewInvitationRepository(s.db.Pool())
+	prescriptionRepo := repository.NewPrescriptionRepository(s.db.Pool())
+	labTestRepo := repository.NewLabTestRepository(s.db.Pool())
+	patientInviteRepo := repository.NewPatientInviteRepository(s.db.Pool())
+	txManager := repository.NewTxManager(s.db.Pool())
+
+	s.AddWorker("idempotency key sweeper", s.idempotencySweeper(idempotencyRepo))
+	idempotent := middleware.Idempotency(idempotencyRepo, s.cfg.ServerWriteTimeout)
+
+	userService := service.NewUserService(userRepo, txManager)
+	hospitalConfigService := service.NewHospitalConfigService(hospitalConfigRepo, txManager)
+	registrationService := service.NewPatientRegistrationService(userService, hospitalConfigService, patientInviteRepo,
+		auditRepo, s.cfg.PatientInviteTTL, txManager)
+	deptService := service.NewDepartmentService(deptRepo, txManager)
+	doctorService := service.NewDoctorService(doctorRepo, userRepo, deptRepo, appointmentRepo, auditRepo, txManager)
+	nurseService := service.NewNurseService(nurseRepo, userRepo, deptRepo, auditRepo, txManager)
+	patientService := service.NewPatientService(patientRepo, userRepo, patientRepo, mrn.MustParse(s.cfg.MRNFormat), txManager)
+	patientMergeService := service.NewPatientMergeService(patientRepo, userRepo, patientRepo, patientRepo, auditRepo, txManager)
+	patientPortalService := service.NewPatientPortalService(patientRepo, userRepo, appointmentRepo, consultationRepo,
+		prescriptionRepo, labTestRepo, auditRepo, txManager)
+	availabilityService := service.NewAvailabilityService(availabilityRepo, doctorRepo, txManager)
+	staffOnboardingService := service.NewStaffOnboardingService(userService, doctorService, nurseService, availabilityService,
+		userRepo, invitationRepo, invite.LogSender{}, s.cfg.InvitationURL, s.cfg.InvitationTTL, txManager)
+	doctorDirectoryService := service.NewDoctorDirectoryService(doctorRepo, availabilityRepo, appointmentRepo)
+	appointmentService := service.NewAppointmentService(appointmentRepo, patientRepo, doctorRepo, hospitalConfigService, txManager)
+	consultationService := service.NewConsultationService(consultationRepo, appointmentRepo, patientRepo, doctorRepo, txManager)
 
-	userService := service.NewUserService(userRepo)
-	deptService := service.NewDepartmentService(deptRepo)
-	doctorService := service.NewDoctorService(doctorRepo, userRepo)
-	nurseService := service.NewNurseService(nurseRepo, userRepo)
-	patientService := service.NewPatientService(patientRepo, userRepo)
-	availabilityService := service.NewAvailabilityService(availabilityRepo, doctorRepo)
-	hospitalConfigService := service.NewHospitalConfigService(hospitalConfigRepo)
-	appointmentService := service.NewAppointmentService(appointmentRepo, patientRepo, doctorRepo)
-	consultationService := service.NewConsultationService(consultationRepo, appointmentRepo, patientRepo, doctorRepo)
-
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, registrationService)
 	deptHandler := handlers.NewDeptHandler(deptService)
 	doctorHandler := handlers.NewDoctorHandler(doctorService)
+	doctorDirectoryHandler := handlers.NewDoctorDirectoryHandler(doctorDirectoryService)
 	nurseHandler := handlers.NewNurseHandler(nurseService)
+	staffHandler := handlers.NewStaffHandler(staffOnboardingService)
 	patientHandler := handlers.NewPatientHandlers(patientService)
+	patientMergeHandler := handlers.NewPatientMergeHandlers(patientMergeService)
+	patientPortalHandler := handlers.NewPatientPortalHandlers(patientPortalService)
 	availabilityHandler := handlers.NewAvailabilityHandlers(availabilityService)
 	hospitalConfigHandler := handlers.NewHospitalConfigHandler(hospitalConfigService)
 	appointmentHandler := handlers.NewAppointmentHandler(appointmentService)
@@ -69,94 +118,164 @@ func (s *Server) Start(port string) error {
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "Welcome to the HMS API")
 	})
-	r.Get("/health", handlers.HealthCheck)
+	r.Get("/livez", s.health.Livez)
+	r.Get("/readyz", s.health.Readyz)
+	r.Get("/health", s.health.Readyz)
+	r.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
 
 	r.Route("/auth", func(r chi.Router) {
-		r.Post("/signup", userHandler.SignUpPatient)
-		r.Post("/login", userHandler.Login)
+		r.With(s.rateLimit(config.RateLimitSignup), idempotent).Post("/signup", userHandler.SignUpPatient)
+		// Login is deliberately not idempotent: replaying would store tokens.
+		r.With(s.rateLimit(config.RateLimitLogin)).Post("/login", userHandler.Login)
+		r.With(s.rateLimit(config.RateLimitAcceptInvitation)).Post("/accept-invitation", staffHandler.AcceptInvitation)
 	})
 
 	r.Route("/admin", func(r chi.Router) {
 		r.Use(middleware.JWTAuth)
+		r.Use(s.rateLimitWrites())
 		r.Use(middleware.AdminOnly)
+		r.Use(idempotent)
 		r.Route("/users", func(r chi.Router) {
 			r.Post("/", userHandler.CreateUser)
 			r.Get("/", userHandler.ListUsers)
 			r.Get("/{id}", userHandler.GetUser)
+			r.Patch("/{id}", userHandler.PatchUser)
 		})
 		r.Route("/departments", func(r chi.Router) {
 			r.Post("/", deptHandler.CreateDepartment)
-			r.Get("/", deptHandler.GetAllDepartments)
+			r.Get("/", deptHandler.ListDepartments)
 			r.Get("/{id}", deptHandler.GetDepartment)
 			r.Put("/{id}", deptHandler.UpdateDepartment)
 			r.Delete("/{id}", deptHandler.DeleteDepartment)
 		})
 		r.Route("/doctors", func(r chi.Router) {
 			r.Post("/", doctorHandler.CreateDoctor)
+			r.Get("/", doctorHandler.ListDoctors)
+			r.Get("/{id}", doctorHandler.GetDoctor)
+			r.Patch("/{id}", doctorHandler.PatchDoctor)
+			r.Post("/{id}/deactivate", doctorHandler.DeactivateDoctor)
+			r.Post("/{id}/activate", doctorHandler.ActivateDoctor)
 			r.Route("/availability", func(r chi.Router) {
 				r.Post("/", availabilityHandler.CreateAvailability)
 			})
 		})
 		r.Route("/nurses", func(r chi.Router) {
 			r.Post("/", nurseHandler.CreateNurse)
+			r.Get("/", nurseHandler.ListNurses)
+			r.Get("/{id}", nurseHandler.GetNurse)
+			r.Patch("/{id}", nurseHandler.PatchNurse)
+			r.Post("/{id}/deactivate", nurseHandler.DeactivateNurse)
+			r.Post("/{id}/activate", nurseHandler.ActivateNurse)
 		})
-		r.Route("/hospital-configs", func(r chi.Router) {
-			r.Post("/", hospitalConfigHandler.CreateHospitalConfig)
-			r.Get("/", hospitalConfigHandler.GetAllHospitalConfigs)
-			r.Get("/{id}", hospitalConfigHandler.GetHospitalConfig)
-			r.Put("/{id}", hospitalConfigHandler.UpdateHospitalConfig)
-			r.Delete("/{id}", hospitalConfigHandler.DeleteHospitalConfig)
+		r.Route("/staff", func(r chi.Router) {
+			r.Post("/", staffHandler.OnboardStaff)
+			r.Post("/{user_id}/invitation", staffHandler.ResendInvitation)
+		})
+		r.Route("/patients", func(r chi.Router) {
+			r.Post("/{id}/merge", patientMergeHandler.MergePatients)
+		})
+		r.Route("/hospital-config", func(r chi.Router) {
+			r.Get("/", hospitalConfigHandler.GetHospitalConfig)
+			r.Put("/", hospitalConfigHandler.UpdateHospitalConfig)
+			r.Get("/history", hospitalConfigHandler.ListHospitalConfigHistory)
+			r.Get("/history/{revision}", hospitalConfigHandler.GetHospitalConfigRevision)
+			r.Post("/rollback", hospitalConfigHandler.RollbackHospitalConfig)
+		})
+		r.Route("/patient-invites", func(r chi.Router) {
+			r.Post("/", userHandler.CreatePatientInvite)
+		})
+	})
+
+	r.Route("/doctors", func(r chi.Router) {
+		r.Use(middleware.JWTAuth)
+		r.Get("/", doctorDirectoryHandler.ListDoctors)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.DoctorOnly)
+			r.Use(s.rateLimitWrites())
+			r.Use(idempotent)
+			r.Get("/me", doctorHandler.GetMyProfile)
+			r.Patch("/me", doctorHandler.PatchMyProfile)
 		})
 	})
 
+	r.Route("/nurses", func(r chi.Router) {
+		r.Use(middleware.JWTAuth)
+		r.Use(middleware.NurseOnly)
+		r.Use(s.rateLimitWrites())
+		r.Use(idempotent)
+		r.Get("/me", nurseHandler.GetMyProfile)
+		r.Patch("/me", nurseHandler.PatchMyProfile)
+	})
+
 	r.Route("/patients", func(r chi.Router) {
 		r.Use(middleware.JWTAuth)
-		r.Use(middleware.PatientOnly)
-		r.Route("/patientprofile", func(r chi.Router) {
-			r.Post("/", patientHandler.PatientProfile)
+		r.Use(s.rateLimitWrites())
+		r.With(idempotent).Post("/patientprofile", patientHandler.PatientProfile)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.PatientOnly)
+			r.Use(idempotent)
+			r.Route("/me", func(r chi.Router) {
+				r.Get("/", patientPortalHandler.GetMyProfile)
+				r.Patch("/", patientPortalHandler.PatchMyProfile)
+				r.Get("/appointments", patientPortalHandler.ListMyAppointments)
+				r.Get("/consultations", patientPortalHandler.ListMyConsultations)
+				r.Get("/prescriptions", patientPortalHandler.ListMyPrescriptions)
+				r.Get("/lab-results", patientPortalHandler.ListMyLabResults)
+				r.Get("/records", patientPortalHandler.DownloadMyRecords)
+			})
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.HasAnyRole("ADMIN", "DOCTOR", "NURSE"))
+			r.Get("/", patientHandler.ListPatients)
+			r.Get("/search", patientHandler.SearchPatients)
+			r.Patch("/{id}", patientHandler.PatchPatient)
+			r.Get("/{id}/duplicates", patientMergeHandler.FindDuplicates)
 		})
 	})
 
 	r.Route("/appointments", func(r chi.Router) {
 		r.Use(middleware.JWTAuth)
+		r.Use(s.rateLimitWrites())
+		r.Use(idempotent)
 		r.Post("/", appointmentHandler.CreateAppointment)
+		r.With(middleware.HasAnyRole("ADMIN", "DOCTOR", "NURSE")).Get("/", appointmentHandler.ListAppointments)
 		r.Get("/{id}", appointmentHandler.GetAppointment)
 		r.Put("/{id}", appointmentHandler.UpdateAppointment)
+		r.Patch("/{id}", appointmentHandler.PatchAppointment)
 	})
 
 	r.Route("/consultations", func(r chi.Router) {
 		r.Use(middleware.JWTAuth)
+		r.Use(s.rateLimitWrites())
+		r.Use(idempotent)
 		r.Post("/", consultationHandler.CreateConsultation)
 		r.Get("/{id}", consultationHandler.GetConsultation)
 		r.Put("/{id}", consultationHandler.UpdateConsultation)
+		r.Patch("/{id}", consultationHandler.PatchConsultation)
 	})
 
-	s.server = &http.Server{
-		Addr:    ":" + port,
-		Handler: r,
-	}
-
-	log.Printf("Server starting on port %s", port)
-	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		return err
-	}
-	return nil
+	return r
 }
 
-func (s *Server) Shutdown(timeout time.Duration) error {
-	if s.server == nil {
-		return nil
+// rateLimit returns the middleware for the named policy, or a pass-through
+// when rate limiting is disabled.
+func (s *Server) rateLimit(name string) func(http.Handler) http.Handler {
+	if !s.cfg.RateLimitEnabled {
+		return passThrough
 	}
+	return middleware.RateLimit(s.limits, name, s.cfg.RateLimitPolicies[name])
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	if err := s.server.Shutdown(ctx); err != nil {
-		return err
+// rateLimitWrites limits authenticated writes per user. It must run after
+// JWTAuth so the user ID is in the context.
+func (s *Server) rateLimitWrites() func(http.Handler) http.Handler {
+	if !s.cfg.RateLimitEnabled {
+		return passThrough
 	}
+	return middleware.RateLimitWrites(s.limits, config.RateLimitWrite, s.cfg.RateLimitPolicies[config.RateLimitWrite])
+}
 
-	s.db.Close()
-	log.Println("Server stopped gracefully")
-	return nil
+func passThrough(next http.Handler) http.Handler {
+	return next
 }
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/falasefemi2/hms/internal/repository"
+)
+
+const idempotencySweepInterval = time.Hour
+
+// idempotencySweeper deletes stored Idempotency-Key responses once they are
+// older than the configured TTL.
+func (s *Server) idempotencySweeper(repo *repository.IdempotencyRepository) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ticker := time.NewTicker(idempotencySweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				deleted, err := repo.DeleteExpired(ctx, s.cfg.IdempotencyKeyTTL)
+				if err != nil {
+					s.logger.ErrorContext(ctx, "deleting expired idempotency keys failed", "error", err)
+					continue
+				}
+				if deleted > 0 {
+					s.logger.InfoContext(ctx, "deleted expired idempotency keys", "count", deleted)
+				}
+			}
+		}
+	}
+}
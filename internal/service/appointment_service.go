@@ -2,65 +2,135 @@ package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/metrics"
 	"github.com/falasefemi2/hms/internal/models"
 	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/tracing"
+	"github.com/falasefemi2/hms/internal/utils"
 	"github.com/google/uuid"
 )
 
 type AppointmentService struct {
-	appointmentRepo *repository.AppointmentRepository
-	patientRepo     *repository.PatientRepository
-	doctorRepo      *repository.DoctorRepository
+	appointmentRepo AppointmentRepository
+	patientRepo     PatientRepository
+	doctorRepo      DoctorRepository
+	config          ConfigProvider
+	tx              Transactor
 }
 
-func NewAppointmentService(appointmentRepo *repository.AppointmentRepository, patientRepo *repository.PatientRepository, doctorRepo *repository.DoctorRepository) *AppointmentService {
+func NewAppointmentService(appointmentRepo AppointmentRepository, patientRepo PatientRepository, doctorRepo DoctorRepository, config ConfigProvider, tx Transactor) *AppointmentService {
 	return &AppointmentService{
 		appointmentRepo: appointmentRepo,
 		patientRepo:     patientRepo,
 		doctorRepo:      doctorRepo,
+		config:          config,
+		tx:              tx,
 	}
 }
 
+// CreateAppointment books an appointment. A patient always books for
+// themselves, found from their token, and their booking starts PENDING
+// whatever status was asked for; staff name the patient.
 func (s *AppointmentService) CreateAppointment(ctx context.Context, appointment *models.Appointment) (*models.Appointment, error) {
-	// Validate patient exists
-	_, err := s.patientRepo.GetByPatientID(ctx, appointment.PatientID)
-	if err != nil {
-		return nil, errors.New("patient not found")
-	}
-
-	// Validate doctor exists
-	_, errDoc := s.doctorRepo.GetDoctorID(ctx, appointment.DoctorID)
-	if errDoc != nil {
-		return nil, errors.New("doctor not found")
-	}
+	ctx, span := tracing.Start(ctx, "AppointmentService.CreateAppointment")
+	defer span.End()
 
 	// Validate appointment date is in the future
 	if appointment.AppointmentDate.Before(time.Now()) {
-		return nil, errors.New("appointment date must be in the future")
+		return nil, invalidField("appointment_date", "appointment date must be in the future")
+	}
+	if err := s.checkWorkingHours(ctx, appointment); err != nil {
+		return nil, err
 	}
 
-	createdAppointment, err := s.appointmentRepo.Create(ctx, appointment)
+	var (
+		createdAppointment *models.Appointment
+		departmentID       string
+	)
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		patient, err := callerPatient(ctx, s.patientRepo)
+		if err != nil {
+			return err
+		}
+		switch {
+		case patient != nil:
+			if appointment.PatientID != uuid.Nil && appointment.PatientID != patient.PatientID {
+				return utils.NewForbiddenError("not_owner", "patients may only book appointments for themselves")
+			}
+			appointment.PatientID = patient.PatientID
+			appointment.Status = "PENDING"
+		case appointment.PatientID == uuid.Nil:
+			return invalidField("patient_id", "patient_id is required")
+		}
+
+		// Validate patient exists
+		_, err = s.patientRepo.GetByPatientID(ctx, appointment.PatientID)
+		if err != nil {
+			return invalidReference(err, "patient_id", "patient not found")
+		}
+
+		// Validate doctor exists
+		doctor, errDoc := s.doctorRepo.GetDoctorID(ctx, appointment.DoctorID)
+		if errDoc != nil {
+			return invalidReference(errDoc, "doctor_id", "doctor not found")
+		}
+		departmentID = doctor.DepartmentID.String()
+
+		createdAppointment, err = s.appointmentRepo.Create(ctx, appointment)
+		if err != nil {
+			return fmt.Errorf("failed to create appointment: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create appointment: %w", err)
+		return nil, err
 	}
 
+	metrics.AppointmentsCreated.WithLabelValues(departmentID).Inc()
 	return createdAppointment, nil
 }
 
 func (s *AppointmentService) GetAppointmentByID(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error) {
+	ctx, span := tracing.Start(ctx, "AppointmentService.GetAppointmentByID")
+	defer span.End()
+
 	appointment, err := s.appointmentRepo.GetByID(ctx, appointmentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get appointment: %w", err)
 	}
+	if err := checkPatientOwner(ctx, s.patientRepo, appointment.PatientID); err != nil {
+		return nil, err
+	}
 
 	return appointment, nil
 }
 
+func (s *AppointmentService) ListAppointments(ctx context.Context, q listquery.Query[*models.Appointment]) (*listquery.Page[*models.Appointment], error) {
+	ctx, span := tracing.Start(ctx, "AppointmentService.ListAppointments")
+	defer span.End()
+
+	page, err := s.appointmentRepo.List(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appointments: %w", err)
+	}
+
+	return page, nil
+}
+
 func (s *AppointmentService) GetAppointmentsByPatientID(ctx context.Context, patientID uuid.UUID) ([]*models.Appointment, error) {
+	ctx, span := tracing.Start(ctx, "AppointmentService.GetAppointmentsByPatientID")
+	defer span.End()
+
+	if err := checkPatientOwner(ctx, s.patientRepo, patientID); err != nil {
+		return nil, err
+	}
+
 	appointments, err := s.appointmentRepo.GetByPatientID(ctx, patientID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get appointments: %w", err)
@@ -70,6 +140,9 @@ func (s *AppointmentService) GetAppointmentsByPatientID(ctx context.Context, pat
 }
 
 func (s *AppointmentService) GetAppointmentsByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.Appointment, error) {
+	ctx, span := tracing.Start(ctx, "AppointmentService.GetAppointmentsByDoctorID")
+	defer span.End()
+
 	appointments, err := s.appointmentRepo.GetByDoctorID(ctx, doctorID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get appointments: %w", err)
@@ -79,43 +152,124 @@ func (s *AppointmentService) GetAppointmentsByDoctorID(ctx context.Context, doct
 }
 
 func (s *AppointmentService) UpdateAppointment(ctx context.Context, appointment *models.Appointment) (*models.Appointment, error) {
-	// Check if appointment exists
-	existing, err := s.appointmentRepo.GetByID(ctx, appointment.AppointmentID)
-	if err != nil {
-		return nil, errors.New("appointment not found")
-	}
+	ctx, span := tracing.Start(ctx, "AppointmentService.UpdateAppointment")
+	defer span.End()
 
-	// Validate status transition
-	if existing.Status == "COMPLETED" && appointment.Status != "COMPLETED" {
-		return nil, errors.New("cannot change status of completed appointment")
-	}
+	var (
+		updatedAppointment *models.Appointment
+		cancelledIn        string
+	)
 
-	if existing.Status == "CANCELLED" {
-		return nil, errors.New("cannot update cancelled appointment")
-	}
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Check if appointment exists
+		existing, err := s.appointmentRepo.GetByID(ctx, appointment.AppointmentID)
+		if err != nil {
+			return err
+		}
+		if err := checkPatientOwner(ctx, s.patientRepo, existing.PatientID); err != nil {
+			return err
+		}
+
+		// Reject edits based on a stale read
+		if existing.Version != appointment.Version {
+			return repository.VersionConflict("appointment")
+		}
+
+		changed := appointmentChanges(existing, appointment)
+		if err := appointmentFieldPolicy.check(ctx, changed); err != nil {
+			return err
+		}
+
+		if slices.Contains(changed, "appointment_date") && appointment.AppointmentDate.Before(time.Now()) {
+			return invalidField("appointment_date", "appointment date must be in the future")
+		}
+		if slices.Contains(changed, "appointment_date") || slices.Contains(changed, "duration_minutes") {
+			if err := s.checkWorkingHours(ctx, appointment); err != nil {
+				return err
+			}
+		}
+
+		// Validate status transition
+		if existing.Status == "COMPLETED" && appointment.Status != "COMPLETED" {
+			return utils.NewConflictError("appointment_completed", "cannot change status of completed appointment")
+		}
 
-	updatedAppointment, err := s.appointmentRepo.Update(ctx, appointment)
+		if existing.Status == "CANCELLED" {
+			return utils.NewConflictError("appointment_cancelled", "cannot update cancelled appointment")
+		}
+
+		updatedAppointment, err = s.appointmentRepo.Update(ctx, appointment)
+		if err != nil {
+			return fmt.Errorf("failed to update appointment: %w", err)
+		}
+
+		cancelledIn = ""
+		if appointment.Status == "CANCELLED" {
+			cancelledIn = s.departmentOf(ctx, existing.DoctorID)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update appointment: %w", err)
+		return nil, err
+	}
+
+	if cancelledIn != "" {
+		metrics.AppointmentsCancelled.WithLabelValues(cancelledIn).Inc()
 	}
 
 	return updatedAppointment, nil
 }
 
 func (s *AppointmentService) DeleteAppointment(ctx context.Context, appointmentID uuid.UUID) error {
-	appointment, err := s.appointmentRepo.GetByID(ctx, appointmentID)
+	ctx, span := tracing.Start(ctx, "AppointmentService.DeleteAppointment")
+	defer span.End()
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		appointment, err := s.appointmentRepo.GetByID(ctx, appointmentID)
+		if err != nil {
+			return err
+		}
+		if err := checkPatientOwner(ctx, s.patientRepo, appointment.PatientID); err != nil {
+			return err
+		}
+
+		if appointment.Status == "COMPLETED" {
+			return utils.NewConflictError("appointment_completed", "cannot delete completed appointment")
+		}
+
+		err = s.appointmentRepo.Delete(ctx, appointmentID)
+		if err != nil {
+			return fmt.Errorf("failed to delete appointment: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// checkWorkingHours rejects an appointment that would start or run outside
+// the working hours of the active hospital config.
+func (s *AppointmentService) checkWorkingHours(ctx context.Context, appointment *models.Appointment) error {
+	config, err := s.config.ActiveConfig(ctx)
 	if err != nil {
-		return errors.New("appointment not found")
+		return err
 	}
-
-	if appointment.Status == "COMPLETED" {
-		return errors.New("cannot delete completed appointment")
+	minutes := appointment.DurationMinutes
+	if minutes <= 0 {
+		minutes = config.AppointmentDurationMinutes
 	}
+	if !withinWorkingHours(config, appointment.AppointmentDate, minutes) {
+		return invalidField("appointment_date", fmt.Sprintf("appointment must fall within working hours (%s-%s)",
+			config.WorkingHoursStart, config.WorkingHoursEnd))
+	}
+	return nil
+}
 
-	err = s.appointmentRepo.Delete(ctx, appointmentID)
+// departmentOf returns the department ID of doctorID for metric labels, or
+// "unknown" if the doctor cannot be loaded.
+func (s *AppointmentService) departmentOf(ctx context.Context, doctorID uuid.UUID) string {
+	doctor, err := s.doctorRepo.GetDoctorID(ctx, doctorID)
 	if err != nil {
-		return fmt.Errorf("failed to delete appointment: %w", err)
+		return "unknown"
 	}
-
-	return nil
+	return doctor.DepartmentID.String()
 }
@@ -0,0 +1,379 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/falasefemi2/hms/internal/metrics"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+func newAppointmentService(f *fixture) *service.AppointmentService {
+	return service.NewAppointmentService(f.appointments, f.patients, f.doctors,
+		service.NewHospitalConfigService(f.configs, f.tx), f.tx)
+}
+
+func TestCreateAppointment(t *testing.T) {
+	ctx := asRole("NURSE")
+	f := newFixture()
+	svc := newAppointmentService(f)
+	patient := f.addPatient(t)
+	doctor := f.addDoctor(t)
+
+	tests := []struct {
+		name      string
+		patientID uuid.UUID
+		doctorID  uuid.UUID
+		date      time.Time
+		wantErr   string
+	}{
+		{"unknown patient", uuid.New(), doctor.DoctorID, time.Now().Add(time.Hour), "patient not found"},
+		{"unknown doctor", patient.PatientID, uuid.New(), time.Now().Add(time.Hour), "doctor not found"},
+		{"date in the past", patient.PatientID, doctor.DoctorID, time.Now().Add(-time.Hour), "appointment date must be in the future"},
+		{"valid", patient.PatientID, doctor.DoctorID, time.Now().Add(time.Hour), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created, err := svc.CreateAppointment(ctx, &models.Appointment{
+				AppointmentID:   uuid.New(),
+				PatientID:       tt.patientID,
+				DoctorID:        tt.doctorID,
+				AppointmentDate: tt.date,
+				DurationMinutes: 30,
+				Status:          "PENDING",
+			})
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if created.CreatedAt.IsZero() {
+				t.Error("expected created_at to be set")
+			}
+		})
+	}
+}
+
+func TestCreateAppointmentByPatient(t *testing.T) {
+	f := newFixture()
+	svc := newAppointmentService(f)
+	patient := f.addPatient(t)
+	doctor := f.addDoctor(t)
+
+	created, err := svc.CreateAppointment(asUser(patient.UserID, "PATIENT"), &models.Appointment{
+		AppointmentID:   uuid.New(),
+		DoctorID:        doctor.DoctorID,
+		AppointmentDate: time.Now().Add(time.Hour),
+		DurationMinutes: 30,
+		Status:          "COMPLETED",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.PatientID != patient.PatientID || created.Status != "PENDING" {
+		t.Errorf("expected a pending booking for the caller, got patient %s status %s", created.PatientID, created.Status)
+	}
+
+	_, err = svc.CreateAppointment(context.Background(), &models.Appointment{
+		AppointmentID:   uuid.New(),
+		PatientID:       patient.PatientID,
+		DoctorID:        doctor.DoctorID,
+		AppointmentDate: time.Now().Add(time.Hour),
+		DurationMinutes: 30,
+		Status:          "CONFIRMED",
+	})
+	if !errors.Is(err, utils.ErrUnauthorized) {
+		t.Errorf("expected a caller without a role to be unauthorized, got %v", err)
+	}
+}
+
+func TestUpdateAppointmentStatusRules(t *testing.T) {
+	ctx := asRole("DOCTOR")
+	f := newFixture()
+	svc := newAppointmentService(f)
+	patient := f.addPatient(t)
+	doctor := f.addDoctor(t)
+
+	tests := []struct {
+		name      string
+		from      string
+		to        string
+		wantError string
+	}{
+		{"pending to confirmed", "PENDING", "CONFIRMED", ""},
+		{"completed stays completed", "COMPLETED", "COMPLETED", ""},
+		{"completed cannot change", "COMPLETED", "PENDING", "cannot change status of completed appointment"},
+		{"cancelled is final", "CANCELLED", "CONFIRMED", "cannot update cancelled appointment"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := f.addAppointment(t, patient, doctor, tt.from)
+
+			_, err := svc.UpdateAppointment(ctx, &models.Appointment{
+				AppointmentID:   existing.AppointmentID,
+				AppointmentDate: existing.AppointmentDate,
+				DurationMinutes: existing.DurationMinutes,
+				Status:          tt.to,
+				Version:         existing.Version,
+			})
+
+			if tt.wantError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				stored, _ := f.appointments.GetByID(ctx, existing.AppointmentID)
+				if stored.Status != tt.to {
+					t.Errorf("expected status %s, got %s", tt.to, stored.Status)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantError {
+				t.Fatalf("expected error %q, got %v", tt.wantError, err)
+			}
+		})
+	}
+}
+
+func TestAppointmentMetrics(t *testing.T) {
+	ctx := asRole("DOCTOR")
+	f := newFixture()
+	svc := newAppointmentService(f)
+	patient := f.addPatient(t)
+	doctor := f.addDoctor(t)
+	department := doctor.DepartmentID.String()
+
+	created, err := svc.CreateAppointment(ctx, &models.Appointment{
+		AppointmentID:   uuid.New(),
+		PatientID:       patient.PatientID,
+		DoctorID:        doctor.DoctorID,
+		AppointmentDate: time.Now().Add(time.Hour),
+		DurationMinutes: 30,
+		Status:          "PENDING",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.AppointmentsCreated.WithLabelValues(department)); got != 1 {
+		t.Errorf("expected 1 created appointment for department, got %v", got)
+	}
+
+	created.Status = "CANCELLED"
+	if _, err := svc.UpdateAppointment(ctx, created); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.AppointmentsCancelled.WithLabelValues(department)); got != 1 {
+		t.Errorf("expected 1 cancelled appointment for department, got %v", got)
+	}
+}
+
+func TestUpdateAppointmentStaleVersion(t *testing.T) {
+	ctx := asRole("DOCTOR")
+	f := newFixture()
+	svc := newAppointmentService(f)
+	existing := f.addAppointment(t, f.addPatient(t), f.addDoctor(t), "PENDING")
+
+	first := *existing
+	first.Status = "CONFIRMED"
+	updated, err := svc.UpdateAppointment(ctx, &first)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Version != existing.Version+1 {
+		t.Errorf("expected version %d, got %d", existing.Version+1, updated.Version)
+	}
+
+	second := *existing
+	second.Status = "CANCELLED"
+	_, err = svc.UpdateAppointment(ctx, &second)
+	if !errors.Is(err, utils.ErrPreconditionFailed) {
+		t.Fatalf("expected precondition failure for a stale version, got %v", err)
+	}
+
+	stored, _ := f.appointments.GetByID(ctx, existing.AppointmentID)
+	if stored.Status != "CONFIRMED" {
+		t.Errorf("stale update must not overwrite the row, got status %s", stored.Status)
+	}
+}
+
+func TestUpdateAppointmentFieldPermissions(t *testing.T) {
+	f := newFixture()
+	svc := newAppointmentService(f)
+	patient := f.addPatient(t)
+	doctor := f.addDoctor(t)
+
+	t.Run("patient may change notes", func(t *testing.T) {
+		existing := f.addAppointment(t, patient, doctor, "PENDING")
+		change := *existing
+		change.PatientID, change.DoctorID = uuid.Nil, uuid.Nil
+		change.Notes = "running late"
+
+		updated, err := svc.UpdateAppointment(asUser(patient.UserID, "PATIENT"), &change)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if updated.Notes != "running late" {
+			t.Errorf("expected notes to be updated, got %q", updated.Notes)
+		}
+		if updated.PatientID != patient.PatientID || updated.DoctorID != doctor.DoctorID {
+			t.Errorf("expected participants to be kept, got patient %s doctor %s", updated.PatientID, updated.DoctorID)
+		}
+	})
+
+	t.Run("patient may not change status", func(t *testing.T) {
+		existing := f.addAppointment(t, patient, doctor, "PENDING")
+		change := *existing
+		change.Status = "CONFIRMED"
+		change.Notes = "see you then"
+
+		_, err := svc.UpdateAppointment(asUser(patient.UserID, "PATIENT"), &change)
+		var appErr *utils.AppError
+		if !errors.Is(err, utils.ErrForbidden) || !errors.As(err, &appErr) {
+			t.Fatalf("expected forbidden error, got %v", err)
+		}
+		if len(appErr.Fields) != 1 || appErr.Fields[0].Field != "status" {
+			t.Errorf("expected only status to be rejected, got %+v", appErr.Fields)
+		}
+
+		stored, _ := f.appointments.GetByID(context.Background(), existing.AppointmentID)
+		if stored.Notes != "" || stored.Status != "PENDING" {
+			t.Errorf("rejected update must not change the row, got %+v", stored)
+		}
+	})
+
+	t.Run("caller without role", func(t *testing.T) {
+		existing := f.addAppointment(t, patient, doctor, "PENDING")
+		change := *existing
+		change.Notes = "anonymous"
+
+		if _, err := svc.UpdateAppointment(context.Background(), &change); !errors.Is(err, utils.ErrUnauthorized) {
+			t.Fatalf("expected unauthorized error, got %v", err)
+		}
+	})
+
+	t.Run("rescheduling into the past", func(t *testing.T) {
+		existing := f.addAppointment(t, patient, doctor, "PENDING")
+		change := *existing
+		change.AppointmentDate = time.Now().Add(-time.Hour)
+
+		if _, err := svc.UpdateAppointment(asRole("NURSE"), &change); !errors.Is(err, utils.ErrInvalidInput) {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+	})
+}
+
+func TestAppointmentOwnership(t *testing.T) {
+	f := newFixture()
+	svc := newAppointmentService(f)
+	patient := f.addPatient(t)
+	other := f.addPatient(t)
+	doctor := f.addDoctor(t)
+	self := asUser(patient.UserID, "PATIENT")
+
+	t.Run("patient books for themselves", func(t *testing.T) {
+		created, err := svc.CreateAppointment(self, &models.Appointment{
+			AppointmentID:   uuid.New(),
+			DoctorID:        doctor.DoctorID,
+			AppointmentDate: time.Now().Add(time.Hour),
+			DurationMinutes: 30,
+			Status:          "PENDING",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if created.PatientID != patient.PatientID {
+			t.Errorf("expected the appointment booked for the caller, got patient %s", created.PatientID)
+		}
+	})
+
+	t.Run("patient cannot book for someone else", func(t *testing.T) {
+		_, err := svc.CreateAppointment(self, &models.Appointment{
+			AppointmentID:   uuid.New(),
+			PatientID:       other.PatientID,
+			DoctorID:        doctor.DoctorID,
+			AppointmentDate: time.Now().Add(time.Hour),
+			DurationMinutes: 30,
+		})
+		if !errors.Is(err, utils.ErrForbidden) {
+			t.Errorf("expected forbidden error, got %v", err)
+		}
+	})
+
+	t.Run("staff must name the patient", func(t *testing.T) {
+		_, err := svc.CreateAppointment(asRole("NURSE"), &models.Appointment{
+			AppointmentID:   uuid.New(),
+			DoctorID:        doctor.DoctorID,
+			AppointmentDate: time.Now().Add(time.Hour),
+			DurationMinutes: 30,
+		})
+		if !errors.Is(err, utils.ErrInvalidInput) {
+			t.Errorf("expected validation error, got %v", err)
+		}
+	})
+
+	t.Run("patient cannot read or edit another patient's appointment", func(t *testing.T) {
+		theirs := f.addAppointment(t, other, doctor, "PENDING")
+		if _, err := svc.GetAppointmentByID(self, theirs.AppointmentID); !errors.Is(err, utils.ErrForbidden) {
+			t.Errorf("expected forbidden read, got %v", err)
+		}
+
+		change := *theirs
+		change.Notes = "not mine"
+		if _, err := svc.UpdateAppointment(self, &change); !errors.Is(err, utils.ErrForbidden) {
+			t.Errorf("expected forbidden edit, got %v", err)
+		}
+		if stored, _ := f.appointments.GetByID(context.Background(), theirs.AppointmentID); stored.Notes != "" {
+			t.Errorf("rejected edit must not change the row, got %+v", stored)
+		}
+
+		if _, err := svc.GetAppointmentByID(asRole("NURSE"), theirs.AppointmentID); err != nil {
+			t.Errorf("expected staff to read any appointment, got %v", err)
+		}
+	})
+}
+
+func TestUpdateAppointmentNotFound(t *testing.T) {
+	svc := newAppointmentService(newFixture())
+
+	_, err := svc.UpdateAppointment(asRole("ADMIN"), &models.Appointment{AppointmentID: uuid.New()})
+	if err == nil || err.Error() != "appointment not found" {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if !errors.Is(err, utils.ErrNotFound) {
+		t.Errorf("expected error to be a not found error, got %v", err)
+	}
+}
+
+func TestDeleteAppointment(t *testing.T) {
+	ctx := asRole("ADMIN")
+	f := newFixture()
+	svc := newAppointmentService(f)
+	patient := f.addPatient(t)
+	doctor := f.addDoctor(t)
+
+	completed := f.addAppointment(t, patient, doctor, "COMPLETED")
+	if err := svc.DeleteAppointment(ctx, completed.AppointmentID); err == nil {
+		t.Fatal("expected completed appointment deletion to fail")
+	}
+
+	pending := f.addAppointment(t, patient, doctor, "PENDING")
+	if err := svc.DeleteAppointment(ctx, pending.AppointmentID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := f.appointments.GetByID(ctx, pending.AppointmentID); err == nil {
+		t.Fatal("expected appointment to be deleted")
+	}
+}
@@ -2,37 +2,46 @@ package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	"github.com/falasefemi2/hms/internal/models"
-	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/tracing"
 )
 
 type AvailabilityService struct {
-	availabilityRepo *repository.AvailabilityRepository
-	doctorRepo       *repository.DoctorRepository
+	availabilityRepo AvailabilityRepository
+	doctorRepo       DoctorRepository
+	tx               Transactor
 }
 
-func NewAvailabilityService(availabilityRepo *repository.AvailabilityRepository, doctorRepo *repository.DoctorRepository) *AvailabilityService {
+func NewAvailabilityService(availabilityRepo AvailabilityRepository, doctorRepo DoctorRepository, tx Transactor) *AvailabilityService {
 	return &AvailabilityService{
 		availabilityRepo: availabilityRepo,
 		doctorRepo:       doctorRepo,
+		tx:               tx,
 	}
 }
 
 func (a *AvailabilityService) CreateDoctorAvailability(ctx context.Context, availability *models.Availability) (*models.Availability, error) {
-	doctor, err := a.doctorRepo.GetDoctorID(ctx, availability.DoctorID)
-	if err != nil {
-		return nil, errors.New("doctor not found")
-	}
-	if doctor == nil {
-		return nil, errors.New("doctor does not exist")
-	}
+	ctx, span := tracing.Start(ctx, "AvailabilityService.CreateDoctorAvailability")
+	defer span.End()
+
+	var doctorAvailability *models.Availability
+
+	err := a.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := a.doctorRepo.GetDoctorID(ctx, availability.DoctorID)
+		if err != nil {
+			return invalidReference(err, "doctor_id", "doctor not found")
+		}
 
-	doctorAvailability, err := a.availabilityRepo.CreateAvailability(ctx, availability)
+		doctorAvailability, err = a.availabilityRepo.CreateAvailability(ctx, availability)
+		if err != nil {
+			return fmt.Errorf("failed to create availability: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create availability: %w", err)
+		return nil, err
 	}
 	return doctorAvailability, nil
 }
@@ -7,74 +7,114 @@ import (
 
 	"github.com/falasefemi2/hms/internal/models"
 	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/tracing"
+	"github.com/falasefemi2/hms/internal/utils"
 	"github.com/google/uuid"
 )
 
 type ConsultationService struct {
-	consultationRepo *repository.ConsultationRepository
-	appointmentRepo  *repository.AppointmentRepository
-	patientRepo      *repository.PatientRepository
-	doctorRepo       *repository.DoctorRepository
+	consultationRepo ConsultationRepository
+	appointmentRepo  AppointmentRepository
+	patientRepo      PatientRepository
+	doctorRepo       DoctorRepository
+	tx               Transactor
 }
 
-func NewConsultationService(consultationRepo *repository.ConsultationRepository, appointmentRepo *repository.AppointmentRepository, patientRepo *repository.PatientRepository, doctorRepo *repository.DoctorRepository) *ConsultationService {
+func NewConsultationService(consultationRepo ConsultationRepository, appointmentRepo AppointmentRepository, patientRepo PatientRepository, doctorRepo DoctorRepository, tx Transactor) *ConsultationService {
 	return &ConsultationService{
 		consultationRepo: consultationRepo,
 		appointmentRepo:  appointmentRepo,
 		patientRepo:      patientRepo,
 		doctorRepo:       doctorRepo,
+		tx:               tx,
 	}
 }
 
 func (s *ConsultationService) CreateConsultation(ctx context.Context, consultation *models.Consultation) (*models.Consultation, error) {
-	// Validate appointment exists and is completed
-	appointment, err := s.appointmentRepo.GetByID(ctx, consultation.AppointmentID)
+	ctx, span := tracing.Start(ctx, "ConsultationService.CreateConsultation")
+	defer span.End()
+
+	var createdConsultation *models.Consultation
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Validate appointment exists and is completed
+		appointment, err := s.appointmentRepo.GetByID(ctx, consultation.AppointmentID)
+		if err != nil {
+			return invalidReference(err, "appointment_id", "appointment not found")
+		}
+
+		if appointment.Status != "COMPLETED" {
+			return utils.NewConflictError("appointment_not_completed", "consultation can only be created for completed appointments")
+		}
+
+		// Check if consultation already exists for this appointment
+		_, err = s.consultationRepo.GetByAppointmentID(ctx, consultation.AppointmentID)
+		if err == nil {
+			return utils.NewConflictError("consultation_exists", "consultation already exists for this appointment")
+		}
+		if !errors.Is(err, utils.ErrNotFound) {
+			return fmt.Errorf("failed to check existing consultation: %w", err)
+		}
+
+		// Validate patient and doctor match the appointment
+		if consultation.PatientID != appointment.PatientID || consultation.DoctorID != appointment.DoctorID {
+			return utils.NewValidationError("appointment_mismatch", "patient and doctor must match the appointment",
+				utils.FieldError{Field: "patient_id", Message: "must match the appointment"},
+				utils.FieldError{Field: "doctor_id", Message: "must match the appointment"},
+			)
+		}
+
+		createdConsultation, err = s.consultationRepo.Create(ctx, consultation)
+		if err != nil {
+			return fmt.Errorf("failed to create consultation: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, errors.New("appointment not found")
-	}
-
-	if appointment.Status != "COMPLETED" {
-		return nil, errors.New("consultation can only be created for completed appointments")
-	}
-
-	// Check if consultation already exists for this appointment
-	existing, err := s.consultationRepo.GetByAppointmentID(ctx, consultation.AppointmentID)
-	if err == nil && existing != nil {
-		return nil, errors.New("consultation already exists for this appointment")
-	}
-
-	// Validate patient and doctor match the appointment
-	if consultation.PatientID != appointment.PatientID || consultation.DoctorID != appointment.DoctorID {
-		return nil, errors.New("patient and doctor must match the appointment")
-	}
-
-	createdConsultation, err := s.consultationRepo.Create(ctx, consultation)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create consultation: %w", err)
+		return nil, err
 	}
 
 	return createdConsultation, nil
 }
 
 func (s *ConsultationService) GetConsultationByID(ctx context.Context, consultationID uuid.UUID) (*models.Consultation, error) {
+	ctx, span := tracing.Start(ctx, "ConsultationService.GetConsultationByID")
+	defer span.End()
+
 	consultation, err := s.consultationRepo.GetByID(ctx, consultationID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get consultation: %w", err)
 	}
+	if err := checkPatientOwner(ctx, s.patientRepo, consultation.PatientID); err != nil {
+		return nil, err
+	}
 
 	return consultation, nil
 }
 
 func (s *ConsultationService) GetConsultationByAppointmentID(ctx context.Context, appointmentID uuid.UUID) (*models.Consultation, error) {
+	ctx, span := tracing.Start(ctx, "ConsultationService.GetConsultationByAppointmentID")
+	defer span.End()
+
 	consultation, err := s.consultationRepo.GetByAppointmentID(ctx, appointmentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get consultation: %w", err)
 	}
+	if err := checkPatientOwner(ctx, s.patientRepo, consultation.PatientID); err != nil {
+		return nil, err
+	}
 
 	return consultation, nil
 }
 
 func (s *ConsultationService) GetConsultationsByPatientID(ctx context.Context, patientID uuid.UUID) ([]*models.Consultation, error) {
+	ctx, span := tracing.Start(ctx, "ConsultationService.GetConsultationsByPatientID")
+	defer span.End()
+
+	if err := checkPatientOwner(ctx, s.patientRepo, patientID); err != nil {
+		return nil, err
+	}
+
 	consultations, err := s.consultationRepo.GetByPatientID(ctx, patientID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get consultations: %w", err)
@@ -84,20 +124,40 @@ func (s *ConsultationService) GetConsultationsByPatientID(ctx context.Context, p
 }
 
 func (s *ConsultationService) UpdateConsultation(ctx context.Context, consultation *models.Consultation) (*models.Consultation, error) {
-	// Check if consultation exists
-	existing, err := s.consultationRepo.GetByID(ctx, consultation.ConsultationID)
-	if err != nil {
-		return nil, errors.New("consultation not found")
-	}
-
-	// Check if editable
-	if !existing.IsEditable {
-		return nil, errors.New("consultation is not editable")
-	}
-
-	updatedConsultation, err := s.consultationRepo.Update(ctx, consultation)
+	ctx, span := tracing.Start(ctx, "ConsultationService.UpdateConsultation")
+	defer span.End()
+
+	var updatedConsultation *models.Consultation
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Check if consultation exists
+		existing, err := s.consultationRepo.GetByID(ctx, consultation.ConsultationID)
+		if err != nil {
+			return err
+		}
+
+		// Reject edits based on a stale read
+		if existing.Version != consultation.Version {
+			return repository.VersionConflict("consultation")
+		}
+
+		if err := consultationFieldPolicy.check(ctx, consultationChanges(existing, consultation)); err != nil {
+			return err
+		}
+
+		// Check if editable
+		if !existing.IsEditable {
+			return utils.NewConflictError("consultation_locked", "consultation is not editable")
+		}
+
+		updatedConsultation, err = s.consultationRepo.Update(ctx, consultation)
+		if err != nil {
+			return fmt.Errorf("failed to update consultation: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update consultation: %w", err)
+		return nil, err
 	}
 
 	return updatedConsultation, nil
@@ -0,0 +1,180 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+func newConsultationService(f *fixture) *service.ConsultationService {
+	return service.NewConsultationService(f.consultations, f.appointments, f.patients, f.doctors, f.tx)
+}
+
+func consultationFor(appointment *models.Appointment) *models.Consultation {
+	return &models.Consultation{
+		ConsultationID: uuid.New(),
+		AppointmentID:  appointment.AppointmentID,
+		PatientID:      appointment.PatientID,
+		DoctorID:       appointment.DoctorID,
+		Diagnosis:      "Seasonal allergies",
+		IsEditable:     true,
+	}
+}
+
+func TestCreateConsultation(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	svc := newConsultationService(f)
+	patient := f.addPatient(t)
+	doctor := f.addDoctor(t)
+
+	t.Run("appointment must exist", func(t *testing.T) {
+		_, err := svc.CreateConsultation(ctx, &models.Consultation{AppointmentID: uuid.New()})
+		if err == nil || err.Error() != "appointment not found" {
+			t.Fatalf("expected appointment not found, got %v", err)
+		}
+	})
+
+	t.Run("appointment must be completed", func(t *testing.T) {
+		appointment := f.addAppointment(t, patient, doctor, "CONFIRMED")
+		_, err := svc.CreateConsultation(ctx, consultationFor(appointment))
+		if err == nil || err.Error() != "consultation can only be created for completed appointments" {
+			t.Fatalf("expected completed-only error, got %v", err)
+		}
+	})
+
+	t.Run("patient and doctor must match", func(t *testing.T) {
+		appointment := f.addAppointment(t, patient, doctor, "COMPLETED")
+		consultation := consultationFor(appointment)
+		consultation.DoctorID = uuid.New()
+		_, err := svc.CreateConsultation(ctx, consultation)
+		if err == nil || err.Error() != "patient and doctor must match the appointment" {
+			t.Fatalf("expected mismatch error, got %v", err)
+		}
+	})
+
+	t.Run("one consultation per appointment", func(t *testing.T) {
+		appointment := f.addAppointment(t, patient, doctor, "COMPLETED")
+		if _, err := svc.CreateConsultation(ctx, consultationFor(appointment)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err := svc.CreateConsultation(ctx, consultationFor(appointment))
+		if err == nil || err.Error() != "consultation already exists for this appointment" {
+			t.Fatalf("expected duplicate error, got %v", err)
+		}
+		if !errors.Is(err, utils.ErrConflict) {
+			t.Errorf("expected a conflict error, got %v", err)
+		}
+	})
+}
+
+func TestUpdateConsultation(t *testing.T) {
+	ctx := asRole("DOCTOR")
+	f := newFixture()
+	svc := newConsultationService(f)
+	patient := f.addPatient(t)
+	doctor := f.addDoctor(t)
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := svc.UpdateConsultation(ctx, &models.Consultation{ConsultationID: uuid.New()})
+		if err == nil || err.Error() != "consultation not found" {
+			t.Fatalf("expected not found, got %v", err)
+		}
+	})
+
+	t.Run("locked consultation", func(t *testing.T) {
+		appointment := f.addAppointment(t, patient, doctor, "COMPLETED")
+		locked := consultationFor(appointment)
+		locked.IsEditable = false
+		if _, err := f.consultations.Create(ctx, locked); err != nil {
+			t.Fatalf("seed consultation: %v", err)
+		}
+
+		_, err := svc.UpdateConsultation(ctx, &models.Consultation{
+			ConsultationID: locked.ConsultationID,
+			Diagnosis:      "Changed",
+			Version:        locked.Version,
+		})
+		if err == nil || err.Error() != "consultation is not editable" {
+			t.Fatalf("expected not editable error, got %v", err)
+		}
+	})
+
+	t.Run("editable consultation", func(t *testing.T) {
+		appointment := f.addAppointment(t, patient, doctor, "COMPLETED")
+		created, err := svc.CreateConsultation(ctx, consultationFor(appointment))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err = svc.UpdateConsultation(ctx, &models.Consultation{
+			ConsultationID: created.ConsultationID,
+			Diagnosis:      "Acute sinusitis",
+			Version:        created.Version,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		stored, _ := f.consultations.GetByID(ctx, created.ConsultationID)
+		if stored.Diagnosis != "Acute sinusitis" {
+			t.Errorf("expected diagnosis to be updated, got %q", stored.Diagnosis)
+		}
+
+		_, err = svc.UpdateConsultation(asRole("NURSE"), &models.Consultation{
+			ConsultationID: created.ConsultationID,
+			Diagnosis:      stored.Diagnosis,
+			Notes:          "nurse note",
+			Version:        stored.Version,
+		})
+		if !errors.Is(err, utils.ErrForbidden) {
+			t.Fatalf("expected nurses to be refused, got %v", err)
+		}
+
+		_, err = svc.UpdateConsultation(ctx, &models.Consultation{
+			ConsultationID: created.ConsultationID,
+			Diagnosis:      "Stale edit",
+			Version:        created.Version,
+		})
+		if !errors.Is(err, utils.ErrPreconditionFailed) {
+			t.Fatalf("expected precondition failure for a stale version, got %v", err)
+		}
+	})
+}
+
+func TestCreateConsultationConcurrent(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	svc := newConsultationService(f)
+	appointment := f.addAppointment(t, f.addPatient(t), f.addDoctor(t), "COMPLETED")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.CreateConsultation(ctx, consultationFor(appointment))
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one consultation to be created, got %d", succeeded)
+	}
+}
@@ -2,26 +2,33 @@ package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
 	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/tracing"
+	"github.com/falasefemi2/hms/internal/utils"
 )
 
 type DepartmentService struct {
-	repo *repository.DepartmentRepository
+	repo DepartmentRepository
+	tx   Transactor
 }
 
-func NewDepartmentService(repo *repository.DepartmentRepository) *DepartmentService {
+func NewDepartmentService(repo DepartmentRepository, tx Transactor) *DepartmentService {
 	return &DepartmentService{
 		repo: repo,
+		tx:   tx,
 	}
 }
 
 func (ds *DepartmentService) CreateDepartment(ctx context.Context, req *dto.CreateDepartmentRequest) (*dto.DepartmentResponse, error) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.CreateDepartment")
+	defer span.End()
+
 	if err := ds.validateCreateRequest(req); err != nil {
 		return nil, err
 	}
@@ -36,68 +43,143 @@ func (ds *DepartmentService) CreateDepartment(ctx context.Context, req *dto.Crea
 }
 
 func (ds *DepartmentService) GetDepartmentByID(ctx context.Context, deptID string) (*dto.DepartmentResponse, error) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.GetDepartmentByID")
+	defer span.End()
+
 	dept, err := ds.repo.GetByID(ctx, deptID)
 	if err != nil {
-		return nil, fmt.Errorf("department not found: %w", err)
+		return nil, err
 	}
 
 	if !dept.IsActive {
-		return nil, errors.New("department is inactive")
+		return nil, utils.NewNotFoundError("department_inactive", "department is inactive")
 	}
 
 	return ModelToDepartmentResponse(dept), nil
 }
 
-func (ds *DepartmentService) GetAllDepartments(ctx context.Context, req *dto.PaginationRequest) (*dto.DepartmentListResponse, error) {
-	if err := ds.validatePagination(req); err != nil {
-		return nil, err
-	}
+// ListDepartments returns one page of departments matching q.
+func (ds *DepartmentService) ListDepartments(ctx context.Context, q listquery.Query[*models.Department]) (*dto.ListResponse[dto.DepartmentResponse], error) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.ListDepartments")
+	defer span.End()
 
-	params := PaginationToRepositoryParams(req)
-	resutl, err := ds.repo.GetAll(ctx, params)
+	page, err := ds.repo.List(ctx, q)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch departments: %w", err)
 	}
-	return ModelsToListResponse(resutl.Data, resutl.TotalCount, req.Page, req.PageSize), nil
+	return dto.NewListResponse(page, func(dept *models.Department) dto.DepartmentResponse {
+		return *ModelToDepartmentResponse(dept)
+	}), nil
 }
 
-func (ds *DepartmentService) UpdateDepartment(ctx context.Context, deptID string, req *dto.UpdateDepartmentRequest) (*dto.DepartmentResponse, error) {
+// UpdateDepartment applies req to the department if it is still at version,
+// the version the caller last read.
+func (ds *DepartmentService) UpdateDepartment(ctx context.Context, deptID string, version int, req *dto.UpdateDepartmentRequest) (*dto.DepartmentResponse, error) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.UpdateDepartment")
+	defer span.End()
+
 	if err := ds.validateUpdateRequest(req); err != nil {
 		return nil, err
 	}
-	exisitng, err := ds.repo.GetByID(ctx, deptID)
-	if err != nil {
-		return nil, fmt.Errorf("department not found: %w", err)
-	}
 
-	if !exisitng.IsActive {
-		return nil, errors.New("cannot update an inactive department")
-	}
+	var updated *models.Department
+
+	err := ds.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		exisitng, err := ds.repo.GetByID(ctx, deptID)
+		if err != nil {
+			return err
+		}
+
+		if exisitng.Version != version {
+			return repository.VersionConflict("department")
+		}
+
+		if !exisitng.IsActive {
+			return utils.NewConflictError("department_inactive", "cannot update an inactive department")
+		}
 
-	repoReq := UpdateRequestToRepositoryRequest(req)
-	updated, err := ds.repo.UpdateDepartment(ctx, deptID, repoReq)
+		repoReq := UpdateRequestToRepositoryRequest(req)
+		repoReq.Version = version
+		updated, err = ds.repo.UpdateDepartment(ctx, deptID, repoReq)
+		if err != nil {
+			return fmt.Errorf("failed to update department: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update department: %w", err)
+		return nil, err
 	}
 
 	return ModelToDepartmentResponse(updated), nil
 }
 
 func (ds *DepartmentService) DeleteDepartment(ctx context.Context, deptID string) error {
-	existing, err := ds.repo.GetByID(ctx, deptID)
-	if err != nil {
-		return fmt.Errorf("department not found: %w", err)
-	}
-	if !existing.IsActive {
-		return errors.New("department is already deleted")
-	}
+	ctx, span := tracing.Start(ctx, "DepartmentService.DeleteDepartment")
+	defer span.End()
 
-	err = ds.repo.DeleteDepartment(ctx, deptID)
+	return ds.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := ds.repo.GetByID(ctx, deptID)
+		if err != nil {
+			return err
+		}
+		if !existing.IsActive {
+			return utils.NewConflictError("department_already_deleted", "department is already deleted")
+		}
+
+		err = ds.repo.DeleteDepartment(ctx, deptID)
+		if err != nil {
+			return fmt.Errorf("failed to delete department: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// SeedDepartments creates, in a single transaction, each department that does
+// not already exist by name and returns the created departments and the names
+// that were skipped.
+func (ds *DepartmentService) SeedDepartments(ctx context.Context, reqs []dto.CreateDepartmentRequest) ([]*dto.DepartmentResponse, []string, error) {
+	ctx, span := tracing.Start(ctx, "DepartmentService.SeedDepartments")
+	defer span.End()
+
+	var created []*dto.DepartmentResponse
+	var skipped []string
+
+	err := ds.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		created, skipped = nil, nil
+
+		for i := range reqs {
+			req := &reqs[i]
+			req.Name = strings.TrimSpace(req.Name)
+			req.Description = strings.TrimSpace(req.Description)
+
+			if err := ds.validateCreateRequest(req); err != nil {
+				return fmt.Errorf("department %q: %w", req.Name, err)
+			}
+
+			exists, err := ds.repo.ExistsByName(ctx, req.Name)
+			if err != nil {
+				return fmt.Errorf("failed to check department %q: %w", req.Name, err)
+			}
+			if exists {
+				skipped = append(skipped, req.Name)
+				continue
+			}
+
+			dept, err := ds.CreateDepartment(ctx, req)
+			if err != nil {
+				return err
+			}
+			created = append(created, dept)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete department: %w", err)
+		return nil, nil, err
 	}
 
-	return nil
+	return created, skipped, nil
 }
 
 func ModelToDepartmentResponse(dept *models.Department) *dto.DepartmentResponse {
@@ -112,27 +194,7 @@ func ModelToDepartmentResponse(dept *models.Department) *dto.DepartmentResponse
 		IsActive:    dept.IsActive,
 		CreatedAt:   dept.CreatedAt,
 		UpdatedAt:   dept.UpdatedAt,
-	}
-}
-
-func ModelsToListResponse(departments []*models.Department, totalCount int, page int, pageSize int) *dto.DepartmentListResponse {
-	responses := make([]dto.DepartmentResponse, len(departments))
-
-	for i, dept := range departments {
-		responses[i] = *ModelToDepartmentResponse(dept)
-	}
-
-	totalPages := (totalCount + pageSize - 1) / pageSize
-	if totalPages == 0 {
-		totalPages = 1
-	}
-
-	return &dto.DepartmentListResponse{
-		Data:       responses,
-		TotalCount: totalCount,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
+		Version:     dept.Version,
 	}
 }
 
@@ -160,18 +222,9 @@ func UpdateRequestToRepositoryRequest(req *dto.UpdateDepartmentRequest) *reposit
 	}
 }
 
-func PaginationToRepositoryParams(req *dto.PaginationRequest) repository.PaginationParams {
-	offset := (req.Page - 1) * req.PageSize
-
-	return repository.PaginationParams{
-		Limit:  req.PageSize,
-		Offset: offset,
-	}
-}
-
 func (s *DepartmentService) validateCreateRequest(req *dto.CreateDepartmentRequest) error {
 	if req == nil {
-		return errors.New("request cannot be nil")
+		return utils.NewValidationError("invalid_request", "request cannot be nil")
 	}
 
 	// Validate name
@@ -181,7 +234,7 @@ func (s *DepartmentService) validateCreateRequest(req *dto.CreateDepartmentReque
 
 	// Validate description
 	if len(req.Description) > 500 {
-		return errors.New("description cannot exceed 500 characters")
+		return invalidField("description", "description cannot exceed 500 characters")
 	}
 
 	return nil
@@ -189,12 +242,12 @@ func (s *DepartmentService) validateCreateRequest(req *dto.CreateDepartmentReque
 
 func (s *DepartmentService) validateUpdateRequest(req *dto.UpdateDepartmentRequest) error {
 	if req == nil {
-		return errors.New("request cannot be nil")
+		return utils.NewValidationError("invalid_request", "request cannot be nil")
 	}
 
 	// At least one field must be provided
 	if req.Name == nil && req.Description == nil && req.IsActive == nil {
-		return errors.New("at least one field must be provided for update")
+		return utils.NewValidationError("empty_update", "at least one field must be provided for update")
 	}
 
 	// Validate name if provided
@@ -207,7 +260,7 @@ func (s *DepartmentService) validateUpdateRequest(req *dto.UpdateDepartmentReque
 	// Validate description if provided
 	if req.Description != nil {
 		if len(*req.Description) > 500 {
-			return errors.New("description cannot exceed 500 characters")
+			return invalidField("description", "description cannot exceed 500 characters")
 		}
 	}
 
@@ -218,35 +271,15 @@ func (s *DepartmentService) validateName(name string) error {
 	name = strings.TrimSpace(name)
 
 	if name == "" {
-		return errors.New("name cannot be empty")
+		return invalidField("name", "name cannot be empty")
 	}
 
 	if len(name) > 255 {
-		return errors.New("name cannot exceed 255 characters")
+		return invalidField("name", "name cannot exceed 255 characters")
 	}
 
 	if len(name) < 2 {
-		return errors.New("name must be at least 2 characters long")
-	}
-
-	return nil
-}
-
-func (s *DepartmentService) validatePagination(req *dto.PaginationRequest) error {
-	if req == nil {
-		return errors.New("pagination request cannot be nil")
-	}
-
-	if req.Page < 1 {
-		return errors.New("page must be greater than 0")
-	}
-
-	if req.PageSize < 1 {
-		return errors.New("page_size must be greater than 0")
-	}
-
-	if req.PageSize > 100 {
-		return errors.New("page_size cannot exceed 100")
+		return invalidField("name", "name must be at least 2 characters long")
 	}
 
 	return nil
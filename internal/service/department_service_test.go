@@ -0,0 +1,163 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/falasefemi2/hms/internal/dto"
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+func TestCreateDepartmentValidation(t *testing.T) {
+	svc := newDepartmentService(newFixture())
+
+	tests := []struct {
+		name    string
+		req     *dto.CreateDepartmentRequest
+		wantErr string
+	}{
+		{"nil request", nil, "request cannot be nil"},
+		{"empty name", &dto.CreateDepartmentRequest{Name: "  "}, "name cannot be empty"},
+		{"short name", &dto.CreateDepartmentRequest{Name: "A"}, "name must be at least 2 characters long"},
+		{"long name", &dto.CreateDepartmentRequest{Name: strings.Repeat("a", 256)}, "name cannot exceed 255 characters"},
+		{"long description", &dto.CreateDepartmentRequest{Name: "Cardiology", Description: strings.Repeat("a", 501)}, "description cannot exceed 500 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateDepartment(context.Background(), tt.req)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDepartmentLifecycle(t *testing.T) {
+	ctx := context.Background()
+	svc := newDepartmentService(newFixture())
+
+	created, err := svc.CreateDepartment(ctx, &dto.CreateDepartmentRequest{Name: "Cardiology", Description: "Heart"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !created.IsActive {
+		t.Error("new departments should be active")
+	}
+
+	if _, err := svc.UpdateDepartment(ctx, created.ID.String(), created.Version, &dto.UpdateDepartmentRequest{}); err == nil {
+		t.Error("expected empty update to be rejected")
+	}
+
+	updated, err := svc.UpdateDepartment(ctx, created.ID.String(), created.Version, &dto.UpdateDepartmentRequest{Name: strPtr("Cardiac Care")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Name != "Cardiac Care" || updated.Description != "Heart" {
+		t.Errorf("unexpected update result: %+v", updated)
+	}
+	if updated.Version != created.Version+1 {
+		t.Errorf("expected version %d, got %d", created.Version+1, updated.Version)
+	}
+
+	_, err = svc.UpdateDepartment(ctx, created.ID.String(), created.Version, &dto.UpdateDepartmentRequest{Name: strPtr("Stale")})
+	if !errors.Is(err, utils.ErrPreconditionFailed) {
+		t.Fatalf("expected stale update to fail the precondition, got %v", err)
+	}
+
+	if err := svc.DeleteDepartment(ctx, created.ID.String()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.DeleteDepartment(ctx, created.ID.String()); err == nil || err.Error() != "department is already deleted" {
+		t.Fatalf("expected already deleted error, got %v", err)
+	}
+	if _, err := svc.GetDepartmentByID(ctx, created.ID.String()); err == nil || err.Error() != "department is inactive" {
+		t.Fatalf("expected inactive error, got %v", err)
+	}
+	if _, err := svc.UpdateDepartment(ctx, created.ID.String(), updated.Version+1, &dto.UpdateDepartmentRequest{IsActive: boolPtr(true)}); err == nil || err.Error() != "cannot update an inactive department" {
+		t.Fatalf("expected update of inactive department to fail, got %v", err)
+	}
+}
+
+func TestListDepartmentsPagination(t *testing.T) {
+	ctx := context.Background()
+	svc := newDepartmentService(newFixture())
+
+	for _, name := range []string{"Cardiology", "Neurology", "Oncology"} {
+		if _, err := svc.CreateDepartment(ctx, &dto.CreateDepartmentRequest{Name: name}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	q, err := listquery.Parse(url.Values{"sort": {"name"}, "limit": {"2"}}, repository.DepartmentList)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first, err := svc.ListDepartments(ctx, q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first.Data) != 2 || !first.HasMore || first.NextCursor == "" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+
+	q, err = listquery.Parse(url.Values{"sort": {"name"}, "limit": {"2"}, "cursor": {first.NextCursor}}, repository.DepartmentList)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := svc.ListDepartments(ctx, q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second.Data) != 1 || second.HasMore || second.NextCursor != "" {
+		t.Fatalf("unexpected second page: %+v", second)
+	}
+
+	names := []string{first.Data[0].Name, first.Data[1].Name, second.Data[0].Name}
+	if !slices.Equal(names, []string{"Cardiology", "Neurology", "Oncology"}) {
+		t.Errorf("expected departments in name order across pages, got %v", names)
+	}
+
+	q, _ = listquery.Parse(url.Values{"is_active": {"false"}}, repository.DepartmentList)
+	inactive, err := svc.ListDepartments(ctx, q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(inactive.Data) != 0 {
+		t.Errorf("expected no inactive departments, got %d", len(inactive.Data))
+	}
+}
+
+func TestSeedDepartmentsSkipsExisting(t *testing.T) {
+	ctx := context.Background()
+	svc := newDepartmentService(newFixture())
+
+	if _, err := svc.CreateDepartment(ctx, &dto.CreateDepartmentRequest{Name: "Cardiology"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	created, skipped, err := svc.SeedDepartments(ctx, []dto.CreateDepartmentRequest{
+		{Name: "cardiology"},
+		{Name: "Radiology"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(created) != 1 || created[0].Name != "Radiology" {
+		t.Errorf("expected only Radiology to be created, got %+v", created)
+	}
+	if len(skipped) != 1 || skipped[0] != "cardiology" {
+		t.Errorf("expected cardiology to be skipped, got %v", skipped)
+	}
+}
+
+func newDepartmentService(f *fixture) *service.DepartmentService {
+	return service.NewDepartmentService(f.departments, f.tx)
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/tracing"
+)
+
+const (
+	// slotLength is the length of the slots offered in the directory.
+	slotLength = 30 * time.Minute
+	// slotHorizon is how far ahead the directory looks for a free slot.
+	slotHorizon = 14 * 24 * time.Hour
+)
+
+// DoctorDirectoryService lists doctors for patients choosing whom to book,
+// with each doctor's next free slot.
+type DoctorDirectoryService struct {
+	directory        DoctorDirectory
+	availabilityRepo AvailabilityRepository
+	appointmentRepo  AppointmentRepository
+}
+
+func NewDoctorDirectoryService(directory DoctorDirectory, availabilityRepo AvailabilityRepository, appointmentRepo AppointmentRepository) *DoctorDirectoryService {
+	return &DoctorDirectoryService{
+		directory:        directory,
+		availabilityRepo: availabilityRepo,
+		appointmentRepo:  appointmentRepo,
+	}
+}
+
+// ListDoctors returns one page of the directory. Doctors marked unavailable
+// have no next slot.
+func (s *DoctorDirectoryService) ListDoctors(ctx context.Context, q listquery.Query[*models.DoctorListing]) (*listquery.Page[*models.DoctorListing], error) {
+	ctx, span := tracing.Start(ctx, "DoctorDirectoryService.ListDoctors")
+	defer span.End()
+
+	page, err := s.directory.Directory(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list doctors: %w", err)
+	}
+
+	var doctorIDs []uuid.UUID
+	for _, listing := range page.Items {
+		if listing.Doctor.IsAvailable {
+			doctorIDs = append(doctorIDs, listing.Doctor.DoctorID)
+		}
+	}
+	if len(doctorIDs) == 0 {
+		return page, nil
+	}
+
+	now := time.Now().UTC()
+	availability, err := s.availabilityRepo.ListByDoctorIDs(ctx, doctorIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor availability: %w", err)
+	}
+	// Start at midnight so appointments already booked earlier today count
+	// towards today's max_appointments.
+	booked, err := s.appointmentRepo.ListBooked(ctx, doctorIDs, startOfDay(now), now.Add(slotHorizon+24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booked appointments: %w", err)
+	}
+
+	availabilityByDoctor := make(map[uuid.UUID][]*models.Availability)
+	for _, a := range availability {
+		availabilityByDoctor[a.DoctorID] = append(availabilityByDoctor[a.DoctorID], a)
+	}
+	bookedByDoctor := make(map[uuid.UUID][]*models.Appointment)
+	for _, a := range booked {
+		bookedByDoctor[a.DoctorID] = append(bookedByDoctor[a.DoctorID], a)
+	}
+
+	for _, listing := range page.Items {
+		if listing.Doctor.IsAvailable {
+			id := listing.Doctor.DoctorID
+			listing.NextAvailableSlot = nextAvailableSlot(availabilityByDoctor[id], bookedByDoctor[id], now)
+		}
+	}
+	return page, nil
+}
+
+// nextAvailableSlot returns the start of the first slot after now, within
+// slotHorizon, that falls inside one of the doctor's weekly availability
+// windows, overlaps no booked appointment, and belongs to a window that has
+// not reached its max_appointments. It returns nil if there is none.
+// Windows, like working hours, are UTC days and clock times.
+func nextAvailableSlot(availability []*models.Availability, booked []*models.Appointment, now time.Time) *time.Time {
+	now = now.UTC()
+	windows := slices.Clone(availability)
+	slices.SortFunc(windows, func(a, b *models.Availability) int {
+		return strings.Compare(a.StartTime, b.StartTime)
+	})
+
+	for day := startOfDay(now); day.Before(now.Add(slotHorizon)); day = day.AddDate(0, 0, 1) {
+		for _, window := range windows {
+			if !strings.EqualFold(window.DayOfWeek, day.Weekday().String()) {
+				continue
+			}
+			start, okStart := clockOn(day, window.StartTime)
+			end, okEnd := clockOn(day, window.EndTime)
+			if !okStart || !okEnd {
+				continue
+			}
+
+			if window.MaxAppointment > 0 {
+				count := 0
+				for _, a := range booked {
+					if !a.AppointmentDate.Before(start) && a.AppointmentDate.Before(end) {
+						count++
+					}
+				}
+				if count >= window.MaxAppointment {
+					continue
+				}
+			}
+
+			for slot := start; !slot.Add(slotLength).After(end); slot = slot.Add(slotLength) {
+				if slot.Before(now) || overlapsBooking(slot, booked) {
+					continue
+				}
+				return &slot
+			}
+		}
+	}
+	return nil
+}
+
+func overlapsBooking(slot time.Time, booked []*models.Appointment) bool {
+	slotEnd := slot.Add(slotLength)
+	for _, a := range booked {
+		if a.AppointmentDate.Before(slotEnd) && slot.Before(appointmentEnd(a)) {
+			return true
+		}
+	}
+	return false
+}
+
+// appointmentEnd returns when a ends, taking an unset duration as one slot.
+func appointmentEnd(a *models.Appointment) time.Time {
+	duration := time.Duration(a.DurationMinutes) * time.Minute
+	if duration <= 0 {
+		duration = slotLength
+	}
+	return a.AppointmentDate.Add(duration)
+}
+
+// clockOn returns the time of day clock ("15:04") on day.
+func clockOn(day time.Time, clock string) (time.Time, bool) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		// TIME columns read back with seconds.
+		if t, err = time.Parse("15:04:05", clock); err != nil {
+			return time.Time{}, false
+		}
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location()), true
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,153 @@
+package service_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/repository/memory"
+	"github.com/falasefemi2/hms/internal/service"
+)
+
+// addListedDoctor adds a doctor with a named user account in department.
+func (f *fixture) addListedDoctor(t *testing.T, last string, department *models.Department, fee float64, available bool) *models.Doctor {
+	t.Helper()
+	ctx := context.Background()
+
+	user, err := f.users.Create(ctx, &models.User{
+		Username:  "dr" + last,
+		Email:     "dr." + last + "@example.com",
+		FirstName: strPtr("Ada"),
+		LastName:  strPtr(last),
+		Role:      "DOCTOR",
+	})
+	if err != nil {
+		t.Fatalf("add user: %v", err)
+	}
+	doctor, err := f.doctors.Create(ctx, &models.Doctor{
+		DoctorID:        uuid.New(),
+		UserID:          user.ID,
+		DepartmentID:    department.ID,
+		Specialization:  department.Name,
+		LicenseNumber:   uuid.NewString(),
+		ConsultationFee: fee,
+	})
+	if err != nil {
+		t.Fatalf("add doctor: %v", err)
+	}
+	if !available {
+		doctor.IsAvailable = false
+		if doctor, err = f.doctors.Update(ctx, doctor); err != nil {
+			t.Fatalf("mark doctor unavailable: %v", err)
+		}
+	}
+	return doctor
+}
+
+func TestDoctorDirectory(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	availability := memory.NewAvailabilityRepository()
+	svc := service.NewDoctorDirectoryService(memory.NewDoctorDirectory(f.doctors, f.users, f.departments), availability, f.appointments)
+
+	cardiology, err := f.departments.CreateDepartment(ctx, &models.Department{ID: uuid.New(), Name: "Cardiology", IsActive: true})
+	if err != nil {
+		t.Fatalf("add department: %v", err)
+	}
+
+	// The clinic day is two days out, so it is always in the future; the
+	// same weekday recurs a week later, still inside the horizon.
+	clinicDay := time.Now().UTC().AddDate(0, 0, 2)
+	at := func(day time.Time, hour, minute int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+	}
+	addClinic := func(doctor *models.Doctor, maxAppointments int) {
+		_, err := availability.CreateAvailability(ctx, &models.Availability{
+			AvailabilityID: uuid.New(),
+			DoctorID:       doctor.DoctorID,
+			DayOfWeek:      clinicDay.Weekday().String(),
+			StartTime:      "09:00",
+			EndTime:        "10:00",
+			MaxAppointment: maxAppointments,
+		})
+		if err != nil {
+			t.Fatalf("add availability: %v", err)
+		}
+	}
+	book := func(doctor *models.Doctor, at time.Time) {
+		_, err := f.appointments.Create(ctx, &models.Appointment{
+			AppointmentID:   uuid.New(),
+			PatientID:       uuid.New(),
+			DoctorID:        doctor.DoctorID,
+			AppointmentDate: at,
+			DurationMinutes: 30,
+			Status:          "CONFIRMED",
+		})
+		if err != nil {
+			t.Fatalf("add appointment: %v", err)
+		}
+	}
+
+	partlyBooked := f.addListedDoctor(t, "Adeyemi", cardiology, 5000, true)
+	addClinic(partlyBooked, 2)
+	book(partlyBooked, at(clinicDay, 9, 0))
+
+	fullyBooked := f.addListedDoctor(t, "Bello", cardiology, 8000, true)
+	addClinic(fullyBooked, 1)
+	book(fullyBooked, at(clinicDay, 9, 30))
+
+	onLeave := f.addListedDoctor(t, "Chukwu", cardiology, 6000, false)
+	addClinic(onLeave, 2)
+
+	deactivated := f.addListedDoctor(t, "Danjuma", cardiology, 6000, true)
+	if err := f.users.SetActive(ctx, deactivated.UserID.String(), false); err != nil {
+		t.Fatalf("deactivate user: %v", err)
+	}
+
+	list := func(t *testing.T, query url.Values) []*models.DoctorListing {
+		t.Helper()
+		q, err := listquery.Parse(query, repository.DoctorDirectory)
+		if err != nil {
+			t.Fatalf("parse query: %v", err)
+		}
+		page, err := svc.ListDoctors(ctx, q)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return page.Items
+	}
+
+	t.Run("lists active doctors with their next free slot", func(t *testing.T) {
+		listings := list(t, url.Values{})
+		if len(listings) != 3 {
+			t.Fatalf("expected the 3 active doctors, got %d", len(listings))
+		}
+		want := map[uuid.UUID]*time.Time{
+			partlyBooked.DoctorID: timePtr(at(clinicDay, 9, 30)),
+			fullyBooked.DoctorID:  timePtr(at(clinicDay.AddDate(0, 0, 7), 9, 0)),
+			onLeave.DoctorID:      nil,
+		}
+		for _, listing := range listings {
+			if listing.DepartmentName != "Cardiology" || listing.FirstName != "Ada" {
+				t.Errorf("expected user and department joined, got %+v", listing)
+			}
+			got, expected := listing.NextAvailableSlot, want[listing.Doctor.DoctorID]
+			if (got == nil) != (expected == nil) || (got != nil && !got.Equal(*expected)) {
+				t.Errorf("%s: expected next slot %v, got %v", listing.LastName, expected, got)
+			}
+		}
+	})
+
+	t.Run("filters by fee range and availability", func(t *testing.T) {
+		listings := list(t, url.Values{"fee_max": {"7000"}, "is_available": {"true"}})
+		if len(listings) != 1 || listings[0].Doctor.DoctorID != partlyBooked.DoctorID {
+			t.Fatalf("expected only Dr Adeyemi, got %+v", listings)
+		}
+	})
+}
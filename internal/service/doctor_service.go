@@ -1,46 +1,403 @@
-
 package service
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
 
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/metrics"
 	"github.com/falasefemi2/hms/internal/models"
-	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/tracing"
+	"github.com/falasefemi2/hms/internal/utils"
 )
 
 type DoctorService struct {
-	doctorRepo *repository.DoctorRepository
-	userRepo   *repository.UserRepository
+	doctorRepo      DoctorRepository
+	userRepo        UserRepository
+	deptRepo        DepartmentRepository
+	appointmentRepo AppointmentRepository
+	audit           AuditRepository
+	tx              Transactor
 }
 
-func NewDoctorService(doctorRepo *repository.DoctorRepository, userRepo *repository.UserRepository) *DoctorService {
+func NewDoctorService(doctorRepo DoctorRepository, userRepo UserRepository, deptRepo DepartmentRepository, appointmentRepo AppointmentRepository, audit AuditRepository, tx Transactor) *DoctorService {
 	return &DoctorService{
-		doctorRepo: doctorRepo,
-		userRepo:   userRepo,
+		doctorRepo:      doctorRepo,
+		userRepo:        userRepo,
+		deptRepo:        deptRepo,
+		appointmentRepo: appointmentRepo,
+		audit:           audit,
+		tx:              tx,
 	}
 }
 
 func (s *DoctorService) CreateDoctor(ctx context.Context, doctor *models.Doctor) (*models.Doctor, error) {
-	user, err := s.userRepo.GetByID(ctx, doctor.UserID.String())
+	ctx, span := tracing.Start(ctx, "DoctorService.CreateDoctor")
+	defer span.End()
+
+	doctor.LicenseNumber = normalizeLicense(doctor.LicenseNumber)
+
+	var createdDoctor *models.Doctor
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetByID(ctx, doctor.UserID.String())
+		if err != nil {
+			return invalidReference(err, "user_id", "user not found")
+		}
+
+		if user.Role != "DOCTOR" {
+			return invalidField("user_id", "user is not a doctor")
+		}
+
+		_, err = s.doctorRepo.GetByUserID(ctx, user.ID)
+		if err == nil {
+			return utils.NewConflictError("doctor_exists", "doctor already exists for this user")
+		}
+		if !errors.Is(err, utils.ErrNotFound) {
+			return fmt.Errorf("failed to check existing doctor: %w", err)
+		}
+
+		if err := checkDepartment(ctx, s.deptRepo, doctor.DepartmentID); err != nil {
+			return err
+		}
+
+		createdDoctor, err = s.doctorRepo.Create(ctx, doctor)
+		if err != nil {
+			return fmt.Errorf("failed to create doctor: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, err
 	}
 
-	if user.Role != "DOCTOR" {
-		return nil, errors.New("user is not a doctor")
+	return createdDoctor, nil
+}
+
+func (s *DoctorService) GetDoctorByID(ctx context.Context, doctorID uuid.UUID) (*models.Doctor, error) {
+	ctx, span := tracing.Start(ctx, "DoctorService.GetDoctorByID")
+	defer span.End()
+
+	doctor, err := s.doctorRepo.GetDoctorID(ctx, doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor: %w", err)
 	}
 
-	existingDoctor, err := s.doctorRepo.GetByUserID(ctx, user.ID)
-	if err == nil && existingDoctor != nil {
-		return nil, errors.New("doctor already exists for this user")
+	return doctor, nil
+}
+
+// GetDoctorByUserID returns the doctor profile of the user userID.
+func (s *DoctorService) GetDoctorByUserID(ctx context.Context, userID uuid.UUID) (*models.Doctor, error) {
+	ctx, span := tracing.Start(ctx, "DoctorService.GetDoctorByUserID")
+	defer span.End()
+
+	doctor, err := s.doctorRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor: %w", err)
 	}
 
-	createdDoctor, err := s.doctorRepo.Create(ctx, doctor)
+	return doctor, nil
+}
+
+func (s *DoctorService) ListDoctors(ctx context.Context, q listquery.Query[*models.Doctor]) (*listquery.Page[*models.Doctor], error) {
+	ctx, span := tracing.Start(ctx, "DoctorService.ListDoctors")
+	defer span.End()
+
+	page, err := s.doctorRepo.List(ctx, q)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create doctor: %w", err)
+		return nil, fmt.Errorf("failed to list doctors: %w", err)
 	}
 
-	return createdDoctor, nil
+	return page, nil
+}
+
+func (s *DoctorService) UpdateDoctor(ctx context.Context, doctor *models.Doctor) (*models.Doctor, error) {
+	ctx, span := tracing.Start(ctx, "DoctorService.UpdateDoctor")
+	defer span.End()
+
+	doctor.LicenseNumber = normalizeLicense(doctor.LicenseNumber)
+
+	var updatedDoctor *models.Doctor
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := s.doctorRepo.GetDoctorID(ctx, doctor.DoctorID)
+		if err != nil {
+			return err
+		}
+
+		changed := doctorChanges(existing, doctor)
+		if err := doctorFieldPolicy.check(ctx, changed); err != nil {
+			return err
+		}
+
+		if slices.Contains(changed, "department_id") {
+			if err := checkDepartment(ctx, s.deptRepo, doctor.DepartmentID); err != nil {
+				return err
+			}
+		}
+		if slices.Contains(changed, "is_available") && doctor.IsAvailable {
+			if err := s.requireActiveUser(ctx, existing.UserID); err != nil {
+				return err
+			}
+		}
+
+		updatedDoctor, err = s.doctorRepo.Update(ctx, doctor)
+		if err != nil {
+			return fmt.Errorf("failed to update doctor: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedDoctor, nil
+}
+
+// DeactivateDoctor takes a doctor off the roster: their account is disabled,
+// they are marked unavailable, and their upcoming pending and confirmed
+// appointments are moved to reassignTo or, when it is nil, cancelled. A
+// reassignment is refused if any moved appointment would overlap one the
+// other doctor already has.
+func (s *DoctorService) DeactivateDoctor(ctx context.Context, doctorID uuid.UUID, reassignTo *uuid.UUID) (*models.DoctorDeactivation, error) {
+	ctx, span := tracing.Start(ctx, "DoctorService.DeactivateDoctor")
+	defer span.End()
+
+	var deactivation *models.DoctorDeactivation
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		doctor, err := s.doctorRepo.GetDoctorID(ctx, doctorID)
+		if err != nil {
+			return fmt.Errorf("failed to get doctor: %w", err)
+		}
+		user, err := s.userRepo.GetByID(ctx, doctor.UserID.String())
+		if err != nil {
+			return fmt.Errorf("failed to get doctor user: %w", err)
+		}
+		if !user.IsActive {
+			return utils.NewConflictError("staff_inactive", "doctor is already deactivated")
+		}
+
+		now := time.Now()
+		deactivation = &models.DoctorDeactivation{ReassignedTo: reassignTo}
+		if reassignTo != nil {
+			if err := s.checkReassignment(ctx, doctorID, *reassignTo, now); err != nil {
+				return err
+			}
+			deactivation.AppointmentsReassigned, err = s.appointmentRepo.ReassignDoctor(ctx, doctorID, *reassignTo, now)
+			if err != nil {
+				return fmt.Errorf("failed to reassign appointments: %w", err)
+			}
+		} else {
+			deactivation.AppointmentsCancelled, err = s.appointmentRepo.CancelUpcoming(ctx, doctorID, now)
+			if err != nil {
+				return fmt.Errorf("failed to cancel appointments: %w", err)
+			}
+		}
+
+		doctor.IsAvailable = false
+		updated, err := s.doctorRepo.Update(ctx, doctor)
+		if err != nil {
+			return fmt.Errorf("failed to update doctor: %w", err)
+		}
+		if err := s.userRepo.SetActive(ctx, user.ID.String(), false); err != nil {
+			return fmt.Errorf("failed to deactivate doctor user: %w", err)
+		}
+		deactivation.Doctor = *updated
+
+		return recordAudit(ctx, s.audit, AuditDoctorDeactivate, "doctor", doctorID, map[string]any{
+			"reassigned_to":           reassignTo,
+			"appointments_reassigned": deactivation.AppointmentsReassigned,
+			"appointments_cancelled":  deactivation.AppointmentsCancelled,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if deactivation.AppointmentsCancelled > 0 {
+		metrics.AppointmentsCancelled.WithLabelValues(deactivation.Doctor.DepartmentID.String()).Add(float64(deactivation.AppointmentsCancelled))
+	}
+
+	return deactivation, nil
+}
+
+// checkReassignment makes sure the upcoming appointments of doctor from can
+// all move to doctor to.
+func (s *DoctorService) checkReassignment(ctx context.Context, from, to uuid.UUID, now time.Time) error {
+	const field = "reassign_to_doctor_id"
+
+	if from == to {
+		return invalidField(field, "appointments cannot be reassigned to the doctor being deactivated")
+	}
+	target, err := s.doctorRepo.GetDoctorID(ctx, to)
+	if err != nil {
+		return invalidReference(err, field, "doctor not found")
+	}
+	if !target.IsAvailable {
+		return invalidField(field, "doctor is not available")
+	}
+	targetUser, err := s.userRepo.GetByID(ctx, target.UserID.String())
+	if err != nil {
+		return fmt.Errorf("failed to get doctor user: %w", err)
+	}
+	if !targetUser.IsActive {
+		return invalidField(field, "doctor is deactivated")
+	}
+
+	moving, err := s.appointmentRepo.GetByDoctorID(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to get appointments: %w", err)
+	}
+	booked, err := s.appointmentRepo.GetByDoctorID(ctx, to)
+	if err != nil {
+		return fmt.Errorf("failed to get appointments: %w", err)
+	}
+	booked = upcomingAppointments(booked, now)
+	for _, appointment := range upcomingAppointments(moving, now) {
+		for _, other := range booked {
+			if appointmentsOverlap(appointment, other) {
+				return utils.NewConflictError("reassignment_conflict",
+					fmt.Sprintf("doctor is already booked at %s", appointment.AppointmentDate.Format(time.RFC3339)))
+			}
+		}
+	}
+	return nil
+}
+
+// ActivateDoctor puts a deactivated doctor back on the roster and marks them
+// available. Appointments cancelled or reassigned on deactivation stay as
+// they are.
+func (s *DoctorService) ActivateDoctor(ctx context.Context, doctorID uuid.UUID) (*models.Doctor, error) {
+	ctx, span := tracing.Start(ctx, "DoctorService.ActivateDoctor")
+	defer span.End()
+
+	var activated *models.Doctor
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		doctor, err := s.doctorRepo.GetDoctorID(ctx, doctorID)
+		if err != nil {
+			return fmt.Errorf("failed to get doctor: %w", err)
+		}
+		user, err := s.userRepo.GetByID(ctx, doctor.UserID.String())
+		if err != nil {
+			return fmt.Errorf("failed to get doctor user: %w", err)
+		}
+		if user.IsActive {
+			return utils.NewConflictError("staff_active", "doctor is already active")
+		}
+
+		if err := s.userRepo.SetActive(ctx, user.ID.String(), true); err != nil {
+			return fmt.Errorf("failed to activate doctor user: %w", err)
+		}
+		doctor.IsAvailable = true
+		activated, err = s.doctorRepo.Update(ctx, doctor)
+		if err != nil {
+			return fmt.Errorf("failed to update doctor: %w", err)
+		}
+
+		return recordAudit(ctx, s.audit, AuditDoctorActivate, "doctor", doctorID, map[string]any{})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return activated, nil
+}
+
+// GetMyProfile returns the doctor profile of the authenticated caller.
+func (s *DoctorService) GetMyProfile(ctx context.Context) (*models.DoctorProfile, error) {
+	ctx, span := tracing.Start(ctx, "DoctorService.GetMyProfile")
+	defer span.End()
+
+	user, err := currentUser(ctx, s.userRepo)
+	if err != nil {
+		return nil, err
+	}
+	doctor, err := s.doctorRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor: %w", err)
+	}
+
+	return &models.DoctorProfile{Doctor: *doctor, User: *user}, nil
+}
+
+// UpdateMyProfile applies the caller's edits to their own profile: their
+// name and phone, and whether they are taking appointments. Everything else
+// in requested is ignored.
+func (s *DoctorService) UpdateMyProfile(ctx context.Context, requested *models.DoctorProfile) (*models.DoctorProfile, error) {
+	ctx, span := tracing.Start(ctx, "DoctorService.UpdateMyProfile")
+	defer span.End()
+
+	var profile *models.DoctorProfile
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := currentUser(ctx, s.userRepo)
+		if err != nil {
+			return err
+		}
+		doctor, err := s.doctorRepo.GetByUserID(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get doctor: %w", err)
+		}
+
+		updatedUser, err := selfUserUpdate(ctx, user, &requested.User)
+		if err != nil {
+			return err
+		}
+		updatedDoctor := *doctor
+		updatedDoctor.IsAvailable = requested.Doctor.IsAvailable
+		if err := doctorFieldPolicy.check(ctx, doctorChanges(doctor, &updatedDoctor)); err != nil {
+			return err
+		}
+
+		if updatedUser, err = s.userRepo.Update(ctx, updatedUser); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		if doctor, err = s.doctorRepo.Update(ctx, &updatedDoctor); err != nil {
+			return fmt.Errorf("failed to update doctor: %w", err)
+		}
+
+		profile = &models.DoctorProfile{Doctor: *doctor, User: *updatedUser}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// requireActiveUser refuses to mark a doctor available while their account
+// is deactivated.
+func (s *DoctorService) requireActiveUser(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID.String())
+	if err != nil {
+		return fmt.Errorf("failed to get doctor user: %w", err)
+	}
+	if !user.IsActive {
+		return utils.NewConflictError("staff_inactive", "a deactivated doctor cannot be made available")
+	}
+	return nil
+}
+
+// upcomingAppointments keeps the pending and confirmed appointments that
+// start after now.
+func upcomingAppointments(appointments []*models.Appointment, now time.Time) []*models.Appointment {
+	var upcoming []*models.Appointment
+	for _, a := range appointments {
+		if a.AppointmentDate.After(now) && (a.Status == "PENDING" || a.Status == "CONFIRMED") {
+			upcoming = append(upcoming, a)
+		}
+	}
+	return upcoming
+}
+
+func appointmentsOverlap(a, b *models.Appointment) bool {
+	return a.AppointmentDate.Before(appointmentEnd(b)) && b.AppointmentDate.Before(appointmentEnd(a))
 }
@@ -0,0 +1,206 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/repository/memory"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+func newDoctorService(f *fixture, audit *memory.AuditRepository) *service.DoctorService {
+	return service.NewDoctorService(f.doctors, f.users, f.departments, f.appointments, audit, f.tx)
+}
+
+// asUser returns a context carrying the user and role JWTAuth would set.
+func asUser(userID uuid.UUID, role string) context.Context {
+	return context.WithValue(asRole(role), utils.UserIDKey, userID)
+}
+
+func (f *fixture) addDepartment(t *testing.T, name string, active bool) *models.Department {
+	t.Helper()
+
+	ctx := context.Background()
+	department, err := f.departments.CreateDepartment(ctx, &models.Department{Name: name})
+	if err != nil {
+		t.Fatalf("add department: %v", err)
+	}
+	if !active {
+		department, err = f.departments.UpdateDepartment(ctx, department.ID.String(), &repository.UpdateDepartmentRequest{
+			IsActive: boolPtr(false),
+			Version:  department.Version,
+		})
+		if err != nil {
+			t.Fatalf("close department: %v", err)
+		}
+	}
+	return department
+}
+
+func TestCreateDoctorLicenseNumbers(t *testing.T) {
+	ctx := asRole("ADMIN")
+	f := newFixture()
+	svc := newDoctorService(f, memory.NewAuditRepository())
+	cardiology := f.addDepartment(t, "Cardiology", true)
+
+	newDoctor := func(license string, department *models.Department) *models.Doctor {
+		user, err := f.users.Create(ctx, &models.User{Username: uuid.NewString(), Email: uuid.NewString() + "@example.com", Role: "DOCTOR"})
+		if err != nil {
+			t.Fatalf("add user: %v", err)
+		}
+		return &models.Doctor{DoctorID: uuid.New(), UserID: user.ID, DepartmentID: department.ID, LicenseNumber: license}
+	}
+
+	created, err := svc.CreateDoctor(ctx, newDoctor("  md-1234 ", cardiology))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.LicenseNumber != "MD-1234" {
+		t.Errorf("expected license normalised to MD-1234, got %q", created.LicenseNumber)
+	}
+
+	_, err = svc.CreateDoctor(ctx, newDoctor("Md-1234", cardiology))
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.Code != "license_number_taken" {
+		t.Errorf("expected the same license in another case to be taken, got %v", err)
+	}
+
+	closed := f.addDepartment(t, "Closed ward", false)
+	if _, err := svc.CreateDoctor(ctx, newDoctor("MD-5678", closed)); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected an inactive department to be rejected, got %v", err)
+	}
+}
+
+func TestDeactivateDoctorCancelsUpcomingAppointments(t *testing.T) {
+	ctx := asUser(uuid.New(), "ADMIN")
+	f := newFixture()
+	audit := memory.NewAuditRepository()
+	svc := newDoctorService(f, audit)
+	doctor := f.addListedDoctor(t, "Okafor", f.addDepartment(t, "Surgery", true), 5000, true)
+	patient := f.addPatient(t)
+	upcoming := f.addAppointment(t, patient, doctor, "CONFIRMED")
+	completed := f.addAppointment(t, patient, doctor, "COMPLETED")
+
+	deactivation, err := svc.DeactivateDoctor(ctx, doctor.DoctorID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if deactivation.AppointmentsCancelled != 1 || deactivation.AppointmentsReassigned != 0 {
+		t.Errorf("expected 1 appointment cancelled, got %+v", deactivation)
+	}
+	if deactivation.Doctor.IsAvailable {
+		t.Error("expected doctor marked unavailable")
+	}
+	for id, want := range map[uuid.UUID]string{upcoming.AppointmentID: "CANCELLED", completed.AppointmentID: "COMPLETED"} {
+		appointment, err := f.appointments.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("get appointment: %v", err)
+		}
+		if appointment.Status != want {
+			t.Errorf("expected appointment %s, got %s", want, appointment.Status)
+		}
+	}
+	user, err := f.users.GetByID(ctx, doctor.UserID.String())
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if user.IsActive {
+		t.Error("expected doctor user deactivated")
+	}
+	if entries, err := audit.ListByResource(ctx, "doctor", doctor.DoctorID); err != nil || len(entries) != 1 || entries[0].Action != service.AuditDoctorDeactivate {
+		t.Errorf("expected one deactivation audit entry, got %v (%v)", entries, err)
+	}
+
+	if _, err := svc.DeactivateDoctor(ctx, doctor.DoctorID, nil); !errors.Is(err, utils.ErrConflict) {
+		t.Errorf("expected deactivating twice to conflict, got %v", err)
+	}
+	if _, err := svc.UpdateDoctor(ctx, &deactivation.Doctor); err != nil {
+		t.Fatalf("unexpected error on a no-op update: %v", err)
+	}
+	deactivation.Doctor.IsAvailable = true
+	if _, err := svc.UpdateDoctor(ctx, &deactivation.Doctor); !errors.Is(err, utils.ErrConflict) {
+		t.Errorf("expected a deactivated doctor not to be made available, got %v", err)
+	}
+
+	activated, err := svc.ActivateDoctor(ctx, doctor.DoctorID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !activated.IsAvailable {
+		t.Error("expected reactivated doctor to be available")
+	}
+}
+
+func TestDeactivateDoctorReassignsAppointments(t *testing.T) {
+	ctx := asRole("ADMIN")
+	f := newFixture()
+	svc := newDoctorService(f, memory.NewAuditRepository())
+	surgery := f.addDepartment(t, "Surgery", true)
+	leaving := f.addListedDoctor(t, "Okafor", surgery, 5000, true)
+	cover := f.addListedDoctor(t, "Lawal", surgery, 5000, true)
+	busy := f.addListedDoctor(t, "Musa", surgery, 5000, true)
+	appointment := f.addAppointment(t, f.addPatient(t), leaving, "PENDING")
+	clash := f.addAppointment(t, f.addPatient(t), busy, "CONFIRMED")
+	clash.AppointmentDate = appointment.AppointmentDate.Add(15 * time.Minute)
+	if _, err := f.appointments.Update(ctx, clash); err != nil {
+		t.Fatalf("move clashing appointment: %v", err)
+	}
+
+	_, err := svc.DeactivateDoctor(ctx, leaving.DoctorID, &busy.DoctorID)
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.Code != "reassignment_conflict" {
+		t.Fatalf("expected a clash with the other doctor's booking, got %v", err)
+	}
+	if _, err := svc.DeactivateDoctor(ctx, leaving.DoctorID, &leaving.DoctorID); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected reassigning to the same doctor to be invalid, got %v", err)
+	}
+
+	deactivation, err := svc.DeactivateDoctor(ctx, leaving.DoctorID, &cover.DoctorID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deactivation.AppointmentsReassigned != 1 || deactivation.AppointmentsCancelled != 0 {
+		t.Errorf("expected 1 appointment reassigned, got %+v", deactivation)
+	}
+	moved, err := f.appointments.GetByID(ctx, appointment.AppointmentID)
+	if err != nil {
+		t.Fatalf("get appointment: %v", err)
+	}
+	if moved.DoctorID != cover.DoctorID || moved.Status != "PENDING" {
+		t.Errorf("expected appointment moved to Dr Lawal and still pending, got %+v", moved)
+	}
+}
+
+func TestUpdateMyDoctorProfile(t *testing.T) {
+	f := newFixture()
+	svc := newDoctorService(f, memory.NewAuditRepository())
+	doctor := f.addListedDoctor(t, "Okafor", f.addDepartment(t, "Surgery", true), 5000, true)
+	ctx := asUser(doctor.UserID, "DOCTOR")
+
+	profile, err := svc.UpdateMyProfile(ctx, &models.DoctorProfile{
+		Doctor: models.Doctor{IsAvailable: false, ConsultationFee: 1},
+		User:   models.User{FirstName: strPtr("Chiamaka"), LastName: strPtr("Okafor"), Phone: strPtr("08031234567"), Email: "changed@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if *profile.User.FirstName != "Chiamaka" || *profile.User.Phone != "08031234567" || profile.Doctor.IsAvailable {
+		t.Errorf("expected name, phone and availability updated, got %+v", profile)
+	}
+	if profile.User.Email != "dr.Okafor@example.com" || profile.Doctor.ConsultationFee != 5000 {
+		t.Errorf("expected fields outside self-service left alone, got %+v", profile)
+	}
+
+	if _, err := svc.UpdateMyProfile(asUser(uuid.New(), "DOCTOR"), profile); err == nil {
+		t.Error("expected a caller without a doctor profile to fail")
+	}
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+// invalidField reports a validation failure on a single request field.
+func invalidField(field, message string) error {
+	return utils.NewValidationError("validation_failed", message, utils.FieldError{Field: field, Message: message})
+}
+
+// invalidReference turns a not-found error for a record referenced by the
+// request into a validation error on field. Other errors are returned as is.
+func invalidReference(err error, field, message string) error {
+	if errors.Is(err, utils.ErrNotFound) {
+		return utils.NewValidationError("invalid_reference", message, utils.FieldError{Field: field, Message: message})
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/invite"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/repository/memory"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+var (
+	_ service.UserRepository           = (*repository.UserRepository)(nil)
+	_ service.DepartmentRepository     = (*repository.DepartmentRepository)(nil)
+	_ service.DoctorRepository         = (*repository.DoctorRepository)(nil)
+	_ service.DoctorDirectory          = (*repository.DoctorRepository)(nil)
+	_ service.NurseRepository          = (*repository.NurseRepository)(nil)
+	_ service.PatientRepository        = (*repository.PatientRepository)(nil)
+	_ service.PatientSearcher          = (*repository.PatientRepository)(nil)
+	_ service.PatientRecords           = (*repository.PatientRepository)(nil)
+	_ service.AuditRepository          = (*repository.AuditRepository)(nil)
+	_ service.InvitationRepository     = (*repository.InvitationRepository)(nil)
+	_ service.PatientInviteRepository  = (*repository.PatientInviteRepository)(nil)
+	_ service.AvailabilityRepository   = (*repository.AvailabilityRepository)(nil)
+	_ service.HospitalConfigRepository = (*repository.HospitalConfigRepository)(nil)
+	_ service.AppointmentRepository    = (*repository.AppointmentRepository)(nil)
+	_ service.ConsultationRepository   = (*repository.ConsultationRepository)(nil)
+	_ service.PrescriptionRepository   = (*repository.PrescriptionRepository)(nil)
+	_ service.LabTestRepository        = (*repository.LabTestRepository)(nil)
+
+	_ service.UserRepository           = (*memory.UserRepository)(nil)
+	_ service.DepartmentRepository     = (*memory.DepartmentRepository)(nil)
+	_ service.DoctorRepository         = (*memory.DoctorRepository)(nil)
+	_ service.DoctorDirectory          = (*memory.DoctorDirectory)(nil)
+	_ service.NurseRepository          = (*memory.NurseRepository)(nil)
+	_ service.PatientRepository        = (*memory.PatientRepository)(nil)
+	_ service.PatientSearcher          = (*memory.PatientSearcher)(nil)
+	_ service.PatientRecords           = (*memory.PatientRecords)(nil)
+	_ service.AuditRepository          = (*memory.AuditRepository)(nil)
+	_ service.InvitationRepository     = (*memory.InvitationRepository)(nil)
+	_ service.PatientInviteRepository  = (*memory.PatientInviteRepository)(nil)
+	_ service.AvailabilityRepository   = (*memory.AvailabilityRepository)(nil)
+	_ service.HospitalConfigRepository = (*memory.HospitalConfigRepository)(nil)
+	_ service.AppointmentRepository    = (*memory.AppointmentRepository)(nil)
+	_ service.ConsultationRepository   = (*memory.ConsultationRepository)(nil)
+	_ service.PrescriptionRepository   = (*memory.PrescriptionRepository)(nil)
+	_ service.LabTestRepository        = (*memory.LabTestRepository)(nil)
+
+	_ service.Transactor = (*repository.TxManager)(nil)
+	_ service.Transactor = (*memory.Transactor)(nil)
+
+	_ service.InvitationSender = invite.LogSender{}
+)
+
+// fixture bundles in-memory repositories shared by the services under test.
+type fixture struct {
+	users         *memory.UserRepository
+	departments   *memory.DepartmentRepository
+	doctors       *memory.DoctorRepository
+	patients      *memory.PatientRepository
+	appointments  *memory.AppointmentRepository
+	consultations *memory.ConsultationRepository
+	configs       *memory.HospitalConfigRepository
+	tx            *memory.Transactor
+}
+
+func newFixture() *fixture {
+	return &fixture{
+		users:         memory.NewUserRepository(),
+		departments:   memory.NewDepartmentRepository(),
+		doctors:       memory.NewDoctorRepository(),
+		patients:      memory.NewPatientRepository(),
+		appointments:  memory.NewAppointmentRepository(),
+		consultations: memory.NewConsultationRepository(),
+		configs:       memory.NewHospitalConfigRepository(),
+		tx:            memory.NewTransactor(),
+	}
+}
+
+func (f *fixture) addPatient(t *testing.T) *models.Patient {
+	t.Helper()
+
+	patient, err := f.patients.PatientProfile(context.Background(), &models.Patient{
+		PatientID:   uuid.New(),
+		UserID:      uuid.New(),
+		DateOfBirth: time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("add patient: %v", err)
+	}
+	return patient
+}
+
+func (f *fixture) addDoctor(t *testing.T) *models.Doctor {
+	t.Helper()
+
+	doctor, err := f.doctors.Create(context.Background(), &models.Doctor{
+		DoctorID:      uuid.New(),
+		UserID:        uuid.New(),
+		DepartmentID:  uuid.New(),
+		LicenseNumber: uuid.NewString(),
+	})
+	if err != nil {
+		t.Fatalf("add doctor: %v", err)
+	}
+	return doctor
+}
+
+func (f *fixture) addAppointment(t *testing.T, patient *models.Patient, doctor *models.Doctor, status string) *models.Appointment {
+	t.Helper()
+
+	appointment, err := f.appointments.Create(context.Background(), &models.Appointment{
+		AppointmentID:   uuid.New(),
+		PatientID:       patient.PatientID,
+		DoctorID:        doctor.DoctorID,
+		AppointmentDate: time.Now().Add(48 * time.Hour),
+		DurationMinutes: 30,
+		Status:          status,
+	})
+	if err != nil {
+		t.Fatalf("add appointment: %v", err)
+	}
+	return appointment
+}
+
+// asRole returns a context carrying the role JWTAuth would set for a caller.
+func asRole(role string) context.Context {
+	return context.WithValue(context.Background(), utils.RoleKey, role)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
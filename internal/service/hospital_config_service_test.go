@@ -0,0 +1,256 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+// setConfig adds a hospital config revision, in force at once, that lets
+// patients self-register or not and is open from 08:00 to 17:00.
+func setConfig(t *testing.T, svc *service.HospitalConfigService, selfRegistration bool) *models.HospitalConfig {
+	t.Helper()
+
+	ctx := context.Background()
+	latest, err := svc.LatestRevision(ctx)
+	if err != nil {
+		t.Fatalf("latest revision: %v", err)
+	}
+	config, err := svc.UpdateHospitalConfig(ctx, &models.HospitalConfig{
+		WorkingHoursStart:             "08:00",
+		WorkingHoursEnd:               "17:00",
+		EnablePatientSelfRegistration: selfRegistration,
+	}, latest)
+	if err != nil {
+		t.Fatalf("set config: %v", err)
+	}
+	return config
+}
+
+func TestHospitalConfigRevisions(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	svc := service.NewHospitalConfigService(f.configs, f.tx)
+
+	config, err := svc.ActiveConfig(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !config.EnablePatientSelfRegistration || config.AppointmentDurationMinutes != 30 {
+		t.Errorf("expected the defaults before the first revision, got %+v", config)
+	}
+	if _, err := svc.GetHospitalConfig(ctx); !errors.Is(err, utils.ErrNotFound) {
+		t.Errorf("expected no stored config, got %v", err)
+	}
+
+	// The defaults are cached like any other config, so a new revision must
+	// invalidate them.
+	first := setConfig(t, svc, true)
+	closed := setConfig(t, svc, false)
+	if first.Revision != 1 || closed.Revision != 2 || !closed.IsActive {
+		t.Fatalf("expected revisions 1 and 2 with 2 in force, got %d and %+v", first.Revision, closed)
+	}
+	config, err = svc.ActiveConfig(ctx)
+	if err != nil || config.Revision != 2 || config.EnablePatientSelfRegistration {
+		t.Fatalf("expected revision 2 in force, got %+v (%v)", config, err)
+	}
+
+	// Callers get a copy they may change freely.
+	config.EnablePatientSelfRegistration = true
+	if again, _ := svc.ActiveConfig(ctx); again.EnablePatientSelfRegistration {
+		t.Error("expected the cached config unaffected by a caller's change")
+	}
+
+	change := &models.HospitalConfig{WorkingHoursStart: "09:00", WorkingHoursEnd: "17:00"}
+	if _, err := svc.UpdateHospitalConfig(ctx, change, 0); !errors.Is(err, utils.ErrPreconditionRequired) {
+		t.Errorf("expected a change without If-Match refused, got %v", err)
+	}
+	if _, err := svc.UpdateHospitalConfig(ctx, change, 1); !errors.Is(err, utils.ErrPreconditionFailed) {
+		t.Errorf("expected a change based on a stale revision refused, got %v", err)
+	}
+
+	restored, err := svc.RollbackHospitalConfig(ctx, 1, time.Time{}, 2)
+	if err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if restored.Revision != 3 || restored.RolledBackFrom == nil || *restored.RolledBackFrom != 1 || !restored.EnablePatientSelfRegistration {
+		t.Errorf("expected revision 1 restored as revision 3, got %+v", restored)
+	}
+	if config, _ := svc.ActiveConfig(ctx); config.Revision != 3 {
+		t.Errorf("expected the rollback in force at once, got revision %d", config.Revision)
+	}
+	if _, err := svc.RollbackHospitalConfig(ctx, 9, time.Time{}, 3); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected rolling back to an unknown revision to be invalid, got %v", err)
+	}
+
+	later := time.Now().Add(time.Hour)
+	scheduled, err := svc.UpdateHospitalConfig(ctx, &models.HospitalConfig{
+		WorkingHoursStart: "09:00",
+		WorkingHoursEnd:   "17:00",
+		EffectiveFrom:     later,
+	}, 3)
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if scheduled.IsActive {
+		t.Error("expected a scheduled revision not in force yet")
+	}
+	if config, _ := svc.ActiveConfig(ctx); config.Revision != 3 {
+		t.Errorf("expected revision 3 still in force, got %d", config.Revision)
+	}
+	change.EffectiveFrom = time.Time{}
+	if _, err := svc.UpdateHospitalConfig(ctx, change, 4); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected a change taking effect before the scheduled one refused, got %v", err)
+	}
+	change.EffectiveFrom = time.Now().Add(-time.Hour)
+	if _, err := svc.UpdateHospitalConfig(ctx, change, 4); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected a change taking effect in the past refused, got %v", err)
+	}
+
+	// Two pages of two, so the revision in force is not on the last page.
+	var revisions, active []int
+	values := url.Values{"limit": {"2"}}
+	for {
+		q, err := listquery.Parse(values, repository.HospitalConfigHistory)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		history, err := svc.ListHospitalConfigHistory(ctx, q)
+		if err != nil {
+			t.Fatalf("list history: %v", err)
+		}
+		for _, config := range history.Items {
+			revisions = append(revisions, config.Revision)
+			if config.IsActive {
+				active = append(active, config.Revision)
+			}
+		}
+		if !history.HasMore {
+			break
+		}
+		values.Set("cursor", history.NextCursor)
+	}
+	if !slices.Equal(revisions, []int{4, 3, 2, 1}) || !slices.Equal(active, []int{3}) {
+		t.Errorf("expected revisions 4 to 1 with 3 in force, got %v in force of %v", active, revisions)
+	}
+	if latest, err := svc.LatestRevision(ctx); err != nil || latest != 4 {
+		t.Errorf("expected revision 4 as the latest, got %d (%v)", latest, err)
+	}
+
+	if config, err := svc.GetHospitalConfigRevision(ctx, 2); err != nil || config.IsActive || config.EnablePatientSelfRegistration {
+		t.Errorf("expected revision 2 out of force, got %+v (%v)", config, err)
+	}
+	if _, err := svc.GetHospitalConfigRevision(ctx, 9); !errors.Is(err, utils.ErrNotFound) {
+		t.Errorf("expected an unknown revision to be not found, got %v", err)
+	}
+}
+
+func TestHospitalConfigEffectiveFromInUTC(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	svc := service.NewHospitalConfigService(f.configs, f.tx)
+	first := setConfig(t, svc, true)
+
+	lagos := time.FixedZone("+01:00", 60*60)
+	newYork := time.FixedZone("-05:00", -5*60*60)
+	at := time.Now().Add(2 * time.Hour).In(lagos)
+
+	scheduled, err := svc.UpdateHospitalConfig(ctx, &models.HospitalConfig{
+		WorkingHoursStart: "09:00",
+		WorkingHoursEnd:   "17:00",
+		EffectiveFrom:     at,
+	}, first.Revision)
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if scheduled.EffectiveFrom.Location() != time.UTC || !scheduled.EffectiveFrom.Equal(at) {
+		t.Errorf("expected effective_from stored as %s in UTC, got %s", at.UTC(), scheduled.EffectiveFrom)
+	}
+
+	// The same instants in another zone must still be ordered by time, not
+	// by wall clock: 30 minutes earlier is refused, an hour later accepted.
+	change := &models.HospitalConfig{WorkingHoursStart: "10:00", WorkingHoursEnd: "17:00"}
+	change.EffectiveFrom = at.Add(-30 * time.Minute).In(newYork)
+	if _, err := svc.UpdateHospitalConfig(ctx, change, scheduled.Revision); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected a change before the scheduled one refused, got %v", err)
+	}
+	change.EffectiveFrom = at.Add(time.Hour).In(newYork)
+	later, err := svc.UpdateHospitalConfig(ctx, change, scheduled.Revision)
+	if err != nil {
+		t.Fatalf("expected a change after the scheduled one accepted, got %v", err)
+	}
+	if !later.EffectiveFrom.Equal(at.Add(time.Hour)) || later.EffectiveFrom.Location() != time.UTC {
+		t.Errorf("expected effective_from %s in UTC, got %s", at.Add(time.Hour).UTC(), later.EffectiveFrom)
+	}
+}
+
+func TestHospitalConfigWorkingHoursValidation(t *testing.T) {
+	svc := service.NewHospitalConfigService(newFixture().configs, nil)
+
+	for _, hours := range [][2]string{{"", "17:00"}, {"8am", "17:00"}, {"17:00", "08:00"}} {
+		_, err := svc.UpdateHospitalConfig(context.Background(), &models.HospitalConfig{
+			WorkingHoursStart: hours[0],
+			WorkingHoursEnd:   hours[1],
+		}, 0)
+		if !errors.Is(err, utils.ErrInvalidInput) {
+			t.Errorf("expected working hours %v rejected, got %v", hours, err)
+		}
+	}
+}
+
+func TestAppointmentWithinWorkingHours(t *testing.T) {
+	ctx := asRole("NURSE")
+	f := newFixture()
+	configs := service.NewHospitalConfigService(f.configs, f.tx)
+	svc := service.NewAppointmentService(f.appointments, f.patients, f.doctors, configs, f.tx)
+	patient := f.addPatient(t)
+	doctor := f.addDoctor(t)
+
+	setConfig(t, configs, true)
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	at := func(hour, minute int) time.Time {
+		return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name    string
+		date    time.Time
+		wantErr bool
+	}{
+		{"at opening", at(8, 0), false},
+		{"ending at closing", at(16, 30), false},
+		{"before opening", at(7, 30), true},
+		{"running past closing", at(16, 45), true},
+		// 09:00 at UTC+2 is 07:00 UTC, before opening.
+		{"opening in the caller's zone", at(7, 0).In(time.FixedZone("UTC+2", 2*60*60)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateAppointment(ctx, &models.Appointment{
+				AppointmentID:   uuid.New(),
+				PatientID:       patient.PatientID,
+				DoctorID:        doctor.DoctorID,
+				AppointmentDate: tt.date,
+				DurationMinutes: 30,
+				Status:          "PENDING",
+			})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("wantErr %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr && !errors.Is(err, utils.ErrInvalidInput) {
+				t.Errorf("expected a validation error, got %v", err)
+			}
+		})
+	}
+}
@@ -2,78 +2,321 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
 	"github.com/falasefemi2/hms/internal/repository"
-	"github.com/google/uuid"
+	"github.com/falasefemi2/hms/internal/tracing"
+	"github.com/falasefemi2/hms/internal/utils"
 )
 
+// activeConfigTTL bounds how long a cached config is trusted. Changes made
+// through this instance invalidate it at once; the TTL covers changes made
+// through other instances and scheduled revisions coming into force.
+const activeConfigTTL = 30 * time.Second
+
+// ConfigProvider gives the hospital configuration currently in force.
+type ConfigProvider interface {
+	ActiveConfig(ctx context.Context) (*models.HospitalConfig, error)
+}
+
+// DefaultHospitalConfig applies until the first revision takes effect. It
+// matches the column defaults and leaves working hours open.
+func DefaultHospitalConfig() *models.HospitalConfig {
+	return &models.HospitalConfig{
+		AppointmentDurationMinutes:    30,
+		MaxSameDayCancellationHours:   24,
+		EnablePatientSelfRegistration: true,
+	}
+}
+
+// HospitalConfigService manages the hospital config as a single setting with
+// a history. Every change, including a rollback, adds a revision that takes
+// effect from a chosen time; revisions are never edited or deleted.
 type HospitalConfigService struct {
-	hospitalConfigRepo *repository.HospitalConfigRepository
+	hospitalConfigRepo HospitalConfigRepository
+	tx                 Transactor
+
+	mu         sync.Mutex
+	active     *models.HospitalConfig
+	loadedAt   time.Time
+	generation int
 }
 
-func NewHospitalConfigService(hospitalConfigRepo *repository.HospitalConfigRepository) *HospitalConfigService {
+func NewHospitalConfigService(hospitalConfigRepo HospitalConfigRepository, tx Transactor) *HospitalConfigService {
 	return &HospitalConfigService{
 		hospitalConfigRepo: hospitalConfigRepo,
+		tx:                 tx,
 	}
 }
 
-func (s *HospitalConfigService) CreateHospitalConfig(ctx context.Context, config *models.HospitalConfig) (*models.HospitalConfig, error) {
-	// Generate new UUID if not provided
-	if config.ConfigID == uuid.Nil {
-		config.ConfigID = uuid.New()
+// ActiveConfig returns the revision in force, or the defaults before the
+// first one. The result is cached; callers get their own copy.
+func (s *HospitalConfigService) ActiveConfig(ctx context.Context) (*models.HospitalConfig, error) {
+	ctx, span := tracing.Start(ctx, "HospitalConfigService.ActiveConfig")
+	defer span.End()
+
+	s.mu.Lock()
+	if s.active != nil && time.Since(s.loadedAt) < activeConfigTTL {
+		config := *s.active
+		s.mu.Unlock()
+		return &config, nil
 	}
+	generation := s.generation
+	s.mu.Unlock()
 
-	// Set defaults
-	if config.AppointmentDurationMinutes == 0 {
-		config.AppointmentDurationMinutes = 30
+	config, err := s.GetHospitalConfig(ctx)
+	if errors.Is(err, utils.ErrNotFound) {
+		config, err = DefaultHospitalConfig(), nil
 	}
-	if config.MaxSameDayCancellationHours == 0 {
-		config.MaxSameDayCancellationHours = 24
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the result only if nothing was invalidated while it loaded.
+	s.mu.Lock()
+	if s.generation == generation {
+		cached := *config
+		s.active, s.loadedAt = &cached, time.Now()
 	}
-	// EnablePatientSelfRegistration defaults to true in schema
+	s.mu.Unlock()
 
-	createdConfig, err := s.hospitalConfigRepo.Create(ctx, config)
+	return config, nil
+}
+
+// invalidate drops the cached config so the next read loads it.
+func (s *HospitalConfigService) invalidate() {
+	s.mu.Lock()
+	s.active = nil
+	s.generation++
+	s.mu.Unlock()
+}
+
+// GetHospitalConfig returns the stored revision in force, or not found
+// before the first one. Policy checks use ActiveConfig instead, which falls
+// back to the defaults.
+func (s *HospitalConfigService) GetHospitalConfig(ctx context.Context) (*models.HospitalConfig, error) {
+	ctx, span := tracing.Start(ctx, "HospitalConfigService.GetHospitalConfig")
+	defer span.End()
+
+	config, err := s.hospitalConfigRepo.GetEffective(ctx, time.Now())
 	if err != nil {
-		return nil, fmt.Errorf("failed to create hospital config: %w", err)
+		return nil, fmt.Errorf("failed to get hospital config: %w", err)
 	}
+	config.IsActive = true
 
-	return createdConfig, nil
+	return config, nil
 }
 
-func (s *HospitalConfigService) GetHospitalConfigByID(ctx context.Context, configID uuid.UUID) (*models.HospitalConfig, error) {
-	config, err := s.hospitalConfigRepo.GetByID(ctx, configID)
+// GetHospitalConfigRevision returns one revision from the history.
+func (s *HospitalConfigService) GetHospitalConfigRevision(ctx context.Context, revision int) (*models.HospitalConfig, error) {
+	ctx, span := tracing.Start(ctx, "HospitalConfigService.GetHospitalConfigRevision")
+	defer span.End()
+
+	config, err := s.hospitalConfigRepo.GetByRevision(ctx, revision)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get hospital config: %w", err)
 	}
+	effective, err := s.hospitalConfigRepo.GetEffective(ctx, time.Now())
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get hospital config: %w", err)
+	}
+	config.IsActive = effective != nil && effective.Revision == config.Revision
 
 	return config, nil
 }
 
-func (s *HospitalConfigService) GetAllHospitalConfigs(ctx context.Context) ([]*models.HospitalConfig, error) {
-	configs, err := s.hospitalConfigRepo.GetAll(ctx)
+// ListHospitalConfigHistory returns a page of revisions, including any
+// scheduled to take effect later. IsActive marks the one in force.
+func (s *HospitalConfigService) ListHospitalConfigHistory(ctx context.Context, q listquery.Query[*models.HospitalConfig]) (*listquery.Page[*models.HospitalConfig], error) {
+	ctx, span := tracing.Start(ctx, "HospitalConfigService.ListHospitalConfigHistory")
+	defer span.End()
+
+	page, err := s.hospitalConfigRepo.List(ctx, q)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get hospital configs: %w", err)
+		return nil, fmt.Errorf("failed to get hospital config history: %w", err)
+	}
+	effective, err := s.hospitalConfigRepo.GetEffective(ctx, time.Now())
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get hospital config: %w", err)
+	}
+	for _, config := range page.Items {
+		config.IsActive = effective != nil && effective.Revision == config.Revision
 	}
 
-	return configs, nil
+	return page, nil
 }
 
-func (s *HospitalConfigService) UpdateHospitalConfig(ctx context.Context, config *models.HospitalConfig) (*models.HospitalConfig, error) {
-	updatedConfig, err := s.hospitalConfigRepo.Update(ctx, config)
+// LatestRevision returns the newest revision number, scheduled or not, or 0
+// before the first. Changes must name it as their expected revision.
+func (s *HospitalConfigService) LatestRevision(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "HospitalConfigService.LatestRevision")
+	defer span.End()
+
+	latest, err := s.hospitalConfigRepo.GetLatest(ctx)
+	if errors.Is(err, utils.ErrNotFound) {
+		return 0, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to update hospital config: %w", err)
+		return 0, fmt.Errorf("failed to get hospital config: %w", err)
 	}
 
-	return updatedConfig, nil
+	return latest.Revision, nil
 }
 
-func (s *HospitalConfigService) DeleteHospitalConfig(ctx context.Context, configID uuid.UUID) error {
-	err := s.hospitalConfigRepo.Delete(ctx, configID)
+// UpdateHospitalConfig adds config as a new revision. It takes effect at
+// config.EffectiveFrom, or at once if that is zero. expectedRevision is the
+// newest revision the caller knows of; the change is refused if another has
+// been added since. Only the first revision may be made with 0.
+func (s *HospitalConfigService) UpdateHospitalConfig(ctx context.Context, config *models.HospitalConfig, expectedRevision int) (*models.HospitalConfig, error) {
+	ctx, span := tracing.Start(ctx, "HospitalConfigService.UpdateHospitalConfig")
+	defer span.End()
+
+	if config.AppointmentDurationMinutes == 0 {
+		config.AppointmentDurationMinutes = 30
+	}
+	if config.MaxSameDayCancellationHours == 0 {
+		config.MaxSameDayCancellationHours = 24
+	}
+	if err := validateWorkingHours(config); err != nil {
+		return nil, err
+	}
+
+	created, err := s.addRevision(ctx, config, expectedRevision)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	return created, nil
+}
+
+// RollbackHospitalConfig restores the settings of an earlier revision by
+// adding them again as a new revision, so the history keeps both the change
+// and its reversal. It takes effect at effectiveFrom, or at once if that is
+// zero; expectedRevision works as for UpdateHospitalConfig.
+func (s *HospitalConfigService) RollbackHospitalConfig(ctx context.Context, revision int, effectiveFrom time.Time, expectedRevision int) (*models.HospitalConfig, error) {
+	ctx, span := tracing.Start(ctx, "HospitalConfigService.RollbackHospitalConfig")
+	defer span.End()
+
+	var restored *models.HospitalConfig
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		previous, err := s.hospitalConfigRepo.GetByRevision(ctx, revision)
+		if err != nil {
+			return invalidReference(err, "revision", "revision not found")
+		}
+
+		restored, err = s.addRevision(ctx, &models.HospitalConfig{
+			WorkingHoursStart:             previous.WorkingHoursStart,
+			WorkingHoursEnd:               previous.WorkingHoursEnd,
+			AppointmentDurationMinutes:    previous.AppointmentDurationMinutes,
+			MaxSameDayCancellationHours:   previous.MaxSameDayCancellationHours,
+			EnablePatientSelfRegistration: previous.EnablePatientSelfRegistration,
+			EffectiveFrom:                 effectiveFrom,
+			RolledBackFrom:                &previous.Revision,
+		}, expectedRevision)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete hospital config: %w", err)
+		return nil, err
 	}
+	s.invalidate()
+
+	return restored, nil
+}
 
+// addRevision stores config as the next revision. A revision may not take
+// effect in the past, nor before the newest one does, so revisions come
+// into force in the order they were made. Callers invalidate the cached
+// config once the transaction it joins has committed.
+func (s *HospitalConfigService) addRevision(ctx context.Context, config *models.HospitalConfig, expectedRevision int) (*models.HospitalConfig, error) {
+	// Work on a copy so a refused change leaves the caller's config as given.
+	revision := *config
+	now := time.Now()
+	if revision.EffectiveFrom.IsZero() {
+		revision.EffectiveFrom = now
+	}
+	// effective_from is stored without a time zone, so keep it in UTC like
+	// every other timestamp read back from the database.
+	revision.EffectiveFrom = revision.EffectiveFrom.UTC()
+	if revision.EffectiveFrom.Before(now) {
+		return nil, invalidField("effective_from", "effective_from must not be in the past")
+	}
+
+	var created *models.HospitalConfig
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		latest, err := s.hospitalConfigRepo.GetLatest(ctx)
+		switch {
+		case errors.Is(err, utils.ErrNotFound):
+			latest = &models.HospitalConfig{}
+		case err != nil:
+			return fmt.Errorf("failed to get hospital config: %w", err)
+		}
+		switch {
+		case latest.Revision != expectedRevision && expectedRevision == 0:
+			return utils.NewPreconditionRequiredError("if_match_required", "If-Match header with the resource ETag is required")
+		case latest.Revision != expectedRevision:
+			return repository.VersionConflict("hospital config")
+		}
+		if revision.EffectiveFrom.Before(latest.EffectiveFrom) {
+			return invalidField("effective_from", fmt.Sprintf("effective_from must not be before revision %d takes effect (%s)",
+				latest.Revision, latest.EffectiveFrom.Format(time.RFC3339)))
+		}
+
+		revision.ConfigID = uuid.New()
+		revision.CreatedBy = actorID(ctx)
+		if created, err = s.hospitalConfigRepo.Create(ctx, &revision); err != nil {
+			return fmt.Errorf("failed to create hospital config revision: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created.IsActive = !created.EffectiveFrom.After(now)
+	return created, nil
+}
+
+// validateWorkingHours requires the opening and closing times to be clock
+// times with opening first.
+func validateWorkingHours(config *models.HospitalConfig) error {
+	start, okStart := clockOn(time.Time{}, config.WorkingHoursStart)
+	end, okEnd := clockOn(time.Time{}, config.WorkingHoursEnd)
+	if !okStart {
+		return invalidField("working_hours_start", "working hours must be HH:MM")
+	}
+	if !okEnd {
+		return invalidField("working_hours_end", "working hours must be HH:MM")
+	}
+	if !start.Before(end) {
+		return invalidField("working_hours_end", "working hours must end after they start")
+	}
 	return nil
 }
+
+// withinWorkingHours reports whether an appointment starting at start and
+// lasting minutes fits the configured working hours on its day. Working
+// hours are UTC clock times, whatever offset the caller sent start with. It
+// always fits when no working hours are set.
+func withinWorkingHours(config *models.HospitalConfig, start time.Time, minutes int) bool {
+	if config.WorkingHoursStart == "" || config.WorkingHoursEnd == "" {
+		return true
+	}
+	start = start.UTC()
+	open, okOpen := clockOn(start, config.WorkingHoursStart)
+	closing, okClose := clockOn(start, config.WorkingHoursEnd)
+	if !okOpen || !okClose {
+		return true
+	}
+	end := start.Add(time.Duration(minutes) * time.Minute)
+	return !start.Before(open) && !end.After(closing)
+}
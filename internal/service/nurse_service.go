@@ -4,43 +4,255 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
-	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/tracing"
+	"github.com/falasefemi2/hms/internal/utils"
 )
 
 type NurseSerivce struct {
-	nurseRepo *repository.NurseRepository
-	userRepo  *repository.UserRepository
+	nurseRepo NurseRepository
+	userRepo  UserRepository
+	deptRepo  DepartmentRepository
+	audit     AuditRepository
+	tx        Transactor
 }
 
-func NewNurseService(nurseRepo *repository.NurseRepository, userRepo *repository.UserRepository) *NurseSerivce {
+func NewNurseService(nurseRepo NurseRepository, userRepo UserRepository, deptRepo DepartmentRepository, audit AuditRepository, tx Transactor) *NurseSerivce {
 	return &NurseSerivce{
 		nurseRepo: nurseRepo,
 		userRepo:  userRepo,
+		deptRepo:  deptRepo,
+		audit:     audit,
+		tx:        tx,
 	}
 }
 
 func (n *NurseSerivce) CreateNurse(ctx context.Context, nurse *models.Nurse) (*models.Nurse, error) {
+	ctx, span := tracing.Start(ctx, "NurseSerivce.CreateNurse")
+	defer span.End()
+
+	nurse.LicenseNumber = normalizeLicense(nurse.LicenseNumber)
+
+	var createdNurse *models.Nurse
+
+	err := n.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := n.userRepo.GetByID(ctx, nurse.UserID.String())
+		if err != nil {
+			return invalidReference(err, "user_id", "user not found")
+		}
 
-	user, err := n.userRepo.GetByID(ctx, nurse.UserID.String())
+		if user.Role != "NURSE" {
+			return invalidField("user_id", "user is not a nurse")
+		}
+
+		_, err = n.nurseRepo.GetByUserID(ctx, user.ID)
+		if err == nil {
+			return utils.NewConflictError("nurse_exists", "nurse already exists for this user")
+		}
+		if !errors.Is(err, utils.ErrNotFound) {
+			return fmt.Errorf("failed to check existing nurse: %w", err)
+		}
+
+		if err := checkDepartment(ctx, n.deptRepo, nurse.DepartmentID); err != nil {
+			return err
+		}
+
+		createdNurse, err = n.nurseRepo.Create(ctx, nurse)
+		if err != nil {
+			return fmt.Errorf("failed to create nurse: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, err
 	}
 
-	if user.Role != "NURSE" {
-		return nil, errors.New("user is not a nurse")
+	return createdNurse, nil
+}
+
+func (n *NurseSerivce) GetNurseByID(ctx context.Context, nurseID uuid.UUID) (*models.Nurse, error) {
+	ctx, span := tracing.Start(ctx, "NurseSerivce.GetNurseByID")
+	defer span.End()
+
+	nurse, err := n.nurseRepo.GetByNurseID(ctx, nurseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nurse: %w", err)
 	}
 
-	existingDoctor, err := n.nurseRepo.GetByUserID(ctx, user.ID)
-	if err == nil && existingDoctor != nil {
-		return nil, errors.New("nurse already exists for this user")
+	return nurse, nil
+}
+
+// GetNurseByUserID returns the nurse profile of the user userID.
+func (n *NurseSerivce) GetNurseByUserID(ctx context.Context, userID uuid.UUID) (*models.Nurse, error) {
+	ctx, span := tracing.Start(ctx, "NurseSerivce.GetNurseByUserID")
+	defer span.End()
+
+	nurse, err := n.nurseRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nurse: %w", err)
 	}
 
-	createdNurse, err := n.nurseRepo.Create(ctx, nurse)
+	return nurse, nil
+}
+
+func (n *NurseSerivce) ListNurses(ctx context.Context, q listquery.Query[*models.Nurse]) (*listquery.Page[*models.Nurse], error) {
+	ctx, span := tracing.Start(ctx, "NurseSerivce.ListNurses")
+	defer span.End()
+
+	page, err := n.nurseRepo.List(ctx, q)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create nurse: %w", err)
+		return nil, fmt.Errorf("failed to list nurses: %w", err)
 	}
 
-	return createdNurse, nil
+	return page, nil
+}
+
+func (n *NurseSerivce) UpdateNurse(ctx context.Context, nurse *models.Nurse) (*models.Nurse, error) {
+	ctx, span := tracing.Start(ctx, "NurseSerivce.UpdateNurse")
+	defer span.End()
+
+	nurse.LicenseNumber = normalizeLicense(nurse.LicenseNumber)
+
+	var updatedNurse *models.Nurse
+
+	err := n.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := n.nurseRepo.GetByNurseID(ctx, nurse.NurseID)
+		if err != nil {
+			return err
+		}
+
+		changed := nurseChanges(existing, nurse)
+		if err := nurseFieldPolicy.check(ctx, changed); err != nil {
+			return err
+		}
+
+		if slices.Contains(changed, "department_id") {
+			if err := checkDepartment(ctx, n.deptRepo, nurse.DepartmentID); err != nil {
+				return err
+			}
+		}
+
+		updatedNurse, err = n.nurseRepo.Update(ctx, nurse)
+		if err != nil {
+			return fmt.Errorf("failed to update nurse: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedNurse, nil
+}
+
+// DeactivateNurse disables a nurse's account. The nurse record and the care
+// they have recorded are kept.
+func (n *NurseSerivce) DeactivateNurse(ctx context.Context, nurseID uuid.UUID) (*models.Nurse, error) {
+	ctx, span := tracing.Start(ctx, "NurseSerivce.DeactivateNurse")
+	defer span.End()
+
+	return n.setActive(ctx, nurseID, false)
+}
+
+// ActivateNurse re-enables a deactivated nurse's account.
+func (n *NurseSerivce) ActivateNurse(ctx context.Context, nurseID uuid.UUID) (*models.Nurse, error) {
+	ctx, span := tracing.Start(ctx, "NurseSerivce.ActivateNurse")
+	defer span.End()
+
+	return n.setActive(ctx, nurseID, true)
+}
+
+func (n *NurseSerivce) setActive(ctx context.Context, nurseID uuid.UUID, active bool) (*models.Nurse, error) {
+	var nurse *models.Nurse
+
+	err := n.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		nurse, err = n.nurseRepo.GetByNurseID(ctx, nurseID)
+		if err != nil {
+			return fmt.Errorf("failed to get nurse: %w", err)
+		}
+		user, err := n.userRepo.GetByID(ctx, nurse.UserID.String())
+		if err != nil {
+			return fmt.Errorf("failed to get nurse user: %w", err)
+		}
+
+		switch {
+		case user.IsActive && active:
+			return utils.NewConflictError("staff_active", "nurse is already active")
+		case !user.IsActive && !active:
+			return utils.NewConflictError("staff_inactive", "nurse is already deactivated")
+		}
+
+		action := AuditNurseDeactivate
+		if active {
+			action = AuditNurseActivate
+		}
+		if err := n.userRepo.SetActive(ctx, user.ID.String(), active); err != nil {
+			return fmt.Errorf("failed to update nurse user: %w", err)
+		}
+		return recordAudit(ctx, n.audit, action, "nurse", nurseID, map[string]any{})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nurse, nil
+}
+
+// GetMyProfile returns the nurse profile of the authenticated caller.
+func (n *NurseSerivce) GetMyProfile(ctx context.Context) (*models.NurseProfile, error) {
+	ctx, span := tracing.Start(ctx, "NurseSerivce.GetMyProfile")
+	defer span.End()
+
+	user, err := currentUser(ctx, n.userRepo)
+	if err != nil {
+		return nil, err
+	}
+	nurse, err := n.nurseRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nurse: %w", err)
+	}
+
+	return &models.NurseProfile{Nurse: *nurse, User: *user}, nil
+}
+
+// UpdateMyProfile applies the caller's edits to their own name and phone.
+// Shift, department and license stay with the admins.
+func (n *NurseSerivce) UpdateMyProfile(ctx context.Context, requested *models.NurseProfile) (*models.NurseProfile, error) {
+	ctx, span := tracing.Start(ctx, "NurseSerivce.UpdateMyProfile")
+	defer span.End()
+
+	var profile *models.NurseProfile
+
+	err := n.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := currentUser(ctx, n.userRepo)
+		if err != nil {
+			return err
+		}
+		nurse, err := n.nurseRepo.GetByUserID(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get nurse: %w", err)
+		}
+
+		updatedUser, err := selfUserUpdate(ctx, user, &requested.User)
+		if err != nil {
+			return err
+		}
+		if updatedUser, err = n.userRepo.Update(ctx, updatedUser); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+
+		profile = &models.NurseProfile{Nurse: *nurse, User: *updatedUser}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
 }
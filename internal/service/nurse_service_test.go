@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository/memory"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+func TestNurseLifecycle(t *testing.T) {
+	admin := asUser(uuid.New(), "ADMIN")
+	f := newFixture()
+	nurses := memory.NewNurseRepository()
+	audit := memory.NewAuditRepository()
+	svc := service.NewNurseService(nurses, f.users, f.departments, audit, f.tx)
+
+	user, err := f.users.Create(admin, &models.User{
+		Username:  "nurse.bola",
+		Email:     "bola@example.com",
+		FirstName: strPtr("Bola"),
+		LastName:  strPtr("Ade"),
+		Role:      "NURSE",
+	})
+	if err != nil {
+		t.Fatalf("add user: %v", err)
+	}
+	nurse, err := svc.CreateNurse(admin, &models.Nurse{
+		NurseID:       uuid.New(),
+		UserID:        user.ID,
+		DepartmentID:  f.addDepartment(t, "Paediatrics", true).ID,
+		Shift:         "NIGHT",
+		LicenseNumber: "rn-42",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nurse.LicenseNumber != "RN-42" {
+		t.Errorf("expected license normalised to RN-42, got %q", nurse.LicenseNumber)
+	}
+
+	t.Run("edits own contact details only", func(t *testing.T) {
+		self := asUser(user.ID, "NURSE")
+		profile, err := svc.UpdateMyProfile(self, &models.NurseProfile{
+			Nurse: models.Nurse{Shift: "DAY"},
+			User:  models.User{FirstName: strPtr("Bolanle"), LastName: strPtr("Ade")},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *profile.User.FirstName != "Bolanle" || profile.User.Phone != nil || profile.Nurse.Shift != "NIGHT" {
+			t.Errorf("expected only the name changed, got %+v", profile)
+		}
+
+		_, err = svc.UpdateMyProfile(self, &models.NurseProfile{User: models.User{FirstName: strPtr(" "), LastName: strPtr("Ade")}})
+		if !errors.Is(err, utils.ErrInvalidInput) {
+			t.Errorf("expected a blank first name to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("deactivates and reactivates", func(t *testing.T) {
+		if _, err := svc.DeactivateNurse(admin, nurse.NurseID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, _ := f.users.GetByID(admin, user.ID.String()); got.IsActive {
+			t.Error("expected nurse user deactivated")
+		}
+		if _, err := svc.DeactivateNurse(admin, nurse.NurseID); !errors.Is(err, utils.ErrConflict) {
+			t.Errorf("expected deactivating twice to conflict, got %v", err)
+		}
+		if _, err := svc.ActivateNurse(admin, nurse.NurseID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		entries, err := audit.ListByResource(admin, "nurse", nurse.NurseID)
+		if err != nil || len(entries) != 2 {
+			t.Fatalf("expected two audit entries, got %v (%v)", entries, err)
+		}
+	})
+}
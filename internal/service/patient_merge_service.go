@@ -0,0 +1,318 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/tracing"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+// Audit actions recorded by a merge. The survivor's entry carries the details;
+// the duplicate's entry lets its old ID be traced to the survivor.
+const (
+	AuditPatientMerge      = "patient.merge"
+	AuditPatientMergedInto = "patient.merged_into"
+)
+
+// PatientMergeService finds patients registered more than once and folds
+// the duplicates into one record.
+type PatientMergeService struct {
+	patientRepo PatientRepository
+	userRepo    UserRepository
+	searcher    PatientSearcher
+	records     PatientRecords
+	audit       AuditRepository
+	tx          Transactor
+}
+
+func NewPatientMergeService(patientRepo PatientRepository, userRepo UserRepository, searcher PatientSearcher, records PatientRecords, audit AuditRepository, tx Transactor) *PatientMergeService {
+	return &PatientMergeService{
+		patientRepo: patientRepo,
+		userRepo:    userRepo,
+		searcher:    searcher,
+		records:     records,
+		audit:       audit,
+		tx:          tx,
+	}
+}
+
+// FindDuplicates returns the patients that agree with patientID on at least
+// two of name, date of birth and phone, strongest candidates first. A fuzzy
+// name alone is too common to suggest a duplicate. Like a patient search the
+// result is a single page of at most limit candidates (default 20).
+func (s *PatientMergeService) FindDuplicates(ctx context.Context, patientID uuid.UUID, limit int) (*listquery.Page[*models.DuplicateCandidate], error) {
+	ctx, span := tracing.Start(ctx, "PatientMergeService.FindDuplicates")
+	defer span.End()
+
+	if limit == 0 {
+		limit = listquery.DefaultLimit
+	}
+	if limit < 1 || limit > maxSearchResults {
+		return nil, invalidField("limit", fmt.Sprintf("limit must be between 1 and %d", maxSearchResults))
+	}
+
+	patient, err := s.patientRepo.GetByPatientID(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patient: %w", err)
+	}
+	user, err := s.userRepo.GetByID(ctx, patient.UserID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patient user: %w", err)
+	}
+
+	name := strings.TrimSpace(derefString(user.FirstName) + " " + derefString(user.LastName))
+	if utf8.RuneCountInString(name) < minSearchNameLength {
+		name = ""
+	}
+	phone := derefString(user.Phone)
+	if len(repository.PhoneKey(phone)) < minSearchPhoneDigits {
+		phone = ""
+	}
+	dob := patient.DateOfBirth
+
+	var searches []repository.PatientSearch
+	if name != "" {
+		searches = append(searches, repository.PatientSearch{Name: name, DateOfBirth: &dob})
+	}
+	if name != "" && phone != "" {
+		searches = append(searches, repository.PatientSearch{Name: name, Phone: phone})
+	}
+	if phone != "" {
+		searches = append(searches, repository.PatientSearch{Phone: phone, DateOfBirth: &dob})
+	}
+
+	found := make(map[uuid.UUID]*models.DuplicateCandidate)
+	for _, search := range searches {
+		search.Limit = maxSearchResults
+		matches, err := s.searcher.Search(ctx, search)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search for duplicates: %w", err)
+		}
+
+		for _, match := range matches {
+			if match.Patient.PatientID == patient.PatientID {
+				continue
+			}
+			candidate, ok := found[match.Patient.PatientID]
+			if !ok {
+				candidate = &models.DuplicateCandidate{PatientMatch: *match}
+				candidate.Score = 0
+				found[match.Patient.PatientID] = candidate
+			}
+			if search.Name != "" {
+				candidate.Score = max(candidate.Score, match.Score)
+				candidate.MatchedOn = appendOnce(candidate.MatchedOn, "name")
+			}
+			if search.DateOfBirth != nil {
+				candidate.MatchedOn = appendOnce(candidate.MatchedOn, "date_of_birth")
+			}
+			if search.Phone != "" {
+				candidate.MatchedOn = appendOnce(candidate.MatchedOn, "phone")
+			}
+		}
+	}
+
+	candidates := make([]*models.DuplicateCandidate, 0, len(found))
+	for _, candidate := range found {
+		slices.Sort(candidate.MatchedOn)
+		candidates = append(candidates, candidate)
+	}
+	slices.SortFunc(candidates, func(a, b *models.DuplicateCandidate) int {
+		return cmp.Or(
+			cmp.Compare(len(b.MatchedOn), len(a.MatchedOn)),
+			cmp.Compare(b.Score, a.Score),
+			strings.Compare(a.Patient.MRN, b.Patient.MRN),
+		)
+	})
+
+	page := &listquery.Page[*models.DuplicateCandidate]{Items: candidates, Limit: limit}
+	if len(candidates) > limit {
+		page.Items, page.HasMore = candidates[:limit], true
+	}
+	return page, nil
+}
+
+// MergePatients folds duplicateID into survivorID: the duplicate's clinical
+// records move to the survivor, blank survivor details are filled from the
+// duplicate, the duplicate's medical history is appended, and the duplicate
+// patient is deleted and its user deactivated. Everything happens in one
+// transaction together with the audit entries, which keep a full snapshot of
+// the deleted record.
+func (s *PatientMergeService) MergePatients(ctx context.Context, survivorID, duplicateID uuid.UUID) (*models.PatientMerge, error) {
+	ctx, span := tracing.Start(ctx, "PatientMergeService.MergePatients")
+	defer span.End()
+
+	if survivorID == duplicateID {
+		return nil, invalidField("duplicate_patient_id", "a patient cannot be merged into itself")
+	}
+
+	var merge *models.PatientMerge
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		survivor, err := s.patientRepo.GetByPatientID(ctx, survivorID)
+		if err != nil {
+			return fmt.Errorf("failed to get patient: %w", err)
+		}
+		duplicate, err := s.patientRepo.GetByPatientID(ctx, duplicateID)
+		if err != nil {
+			return invalidReference(err, "duplicate_patient_id", "duplicate patient not found")
+		}
+
+		moved, err := s.records.ReassignRecords(ctx, duplicate.PatientID, survivor.PatientID)
+		if err != nil {
+			return fmt.Errorf("failed to move patient records: %w", err)
+		}
+
+		filled := fillFromDuplicate(survivor, duplicate)
+		if len(filled) > 0 {
+			survivor, err = s.patientRepo.Update(ctx, survivor)
+			if err != nil {
+				return fmt.Errorf("failed to update surviving patient: %w", err)
+			}
+		}
+
+		if err := s.patientRepo.Delete(ctx, duplicate.PatientID); err != nil {
+			return fmt.Errorf("failed to delete duplicate patient: %w", err)
+		}
+		if err := s.userRepo.SetActive(ctx, duplicate.UserID.String(), false); err != nil {
+			return fmt.Errorf("failed to deactivate duplicate patient user: %w", err)
+		}
+
+		merge = &models.PatientMerge{
+			Survivor:        *survivor,
+			MergedPatientID: duplicate.PatientID,
+			MergedMRN:       duplicate.MRN,
+			RecordsMoved:    moved,
+			FilledFields:    filled,
+		}
+		return s.auditMerge(ctx, merge, duplicate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return merge, nil
+}
+
+// patientSnapshot is how a merged-away patient is kept in the audit log.
+type patientSnapshot struct {
+	PatientID             uuid.UUID `json:"patient_id"`
+	UserID                uuid.UUID `json:"user_id"`
+	MRN                   string    `json:"mrn"`
+	DateOfBirth           string    `json:"date_of_birth"`
+	Gender                string    `json:"gender"`
+	BloodGroup            string    `json:"blood_group"`
+	EmergencyContactName  string    `json:"emergency_contact_name"`
+	EmergencyContactPhone string    `json:"emergency_contact_phone"`
+	MedicalHistory        string    `json:"medical_history"`
+}
+
+func (s *PatientMergeService) auditMerge(ctx context.Context, merge *models.PatientMerge, duplicate *models.Patient) error {
+	changes, err := json.Marshal(struct {
+		MergedPatient patientSnapshot  `json:"merged_patient"`
+		RecordsMoved  map[string]int64 `json:"records_moved"`
+		FilledFields  []string         `json:"filled_fields"`
+	}{
+		MergedPatient: patientSnapshot{
+			PatientID:             duplicate.PatientID,
+			UserID:                duplicate.UserID,
+			MRN:                   duplicate.MRN,
+			DateOfBirth:           duplicate.DateOfBirth.Format("2006-01-02"),
+			Gender:                duplicate.Gender,
+			BloodGroup:            duplicate.BloodGroup,
+			EmergencyContactName:  duplicate.EmergencyContactName,
+			EmergencyContactPhone: duplicate.EmergencyContactPhone,
+			MedicalHistory:        duplicate.MedicalHistory,
+		},
+		RecordsMoved: merge.RecordsMoved,
+		FilledFields: merge.FilledFields,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode merge audit: %w", err)
+	}
+	mergedInto, err := json.Marshal(map[string]any{
+		"survivor_patient_id": merge.Survivor.PatientID,
+		"survivor_mrn":        merge.Survivor.MRN,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode merge audit: %w", err)
+	}
+
+	actor := actorID(ctx)
+	for _, entry := range []*models.AuditLog{
+		{Action: AuditPatientMerge, ResourceID: merge.Survivor.PatientID, Changes: string(changes)},
+		{Action: AuditPatientMergedInto, ResourceID: duplicate.PatientID, Changes: string(mergedInto)},
+	} {
+		entry.LogID = uuid.New()
+		entry.UserID = actor
+		entry.ResourceType = "patient"
+		if _, err := s.audit.Create(ctx, entry); err != nil {
+			return fmt.Errorf("failed to write merge audit: %w", err)
+		}
+	}
+	return nil
+}
+
+// fillFromDuplicate copies the duplicate's details into the survivor's blank
+// fields and appends a differing medical history. It returns the fields it
+// changed, named as in the API.
+func fillFromDuplicate(survivor, duplicate *models.Patient) []string {
+	var filled []string
+	fill := func(field string, dst *string, src string) {
+		if *dst == "" && src != "" {
+			*dst = src
+			filled = append(filled, field)
+		}
+	}
+	fill("gender", &survivor.Gender, duplicate.Gender)
+	fill("blood_group", &survivor.BloodGroup, duplicate.BloodGroup)
+	fill("emergency_contact_name", &survivor.EmergencyContactName, duplicate.EmergencyContactName)
+	fill("emergency_contact_phone", &survivor.EmergencyContactPhone, duplicate.EmergencyContactPhone)
+
+	switch history := strings.TrimSpace(duplicate.MedicalHistory); {
+	case history == "" || strings.Contains(survivor.MedicalHistory, history):
+	case strings.TrimSpace(survivor.MedicalHistory) == "":
+		survivor.MedicalHistory = duplicate.MedicalHistory
+		filled = append(filled, "medical_history")
+	default:
+		survivor.MedicalHistory += fmt.Sprintf("\n\n[Merged from %s]\n%s", duplicate.MRN, history)
+		filled = append(filled, "medical_history")
+	}
+
+	return filled
+}
+
+// actorID returns the authenticated user JWTAuth stored in ctx, or nil for
+// calls made outside a request.
+func actorID(ctx context.Context) *uuid.UUID {
+	id, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+func appendOnce(list []string, s string) []string {
+	if slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,159 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/repository/memory"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+func newPatientMergeService(f *fixture, audit *memory.AuditRepository) *service.PatientMergeService {
+	return service.NewPatientMergeService(
+		f.patients,
+		f.users,
+		memory.NewPatientSearcher(f.patients, f.users),
+		memory.NewPatientRecords(f.appointments, f.consultations),
+		audit,
+		f.tx,
+	)
+}
+
+func TestFindDuplicates(t *testing.T) {
+	f := newFixture()
+	svc := newPatientMergeService(f, memory.NewAuditRepository())
+
+	dob := time.Date(1985, time.June, 15, 0, 0, 0, 0, time.UTC)
+	walkIn := f.addNamedPatient(t, "John", "Smith", "+234 803 123 4567", dob)
+	selfRegistered := f.addNamedPatient(t, "Jon", "Smith", "0803-123-4567", dob)
+	samePhone := f.addNamedPatient(t, "Ngozi", "Eze", "08031234567", dob)
+	f.addNamedPatient(t, "John", "Smith", "0809 000 0000", time.Date(1972, time.May, 1, 0, 0, 0, 0, time.UTC))
+
+	page, err := svc.FindDuplicates(context.Background(), walkIn.PatientID, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	candidates := page.Items
+
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %+v", candidates)
+	}
+	if got := candidates[0]; got.Patient.PatientID != selfRegistered.PatientID || !slices.Equal(got.MatchedOn, []string{"date_of_birth", "name", "phone"}) {
+		t.Errorf("expected Jon Smith matching on everything first, got %+v", got)
+	}
+	if got := candidates[1]; got.Patient.PatientID != samePhone.PatientID || !slices.Equal(got.MatchedOn, []string{"date_of_birth", "phone"}) || got.Score != 0 {
+		t.Errorf("expected Ngozi Eze matching on phone and birth date, got %+v", got)
+	}
+
+	page, err = svc.FindDuplicates(context.Background(), walkIn.PatientID, 1)
+	if err != nil || len(page.Items) != 1 || !page.HasMore || page.Items[0].Patient.PatientID != selfRegistered.PatientID {
+		t.Errorf("expected the strongest candidate alone with more to come, got %+v (%v)", page, err)
+	}
+	if _, err := svc.FindDuplicates(context.Background(), walkIn.PatientID, 51); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected a limit over 50 rejected, got %v", err)
+	}
+}
+
+func TestMergePatients(t *testing.T) {
+	ctx := context.WithValue(context.Background(), utils.UserIDKey, uuid.New())
+	f := newFixture()
+	audit := memory.NewAuditRepository()
+	svc := newPatientMergeService(f, audit)
+	doctor := f.addDoctor(t)
+
+	dob := time.Date(1985, time.June, 15, 0, 0, 0, 0, time.UTC)
+	survivor := f.addNamedPatient(t, "John", "Smith", "08031234567", dob)
+	duplicate := f.addNamedPatient(t, "Jon", "Smith", "08031234567", dob)
+	duplicate.BloodGroup = "O+"
+	duplicate.MedicalHistory = "Penicillin allergy"
+	if _, err := f.patients.Update(ctx, duplicate); err != nil {
+		t.Fatalf("update duplicate: %v", err)
+	}
+	kept := f.addAppointment(t, survivor, doctor, "COMPLETED")
+	moved := f.addAppointment(t, duplicate, doctor, "CONFIRMED")
+
+	merge, err := svc.MergePatients(ctx, survivor.PatientID, duplicate.PatientID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if merge.RecordsMoved["appointments"] != 1 {
+		t.Errorf("expected 1 appointment moved, got %v", merge.RecordsMoved)
+	}
+	for _, id := range []uuid.UUID{kept.AppointmentID, moved.AppointmentID} {
+		appointment, err := f.appointments.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("get appointment: %v", err)
+		}
+		if appointment.PatientID != survivor.PatientID {
+			t.Errorf("appointment %s still belongs to %s", id, appointment.PatientID)
+		}
+	}
+
+	if merge.Survivor.BloodGroup != "O+" || merge.Survivor.MedicalHistory != "Penicillin allergy" {
+		t.Errorf("expected blank survivor fields filled, got %+v", merge.Survivor)
+	}
+	if !slices.Equal(merge.FilledFields, []string{"blood_group", "medical_history"}) {
+		t.Errorf("unexpected filled fields %v", merge.FilledFields)
+	}
+
+	if _, err := f.patients.GetByPatientID(ctx, duplicate.PatientID); !errors.Is(err, utils.ErrNotFound) {
+		t.Errorf("expected duplicate patient deleted, got %v", err)
+	}
+	user, err := f.users.GetByID(ctx, duplicate.UserID.String())
+	if err != nil {
+		t.Fatalf("get duplicate user: %v", err)
+	}
+	if user.IsActive {
+		t.Error("expected duplicate user deactivated")
+	}
+
+	entries, err := audit.ListByResource(ctx, "patient", survivor.PatientID)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one audit entry for the survivor, got %v (%v)", entries, err)
+	}
+	if entries[0].Action != service.AuditPatientMerge || entries[0].UserID == nil || *entries[0].UserID != ctx.Value(utils.UserIDKey) {
+		t.Errorf("unexpected audit entry %+v", entries[0])
+	}
+	var changes struct {
+		MergedPatient struct {
+			PatientID      uuid.UUID `json:"patient_id"`
+			MedicalHistory string    `json:"medical_history"`
+		} `json:"merged_patient"`
+	}
+	if err := json.Unmarshal([]byte(entries[0].Changes), &changes); err != nil {
+		t.Fatalf("decode audit changes: %v", err)
+	}
+	if changes.MergedPatient.PatientID != duplicate.PatientID || changes.MergedPatient.MedicalHistory != "Penicillin allergy" {
+		t.Errorf("expected a snapshot of the duplicate, got %s", entries[0].Changes)
+	}
+
+	entries, err = audit.ListByResource(ctx, "patient", duplicate.PatientID)
+	if err != nil || len(entries) != 1 || entries[0].Action != service.AuditPatientMergedInto {
+		t.Errorf("expected the duplicate's ID to lead to the survivor, got %v (%v)", entries, err)
+	}
+}
+
+func TestMergePatientsRejectsInvalidPairs(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	svc := newPatientMergeService(f, memory.NewAuditRepository())
+	patient := f.addPatient(t)
+
+	if _, err := svc.MergePatients(ctx, patient.PatientID, patient.PatientID); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected merging a patient into itself to be invalid, got %v", err)
+	}
+	if _, err := svc.MergePatients(ctx, patient.PatientID, uuid.New()); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected an unknown duplicate to be invalid, got %v", err)
+	}
+	if _, err := svc.MergePatients(ctx, uuid.New(), patient.PatientID); !errors.Is(err, utils.ErrNotFound) {
+		t.Errorf("expected an unknown survivor to be not found, got %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/tracing"
+)
+
+// AuditPatientRecordsExport is recorded each time a patient downloads their
+// records.
+const AuditPatientRecordsExport = "patient.records_export"
+
+// Appointment windows a patient can list.
+const (
+	AppointmentsUpcoming = "upcoming"
+	AppointmentsPast     = "past"
+)
+
+// PatientPortalService serves the patient's own view of the hospital. Every
+// method acts on the patient behind the authenticated caller, never on an ID
+// from the request.
+type PatientPortalService struct {
+	patientRepo      PatientRepository
+	userRepo         UserRepository
+	appointmentRepo  AppointmentRepository
+	consultationRepo ConsultationRepository
+	prescriptionRepo PrescriptionRepository
+	labTestRepo      LabTestRepository
+	audit            AuditRepository
+	tx               Transactor
+}
+
+func NewPatientPortalService(patientRepo PatientRepository, userRepo UserRepository, appointmentRepo AppointmentRepository, consultationRepo ConsultationRepository, prescriptionRepo PrescriptionRepository, labTestRepo LabTestRepository, audit AuditRepository, tx Transactor) *PatientPortalService {
+	return &PatientPortalService{
+		patientRepo:      patientRepo,
+		userRepo:         userRepo,
+		appointmentRepo:  appointmentRepo,
+		consultationRepo: consultationRepo,
+		prescriptionRepo: prescriptionRepo,
+		labTestRepo:      labTestRepo,
+		audit:            audit,
+		tx:               tx,
+	}
+}
+
+// GetProfile returns the caller's patient profile.
+func (s *PatientPortalService) GetProfile(ctx context.Context) (*models.PatientProfile, error) {
+	ctx, span := tracing.Start(ctx, "PatientPortalService.GetProfile")
+	defer span.End()
+
+	return s.currentProfile(ctx)
+}
+
+// UpdateProfile applies the caller's edits to their name, phone and
+// emergency contact. Clinical details such as blood group and medical
+// history are kept by staff.
+func (s *PatientPortalService) UpdateProfile(ctx context.Context, requested *models.PatientProfile) (*models.PatientProfile, error) {
+	ctx, span := tracing.Start(ctx, "PatientPortalService.UpdateProfile")
+	defer span.End()
+
+	var profile *models.PatientProfile
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := s.currentProfile(ctx)
+		if err != nil {
+			return err
+		}
+
+		updatedUser, err := selfUserUpdate(ctx, &current.User, &requested.User)
+		if err != nil {
+			return err
+		}
+		updatedPatient := current.Patient
+		updatedPatient.EmergencyContactName = requested.Patient.EmergencyContactName
+		updatedPatient.EmergencyContactPhone = requested.Patient.EmergencyContactPhone
+		if err := patientFieldPolicy.check(ctx, patientChanges(&current.Patient, &updatedPatient)); err != nil {
+			return err
+		}
+
+		if updatedUser, err = s.userRepo.Update(ctx, updatedUser); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		patient, err := s.patientRepo.Update(ctx, &updatedPatient)
+		if err != nil {
+			return fmt.Errorf("failed to update patient: %w", err)
+		}
+
+		profile = &models.PatientProfile{Patient: *patient, User: *updatedUser}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// ListAppointments returns a page of the caller's upcoming appointments or
+// of their past ones. Past covers everything that is not upcoming, including
+// cancelled bookings.
+func (s *PatientPortalService) ListAppointments(ctx context.Context, when string, q listquery.Query[*models.Appointment]) (*listquery.Page[*models.Appointment], error) {
+	ctx, span := tracing.Start(ctx, "PatientPortalService.ListAppointments")
+	defer span.End()
+
+	if when != AppointmentsUpcoming && when != AppointmentsPast {
+		return nil, invalidField("when", "when must be upcoming or past")
+	}
+
+	patient, err := s.currentPatient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q = q.Where(repository.PatientAppointmentList.Fields["patient_id"], listquery.Eq, patient.PatientID).
+		Where(repository.PatientAppointmentList.Fields["upcoming"], listquery.Eq, when == AppointmentsUpcoming)
+
+	page, err := s.appointmentRepo.List(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appointments: %w", err)
+	}
+
+	return page, nil
+}
+
+// ListConsultations returns a page of the caller's consultations.
+func (s *PatientPortalService) ListConsultations(ctx context.Context, q listquery.Query[*models.Consultation]) (*listquery.Page[*models.Consultation], error) {
+	ctx, span := tracing.Start(ctx, "PatientPortalService.ListConsultations")
+	defer span.End()
+
+	patient, err := s.currentPatient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q = q.Where(repository.ConsultationList.Fields["patient_id"], listquery.Eq, patient.PatientID)
+
+	page, err := s.consultationRepo.List(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consultations: %w", err)
+	}
+
+	return page, nil
+}
+
+// ListPrescriptions returns a page of the caller's prescriptions.
+func (s *PatientPortalService) ListPrescriptions(ctx context.Context, q listquery.Query[*models.Prescription]) (*listquery.Page[*models.Prescription], error) {
+	ctx, span := tracing.Start(ctx, "PatientPortalService.ListPrescriptions")
+	defer span.End()
+
+	patient, err := s.currentPatient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q = q.Where(repository.PrescriptionList.Fields["patient_id"], listquery.Eq, patient.PatientID)
+
+	page, err := s.prescriptionRepo.List(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prescriptions: %w", err)
+	}
+
+	return page, nil
+}
+
+// ListLabResults returns the caller's completed lab tests. Tests still
+// being processed are left out until they have a result.
+func (s *PatientPortalService) ListLabResults(ctx context.Context) ([]*models.LabTest, error) {
+	ctx, span := tracing.Start(ctx, "PatientPortalService.ListLabResults")
+	defer span.End()
+
+	patient, err := s.currentPatient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tests, err := s.labTestRepo.GetCompletedByPatientID(ctx, patient.PatientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lab results: %w", err)
+	}
+
+	return nonNil(tests), nil
+}
+
+// ExportRecords gathers everything the caller may see into one record for
+// download, and notes the download in the audit log.
+func (s *PatientPortalService) ExportRecords(ctx context.Context) (*models.PatientRecord, error) {
+	ctx, span := tracing.Start(ctx, "PatientPortalService.ExportRecords")
+	defer span.End()
+
+	var record *models.PatientRecord
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		profile, err := s.currentProfile(ctx)
+		if err != nil {
+			return err
+		}
+		patientID := profile.Patient.PatientID
+
+		record = &models.PatientRecord{Profile: *profile, GeneratedAt: time.Now()}
+		if record.Appointments, err = s.appointmentRepo.GetByPatientID(ctx, patientID); err != nil {
+			return fmt.Errorf("failed to get appointments: %w", err)
+		}
+		if record.Consultations, err = s.consultationRepo.GetByPatientID(ctx, patientID); err != nil {
+			return fmt.Errorf("failed to get consultations: %w", err)
+		}
+		if record.Prescriptions, err = s.prescriptionRepo.GetByPatientID(ctx, patientID); err != nil {
+			return fmt.Errorf("failed to get prescriptions: %w", err)
+		}
+		if record.LabResults, err = s.labTestRepo.GetCompletedByPatientID(ctx, patientID); err != nil {
+			return fmt.Errorf("failed to get lab results: %w", err)
+		}
+		record.Appointments = nonNil(record.Appointments)
+		record.Consultations = nonNil(record.Consultations)
+		record.Prescriptions = nonNil(record.Prescriptions)
+		record.LabResults = nonNil(record.LabResults)
+
+		return recordAudit(ctx, s.audit, AuditPatientRecordsExport, "patient", patientID, map[string]int{
+			"appointments":  len(record.Appointments),
+			"consultations": len(record.Consultations),
+			"prescriptions": len(record.Prescriptions),
+			"lab_results":   len(record.LabResults),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// currentPatient returns the patient profile of the authenticated caller.
+// A patient who has signed up but not yet created a profile gets not found.
+func (s *PatientPortalService) currentPatient(ctx context.Context) (*models.Patient, error) {
+	profile, err := s.currentProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &profile.Patient, nil
+}
+
+func (s *PatientPortalService) currentProfile(ctx context.Context) (*models.PatientProfile, error) {
+	user, err := currentUser(ctx, s.userRepo)
+	if err != nil {
+		return nil, err
+	}
+	patient, err := s.patientRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patient: %w", err)
+	}
+	return &models.PatientProfile{Patient: *patient, User: *user}, nil
+}
+
+// nonNil turns a nil slice into an empty one, so it encodes as [] rather
+// than null.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return make([]T, 0)
+	}
+	return items
+}
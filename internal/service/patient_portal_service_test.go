@@ -0,0 +1,230 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/repository/memory"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+type portalFixture struct {
+	*fixture
+	prescriptions *memory.PrescriptionRepository
+	labTests      *memory.LabTestRepository
+	audit         *memory.AuditRepository
+	svc           *service.PatientPortalService
+}
+
+func newPortalFixture() *portalFixture {
+	f := &portalFixture{
+		fixture:       newFixture(),
+		prescriptions: memory.NewPrescriptionRepository(),
+		labTests:      memory.NewLabTestRepository(),
+		audit:         memory.NewAuditRepository(),
+	}
+	f.svc = service.NewPatientPortalService(f.patients, f.users, f.appointments, f.consultations,
+		f.prescriptions, f.labTests, f.audit, f.tx)
+	return f
+}
+
+// addPatientUser creates a patient with a user account and returns it with
+// a context authenticated as that user.
+func (f *portalFixture) addPatientUser(t *testing.T, email string) (*models.Patient, context.Context) {
+	t.Helper()
+
+	ctx := context.Background()
+	user, err := f.users.Create(ctx, &models.User{
+		Username:  email,
+		Email:     email,
+		FirstName: strPtr("Amaka"),
+		LastName:  strPtr("Eze"),
+		Role:      "PATIENT",
+	})
+	if err != nil {
+		t.Fatalf("add user: %v", err)
+	}
+	patient, err := f.patients.PatientProfile(ctx, &models.Patient{
+		PatientID:      uuid.New(),
+		UserID:         user.ID,
+		DateOfBirth:    time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC),
+		BloodGroup:     "O+",
+		MedicalHistory: "asthma",
+	})
+	if err != nil {
+		t.Fatalf("add patient: %v", err)
+	}
+	return patient, asUser(user.ID, "PATIENT")
+}
+
+func TestPatientPortalProfile(t *testing.T) {
+	f := newPortalFixture()
+	_, ctx := f.addPatientUser(t, "amaka@example.com")
+
+	profile, err := f.svc.UpdateProfile(ctx, &models.PatientProfile{
+		Patient: models.Patient{EmergencyContactName: "Obi Eze", EmergencyContactPhone: "08039999999", BloodGroup: "AB-"},
+		User:    models.User{FirstName: strPtr("Amarachi"), LastName: strPtr("Eze"), Phone: strPtr("08031111111")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *profile.User.FirstName != "Amarachi" || profile.Patient.EmergencyContactName != "Obi Eze" {
+		t.Errorf("expected name and emergency contact updated, got %+v", profile)
+	}
+	if profile.Patient.BloodGroup != "O+" || profile.Patient.MedicalHistory != "asthma" {
+		t.Errorf("expected clinical details left alone, got %+v", profile.Patient)
+	}
+
+	_, err = f.svc.UpdateProfile(ctx, &models.PatientProfile{User: models.User{FirstName: strPtr(""), LastName: strPtr("Eze")}})
+	if !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected a blank first name to be rejected, got %v", err)
+	}
+
+	noProfile := asUser(uuid.New(), "PATIENT")
+	if _, err := f.svc.GetProfile(noProfile); err == nil {
+		t.Error("expected a caller without a patient profile to fail")
+	}
+}
+
+func TestPatientPortalListsOnlyOwnRecords(t *testing.T) {
+	f := newPortalFixture()
+	patient, ctx := f.addPatientUser(t, "amaka@example.com")
+	other, _ := f.addPatientUser(t, "someone@example.com")
+	doctor := f.addDoctor(t)
+
+	soon := f.addAppointment(t, patient, doctor, "CONFIRMED")
+	later := f.addAppointment(t, patient, doctor, "PENDING")
+	later.AppointmentDate = soon.AppointmentDate.Add(24 * time.Hour)
+	if _, err := f.appointments.Update(ctx, later); err != nil {
+		t.Fatalf("move appointment: %v", err)
+	}
+	cancelled := f.addAppointment(t, patient, doctor, "CANCELLED")
+	f.addAppointment(t, other, doctor, "CONFIRMED")
+
+	soonestFirst, _ := listquery.Parse(url.Values{"sort": {"appointment_date"}, "limit": {"1"}}, repository.PatientAppointmentList)
+	upcoming, err := f.svc.ListAppointments(ctx, service.AppointmentsUpcoming, soonestFirst)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(upcoming.Items) != 1 || upcoming.Items[0].AppointmentID != soon.AppointmentID || !upcoming.HasMore {
+		t.Errorf("expected the soonest own appointment on the first page, got %+v", upcoming)
+	}
+	next, err := listquery.Parse(url.Values{"sort": {"appointment_date"}, "limit": {"1"}, "cursor": {upcoming.NextCursor}}, repository.PatientAppointmentList)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	upcoming, err = f.svc.ListAppointments(ctx, service.AppointmentsUpcoming, next)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(upcoming.Items) != 1 || upcoming.Items[0].AppointmentID != later.AppointmentID || upcoming.HasMore {
+		t.Errorf("expected the later own appointment on the last page, got %+v", upcoming)
+	}
+
+	q, _ := listquery.Parse(url.Values{}, repository.PatientAppointmentList)
+	past, err := f.svc.ListAppointments(ctx, service.AppointmentsPast, q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(past.Items) != 1 || past.Items[0].AppointmentID != cancelled.AppointmentID {
+		t.Errorf("expected the cancelled appointment in past, got %+v", past.Items)
+	}
+	if _, err := f.svc.ListAppointments(ctx, "tomorrow", q); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected an unknown window to be invalid, got %v", err)
+	}
+
+	completedAt := time.Now()
+	for _, test := range []*models.LabTest{
+		{TestID: uuid.New(), PatientID: patient.PatientID, DoctorID: doctor.DoctorID, TestName: "Full blood count", Status: "COMPLETED", CompletedAt: &completedAt},
+		{TestID: uuid.New(), PatientID: patient.PatientID, DoctorID: doctor.DoctorID, TestName: "Lipid panel", Status: "IN_PROGRESS"},
+		{TestID: uuid.New(), PatientID: other.PatientID, DoctorID: doctor.DoctorID, TestName: "Malaria", Status: "COMPLETED", CompletedAt: &completedAt},
+	} {
+		if _, err := f.labTests.Create(ctx, test); err != nil {
+			t.Fatalf("add lab test: %v", err)
+		}
+	}
+	results, err := f.svc.ListLabResults(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].TestName != "Full blood count" {
+		t.Errorf("expected only the own completed test, got %+v", results)
+	}
+
+	days := 5
+	if _, err := f.prescriptions.Create(ctx, &models.Prescription{
+		PrescriptionID: uuid.New(),
+		PatientID:      other.PatientID,
+		DoctorID:       doctor.DoctorID,
+		MedicationName: "Artemether",
+		DurationDays:   &days,
+	}); err != nil {
+		t.Fatalf("add prescription: %v", err)
+	}
+	newestFirst, _ := listquery.Parse(url.Values{}, repository.PrescriptionList)
+	prescriptions, err := f.svc.ListPrescriptions(ctx, newestFirst)
+	if err != nil || len(prescriptions.Items) != 0 || prescriptions.HasMore {
+		t.Errorf("expected an empty page of prescriptions, got %+v (%v)", prescriptions, err)
+	}
+}
+
+func TestPatientPortalExportRecords(t *testing.T) {
+	f := newPortalFixture()
+	patient, ctx := f.addPatientUser(t, "amaka@example.com")
+	doctor := f.addDoctor(t)
+	appointment := f.addAppointment(t, patient, doctor, "COMPLETED")
+
+	consultation, err := f.consultations.Create(ctx, &models.Consultation{
+		ConsultationID: uuid.New(),
+		AppointmentID:  appointment.AppointmentID,
+		PatientID:      patient.PatientID,
+		DoctorID:       doctor.DoctorID,
+		Diagnosis:      "Sinusitis",
+	})
+	if err != nil {
+		t.Fatalf("add consultation: %v", err)
+	}
+	q, _ := listquery.Parse(url.Values{}, repository.ConsultationList)
+	consultations, err := f.svc.ListConsultations(ctx, q)
+	if err != nil || len(consultations.Items) != 1 || consultations.Items[0].ConsultationID != consultation.ConsultationID {
+		t.Errorf("expected the own consultation listed, got %+v (%v)", consultations, err)
+	}
+	days := 7
+	if _, err := f.prescriptions.Create(ctx, &models.Prescription{
+		PrescriptionID: uuid.New(),
+		ConsultationID: consultation.ConsultationID,
+		PatientID:      patient.PatientID,
+		DoctorID:       doctor.DoctorID,
+		MedicationName: "Amoxicillin",
+		Dosage:         "500mg",
+		Frequency:      "Three times daily",
+		DurationDays:   &days,
+	}); err != nil {
+		t.Fatalf("add prescription: %v", err)
+	}
+
+	record, err := f.svc.ExportRecords(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if record.Profile.Patient.PatientID != patient.PatientID {
+		t.Errorf("expected the caller's own record, got %+v", record.Profile.Patient)
+	}
+	if len(record.Appointments) != 1 || len(record.Consultations) != 1 || len(record.Prescriptions) != 1 || len(record.LabResults) != 0 {
+		t.Errorf("expected one appointment, consultation and prescription, got %+v", record)
+	}
+
+	entries, err := f.audit.ListByResource(ctx, "patient", patient.PatientID)
+	if err != nil || len(entries) != 1 || entries[0].Action != service.AuditPatientRecordsExport {
+		t.Errorf("expected the download audited, got %v (%v)", entries, err)
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/tracing"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+// AuditPatientInviteCreate is recorded when an admin issues a patient invite
+// code.
+const AuditPatientInviteCreate = "patient_invite.create"
+
+// PatientRegistrationService decides whether a patient may sign up. While
+// the active hospital config allows self-registration anyone may; otherwise
+// a one-time invite code issued by an admin is required.
+type PatientRegistrationService struct {
+	users     *UserService
+	config    ConfigProvider
+	invites   PatientInviteRepository
+	audit     AuditRepository
+	inviteTTL time.Duration
+	tx        Transactor
+}
+
+func NewPatientRegistrationService(users *UserService, config ConfigProvider, invites PatientInviteRepository, audit AuditRepository, inviteTTL time.Duration, tx Transactor) *PatientRegistrationService {
+	return &PatientRegistrationService{
+		users:     users,
+		config:    config,
+		invites:   invites,
+		audit:     audit,
+		inviteTTL: inviteTTL,
+		tx:        tx,
+	}
+}
+
+// SignUp creates a patient account. inviteCode is ignored while
+// self-registration is enabled; when it is disabled the code must be one
+// that has not expired or been used, and using it spends it.
+func (s *PatientRegistrationService) SignUp(ctx context.Context, username, email, password, firstName, lastName, phone, inviteCode string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "PatientRegistrationService.SignUp")
+	defer span.End()
+
+	config, err := s.config.ActiveConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if config.EnablePatientSelfRegistration {
+		return s.users.CreatePatientUser(ctx, username, email, password, firstName, lastName, phone)
+	}
+
+	code := normalizeInviteCode(inviteCode)
+	if code == "" {
+		return nil, utils.NewForbiddenError("self_registration_disabled", "patient self-registration is disabled; an invite code is required")
+	}
+
+	var user *models.User
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		invite, err := s.invites.GetByCodeHash(ctx, hashInvitationToken(code))
+		if errors.Is(err, utils.ErrNotFound) {
+			return invalidInviteCode()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get patient invite: %w", err)
+		}
+		if invite.UsedAt != nil || !time.Now().UTC().Before(invite.ExpiresAt) {
+			return invalidInviteCode()
+		}
+
+		user, err = s.users.CreatePatientUser(ctx, username, email, password, firstName, lastName, phone)
+		if err != nil {
+			return err
+		}
+
+		if err := s.invites.MarkUsed(ctx, invite.InviteID, user.ID); err != nil {
+			return fmt.Errorf("failed to use patient invite: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// CreateInvite issues a one-time invite code for a patient to sign up with.
+// Only its hash is stored, so the code is returned here and nowhere else.
+func (s *PatientRegistrationService) CreateInvite(ctx context.Context) (string, *models.PatientInvite, error) {
+	ctx, span := tracing.Start(ctx, "PatientRegistrationService.CreateInvite")
+	defer span.End()
+
+	code, err := newInviteCode()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var invite *models.PatientInvite
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// expires_at is stored without a time zone, so it is kept in UTC.
+		invite = &models.PatientInvite{
+			InviteID:  uuid.New(),
+			CodeHash:  hashInvitationToken(normalizeInviteCode(code)),
+			CreatedBy: actorID(ctx),
+			ExpiresAt: time.Now().UTC().Add(s.inviteTTL),
+		}
+
+		var err error
+		if invite, err = s.invites.Create(ctx, invite); err != nil {
+			return fmt.Errorf("failed to create patient invite: %w", err)
+		}
+
+		return recordAudit(ctx, s.audit, AuditPatientInviteCreate, "patient_invite", invite.InviteID, map[string]time.Time{
+			"expires_at": invite.ExpiresAt,
+		})
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return code, invite, nil
+}
+
+func invalidInviteCode() error {
+	const message = "invite code is invalid, expired or already used"
+	return utils.NewValidationError("invite_code_invalid", message, utils.FieldError{Field: "invite_code", Message: message})
+}
+
+// newInviteCode returns 80 random bits as four groups of four base32
+// characters, short enough to read out over the phone.
+func newInviteCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate invite code: %w", err)
+	}
+	code := base32.StdEncoding.EncodeToString(b)
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16], nil
+}
+
+// normalizeInviteCode makes codes compare equal however they were typed.
+func normalizeInviteCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/falasefemi2/hms/internal/repository/memory"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+func newRegistrationService(f *fixture, configs *service.HospitalConfigService, ttl time.Duration) *service.PatientRegistrationService {
+	return service.NewPatientRegistrationService(service.NewUserService(f.users, f.tx), configs,
+		memory.NewPatientInviteRepository(), memory.NewAuditRepository(), ttl, f.tx)
+}
+
+func TestSignUpFollowsActiveConfig(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	configs := service.NewHospitalConfigService(f.configs, f.tx)
+	svc := newRegistrationService(f, configs, time.Hour)
+
+	if _, err := svc.SignUp(ctx, "ada", "ada@example.com", "password1", "Ada", "Lovelace", "", ""); err != nil {
+		t.Fatalf("expected signup open with no config, got %v", err)
+	}
+
+	setConfig(t, configs, false)
+	_, err := svc.SignUp(ctx, "grace", "grace@example.com", "password1", "Grace", "Hopper", "", "")
+	if !errors.Is(err, utils.ErrForbidden) {
+		t.Fatalf("expected signup without a code forbidden, got %v", err)
+	}
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.Code != "self_registration_disabled" {
+		t.Errorf("expected code self_registration_disabled, got %v", err)
+	}
+}
+
+func TestSignUpWithInviteCode(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	configs := service.NewHospitalConfigService(f.configs, f.tx)
+	setConfig(t, configs, false)
+	svc := newRegistrationService(f, configs, time.Hour)
+
+	code, invite, err := svc.CreateInvite(asRole("ADMIN"))
+	if err != nil {
+		t.Fatalf("create invite: %v", err)
+	}
+	if invite.CodeHash == code || len(code) != 19 {
+		t.Errorf("expected a formatted code stored only as a hash, got %q", code)
+	}
+	if invite.ExpiresAt.Location() != time.UTC {
+		t.Errorf("expected the expiry in UTC, got %v", invite.ExpiresAt)
+	}
+
+	if _, err := svc.SignUp(ctx, "grace", "grace@example.com", "password1", "Grace", "Hopper", "", "AAAA-BBBB-CCCC-DDDD"); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected an unknown code rejected, got %v", err)
+	}
+
+	// Codes are accepted however they are typed.
+	typed := strings.ToLower(strings.ReplaceAll(code, "-", " "))
+	user, err := svc.SignUp(ctx, "grace", "grace@example.com", "password1", "Grace", "Hopper", "", typed)
+	if err != nil {
+		t.Fatalf("expected signup with the code, got %v", err)
+	}
+	if user.Role != "PATIENT" {
+		t.Errorf("expected a patient, got %s", user.Role)
+	}
+
+	if _, err := svc.SignUp(ctx, "ada", "ada@example.com", "password1", "Ada", "Lovelace", "", code); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected a used code rejected, got %v", err)
+	}
+
+	expired := newRegistrationService(f, configs, -time.Minute)
+	code, _, err = expired.CreateInvite(ctx)
+	if err != nil {
+		t.Fatalf("create invite: %v", err)
+	}
+	if _, err := expired.SignUp(ctx, "ada", "ada@example.com", "password1", "Ada", "Lovelace", "", code); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected an expired code rejected, got %v", err)
+	}
+}
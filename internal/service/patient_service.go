@@ -4,38 +4,194 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
 	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/mrn"
 	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/tracing"
+	"github.com/falasefemi2/hms/internal/utils"
 )
 
 type PatientService struct {
-	patientRepo *repository.PatientRepository
-	userRepo    *repository.UserRepository
+	patientRepo PatientRepository
+	userRepo    UserRepository
+	searcher    PatientSearcher
+	mrnFormat   mrn.Format
+	tx          Transactor
 }
 
-func NewPatientService(patientRepo *repository.PatientRepository, userRepo *repository.UserRepository) *PatientService {
+const (
+	minSearchNameLength  = 2
+	minSearchPhoneDigits = 7
+	maxSearchResults     = 50
+)
+
+func NewPatientService(patientRepo PatientRepository, userRepo UserRepository, searcher PatientSearcher, mrnFormat mrn.Format, tx Transactor) *PatientService {
 	return &PatientService{
 		patientRepo: patientRepo,
 		userRepo:    userRepo,
+		searcher:    searcher,
+		mrnFormat:   mrnFormat,
+		tx:          tx,
 	}
 }
 
+// PatientProfile creates the patient record for a user. A patient creates
+// their own, found from their token; staff name the user.
 func (p *PatientService) PatientProfile(ctx context.Context, patient *models.Patient) (*models.Patient, error) {
-	user, err := p.userRepo.GetByID(ctx, patient.UserID.String())
+	ctx, span := tracing.Start(ctx, "PatientService.PatientProfile")
+	defer span.End()
+
+	var patientProfile *models.Patient
+
+	if role, _ := utils.GetRoleFromContext(ctx); role == patientRole {
+		userID, err := utils.GetUserIDFromContext(ctx)
+		if err != nil {
+			return nil, utils.NewUnauthorizedError("unauthorized", "caller is not authenticated")
+		}
+		if patient.UserID != uuid.Nil && patient.UserID != userID {
+			return nil, utils.NewForbiddenError("not_owner", "patients may only create their own profile")
+		}
+		patient.UserID = userID
+	} else if patient.UserID == uuid.Nil {
+		return nil, invalidField("user_id", "user_id is required")
+	}
+
+	err := p.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := p.userRepo.GetByID(ctx, patient.UserID.String())
+		if err != nil {
+			return invalidReference(err, "user_id", "user not found")
+		}
+		if user.Role != "PATIENT" {
+			return invalidField("user_id", "user is not a patient")
+		}
+		_, err = p.patientRepo.GetByUserID(ctx, user.ID)
+		if err == nil {
+			return utils.NewConflictError("patient_exists", "patient already exists for this user")
+		}
+		if !errors.Is(err, utils.ErrNotFound) {
+			return fmt.Errorf("failed to check existing patient: %w", err)
+		}
+		seq, err := p.patientRepo.NextMRNSequence(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to assign medical record number: %w", err)
+		}
+		patient.MRN = p.mrnFormat.Generate(seq, time.Now())
+		patientProfile, err = p.patientRepo.PatientProfile(ctx, patient)
+		if err != nil {
+			return fmt.Errorf("failed to create patient profile: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, err
 	}
-	if user.Role != "PATIENT" {
-		return nil, errors.New("user is not a patient")
+	return patientProfile, nil
+}
+
+func (p *PatientService) GetPatientByID(ctx context.Context, patientID uuid.UUID) (*models.Patient, error) {
+	ctx, span := tracing.Start(ctx, "PatientService.GetPatientByID")
+	defer span.End()
+
+	patient, err := p.patientRepo.GetByPatientID(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patient: %w", err)
 	}
-	exisitngPatient, err := p.patientRepo.GetByUserID(ctx, user.ID)
-	if err == nil && exisitngPatient != nil {
-		return nil, errors.New("patient already exists for this user")
+	if err := checkPatientOwner(ctx, p.patientRepo, patient.PatientID); err != nil {
+		return nil, err
 	}
-	patientProfile, err := p.patientRepo.PatientProfile(ctx, patient)
+
+	return patient, nil
+}
+
+func (p *PatientService) ListPatients(ctx context.Context, q listquery.Query[*models.Patient]) (*listquery.Page[*models.Patient], error) {
+	ctx, span := tracing.Start(ctx, "PatientService.ListPatients")
+	defer span.End()
+
+	page, err := p.patientRepo.List(ctx, q)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create patient profile: %w", err)
+		return nil, fmt.Errorf("failed to list patients: %w", err)
 	}
-	return patientProfile, nil
+
+	return page, nil
+}
+
+// SearchPatients returns up to limit patients matching search, best match
+// first. At least one criterion is required so that search cannot be used to
+// dump the patient list.
+func (p *PatientService) SearchPatients(ctx context.Context, search repository.PatientSearch) (*listquery.Page[*models.PatientMatch], error) {
+	ctx, span := tracing.Start(ctx, "PatientService.SearchPatients")
+	defer span.End()
+
+	search.Name = strings.TrimSpace(search.Name)
+	switch {
+	case search.Name == "" && search.Phone == "" && search.DateOfBirth == nil:
+		return nil, utils.NewValidationError("search_criteria_required", "provide at least one of name, phone or dob")
+	case search.Name != "" && utf8.RuneCountInString(search.Name) < minSearchNameLength:
+		return nil, invalidField("name", fmt.Sprintf("name must be at least %d characters", minSearchNameLength))
+	case search.Phone != "" && len(repository.PhoneKey(search.Phone)) < minSearchPhoneDigits:
+		return nil, invalidField("phone", fmt.Sprintf("phone must contain at least %d digits", minSearchPhoneDigits))
+	}
+
+	if search.Limit == 0 {
+		search.Limit = listquery.DefaultLimit
+	}
+	if search.Limit < 1 || search.Limit > maxSearchResults {
+		return nil, invalidField("limit", fmt.Sprintf("limit must be between 1 and %d", maxSearchResults))
+	}
+	limit := search.Limit
+	search.Limit++
+
+	matches, err := p.searcher.Search(ctx, search)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search patients: %w", err)
+	}
+
+	page := &listquery.Page[*models.PatientMatch]{Items: matches, Limit: limit}
+	if len(matches) > limit {
+		page.Items, page.HasMore = matches[:limit], true
+	}
+	if page.Items == nil {
+		page.Items = make([]*models.PatientMatch, 0)
+	}
+	return page, nil
+}
+
+func (p *PatientService) UpdatePatient(ctx context.Context, patient *models.Patient) (*models.Patient, error) {
+	ctx, span := tracing.Start(ctx, "PatientService.UpdatePatient")
+	defer span.End()
+
+	if patient.DateOfBirth.After(time.Now()) {
+		return nil, invalidField("date_of_birth", "date of birth cannot be in the future")
+	}
+
+	var updatedPatient *models.Patient
+
+	err := p.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := p.patientRepo.GetByPatientID(ctx, patient.PatientID)
+		if err != nil {
+			return err
+		}
+
+		if err := patientFieldPolicy.check(ctx, patientChanges(existing, patient)); err != nil {
+			return err
+		}
+
+		updatedPatient, err = p.patientRepo.Update(ctx, patient)
+		if err != nil {
+			return fmt.Errorf("failed to update patient: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedPatient, nil
 }
@@ -0,0 +1,204 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/mrn"
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/repository/memory"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+func newPatientService(f *fixture) *service.PatientService {
+	return service.NewPatientService(f.patients, f.users, memory.NewPatientSearcher(f.patients, f.users), mrn.MustParse(mrn.DefaultFormat), f.tx)
+}
+
+// addNamedPatient registers a patient user and profile for search tests.
+// The same person may be added more than once.
+func (f *fixture) addNamedPatient(t *testing.T, first, last, phone string, dob time.Time) *models.Patient {
+	t.Helper()
+
+	suffix := uuid.NewString()[:8]
+	user, err := f.users.Create(context.Background(), &models.User{
+		Username:  first + last + suffix,
+		Email:     first + "." + last + "." + suffix + "@example.com",
+		FirstName: strPtr(first),
+		LastName:  strPtr(last),
+		Phone:     strPtr(phone),
+		Role:      "PATIENT",
+	})
+	if err != nil {
+		t.Fatalf("add user: %v", err)
+	}
+	patient, err := f.patients.PatientProfile(context.Background(), &models.Patient{
+		PatientID:   uuid.New(),
+		UserID:      user.ID,
+		DateOfBirth: dob,
+	})
+	if err != nil {
+		t.Fatalf("add patient: %v", err)
+	}
+	return patient
+}
+
+func TestPatientProfileAssignsMRN(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	svc := newPatientService(f)
+
+	year := time.Now().Year()
+	for i, want := range []string{
+		fmt.Sprintf("MRN-%d-000001", year),
+		fmt.Sprintf("MRN-%d-000002", year),
+	} {
+		user, err := f.users.Create(ctx, &models.User{
+			Username: fmt.Sprintf("patient%d", i),
+			Email:    fmt.Sprintf("patient%d@example.com", i),
+			Role:     "PATIENT",
+		})
+		if err != nil {
+			t.Fatalf("add user: %v", err)
+		}
+
+		patient, err := svc.PatientProfile(ctx, &models.Patient{
+			PatientID:   uuid.New(),
+			UserID:      user.ID,
+			DateOfBirth: time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if patient.MRN != want {
+			t.Errorf("expected MRN %q, got %q", want, patient.MRN)
+		}
+	}
+}
+
+func TestPatientProfileOwner(t *testing.T) {
+	f := newFixture()
+	svc := newPatientService(f)
+	dob := time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	addUser := func(name string) *models.User {
+		user, err := f.users.Create(context.Background(), &models.User{Username: name, Email: name + "@example.com", Role: "PATIENT"})
+		if err != nil {
+			t.Fatalf("add user: %v", err)
+		}
+		return user
+	}
+	self, other, walkIn := addUser("self"), addUser("other"), addUser("walkin")
+
+	_, err := svc.PatientProfile(asUser(self.ID, "PATIENT"), &models.Patient{PatientID: uuid.New(), UserID: other.ID, DateOfBirth: dob})
+	if !errors.Is(err, utils.ErrForbidden) {
+		t.Errorf("expected a profile for another user to be forbidden, got %v", err)
+	}
+
+	patient, err := svc.PatientProfile(asUser(self.ID, "PATIENT"), &models.Patient{PatientID: uuid.New(), DateOfBirth: dob})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if patient.UserID != self.ID {
+		t.Errorf("expected the profile linked to the caller, got user %s", patient.UserID)
+	}
+
+	if _, err := svc.PatientProfile(asRole("NURSE"), &models.Patient{PatientID: uuid.New(), DateOfBirth: dob}); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected staff to have to name the user, got %v", err)
+	}
+	if _, err := svc.PatientProfile(asRole("NURSE"), &models.Patient{PatientID: uuid.New(), UserID: walkIn.ID, DateOfBirth: dob}); err != nil {
+		t.Errorf("expected staff to register a walk-in patient, got %v", err)
+	}
+
+	if _, err := svc.GetPatientByID(asUser(other.ID, "PATIENT"), patient.PatientID); !errors.Is(err, utils.ErrForbidden) {
+		t.Errorf("expected a patient without a profile to be refused, got %v", err)
+	}
+}
+
+func TestSearchPatients(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	svc := newPatientService(f)
+
+	dob := time.Date(1985, time.June, 15, 0, 0, 0, 0, time.UTC)
+	john := f.addNamedPatient(t, "John", "Smith", "+234 803 123 4567", dob)
+	jon := f.addNamedPatient(t, "Jonathan", "Smithers", "0805 999 0000", time.Date(1990, time.March, 2, 0, 0, 0, 0, time.UTC))
+	f.addNamedPatient(t, "Amaka", "Obi", "0807 111 2222", dob)
+
+	t.Run("misspelt names still match", func(t *testing.T) {
+		page, err := svc.SearchPatients(ctx, repository.PatientSearch{Name: "Jon Smyth"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page.Items) != 1 || page.Items[0].Patient.PatientID != john.PatientID {
+			t.Fatalf("expected only John Smith, got %+v", page.Items)
+		}
+	})
+
+	t.Run("closest match ranks first", func(t *testing.T) {
+		page, err := svc.SearchPatients(ctx, repository.PatientSearch{Name: "smith"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page.Items) != 2 || page.Items[0].Patient.PatientID != john.PatientID || page.Items[1].Patient.PatientID != jon.PatientID {
+			t.Fatalf("expected John Smith then Jonathan Smithers, got %+v", page.Items)
+		}
+		if page.Items[0].Score <= page.Items[1].Score {
+			t.Errorf("expected descending scores, got %v then %v", page.Items[0].Score, page.Items[1].Score)
+		}
+	})
+
+	t.Run("phone is matched whatever its format", func(t *testing.T) {
+		page, err := svc.SearchPatients(ctx, repository.PatientSearch{Phone: "0803-123-4567"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page.Items) != 1 || page.Items[0].Patient.PatientID != john.PatientID {
+			t.Fatalf("expected only John Smith, got %+v", page.Items)
+		}
+	})
+
+	t.Run("date of birth must match exactly", func(t *testing.T) {
+		page, err := svc.SearchPatients(ctx, repository.PatientSearch{Name: "smith", DateOfBirth: &dob})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page.Items) != 1 || page.Items[0].Patient.PatientID != john.PatientID {
+			t.Fatalf("expected only John Smith, got %+v", page.Items)
+		}
+	})
+
+	t.Run("limit reports more results", func(t *testing.T) {
+		page, err := svc.SearchPatients(ctx, repository.PatientSearch{DateOfBirth: &dob, Limit: 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page.Items) != 1 || !page.HasMore {
+			t.Fatalf("expected one result and more to come, got %+v", page)
+		}
+	})
+}
+
+func TestSearchPatientsRejectsWeakCriteria(t *testing.T) {
+	svc := newPatientService(newFixture())
+
+	for name, search := range map[string]repository.PatientSearch{
+		"no criteria":     {},
+		"blank name":      {Name: "   "},
+		"short name":      {Name: "j"},
+		"too few digits":  {Phone: "12-34"},
+		"limit too large": {Name: "john", Limit: 51},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := svc.SearchPatients(context.Background(), search); !errors.Is(err, utils.ErrInvalidInput) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+// fieldPolicy lists, per role, the fields an update may change. Fields are
+// named as in the request body. A role without an entry may not change any
+// field.
+type fieldPolicy map[string][]string
+
+var (
+	appointmentFieldPolicy = fieldPolicy{
+		"ADMIN":   {"appointment_date", "duration_minutes", "status", "notes"},
+		"DOCTOR":  {"appointment_date", "duration_minutes", "status", "notes"},
+		"NURSE":   {"appointment_date", "duration_minutes", "status", "notes"},
+		"PATIENT": {"notes"},
+	}
+	consultationFieldPolicy = fieldPolicy{
+		"ADMIN":  {"diagnosis", "notes"},
+		"DOCTOR": {"diagnosis", "notes"},
+	}
+	userFieldPolicy = fieldPolicy{
+		"ADMIN":   {"username", "email", "first_name", "last_name", "phone", "is_active"},
+		"DOCTOR":  {"first_name", "last_name", "phone"},
+		"NURSE":   {"first_name", "last_name", "phone"},
+		"PATIENT": {"first_name", "last_name", "phone"},
+	}
+	doctorFieldPolicy = fieldPolicy{
+		"ADMIN":  {"specialization", "license_number", "department_id", "consultation_fee", "is_available"},
+		"DOCTOR": {"is_available"},
+	}
+	nurseFieldPolicy = fieldPolicy{
+		"ADMIN": {"shift", "license_number", "department_id"},
+	}
+	patientFieldPolicy = fieldPolicy{
+		"ADMIN":   {"date_of_birth", "gender", "blood_group", "emergency_contact_name", "emergency_contact_phone", "medical_history"},
+		"DOCTOR":  {"date_of_birth", "gender", "blood_group", "emergency_contact_name", "emergency_contact_phone", "medical_history"},
+		"NURSE":   {"date_of_birth", "gender", "blood_group", "emergency_contact_name", "emergency_contact_phone", "medical_history"},
+		"PATIENT": {"emergency_contact_name", "emergency_contact_phone"},
+	}
+)
+
+// check rejects the update if the caller's role may not change every field
+// in changed. Each rejected field is listed in the error.
+func (p fieldPolicy) check(ctx context.Context, changed []string) error {
+	if len(changed) == 0 {
+		return nil
+	}
+
+	role, err := utils.GetRoleFromContext(ctx)
+	if err != nil {
+		return utils.NewForbiddenError("forbidden", "caller role is unknown")
+	}
+
+	var denied []utils.FieldError
+	for _, field := range changed {
+		if !slices.Contains(p[role], field) {
+			denied = append(denied, utils.FieldError{Field: field, Message: "role " + role + " may not change this field"})
+		}
+	}
+	if len(denied) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(denied))
+	for i, d := range denied {
+		names[i] = d.Field
+	}
+	return &utils.AppError{
+		Kind:    utils.ErrForbidden,
+		Code:    "field_not_permitted",
+		Message: "not permitted to change " + strings.Join(names, ", "),
+		Fields:  denied,
+	}
+}
+
+// callerPatient returns the patient record of a PATIENT caller, found from
+// the user in their token. Staff callers get nil: what they may reach is
+// decided by role alone. A caller without a role is not let through as
+// staff.
+func callerPatient(ctx context.Context, patientRepo PatientRepository) (*models.Patient, error) {
+	role, err := utils.GetRoleFromContext(ctx)
+	if err != nil {
+		return nil, utils.NewUnauthorizedError("unauthorized", "caller role is unknown")
+	}
+	if role != patientRole {
+		return nil, nil
+	}
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, utils.NewUnauthorizedError("unauthorized", "caller is not authenticated")
+	}
+
+	patient, err := patientRepo.GetByUserID(ctx, userID)
+	if errors.Is(err, utils.ErrNotFound) {
+		return nil, utils.NewForbiddenError("patient_profile_required", "create a patient profile first")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patient: %w", err)
+	}
+	return patient, nil
+}
+
+// checkPatientOwner rejects a PATIENT caller reaching records that belong to
+// another patient. Staff callers pass.
+func checkPatientOwner(ctx context.Context, patientRepo PatientRepository, patientID uuid.UUID) error {
+	patient, err := callerPatient(ctx, patientRepo)
+	if err != nil || patient == nil {
+		return err
+	}
+	if patient.PatientID != patientID {
+		return utils.NewForbiddenError("not_owner", "patients may only access their own records")
+	}
+	return nil
+}
+
+// changeSet collects the names of fields whose value differs between the
+// stored row and the requested update.
+type changeSet []string
+
+func (c *changeSet) add(field string, changed bool) {
+	if changed {
+		*c = append(*c, field)
+	}
+}
+
+func appointmentChanges(existing, updated *models.Appointment) []string {
+	var c changeSet
+	c.add("appointment_date", !existing.AppointmentDate.Equal(updated.AppointmentDate))
+	c.add("duration_minutes", existing.DurationMinutes != updated.DurationMinutes)
+	c.add("status", existing.Status != updated.Status)
+	c.add("notes", existing.Notes != updated.Notes)
+	return c
+}
+
+func consultationChanges(existing, updated *models.Consultation) []string {
+	var c changeSet
+	c.add("diagnosis", existing.Diagnosis != updated.Diagnosis)
+	c.add("notes", existing.Notes != updated.Notes)
+	return c
+}
+
+func userChanges(existing, updated *models.User) []string {
+	var c changeSet
+	c.add("username", existing.Username != updated.Username)
+	c.add("email", existing.Email != updated.Email)
+	c.add("first_name", !equalPtr(existing.FirstName, updated.FirstName))
+	c.add("last_name", !equalPtr(existing.LastName, updated.LastName))
+	c.add("phone", !equalPtr(existing.Phone, updated.Phone))
+	c.add("role", existing.Role != updated.Role)
+	c.add("is_active", existing.IsActive != updated.IsActive)
+	return c
+}
+
+func doctorChanges(existing, updated *models.Doctor) []string {
+	var c changeSet
+	c.add("specialization", existing.Specialization != updated.Specialization)
+	c.add("license_number", existing.LicenseNumber != updated.LicenseNumber)
+	c.add("department_id", existing.DepartmentID != updated.DepartmentID)
+	c.add("consultation_fee", existing.ConsultationFee != updated.ConsultationFee)
+	c.add("is_available", existing.IsAvailable != updated.IsAvailable)
+	return c
+}
+
+func nurseChanges(existing, updated *models.Nurse) []string {
+	var c changeSet
+	c.add("shift", existing.Shift != updated.Shift)
+	c.add("license_number", existing.LicenseNumber != updated.LicenseNumber)
+	c.add("department_id", existing.DepartmentID != updated.DepartmentID)
+	return c
+}
+
+func patientChanges(existing, updated *models.Patient) []string {
+	var c changeSet
+	c.add("date_of_birth", !existing.DateOfBirth.Equal(updated.DateOfBirth))
+	c.add("gender", existing.Gender != updated.Gender)
+	c.add("blood_group", existing.BloodGroup != updated.BloodGroup)
+	c.add("emergency_contact_name", existing.EmergencyContactName != updated.EmergencyContactName)
+	c.add("emergency_contact_phone", existing.EmergencyContactPhone != updated.EmergencyContactPhone)
+	c.add("medical_history", existing.MedicalHistory != updated.MedicalHistory)
+	return c
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository"
+)
+
+// The interfaces below describe the storage operations each service depends
+// on. The Postgres implementations live in the repository package and the
+// in-memory ones used by tests live in repository/memory.
+
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) (*models.User, error)
+	GetByID(ctx context.Context, userID string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	Update(ctx context.Context, user *models.User) (*models.User, error)
+	Delete(ctx context.Context, userID int64) error
+	List(ctx context.Context, q listquery.Query[*models.User]) (*listquery.Page[*models.User], error)
+	ExistsByUsername(ctx context.Context, username string) (bool, error)
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	Count(ctx context.Context) (int64, error)
+	UpdatePassword(ctx context.Context, userID string, passwordHash string) error
+	SetActive(ctx context.Context, userID string, active bool) error
+}
+
+type DepartmentRepository interface {
+	CreateDepartment(ctx context.Context, department *models.Department) (*models.Department, error)
+	GetByID(ctx context.Context, deptID string) (*models.Department, error)
+	List(ctx context.Context, q listquery.Query[*models.Department]) (*listquery.Page[*models.Department], error)
+	UpdateDepartment(ctx context.Context, deptID string, request *repository.UpdateDepartmentRequest) (*models.Department, error)
+	DeleteDepartment(ctx context.Context, deptID string) error
+	ExistsByName(ctx context.Context, name string) (bool, error)
+}
+
+type DoctorRepository interface {
+	Create(ctx context.Context, doctor *models.Doctor) (*models.Doctor, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.Doctor, error)
+	GetDoctorID(ctx context.Context, doctorID uuid.UUID) (*models.Doctor, error)
+	Update(ctx context.Context, doctor *models.Doctor) (*models.Doctor, error)
+	List(ctx context.Context, q listquery.Query[*models.Doctor]) (*listquery.Page[*models.Doctor], error)
+}
+
+type DoctorDirectory interface {
+	Directory(ctx context.Context, q listquery.Query[*models.DoctorListing]) (*listquery.Page[*models.DoctorListing], error)
+}
+
+type NurseRepository interface {
+	Create(ctx context.Context, nurse *models.Nurse) (*models.Nurse, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.Nurse, error)
+	GetByNurseID(ctx context.Context, nurseID uuid.UUID) (*models.Nurse, error)
+	Update(ctx context.Context, nurse *models.Nurse) (*models.Nurse, error)
+	List(ctx context.Context, q listquery.Query[*models.Nurse]) (*listquery.Page[*models.Nurse], error)
+}
+
+type PatientRepository interface {
+	PatientProfile(ctx context.Context, patient *models.Patient) (*models.Patient, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.Patient, error)
+	GetByPatientID(ctx context.Context, patientID uuid.UUID) (*models.Patient, error)
+	Update(ctx context.Context, patient *models.Patient) (*models.Patient, error)
+	List(ctx context.Context, q listquery.Query[*models.Patient]) (*listquery.Page[*models.Patient], error)
+	NextMRNSequence(ctx context.Context) (int64, error)
+	Delete(ctx context.Context, patientID uuid.UUID) error
+}
+
+type PatientSearcher interface {
+	Search(ctx context.Context, search repository.PatientSearch) ([]*models.PatientMatch, error)
+}
+
+// PatientRecords moves a patient's clinical records to another patient and
+// reports how many rows of each table moved.
+type PatientRecords interface {
+	ReassignRecords(ctx context.Context, from, to uuid.UUID) (map[string]int64, error)
+}
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry *models.AuditLog) (*models.AuditLog, error)
+	ListByResource(ctx context.Context, resourceType string, resourceID uuid.UUID) ([]*models.AuditLog, error)
+}
+
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *models.Invitation) (*models.Invitation, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error)
+	MarkUsed(ctx context.Context, invitationID uuid.UUID) error
+	DeleteUnused(ctx context.Context, userID uuid.UUID) error
+}
+
+type PatientInviteRepository interface {
+	Create(ctx context.Context, invite *models.PatientInvite) (*models.PatientInvite, error)
+	GetByCodeHash(ctx context.Context, codeHash string) (*models.PatientInvite, error)
+	MarkUsed(ctx context.Context, inviteID, userID uuid.UUID) error
+}
+
+type AvailabilityRepository interface {
+	CreateAvailability(ctx context.Context, availability *models.Availability) (*models.Availability, error)
+	ListByDoctorIDs(ctx context.Context, doctorIDs []uuid.UUID) ([]*models.Availability, error)
+}
+
+// HospitalConfigRepository stores the hospital config as numbered
+// revisions. Create assigns the next revision number.
+type HospitalConfigRepository interface {
+	Create(ctx context.Context, config *models.HospitalConfig) (*models.HospitalConfig, error)
+	GetByRevision(ctx context.Context, revision int) (*models.HospitalConfig, error)
+	GetEffective(ctx context.Context, at time.Time) (*models.HospitalConfig, error)
+	GetLatest(ctx context.Context) (*models.HospitalConfig, error)
+	List(ctx context.Context, q listquery.Query[*models.HospitalConfig]) (*listquery.Page[*models.HospitalConfig], error)
+}
+
+type AppointmentRepository interface {
+	Create(ctx context.Context, appointment *models.Appointment) (*models.Appointment, error)
+	GetByID(ctx context.Context, appointmentID uuid.UUID) (*models.Appointment, error)
+	GetByPatientID(ctx context.Context, patientID uuid.UUID) ([]*models.Appointment, error)
+	GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.Appointment, error)
+	ListBooked(ctx context.Context, doctorIDs []uuid.UUID, from, to time.Time) ([]*models.Appointment, error)
+	ReassignDoctor(ctx context.Context, from, to uuid.UUID, after time.Time) (int64, error)
+	CancelUpcoming(ctx context.Context, doctorID uuid.UUID, after time.Time) (int64, error)
+	Update(ctx context.Context, appointment *models.Appointment) (*models.Appointment, error)
+	Delete(ctx context.Context, appointmentID uuid.UUID) error
+	List(ctx context.Context, q listquery.Query[*models.Appointment]) (*listquery.Page[*models.Appointment], error)
+}
+
+type ConsultationRepository interface {
+	Create(ctx context.Context, consultation *models.Consultation) (*models.Consultation, error)
+	GetByID(ctx context.Context, consultationID uuid.UUID) (*models.Consultation, error)
+	GetByAppointmentID(ctx context.Context, appointmentID uuid.UUID) (*models.Consultation, error)
+	GetByPatientID(ctx context.Context, patientID uuid.UUID) ([]*models.Consultation, error)
+	Update(ctx context.Context, consultation *models.Consultation) (*models.Consultation, error)
+	List(ctx context.Context, q listquery.Query[*models.Consultation]) (*listquery.Page[*models.Consultation], error)
+}
+
+type PrescriptionRepository interface {
+	GetByPatientID(ctx context.Context, patientID uuid.UUID) ([]*models.Prescription, error)
+	List(ctx context.Context, q listquery.Query[*models.Prescription]) (*listquery.Page[*models.Prescription], error)
+}
+
+type LabTestRepository interface {
+	GetCompletedByPatientID(ctx context.Context, patientID uuid.UUID) ([]*models.LabTest, error)
+}
+
+// Transactor runs fn inside a transaction. Repository calls made with the
+// ctx passed to fn participate in that transaction.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+// Audit actions recorded when staff are taken off or put back on the roster.
+const (
+	AuditDoctorDeactivate = "doctor.deactivate"
+	AuditDoctorActivate   = "doctor.activate"
+	AuditNurseDeactivate  = "nurse.deactivate"
+	AuditNurseActivate    = "nurse.activate"
+)
+
+// normalizeLicense trims and upper-cases a license number so the unique
+// constraint catches the same license typed in a different case.
+func normalizeLicense(license string) string {
+	return strings.ToUpper(strings.TrimSpace(license))
+}
+
+// checkDepartment rejects a staff member's department unless it exists and
+// is active.
+func checkDepartment(ctx context.Context, deptRepo DepartmentRepository, departmentID uuid.UUID) error {
+	department, err := deptRepo.GetByID(ctx, departmentID.String())
+	if err != nil {
+		return invalidReference(err, "department_id", "department not found")
+	}
+	if !department.IsActive {
+		return invalidField("department_id", "department is inactive")
+	}
+	return nil
+}
+
+// currentUser returns the account of the authenticated caller, for the /me
+// endpoints.
+func currentUser(ctx context.Context, userRepo UserRepository) (*models.User, error) {
+	id := actorID(ctx)
+	if id == nil {
+		return nil, utils.NewUnauthorizedError("unauthorized", "caller is not authenticated")
+	}
+	user, err := userRepo.GetByID(ctx, id.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// selfUserUpdate copies the contact fields users may edit on their own
+// account onto a copy of existing, after checking the caller's role may
+// change them.
+func selfUserUpdate(ctx context.Context, existing, requested *models.User) (*models.User, error) {
+	updated := *existing
+	updated.FirstName = requested.FirstName
+	updated.LastName = requested.LastName
+	updated.Phone = requested.Phone
+
+	if err := userFieldPolicy.check(ctx, userChanges(existing, &updated)); err != nil {
+		return nil, err
+	}
+	if updated.FirstName == nil || strings.TrimSpace(*updated.FirstName) == "" {
+		return nil, invalidField("first_name", "first name is required")
+	}
+	if updated.LastName == nil || strings.TrimSpace(*updated.LastName) == "" {
+		return nil, invalidField("last_name", "last name is required")
+	}
+	return &updated, nil
+}
+
+// recordAudit writes one audit entry for resourceType/resourceID, attributed
+// to the caller.
+func recordAudit(ctx context.Context, audit AuditRepository, action, resourceType string, resourceID uuid.UUID, changes any) error {
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s audit: %w", action, err)
+	}
+	_, err = audit.Create(ctx, &models.AuditLog{
+		LogID:        uuid.New(),
+		UserID:       actorID(ctx),
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Changes:      string(encoded),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s audit: %w", action, err)
+	}
+	return nil
+}
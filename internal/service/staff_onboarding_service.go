@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/invite"
+	"github.com/falasefemi2/hms/internal/logging"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/tracing"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+// InvitationSender delivers a set-password link to a new user. The link
+// carries the secret token the user redeems; it is never stored.
+type InvitationSender interface {
+	SendInvitation(ctx context.Context, user *models.User, link string, expiresAt time.Time) error
+}
+
+// StaffOnboardingService creates a doctor or nurse, with their user account,
+// department and availability, in one transaction and invites them to choose
+// a password. It reuses the per-resource services, whose transactions join
+// the onboarding one.
+type StaffOnboardingService struct {
+	users         *UserService
+	doctors       *DoctorService
+	nurses        *NurseSerivce
+	availability  *AvailabilityService
+	userRepo      UserRepository
+	invitations   InvitationRepository
+	sender        InvitationSender
+	invitationURL string
+	invitationTTL time.Duration
+	tx            Transactor
+}
+
+func NewStaffOnboardingService(users *UserService, doctors *DoctorService, nurses *NurseSerivce, availability *AvailabilityService, userRepo UserRepository, invitations InvitationRepository, sender InvitationSender, invitationURL string, invitationTTL time.Duration, tx Transactor) *StaffOnboardingService {
+	return &StaffOnboardingService{
+		users:         users,
+		doctors:       doctors,
+		nurses:        nurses,
+		availability:  availability,
+		userRepo:      userRepo,
+		invitations:   invitations,
+		sender:        sender,
+		invitationURL: invitationURL,
+		invitationTTL: invitationTTL,
+		tx:            tx,
+	}
+}
+
+// OnboardStaff creates everything in onboarding or nothing. The account gets
+// a random password nobody knows, so the new starter cannot sign in until
+// they redeem the invitation. The invitation is sent after the transaction
+// commits; if sending fails the staff member still exists, the invitation is
+// reported as not sent and its link is returned for the admin to pass on.
+func (s *StaffOnboardingService) OnboardStaff(ctx context.Context, onboarding *models.StaffOnboarding) (*models.StaffOnboarding, error) {
+	ctx, span := tracing.Start(ctx, "StaffOnboardingService.OnboardStaff")
+	defer span.End()
+
+	if err := validateOnboarding(onboarding); err != nil {
+		return nil, err
+	}
+
+	var (
+		result *models.StaffOnboarding
+		token  string
+	)
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		placeholder, err := newInvitationToken()
+		if err != nil {
+			return err
+		}
+		u := onboarding.User
+		user, err := s.users.CreateAdminUser(ctx, u.Username, u.Email, placeholder,
+			derefString(u.FirstName), derefString(u.LastName), derefString(u.Phone), u.Role)
+		if err != nil {
+			return err
+		}
+		result = &models.StaffOnboarding{User: *user}
+
+		if onboarding.Doctor != nil {
+			doctor := *onboarding.Doctor
+			doctor.DoctorID = uuid.New()
+			doctor.UserID = user.ID
+			if result.Doctor, err = s.doctors.CreateDoctor(ctx, &doctor); err != nil {
+				return err
+			}
+			for _, window := range onboarding.Availability {
+				availability := *window
+				availability.AvailabilityID = uuid.New()
+				availability.DoctorID = doctor.DoctorID
+				created, err := s.availability.CreateDoctorAvailability(ctx, &availability)
+				if err != nil {
+					return err
+				}
+				result.Availability = append(result.Availability, created)
+			}
+		} else {
+			nurse := *onboarding.Nurse
+			nurse.NurseID = uuid.New()
+			nurse.UserID = user.ID
+			if result.Nurse, err = s.nurses.CreateNurse(ctx, &nurse); err != nil {
+				return err
+			}
+		}
+
+		token, result.Invitation.ExpiresAt, err = s.issueInvitation(ctx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Invitation, err = s.sendInvitation(ctx, &result.User, token, result.Invitation.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ResendInvitation replaces a staff member's outstanding invitations with a
+// new one and sends it. As with OnboardStaff, an invitation that cannot be
+// sent comes back with its link.
+func (s *StaffOnboardingService) ResendInvitation(ctx context.Context, userID uuid.UUID) (*models.IssuedInvitation, error) {
+	ctx, span := tracing.Start(ctx, "StaffOnboardingService.ResendInvitation")
+	defer span.End()
+
+	var (
+		user      *models.User
+		token     string
+		expiresAt time.Time
+	)
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.userRepo.GetByID(ctx, userID.String())
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if user.Role == patientRole {
+			return invalidField("user_id", "patients set their own password at signup")
+		}
+		if !user.IsActive {
+			return utils.NewConflictError("staff_inactive", "user is deactivated")
+		}
+
+		if err := s.invitations.DeleteUnused(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to revoke invitations: %w", err)
+		}
+		token, expiresAt, err = s.issueInvitation(ctx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.sendInvitation(ctx, user, token, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// sendInvitation hands the set-password link for token to the sender. If it
+// cannot be sent the link is returned instead, since the invitation is
+// already stored and the user has no other way to redeem it.
+func (s *StaffOnboardingService) sendInvitation(ctx context.Context, user *models.User, token string, expiresAt time.Time) (models.IssuedInvitation, error) {
+	invitation := models.IssuedInvitation{ExpiresAt: expiresAt}
+
+	link, err := invite.Link(s.invitationURL, token)
+	if err != nil {
+		return invitation, fmt.Errorf("failed to build invitation link: %w", err)
+	}
+
+	err = s.sender.SendInvitation(ctx, user, link, expiresAt)
+	switch {
+	case err == nil:
+		invitation.Sent = true
+	case errors.Is(err, invite.ErrNoDelivery):
+		invitation.Link = link
+	default:
+		logging.FromContext(ctx).WarnContext(ctx, "failed to send staff invitation", "user_id", user.ID, "error", err)
+		invitation.Link = link
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation sets the password of the user token was issued to and
+// uses the invitation up.
+func (s *StaffOnboardingService) AcceptInvitation(ctx context.Context, token, password string) error {
+	ctx, span := tracing.Start(ctx, "StaffOnboardingService.AcceptInvitation")
+	defer span.End()
+
+	invalid := utils.NewValidationError("invitation_invalid", "invitation is invalid, used or expired")
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		invitation, err := s.invitations.GetByTokenHash(ctx, hashInvitationToken(token))
+		if errors.Is(err, utils.ErrNotFound) {
+			return invalid
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get invitation: %w", err)
+		}
+		if invitation.UsedAt != nil || !time.Now().UTC().Before(invitation.ExpiresAt) {
+			return invalid
+		}
+
+		user, err := s.userRepo.GetByID(ctx, invitation.UserID.String())
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if !user.IsActive {
+			return invalid
+		}
+
+		if err := s.users.ResetPassword(ctx, user.ID.String(), password); err != nil {
+			return err
+		}
+		if err := s.invitations.MarkUsed(ctx, invitation.InvitationID); err != nil {
+			return fmt.Errorf("failed to use invitation: %w", err)
+		}
+		return nil
+	})
+}
+
+// issueInvitation stores a new invitation for userID and returns its token.
+func (s *StaffOnboardingService) issueInvitation(ctx context.Context, userID uuid.UUID) (string, time.Time, error) {
+	token, err := newInvitationToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	// expires_at is stored without a time zone, so it is kept in UTC.
+	invitation, err := s.invitations.Create(ctx, &models.Invitation{
+		InvitationID: uuid.New(),
+		UserID:       userID,
+		TokenHash:    hashInvitationToken(token),
+		ExpiresAt:    time.Now().UTC().Add(s.invitationTTL),
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	return token, invitation.ExpiresAt, nil
+}
+
+// validateOnboarding checks the parts of an onboarding that depend on the
+// role; the per-resource services check the rest.
+func validateOnboarding(onboarding *models.StaffOnboarding) error {
+	switch onboarding.User.Role {
+	case "DOCTOR":
+		doctor := onboarding.Doctor
+		if doctor == nil || onboarding.Nurse != nil {
+			return invalidField("role", "a doctor needs doctor details and no nurse details")
+		}
+		if doctor.Specialization == "" {
+			return invalidField("specialization", "specialization is required for doctors")
+		}
+		if doctor.ConsultationFee <= 0 {
+			return invalidField("consultation_fee", "consultation fee must be positive")
+		}
+	case "NURSE":
+		if onboarding.Nurse == nil || onboarding.Doctor != nil {
+			return invalidField("role", "a nurse needs nurse details and no doctor details")
+		}
+		if onboarding.Nurse.Shift == "" {
+			return invalidField("shift", "shift is required for nurses")
+		}
+		if len(onboarding.Availability) > 0 {
+			return invalidField("availability", "availability can only be set for doctors")
+		}
+	default:
+		return invalidField("role", "role must be DOCTOR or NURSE")
+	}
+
+	for i, window := range onboarding.Availability {
+		start, okStart := clockOn(time.Time{}, window.StartTime)
+		end, okEnd := clockOn(time.Time{}, window.EndTime)
+		if !okStart || !okEnd || !start.Before(end) {
+			return invalidField(fmt.Sprintf("availability[%d]", i), "start time must be before end time")
+		}
+	}
+	return nil
+}
+
+// newInvitationToken returns 256 random bits, URL-safe encoded.
+func newInvitationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
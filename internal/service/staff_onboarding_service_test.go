@@ -0,0 +1,214 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/invite"
+	"github.com/falasefemi2/hms/internal/models"
+	"github.com/falasefemi2/hms/internal/repository/memory"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+const invitationURL = "https://hms.example.com/set-password"
+
+// recordingSender keeps the tokens of the links it is asked to send, or
+// fails with err.
+type recordingSender struct {
+	tokens []string
+	err    error
+}
+
+func (s *recordingSender) SendInvitation(ctx context.Context, user *models.User, link string, expiresAt time.Time) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.tokens = append(s.tokens, linkToken(link))
+	return nil
+}
+
+func linkToken(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || !strings.HasPrefix(link, invitationURL+"?") {
+		return ""
+	}
+	return u.Query().Get("token")
+}
+
+func newStaffOnboardingService(f *fixture, sender service.InvitationSender, ttl time.Duration) *service.StaffOnboardingService {
+	audit := memory.NewAuditRepository()
+	return service.NewStaffOnboardingService(
+		service.NewUserService(f.users, f.tx),
+		newDoctorService(f, audit),
+		service.NewNurseService(memory.NewNurseRepository(), f.users, f.departments, audit, f.tx),
+		service.NewAvailabilityService(memory.NewAvailabilityRepository(), f.doctors, f.tx),
+		f.users,
+		memory.NewInvitationRepository(),
+		sender,
+		invitationURL,
+		ttl,
+		f.tx,
+	)
+}
+
+func newDoctorOnboarding(department *models.Department, email string) *models.StaffOnboarding {
+	return &models.StaffOnboarding{
+		User: models.User{
+			Username:  email,
+			Email:     email,
+			FirstName: strPtr("Tunde"),
+			LastName:  strPtr("Bakare"),
+			Phone:     strPtr("08030000000"),
+			Role:      "DOCTOR",
+		},
+		Doctor: &models.Doctor{
+			Specialization:  "Cardiology",
+			LicenseNumber:   "md-" + email,
+			DepartmentID:    department.ID,
+			ConsultationFee: 7500,
+		},
+		Availability: []*models.Availability{
+			{DayOfWeek: "Monday", StartTime: "09:00", EndTime: "12:00", MaxAppointment: 6},
+		},
+	}
+}
+
+func TestOnboardStaff(t *testing.T) {
+	ctx := asRole("ADMIN")
+	f := newFixture()
+	sender := &recordingSender{}
+	svc := newStaffOnboardingService(f, sender, time.Hour)
+	cardiology := f.addDepartment(t, "Cardiology", true)
+
+	onboarded, err := svc.OnboardStaff(ctx, newDoctorOnboarding(cardiology, "tunde@example.com"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if onboarded.User.Role != "DOCTOR" || onboarded.Doctor == nil || onboarded.Doctor.UserID != onboarded.User.ID {
+		t.Fatalf("expected a doctor linked to the new user, got %+v", onboarded)
+	}
+	if onboarded.Doctor.LicenseNumber != "MD-TUNDE@EXAMPLE.COM" {
+		t.Errorf("expected the license normalised, got %q", onboarded.Doctor.LicenseNumber)
+	}
+	if len(onboarded.Availability) != 1 || onboarded.Availability[0].DoctorID != onboarded.Doctor.DoctorID {
+		t.Errorf("expected the availability attached to the doctor, got %+v", onboarded.Availability)
+	}
+	if !onboarded.Invitation.Sent || onboarded.Invitation.Link != "" || len(sender.tokens) != 1 || sender.tokens[0] == "" {
+		t.Fatalf("expected one invitation sent, got %v", sender.tokens)
+	}
+
+	users := service.NewUserService(f.users, f.tx)
+	if _, err := users.Login(ctx, "tunde@example.com", sender.tokens[0]); err == nil {
+		t.Fatal("expected no login before the invitation is accepted")
+	}
+	if err := svc.AcceptInvitation(ctx, "not-a-token", "correct-horse"); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected an unknown token to be invalid, got %v", err)
+	}
+	if err := svc.AcceptInvitation(ctx, sender.tokens[0], "correct-horse"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := users.Login(ctx, "tunde@example.com", "correct-horse"); err != nil {
+		t.Errorf("expected login with the chosen password, got %v", err)
+	}
+	if err := svc.AcceptInvitation(ctx, sender.tokens[0], "another-password"); !errors.Is(err, utils.ErrInvalidInput) {
+		t.Errorf("expected a used invitation to be invalid, got %v", err)
+	}
+}
+
+func TestOnboardStaffRejectsInvalidRequests(t *testing.T) {
+	ctx := asRole("ADMIN")
+	f := newFixture()
+	svc := newStaffOnboardingService(f, &recordingSender{}, time.Hour)
+	cardiology := f.addDepartment(t, "Cardiology", true)
+
+	nurseWithClinic := &models.StaffOnboarding{
+		User:         models.User{Username: "ada", Email: "ada@example.com", FirstName: strPtr("Ada"), LastName: strPtr("Obi"), Role: "NURSE"},
+		Nurse:        &models.Nurse{Shift: "DAY", LicenseNumber: "RN-1", DepartmentID: cardiology.ID},
+		Availability: []*models.Availability{{DayOfWeek: "Monday", StartTime: "09:00", EndTime: "12:00", MaxAppointment: 6}},
+	}
+	backwards := newDoctorOnboarding(cardiology, "b@example.com")
+	backwards.Availability[0].StartTime = "13:00"
+	noFee := newDoctorOnboarding(cardiology, "c@example.com")
+	noFee.Doctor.ConsultationFee = 0
+	unknownDepartment := newDoctorOnboarding(&models.Department{ID: uuid.New()}, "d@example.com")
+
+	for name, onboarding := range map[string]*models.StaffOnboarding{
+		"nurse with availability":  nurseWithClinic,
+		"window ends before start": backwards,
+		"doctor without fee":       noFee,
+		"unknown department":       unknownDepartment,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := svc.OnboardStaff(ctx, onboarding); !errors.Is(err, utils.ErrInvalidInput) {
+				t.Errorf("expected invalid input, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStaffInvitations(t *testing.T) {
+	ctx := asRole("ADMIN")
+
+	t.Run("expired invitations cannot be accepted", func(t *testing.T) {
+		f := newFixture()
+		sender := &recordingSender{}
+		svc := newStaffOnboardingService(f, sender, -time.Minute)
+		if _, err := svc.OnboardStaff(ctx, newDoctorOnboarding(f.addDepartment(t, "Cardiology", true), "e@example.com")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := svc.AcceptInvitation(ctx, sender.tokens[0], "correct-horse"); !errors.Is(err, utils.ErrInvalidInput) {
+			t.Errorf("expected an expired invitation to be invalid, got %v", err)
+		}
+	})
+
+	t.Run("a failed send keeps the staff member and can be resent", func(t *testing.T) {
+		f := newFixture()
+		sender := &recordingSender{err: errors.New("mail server down")}
+		svc := newStaffOnboardingService(f, sender, time.Hour)
+		onboarded, err := svc.OnboardStaff(ctx, newDoctorOnboarding(f.addDepartment(t, "Cardiology", true), "f@example.com"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if onboarded.Invitation.Sent || linkToken(onboarded.Invitation.Link) == "" {
+			t.Errorf("expected the invitation reported as not sent with its link, got %+v", onboarded.Invitation)
+		}
+
+		sender.err = nil
+		if resent, err := svc.ResendInvitation(ctx, onboarded.User.ID); err != nil || !resent.Sent || resent.Link != "" {
+			t.Fatalf("expected the invitation sent, got %+v (%v)", resent, err)
+		}
+		first := sender.tokens[0]
+		if _, err := svc.ResendInvitation(ctx, onboarded.User.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := svc.AcceptInvitation(ctx, first, "correct-horse"); !errors.Is(err, utils.ErrInvalidInput) {
+			t.Errorf("expected a superseded invitation to be invalid, got %v", err)
+		}
+		if err := svc.AcceptInvitation(ctx, sender.tokens[1], "correct-horse"); err != nil {
+			t.Errorf("expected the newest invitation to work, got %v", err)
+		}
+	})
+
+	t.Run("without a mail integration the admin gets the link", func(t *testing.T) {
+		f := newFixture()
+		svc := newStaffOnboardingService(f, invite.LogSender{}, time.Hour)
+		onboarded, err := svc.OnboardStaff(ctx, newDoctorOnboarding(f.addDepartment(t, "Cardiology", true), "g@example.com"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if onboarded.Invitation.Sent {
+			t.Error("expected the invitation reported as not sent")
+		}
+		if err := svc.AcceptInvitation(ctx, linkToken(onboarded.Invitation.Link), "correct-horse"); err != nil {
+			t.Errorf("expected the returned link to work, got %v", err)
+		}
+	})
+}
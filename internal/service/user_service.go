@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"regexp"
 
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/logging"
+	"github.com/falasefemi2/hms/internal/metrics"
 	"github.com/falasefemi2/hms/internal/models"
-	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/tracing"
 	"github.com/falasefemi2/hms/internal/utils"
 )
 
@@ -19,17 +22,24 @@ var validAdminRoles = map[string]bool{
 
 const patientRole = "PATIENT"
 
+var errInvalidCredentials = utils.NewUnauthorizedError("invalid_credentials", "invalid credentials")
+
 type UserService struct {
-	repo *repository.UserRepository
+	repo UserRepository
+	tx   Transactor
 }
 
-func NewUserService(repo *repository.UserRepository) *UserService {
+func NewUserService(repo UserRepository, tx Transactor) *UserService {
 	return &UserService{
 		repo: repo,
+		tx:   tx,
 	}
 }
 
 func (us *UserService) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+
 	if err := validateUserInput(user); err != nil {
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
@@ -41,23 +51,12 @@ func (us *UserService) CreateUser(ctx context.Context, user *models.User) (*mode
 }
 
 func (us *UserService) CreatePatientUser(ctx context.Context, username, email, password, firstName, lastName, phone string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreatePatientUser")
+	defer span.End()
+
 	if err := validatePatientInput(username, email, password, firstName, lastName); err != nil {
 		return nil, err
 	}
-	exists, err := us.repo.ExistsByEmail(ctx, email)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check email existence: %w", err)
-	}
-	if exists {
-		return nil, errors.New("email already registered")
-	}
-	exists, err = us.repo.ExistsByUsername(ctx, username)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check username existence: %w", err)
-	}
-	if exists {
-		return nil, errors.New("username already taken")
-	}
 
 	passwordHash, err := utils.HashPassword(password)
 	if err != nil {
@@ -75,34 +74,21 @@ func (us *UserService) CreatePatientUser(ctx context.Context, username, email, p
 		IsActive:     true,
 	}
 
-	createdUser, err := us.repo.Create(ctx, user)
+	createdUser, err := us.createUnique(ctx, user)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create patientuser: %w", err)
+		return nil, err
 	}
 	return createdUser, nil
 }
 
 func (us *UserService) CreateAdminUser(ctx context.Context, username, email, password, firstName, lastName, phone, role string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateAdminUser")
+	defer span.End()
+
 	if err := validateAdminUserInput(username, email, password, firstName, lastName, role); err != nil {
 		return nil, err
 	}
 
-	exists, err := us.repo.ExistsByEmail(ctx, email)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check email existence: %w", err)
-	}
-	if exists {
-		return nil, errors.New("email already registered")
-	}
-
-	exists, err = us.repo.ExistsByUsername(ctx, username)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check username existence: %w", err)
-	}
-	if exists {
-		return nil, errors.New("username already taken")
-	}
-
 	passwordHash, err := utils.HashPassword(password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
@@ -119,15 +105,53 @@ func (us *UserService) CreateAdminUser(ctx context.Context, username, email, pas
 		IsActive:     true,
 	}
 
-	createdUser, err := us.repo.Create(ctx, user)
+	createdUser, err := us.createUnique(ctx, user)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+		return nil, err
+	}
+
+	return createdUser, nil
+}
+
+// createUnique inserts user after checking, in the same transaction, that
+// neither the email nor the username is taken.
+func (us *UserService) createUnique(ctx context.Context, user *models.User) (*models.User, error) {
+	var createdUser *models.User
+
+	err := us.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		exists, err := us.repo.ExistsByEmail(ctx, user.Email)
+		if err != nil {
+			return fmt.Errorf("failed to check email existence: %w", err)
+		}
+		if exists {
+			return utils.NewConflictError("email_already_registered", "email already registered")
+		}
+
+		exists, err = us.repo.ExistsByUsername(ctx, user.Username)
+		if err != nil {
+			return fmt.Errorf("failed to check username existence: %w", err)
+		}
+		if exists {
+			return utils.NewConflictError("username_taken", "username already taken")
+		}
+
+		createdUser, err = us.repo.Create(ctx, user)
+		if err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return createdUser, nil
 }
 
 func (us *UserService) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByID")
+	defer span.End()
+
 	user, err := us.repo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -137,8 +161,11 @@ func (us *UserService) GetUserByID(ctx context.Context, userID string) (*models.
 }
 
 func (us *UserService) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByEmail")
+	defer span.End()
+
 	if email == "" {
-		return nil, errors.New("email cannot be empty")
+		return nil, invalidField("email", "email cannot be empty")
 	}
 
 	user, err := us.repo.GetByEmail(ctx, email)
@@ -150,21 +177,49 @@ func (us *UserService) GetUserByEmail(ctx context.Context, email string) (*model
 }
 
 func (us *UserService) UpdateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUser")
+	defer span.End()
+
 	if err := validateUserInput(user); err != nil {
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 
-	updatedUser, err := us.repo.Update(ctx, user)
+	var updatedUser *models.User
+
+	err := us.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		existing, err := us.repo.GetByID(ctx, user.ID.String())
+		if err != nil {
+			return err
+		}
+
+		if err := userFieldPolicy.check(ctx, userChanges(existing, user)); err != nil {
+			return err
+		}
+		if existing.IsActive != user.IsActive {
+			if err := staffStatusError(existing.Role); err != nil {
+				return err
+			}
+		}
+
+		updatedUser, err = us.repo.Update(ctx, user)
+		if err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update user: %w", err)
+		return nil, err
 	}
 
 	return updatedUser, nil
 }
 
 func (us *UserService) DeleteUser(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
 	if userID <= 0 {
-		return errors.New("invalid user ID")
+		return invalidField("user_id", "invalid user ID")
 	}
 
 	err := us.repo.Delete(ctx, userID)
@@ -175,55 +230,110 @@ func (us *UserService) DeleteUser(ctx context.Context, userID int64) error {
 	return nil
 }
 
-func (us *UserService) ListUsers(ctx context.Context, limit, offset int) ([]*models.User, int64, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 10
+func (us *UserService) ListUsers(ctx context.Context, q listquery.Query[*models.User]) (*listquery.Page[*models.User], error) {
+	ctx, span := tracing.Start(ctx, "UserService.ListUsers")
+	defer span.End()
+
+	page, err := us.repo.List(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return page, nil
+}
+
+func (us *UserService) ResetPassword(ctx context.Context, userID, newPassword string) error {
+	ctx, span := tracing.Start(ctx, "UserService.ResetPassword")
+	defer span.End()
+
+	if userID == "" {
+		return invalidField("user_id", "user id is required")
 	}
-	if offset < 0 {
-		offset = 0
+	if newPassword == "" {
+		return invalidField("password", "password is required")
+	}
+	if len(newPassword) < 8 {
+		return invalidField("password", "password must be at least 8 characters")
 	}
 
-	users, err := us.repo.List(ctx, limit, offset)
+	passwordHash, err := utils.HashPassword(newPassword)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := us.repo.UpdatePassword(ctx, userID, passwordHash); err != nil {
+		return fmt.Errorf("failed to reset password: %w", err)
 	}
 
-	total, err := us.repo.Count(ctx)
+	return nil
+}
+
+func (us *UserService) DeactivateUser(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "UserService.DeactivateUser")
+	defer span.End()
+
+	if userID == "" {
+		return invalidField("user_id", "user id is required")
+	}
+
+	user, err := us.repo.GetByID(ctx, userID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := staffStatusError(user.Role); err != nil {
+		return err
+	}
+
+	if err := us.repo.SetActive(ctx, userID, false); err != nil {
+		return fmt.Errorf("failed to deactivate user: %w", err)
 	}
 
-	return users, total, nil
+	return nil
+}
+
+// staffStatusError refuses to activate or deactivate a doctor's or nurse's
+// account directly. DoctorService and NurseSerivce do that, together with
+// the doctor's availability and appointments and the audit log.
+func staffStatusError(role string) error {
+	var message string
+	switch role {
+	case "DOCTOR":
+		message = "use POST /admin/doctors/{id}/deactivate or /activate to change a doctor's status"
+	case "NURSE":
+		message = "use POST /admin/nurses/{id}/deactivate or /activate to change a nurse's status"
+	default:
+		return nil
+	}
+	return utils.NewValidationError("staff_status_endpoint", message, utils.FieldError{Field: "is_active", Message: message})
 }
 
 func validatePatientInput(username, email, password, firstName, lastName string) error {
 	if username == "" {
-		return errors.New("username is required")
+		return invalidField("username", "username is required")
 	}
 	if len(username) < 3 {
-		return errors.New("username must be at least 3 characters")
+		return invalidField("username", "username must be at least 3 characters")
 	}
 
 	if email == "" {
-		return errors.New("email is required")
+		return invalidField("email", "email is required")
 	}
 	if !isValidEmail(email) {
-		return errors.New("invalid email format")
+		return invalidField("email", "invalid email format")
 	}
 
 	if password == "" {
-		return errors.New("password is required")
+		return invalidField("password", "password is required")
 	}
 	if len(password) < 8 {
-		return errors.New("password must be at least 8 characters")
+		return invalidField("password", "password must be at least 8 characters")
 	}
 
 	if firstName == "" {
-		return errors.New("first name is required")
+		return invalidField("first_name", "first name is required")
 	}
 
 	if lastName == "" {
-		return errors.New("last name is required")
+		return invalidField("last_name", "last name is required")
 	}
 
 	return nil
@@ -237,15 +347,15 @@ func validateAdminUserInput(username, email, password, firstName, lastName, role
 
 	// Validate role
 	if role == "" {
-		return errors.New("role is required")
+		return invalidField("role", "role is required")
 	}
 	if !validAdminRoles[role] {
-		return fmt.Errorf("invalid role: %s. must be one of: DOCTOR, NURSE, ADMIN", role)
+		return invalidField("role", fmt.Sprintf("invalid role: %s. must be one of: DOCTOR, NURSE, ADMIN", role))
 	}
 
 	// Prevent patient creation through admin endpoint
 	if role == patientRole {
-		return errors.New("patients must self-register using the patient signup endpoint")
+		return invalidField("role", "patients must self-register using the patient signup endpoint")
 	}
 
 	return nil
@@ -254,35 +364,35 @@ func validateAdminUserInput(username, email, password, firstName, lastName, role
 // validateUserInput validates basic user fields
 func validateUserInput(user *models.User) error {
 	if user == nil {
-		return errors.New("user cannot be nil")
+		return utils.NewValidationError("invalid_request", "user cannot be nil")
 	}
 
 	if user.Username == "" {
-		return errors.New("username is required")
+		return invalidField("username", "username is required")
 	}
 
 	if user.Email == "" {
-		return errors.New("email is required")
+		return invalidField("email", "email is required")
 	}
 
 	if !isValidEmail(user.Email) {
-		return errors.New("invalid email format")
+		return invalidField("email", "invalid email format")
 	}
 
 	if user.PasswordHash == "" {
-		return errors.New("password hash is required")
+		return invalidField("password_hash", "password hash is required")
 	}
 
 	if user.FirstName == nil || *user.FirstName == "" {
-		return errors.New("first name is required")
+		return invalidField("first_name", "first name is required")
 	}
 
 	if user.LastName == nil || *user.LastName == "" {
-		return errors.New("last name is required")
+		return invalidField("last_name", "last name is required")
 	}
 
 	if user.Role == "" {
-		return errors.New("role is required")
+		return invalidField("role", "role is required")
 	}
 
 	return nil
@@ -296,13 +406,31 @@ func isValidEmail(email string) bool {
 }
 
 func (us *UserService) Login(ctx context.Context, email, password string) (string, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Login")
+	defer span.End()
+
+	logger := logging.FromContext(ctx)
+
 	user, err := us.repo.GetByEmail(ctx, email)
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		if errors.Is(err, utils.ErrNotFound) {
+			logger.WarnContext(ctx, "login failed", "reason", "unknown_email")
+			metrics.RecordLogin(false)
+			return "", errInvalidCredentials
+		}
+		return "", fmt.Errorf("failed to get user by email: %w", err)
 	}
 
 	if !utils.ComparePassword(user.PasswordHash, password) {
-		return "", errors.New("invalid credentials")
+		logger.WarnContext(ctx, "login failed", "reason", "wrong_password", "user_id", user.ID.String())
+		metrics.RecordLogin(false)
+		return "", errInvalidCredentials
+	}
+
+	if !user.IsActive {
+		logger.WarnContext(ctx, "login failed", "reason", "account_deactivated", "user_id", user.ID.String())
+		metrics.RecordLogin(false)
+		return "", utils.NewForbiddenError("account_deactivated", "account is deactivated")
 	}
 
 	token, err := utils.GenerateJwt(user)
@@ -310,5 +438,6 @@ func (us *UserService) Login(ctx context.Context, email, password string) (strin
 		return "", errors.New("failed to generate token")
 	}
 
+	metrics.RecordLogin(true)
 	return token, nil
 }
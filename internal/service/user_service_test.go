@@ -0,0 +1,240 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/falasefemi2/hms/internal/listquery"
+	"github.com/falasefemi2/hms/internal/metrics"
+	"github.com/falasefemi2/hms/internal/repository"
+	"github.com/falasefemi2/hms/internal/service"
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+func TestCreatePatientUserValidation(t *testing.T) {
+	svc := newUserService(newFixture())
+
+	tests := []struct {
+		name                                           string
+		username, email, password, firstName, lastName string
+		wantErr                                        string
+	}{
+		{"missing username", "", "a@b.com", "password1", "Ada", "Lovelace", "username is required"},
+		{"short username", "ab", "a@b.com", "password1", "Ada", "Lovelace", "username must be at least 3 characters"},
+		{"bad email", "ada", "not-an-email", "password1", "Ada", "Lovelace", "invalid email format"},
+		{"short password", "ada", "a@b.com", "short", "Ada", "Lovelace", "password must be at least 8 characters"},
+		{"missing first name", "ada", "a@b.com", "password1", "", "Lovelace", "first name is required"},
+		{"missing last name", "ada", "a@b.com", "password1", "Ada", "", "last name is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreatePatientUser(context.Background(), tt.username, tt.email, tt.password, tt.firstName, tt.lastName, "")
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCreatePatientUserUniqueness(t *testing.T) {
+	ctx := context.Background()
+	svc := newUserService(newFixture())
+
+	user, err := svc.CreatePatientUser(ctx, "ada", "ada@example.com", "password1", "Ada", "Lovelace", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.Role != "PATIENT" {
+		t.Errorf("expected PATIENT role, got %s", user.Role)
+	}
+
+	_, err = svc.CreatePatientUser(ctx, "ada2", "ada@example.com", "password1", "Ada", "Lovelace", "")
+	if err == nil || err.Error() != "email already registered" {
+		t.Fatalf("expected duplicate email error, got %v", err)
+	}
+
+	_, err = svc.CreatePatientUser(ctx, "ada", "ada2@example.com", "password1", "Ada", "Lovelace", "")
+	if err == nil || err.Error() != "username already taken" {
+		t.Fatalf("expected duplicate username error, got %v", err)
+	}
+}
+
+func TestCreateAdminUserRoles(t *testing.T) {
+	ctx := context.Background()
+	svc := newUserService(newFixture())
+
+	for _, role := range []string{"DOCTOR", "NURSE", "ADMIN"} {
+		username := strings.ToLower(role) + "_user"
+		if _, err := svc.CreateAdminUser(ctx, username, username+"@example.com", "password1", "Test", "User", "", role); err != nil {
+			t.Errorf("role %s: unexpected error: %v", role, err)
+		}
+	}
+
+	for _, role := range []string{"PATIENT", "JANITOR", ""} {
+		if _, err := svc.CreateAdminUser(ctx, "someone", "someone@example.com", "password1", "Test", "User", "", role); err == nil {
+			t.Errorf("role %q: expected error", role)
+		}
+	}
+}
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+	svc := newUserService(newFixture())
+
+	user, err := svc.CreatePatientUser(ctx, "ada", "ada@example.com", "password1", "Ada", "Lovelace", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	successes := testutil.ToFloat64(metrics.Logins.WithLabelValues("success"))
+	failures := testutil.ToFloat64(metrics.Logins.WithLabelValues("failure"))
+
+	if _, err := svc.Login(ctx, "ada@example.com", "password1"); err != nil {
+		t.Fatalf("expected login to succeed, got %v", err)
+	}
+	if _, err := svc.Login(ctx, "ada@example.com", "wrong-password"); err == nil {
+		t.Fatal("expected wrong password to fail")
+	}
+	if _, err := svc.Login(ctx, "nobody@example.com", "password1"); err == nil {
+		t.Fatal("expected unknown email to fail")
+	}
+
+	if err := svc.DeactivateUser(ctx, user.ID.String()); err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+	if _, err := svc.Login(ctx, "ada@example.com", "password1"); err == nil {
+		t.Fatal("expected deactivated user login to fail")
+	}
+
+	if got := testutil.ToFloat64(metrics.Logins.WithLabelValues("success")) - successes; got != 1 {
+		t.Errorf("expected 1 successful login recorded, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.Logins.WithLabelValues("failure")) - failures; got != 3 {
+		t.Errorf("expected 3 failed logins recorded, got %v", got)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	svc := newUserService(newFixture())
+
+	user, err := svc.CreateAdminUser(ctx, "admin", "admin@example.com", "password1", "Ada", "Admin", "", "ADMIN")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, password := range []string{"", "short"} {
+		if err := svc.ResetPassword(ctx, user.ID.String(), password); !errors.Is(err, utils.ErrInvalidInput) {
+			t.Fatalf("expected password %q to be rejected, got %v", password, err)
+		}
+	}
+	if err := svc.ResetPassword(ctx, user.ID.String(), "new-password"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Login(ctx, "admin@example.com", "new-password"); err != nil {
+		t.Fatalf("expected login with new password, got %v", err)
+	}
+}
+
+func TestUpdateUserFieldPermissions(t *testing.T) {
+	svc := newUserService(newFixture())
+
+	created, err := svc.CreateAdminUser(context.Background(), "nurse", "nurse@example.com", "password1", "Nia", "Nurse", "", "NURSE")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	user, _ := svc.GetUserByID(context.Background(), created.ID.String())
+	user.Phone = strPtr("+2348000000000")
+	updated, err := svc.UpdateUser(asRole("ADMIN"), user)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Phone == nil || *updated.Phone != "+2348000000000" {
+		t.Errorf("expected phone to be updated, got %v", updated.Phone)
+	}
+
+	user, _ = svc.GetUserByID(context.Background(), created.ID.String())
+	user.Role = "ADMIN"
+	if _, err := svc.UpdateUser(asRole("ADMIN"), user); !errors.Is(err, utils.ErrForbidden) {
+		t.Fatalf("expected role change to be refused, got %v", err)
+	}
+
+	user, _ = svc.GetUserByID(context.Background(), created.ID.String())
+	user.Email = "me@example.com"
+	if _, err := svc.UpdateUser(asRole("NURSE"), user); !errors.Is(err, utils.ErrForbidden) {
+		t.Fatalf("expected non-admins to be refused, got %v", err)
+	}
+}
+
+func TestStaffStatusOnlyThroughStaffServices(t *testing.T) {
+	svc := newUserService(newFixture())
+
+	for _, role := range []string{"DOCTOR", "NURSE"} {
+		created, err := svc.CreateAdminUser(context.Background(), role, role+"@example.com", "password1", "Sam", "Staff", "", role)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		user, _ := svc.GetUserByID(context.Background(), created.ID.String())
+		user.IsActive = false
+		var appErr *utils.AppError
+		if _, err := svc.UpdateUser(asRole("ADMIN"), user); !errors.As(err, &appErr) || appErr.Code != "staff_status_endpoint" {
+			t.Errorf("expected deactivating a %s by patch refused, got %v", role, err)
+		}
+		if err := svc.DeactivateUser(context.Background(), created.ID.String()); !errors.Is(err, utils.ErrInvalidInput) {
+			t.Errorf("expected deactivating a %s directly refused, got %v", role, err)
+		}
+		if user, _ := svc.GetUserByID(context.Background(), created.ID.String()); !user.IsActive {
+			t.Errorf("expected the %s still active", role)
+		}
+	}
+}
+
+func TestListUsersFiltersAndPages(t *testing.T) {
+	ctx := context.Background()
+	svc := newUserService(newFixture())
+
+	for _, name := range []string{"alice", "bobby", "carol"} {
+		if _, err := svc.CreatePatientUser(ctx, name, name+"@example.com", "password1", "Test", "User", ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := svc.CreateAdminUser(ctx, "admin", "admin@example.com", "password1", "Test", "Admin", "", "ADMIN"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	q, err := listquery.Parse(url.Values{"role": {"PATIENT"}, "sort": {"-username"}, "limit": {"2"}}, repository.UserList)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	page, err := svc.ListUsers(ctx, q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Items) != 2 || page.Items[0].Username != "carol" || page.Items[1].Username != "bobby" || !page.HasMore {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+
+	q, err = listquery.Parse(url.Values{"role": {"PATIENT"}, "sort": {"-username"}, "limit": {"2"}, "cursor": {page.NextCursor}}, repository.UserList)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	page, err = svc.ListUsers(ctx, q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].Username != "alice" || page.HasMore {
+		t.Fatalf("unexpected second page: %+v", page)
+	}
+}
+
+func newUserService(f *fixture) *service.UserService {
+	return service.NewUserService(f.users, f.tx)
+}
@@ -0,0 +1,101 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/falasefemi2/hms/internal/tracing"
+)
+
+func TestSetupDisabledStillPropagates(t *testing.T) {
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{Enabled: false})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	header := http.Header{"Traceparent": []string{traceparent}}
+
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+	out := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(out))
+
+	if got := out.Get("Traceparent"); got != traceparent {
+		t.Errorf("expected traceparent %q to round-trip, got %q", traceparent, got)
+	}
+}
+
+func TestStartCreatesChildSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, parent := tracing.Start(context.Background(), "AppointmentService.CreateAppointment")
+	_, child := tracing.Start(ctx, "SELECT")
+	child.End()
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Error("expected SELECT span to be a child of the service span")
+	}
+}
+
+func TestSetupExportsToEndpointURL(t *testing.T) {
+	paths := make(chan string, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case paths <- r.URL.Path:
+		default:
+		}
+	}))
+	defer collector.Close()
+
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+		Enabled:     true,
+		Endpoint:    collector.URL + "/otlp/",
+		ServiceName: "hms-test",
+		SampleRatio: 1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, span := tracing.Start(context.Background(), "test")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+
+	select {
+	case path := <-paths:
+		if path != "/otlp/v1/traces" {
+			t.Errorf("expected spans posted to /otlp/v1/traces, got %s", path)
+		}
+	default:
+		t.Fatal("expected spans exported on shutdown")
+	}
+}
+
+func TestSetupRejectsEndpointWithoutScheme(t *testing.T) {
+	if _, err := tracing.Setup(context.Background(), tracing.Config{Enabled: true, Endpoint: "localhost:4318"}); err == nil {
+		t.Fatal("expected a host:port endpoint to be rejected")
+	}
+}
@@ -1,18 +1,27 @@
 package utils
 
-import "context"
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
 
 type contextKey string
 
 const (
 	UserIDKey contextKey = "userID"
 	RoleKey   contextKey = "role"
+	// APIKeyIDKey holds a short hash of the X-API-Key, set only once the key
+	// has been checked against the configured keys.
+	APIKeyIDKey contextKey = "apiKeyID"
 )
 
-func GetUserIDFromContext(ctx context.Context) (string, error) {
-	userID, ok := ctx.Value(UserIDKey).(string)
-	if !ok || userID == "" {
-		return "", ErrMissingUserID
+// GetUserIDFromContext returns the ID of the authenticated user. JWTAuth
+// stores it as a uuid.UUID.
+func GetUserIDFromContext(ctx context.Context) (uuid.UUID, error) {
+	userID, ok := ctx.Value(UserIDKey).(uuid.UUID)
+	if !ok || userID == uuid.Nil {
+		return uuid.Nil, ErrMissingUserID
 	}
 	return userID, nil
 }
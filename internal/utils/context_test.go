@@ -0,0 +1,41 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+func TestGetUserIDFromContext(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name    string
+		value   any
+		want    uuid.UUID
+		wantErr error
+	}{
+		{"uuid as set by JWTAuth", id, id, nil},
+		{"missing", nil, uuid.Nil, utils.ErrMissingUserID},
+		{"nil uuid", uuid.Nil, uuid.Nil, utils.ErrMissingUserID},
+		{"string", id.String(), uuid.Nil, utils.ErrMissingUserID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.value != nil {
+				ctx = context.WithValue(ctx, utils.UserIDKey, tt.value)
+			}
+
+			got, err := utils.GetUserIDFromContext(ctx)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("expected %s, %v; got %s, %v", tt.want, tt.wantErr, got, err)
+			}
+		})
+	}
+}
@@ -18,4 +18,65 @@ var (
 	ErrInternal        = errors.New("internal server error")
 	ErrUserNotFound    = errors.New("user not found")
 	ErrUserExists      = errors.New("user already exists")
+	ErrPayloadTooLarge = errors.New("request body too large")
+	ErrUnsupportedType = errors.New("unsupported media type")
+
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrPreconditionRequired = errors.New("precondition required")
 )
+
+// AppError is a domain error returned by the services. Kind is one of the
+// sentinel errors above and decides the HTTP status, Code is a stable
+// identifier clients can rely on and Message is safe to show to the caller.
+type AppError struct {
+	Kind    error
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *AppError) Error() string {
+	return e.Message
+}
+
+func (e *AppError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+func NewNotFoundError(code, message string) error {
+	return &AppError{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+func NewConflictError(code, message string) error {
+	return &AppError{Kind: ErrConflict, Code: code, Message: message}
+}
+
+func NewValidationError(code, message string, fields ...FieldError) error {
+	return &AppError{Kind: ErrInvalidInput, Code: code, Message: message, Fields: fields}
+}
+
+func NewForbiddenError(code, message string) error {
+	return &AppError{Kind: ErrForbidden, Code: code, Message: message}
+}
+
+func NewPreconditionFailedError(code, message string) error {
+	return &AppError{Kind: ErrPreconditionFailed, Code: code, Message: message}
+}
+
+func NewPreconditionRequiredError(code, message string) error {
+	return &AppError{Kind: ErrPreconditionRequired, Code: code, Message: message}
+}
+
+func NewUnauthorizedError(code, message string) error {
+	return &AppError{Kind: ErrUnauthorized, Code: code, Message: message}
+}
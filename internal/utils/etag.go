@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ETag formats a row version as a strong entity tag, e.g. "3".
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// SetETag sets the ETag response header for a resource at version.
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", ETag(version))
+}
+
+// IfMatchVersion returns the row version named by the request's If-Match
+// header. A missing header is reported as ErrPreconditionRequired; a value
+// that cannot name a version of the resource (a weak tag, "*" or a list)
+// can never match and is reported as ErrPreconditionFailed.
+func IfMatchVersion(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" {
+		return 0, NewPreconditionRequiredError("if_match_required", "If-Match header with the resource ETag is required")
+	}
+
+	unquoted, ok := strings.CutPrefix(value, `"`)
+	if ok {
+		unquoted, ok = strings.CutSuffix(unquoted, `"`)
+	}
+	version, err := strconv.Atoi(unquoted)
+	if !ok || err != nil || version < 1 {
+		return 0, NewPreconditionFailedError("version_mismatch", "If-Match does not match the current ETag")
+	}
+
+	return version, nil
+}
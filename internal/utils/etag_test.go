@@ -0,0 +1,64 @@
+package utils_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		wantVersion int
+		wantErr     error
+	}{
+		{"strong tag", `"3"`, 3, nil},
+		{"surrounding space", ` "12" `, 12, nil},
+		{"missing", "", 0, utils.ErrPreconditionRequired},
+		{"weak tag", `W/"3"`, 0, utils.ErrPreconditionFailed},
+		{"unquoted", "3", 0, utils.ErrPreconditionFailed},
+		{"wildcard", "*", 0, utils.ErrPreconditionFailed},
+		{"not a version", `"abc"`, 0, utils.ErrPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/appointments/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			version, err := utils.IfMatchVersion(r)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil || version != tt.wantVersion {
+				t.Fatalf("expected version %d, got %d (%v)", tt.wantVersion, version, err)
+			}
+		})
+	}
+}
+
+func TestETagStatuses(t *testing.T) {
+	for err, want := range map[error]int{
+		utils.NewPreconditionFailedError("version_mismatch", "stale"):                   http.StatusPreconditionFailed,
+		&utils.AppError{Kind: utils.ErrPreconditionRequired, Code: "if_match_required"}: http.StatusPreconditionRequired,
+	} {
+		rec := httptest.NewRecorder()
+		utils.HandleServiceError(rec, httptest.NewRequest(http.MethodPut, "/", nil), err)
+		if rec.Code != want {
+			t.Errorf("expected %d for %v, got %d", want, err, rec.Code)
+		}
+	}
+
+	if got := utils.ETag(7); got != `"7"` {
+		t.Errorf(`expected "7", got %s`, got)
+	}
+}
@@ -2,11 +2,26 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 )
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+// MaxRequestBodyBytes caps the size of JSON request bodies read by DecodeJSON.
+var MaxRequestBodyBytes int64 = 1 << 20
+
+// Problem is an RFC 7807 problem details body. Code is a stable identifier
+// for the error and Errors lists per-field validation failures.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
 }
 
 func WriteJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -18,8 +33,119 @@ func WriteJSON(w http.ResponseWriter, status int, data interface{}) {
 	}
 }
 
+// DecodeJSON decodes the request body into dst and validates it against its
+// validate tags. Unknown fields, trailing data and bodies larger than
+// MaxRequestBodyBytes are rejected. The returned error is suitable for
+// HandleServiceError.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxRequestBodyBytes)
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return decodeError(err)
+		}
+		return NewValidationError("invalid_json", "request body must contain a single JSON object")
+	}
+
+	return Validate(dst)
+}
+
+func decodeError(err error) error {
+	var (
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		maxBytesErr *http.MaxBytesError
+	)
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return &AppError{
+			Kind:    ErrPayloadTooLarge,
+			Code:    "request_too_large",
+			Message: fmt.Sprintf("request body must not exceed %d bytes", maxBytesErr.Limit),
+			Err:     err,
+		}
+
+	case errors.Is(err, io.EOF):
+		return NewValidationError("invalid_json", "request body is required")
+
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return NewValidationError("invalid_json", "request body contains malformed JSON")
+
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			return NewValidationError("invalid_json", "request body must be a JSON object")
+		}
+		return NewValidationError("validation_failed", "request validation failed", FieldError{
+			Field:   field,
+			Message: fmt.Sprintf("%s must be of type %s", field, typeErr.Type),
+		})
+
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return NewValidationError("unknown_field", "request body contains an unknown field", FieldError{
+			Field:   field,
+			Message: field + " is not a recognised field",
+		})
+	}
+
+	return NewValidationError("invalid_json", "request body could not be decoded")
+}
+
+func WriteProblem(w http.ResponseWriter, problem Problem) {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
 func WriteError(w http.ResponseWriter, status int, message string) {
-	WriteJSON(w, status, ErrorResponse{
-		Error: message,
+	WriteProblem(w, Problem{
+		Status: status,
+		Detail: message,
+		Code:   codeForStatus(status),
 	})
 }
+
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusPreconditionFailed:
+		return "precondition_failed"
+	case http.StatusRequestEntityTooLarge:
+		return "request_too_large"
+	case http.StatusUnsupportedMediaType:
+		return "unsupported_media_type"
+	case http.StatusPreconditionRequired:
+		return "precondition_required"
+	case http.StatusTooManyRequests:
+		return "too_many_requests"
+	case http.StatusInternalServerError:
+		return "internal_error"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+)
+
+// MergePatchContentType is the media type of a JSON Merge Patch (RFC 7396).
+const MergePatchContentType = "application/merge-patch+json"
+
+// DecodeMergePatch applies the JSON Merge Patch in the request body to dst.
+// dst must already hold the current representation of the resource; members
+// present in the patch replace it, null members reset it to its zero value
+// and absent members are left alone. The patched value is decoded with the
+// same rules as DecodeJSON and validated against its validate tags.
+//
+// Both application/merge-patch+json and application/json are accepted.
+func DecodeMergePatch(w http.ResponseWriter, r *http.Request, dst any) error {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mediaType != MergePatchContentType && mediaType != "application/json") {
+			return &AppError{
+				Kind:    ErrUnsupportedType,
+				Code:    "unsupported_media_type",
+				Message: "Content-Type must be " + MergePatchContentType,
+			}
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxRequestBodyBytes)
+
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+
+	var patch any
+	if err := dec.Decode(&patch); err != nil {
+		return decodeError(err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return decodeError(err)
+		}
+		return NewValidationError("invalid_json", "request body must contain a single JSON object")
+	}
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return NewValidationError("invalid_json", "merge patch must be a JSON object")
+	}
+
+	current, err := json.Marshal(dst)
+	if err != nil {
+		return err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(current, &doc); err != nil {
+		return err
+	}
+
+	patched, err := json.Marshal(mergePatch(doc, patchObj))
+	if err != nil {
+		return err
+	}
+
+	// Start from the zero value so members removed by the patch are reset
+	// rather than keeping their current value.
+	reflect.ValueOf(dst).Elem().SetZero()
+
+	out := json.NewDecoder(bytes.NewReader(patched))
+	out.DisallowUnknownFields()
+	if err := out.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+
+	return Validate(dst)
+}
+
+// mergePatch applies patch to target as described in RFC 7396.
+func mergePatch(target, patch map[string]any) map[string]any {
+	if target == nil {
+		target = make(map[string]any)
+	}
+	for key, value := range patch {
+		switch value := value.(type) {
+		case nil:
+			delete(target, key)
+		case map[string]any:
+			existing, _ := target[key].(map[string]any)
+			target[key] = mergePatch(existing, value)
+		default:
+			target[key] = value
+		}
+	}
+	return target
+}
@@ -0,0 +1,83 @@
+package utils_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+type patchTarget struct {
+	Name    string            `json:"name" validate:"required"`
+	Notes   *string           `json:"notes,omitempty"`
+	Count   int               `json:"count" validate:"gte=0"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+func patchRequest(body, contentType string) *http.Request {
+	r := httptest.NewRequest(http.MethodPatch, "/things/1", strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+func TestDecodeMergePatch(t *testing.T) {
+	notes := "keep"
+	dst := patchTarget{
+		Name:    "before",
+		Notes:   &notes,
+		Count:   2,
+		Details: map[string]string{"a": "1", "b": "2"},
+	}
+
+	body := `{"count": 5, "notes": null, "details": {"b": null, "c": "3"}}`
+	err := utils.DecodeMergePatch(httptest.NewRecorder(), patchRequest(body, utils.MergePatchContentType), &dst)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if dst.Name != "before" {
+		t.Errorf("absent member changed: name = %q", dst.Name)
+	}
+	if dst.Count != 5 {
+		t.Errorf("count = %d, want 5", dst.Count)
+	}
+	if dst.Notes != nil {
+		t.Errorf("null member not removed: notes = %q", *dst.Notes)
+	}
+	if len(dst.Details) != 2 || dst.Details["a"] != "1" || dst.Details["c"] != "3" {
+		t.Errorf("nested merge wrong: %v", dst.Details)
+	}
+}
+
+func TestDecodeMergePatchErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		wantKind    error
+		wantCode    string
+	}{
+		{"not an object", `[1]`, "", utils.ErrInvalidInput, "invalid_json"},
+		{"unknown field", `{"colour": "red"}`, "", utils.ErrInvalidInput, "unknown_field"},
+		{"wrong type", `{"count": "five"}`, "", utils.ErrInvalidInput, "validation_failed"},
+		{"fails validation", `{"name": null}`, "", utils.ErrInvalidInput, "validation_failed"},
+		{"unsupported media type", `{}`, "text/plain", utils.ErrUnsupportedType, "unsupported_media_type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := patchTarget{Name: "before"}
+			err := utils.DecodeMergePatch(httptest.NewRecorder(), patchRequest(tt.body, tt.contentType), &dst)
+
+			var appErr *utils.AppError
+			if !errors.Is(err, tt.wantKind) || !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+				t.Errorf("got %v, want %v with code %s", err, tt.wantKind, tt.wantCode)
+			}
+		})
+	}
+}
@@ -3,35 +3,84 @@ package utils
 import (
 	"errors"
 	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/falasefemi2/hms/internal/logging"
 )
 
-func HandleServiceError(w http.ResponseWriter, err error) {
+// HandleServiceError writes err as a problem+json response. AppErrors keep
+// their code, message and field details; any error that does not map to a
+// known kind is logged and reported as a generic internal error.
+func HandleServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	status := statusForError(err)
+
+	problem := Problem{
+		Status:   status,
+		Code:     codeForStatus(status),
+		Detail:   err.Error(),
+		Instance: r.URL.Path,
+	}
+
+	var appErr *AppError
 	switch {
-	case errors.Is(err, ErrUnauthorized):
-		WriteError(w, http.StatusUnauthorized, ErrUnauthorized.Error())
+	case errors.As(err, &appErr):
+		problem.Code = appErr.Code
+		problem.Detail = appErr.Message
+		problem.Errors = appErr.Fields
+	case status == http.StatusBadRequest:
+		problem.Code = "validation_failed"
+	case status == http.StatusInternalServerError:
+		logging.FromContext(r.Context()).ErrorContext(r.Context(), "unhandled service error", "error", err)
+		span := trace.SpanFromContext(r.Context())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		problem.Detail = ErrInternal.Error()
+	}
 
-	case errors.Is(err, ErrForbidden):
-		WriteError(w, http.StatusForbidden, ErrForbidden.Error())
+	WriteProblem(w, problem)
+}
 
-	case errors.Is(err, ErrInvalidToken),
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, ErrUnauthorized),
+		errors.Is(err, ErrInvalidToken),
 		errors.Is(err, ErrExpiredToken):
-		WriteError(w, http.StatusUnauthorized, err.Error())
+		return http.StatusUnauthorized
+
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
 
 	case errors.Is(err, ErrMissingUserID),
 		errors.Is(err, ErrMissingUserRole),
 		errors.Is(err, ErrInvalidInput),
 		errors.Is(err, ErrWeakPassword),
 		errors.Is(err, ErrInvalidEmail):
-		WriteError(w, http.StatusBadRequest, err.Error())
+		return http.StatusBadRequest
 
 	case errors.Is(err, ErrAlreadyExists),
-		errors.Is(err, ErrConflict):
-		WriteError(w, http.StatusConflict, err.Error())
+		errors.Is(err, ErrConflict),
+		errors.Is(err, ErrUserExists):
+		return http.StatusConflict
+
+	case errors.Is(err, ErrNotFound),
+		errors.Is(err, ErrUserNotFound):
+		return http.StatusNotFound
+
+	case errors.Is(err, ErrPayloadTooLarge):
+		return http.StatusRequestEntityTooLarge
+
+	case errors.Is(err, ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+
+	case errors.Is(err, ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
 
-	case errors.Is(err, ErrNotFound):
-		WriteError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrPreconditionRequired):
+		return http.StatusPreconditionRequired
 
 	default:
-		WriteError(w, http.StatusInternalServerError, ErrInternal.Error())
+		return http.StatusInternalServerError
 	}
 }
@@ -0,0 +1,97 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+func TestHandleServiceError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+		wantFields int
+	}{
+		{
+			name:       "wrapped not found",
+			err:        fmt.Errorf("failed to get appointment: %w", utils.NewNotFoundError("appointment_not_found", "appointment not found")),
+			wantStatus: http.StatusNotFound,
+			wantCode:   "appointment_not_found",
+			wantDetail: "appointment not found",
+		},
+		{
+			name:       "validation with fields",
+			err:        utils.NewValidationError("validation_failed", "name cannot be empty", utils.FieldError{Field: "name", Message: "name cannot be empty"}),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "validation_failed",
+			wantDetail: "name cannot be empty",
+			wantFields: 1,
+		},
+		{
+			name:       "conflict",
+			err:        utils.NewConflictError("consultation_locked", "consultation is not editable"),
+			wantStatus: http.StatusConflict,
+			wantCode:   "consultation_locked",
+			wantDetail: "consultation is not editable",
+		},
+		{
+			name:       "forbidden",
+			err:        utils.NewForbiddenError("account_deactivated", "account is deactivated"),
+			wantStatus: http.StatusForbidden,
+			wantCode:   "account_deactivated",
+			wantDetail: "account is deactivated",
+		},
+		{
+			name:       "sentinel",
+			err:        fmt.Errorf("validation error: %w", utils.ErrWeakPassword),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "validation_failed",
+			wantDetail: "validation error: password must be at least 8 characters",
+		},
+		{
+			name:       "unknown error is not leaked",
+			err:        errors.New(`ERROR: relation "users" does not exist (SQLSTATE 42P01)`),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "internal_error",
+			wantDetail: "internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/appointments/123", nil)
+
+			utils.HandleServiceError(rec, req, tt.err)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("unexpected content type %q", ct)
+			}
+
+			var problem utils.Problem
+			if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+				t.Fatalf("decode problem: %v", err)
+			}
+			if problem.Status != tt.wantStatus || problem.Code != tt.wantCode || problem.Detail != tt.wantDetail {
+				t.Errorf("unexpected problem: %+v", problem)
+			}
+			if problem.Instance != "/appointments/123" || problem.Type != "about:blank" || problem.Title == "" {
+				t.Errorf("missing problem members: %+v", problem)
+			}
+			if len(problem.Errors) != tt.wantFields {
+				t.Errorf("expected %d field errors, got %d", tt.wantFields, len(problem.Errors))
+			}
+		})
+	}
+}
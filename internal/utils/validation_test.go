@@ -0,0 +1,126 @@
+package utils_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/falasefemi2/hms/internal/utils"
+)
+
+type testRequest struct {
+	Name  string `json:"name" validate:"required,min=2"`
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"omitempty,oneof=DOCTOR NURSE"`
+	Age   int    `json:"age" validate:"min=0"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantKind   error
+		wantCode   string
+		wantFields []string
+	}{
+		{
+			name: "valid",
+			body: `{"name":"Ada","email":"ada@example.com","role":"DOCTOR"}`,
+		},
+		{
+			name:       "all field errors reported",
+			body:       `{"name":"A","email":"not-an-email","role":"PATIENT"}`,
+			wantKind:   utils.ErrInvalidInput,
+			wantCode:   "validation_failed",
+			wantFields: []string{"name", "email", "role"},
+		},
+		{
+			name:       "unknown field",
+			body:       `{"name":"Ada","email":"ada@example.com","is_admin":true}`,
+			wantKind:   utils.ErrInvalidInput,
+			wantCode:   "unknown_field",
+			wantFields: []string{"is_admin"},
+		},
+		{
+			name:       "wrong type",
+			body:       `{"name":"Ada","email":"ada@example.com","age":"old"}`,
+			wantKind:   utils.ErrInvalidInput,
+			wantCode:   "validation_failed",
+			wantFields: []string{"age"},
+		},
+		{
+			name:     "empty body",
+			body:     ``,
+			wantKind: utils.ErrInvalidInput,
+			wantCode: "invalid_json",
+		},
+		{
+			name:     "malformed",
+			body:     `{"name":`,
+			wantKind: utils.ErrInvalidInput,
+			wantCode: "invalid_json",
+		},
+		{
+			name:     "trailing data",
+			body:     `{"name":"Ada","email":"ada@example.com"}{}`,
+			wantKind: utils.ErrInvalidInput,
+			wantCode: "invalid_json",
+		},
+		{
+			name:     "too large",
+			body:     `{"name":"` + strings.Repeat("a", int(utils.MaxRequestBodyBytes)) + `"}`,
+			wantKind: utils.ErrPayloadTooLarge,
+			wantCode: "request_too_large",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.body))
+
+			var dst testRequest
+			err := utils.DecodeJSON(rec, req, &dst)
+
+			if tt.wantKind == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("expected %v, got %v", tt.wantKind, err)
+			}
+
+			var appErr *utils.AppError
+			if !errors.As(err, &appErr) {
+				t.Fatalf("expected an AppError, got %T", err)
+			}
+			if appErr.Code != tt.wantCode {
+				t.Errorf("expected code %q, got %q", tt.wantCode, appErr.Code)
+			}
+			if len(appErr.Fields) != len(tt.wantFields) {
+				t.Fatalf("expected fields %v, got %+v", tt.wantFields, appErr.Fields)
+			}
+			for i, field := range tt.wantFields {
+				if appErr.Fields[i].Field != field {
+					t.Errorf("expected field %q at %d, got %q", field, i, appErr.Fields[i].Field)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleServiceErrorPayloadTooLarge(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(strings.Repeat(" ", int(utils.MaxRequestBodyBytes)+1)))
+
+	var dst testRequest
+	utils.HandleServiceError(rec, req, utils.DecodeJSON(rec, req, &dst))
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status 413, got %d", rec.Code)
+	}
+}